@@ -5,6 +5,15 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
 	"github.com/locktivity/epack-collector-github/internal/collector"
 	"github.com/locktivity/epack/componentsdk"
 )
@@ -16,6 +25,60 @@ var (
 )
 
 func main() {
+	// --describe-config prints the accepted config keys and secrets as JSON
+	// (for the epack UI and config tooling) without running a collection.
+	if slices.Contains(os.Args[1:], "--describe-config") {
+		if err := describeConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "describe-config: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	// --print-mappings prints the compliance control mapping table for the
+	// emitted metrics.
+	if slices.Contains(os.Args[1:], "--print-mappings") {
+		if err := printMappings(); err != nil {
+			fmt.Fprintf(os.Stderr, "print-mappings: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// --doctor checks the configured credentials, permissions, and
+	// connectivity without collecting, exiting 1 when a check fails. Config
+	// and secrets are read as the runner passes them: EPACK_COLLECTOR_CONFIG
+	// and the environment.
+	if slices.Contains(os.Args[1:], "--doctor") {
+		failed, err := doctor()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "doctor: %v\n", err)
+			os.Exit(1)
+		}
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// --compare BASELINE CURRENT diffs two github.json artifacts, exiting 2
+	// when posture regressed, for gating CI without the epack pipeline.
+	if i := slices.Index(os.Args[1:], "--compare"); i >= 0 {
+		files := os.Args[i+2:]
+		if len(files) != 2 {
+			fmt.Fprintln(os.Stderr, "compare: usage: --compare BASELINE.json CURRENT.json")
+			os.Exit(1)
+		}
+		regressed, err := compare(files[0], files[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "compare: %v\n", err)
+			os.Exit(1)
+		}
+		if regressed {
+			os.Exit(2)
+		}
+		return
+	}
+
 	componentsdk.RunCollector(componentsdk.CollectorSpec{
 		Name:        "github",
 		Version:     Version,
@@ -25,29 +88,36 @@ func main() {
 }
 
 func run(ctx componentsdk.CollectorContext) error {
-	// Build config from SDK context
-	cfg := ctx.Config()
-	config := collector.Config{
-		Organization:    getString(cfg, "organization"),
-		GitHubToken:     ctx.Secret("GITHUB_TOKEN"),
-		AppID:           getInt64(cfg, "app_id"),
-		InstallationID:  getInt64(cfg, "installation_id"),
-		PrivateKey:      ctx.Secret("GITHUB_APP_PRIVATE_KEY"),
-		IncludePatterns: getStringSlice(cfg, "include_patterns"),
-		ExcludePatterns: getStringSlice(cfg, "exclude_patterns"),
-		OnStatus:        ctx.Status,
-		OnProgress:      ctx.Progress,
+	// Build config from SDK context, with any profile's settings filled in
+	config, err := collector.ConfigFromMap(ctx.Config(), ctx.Secret)
+	if err != nil {
+		return componentsdk.NewConfigError("%v", err)
 	}
+	config.OnStatus = ctx.Status
+	config.OnProgress = ctx.Progress
 
 	if config.Organization == "" {
 		return componentsdk.NewConfigError("organization is required")
 	}
 
+	// Read credentials kept in a secret manager before checking for them.
+	if err := collector.LoadStoredSecrets(ctx.Context(), &config); err != nil {
+		return componentsdk.NewAuthError("loading secrets: %v", err)
+	}
+
 	// Check for valid auth configuration
 	hasAppAuth := config.AppID != 0 && config.PrivateKey != ""
-	hasTokenAuth := config.GitHubToken != ""
+	hasTokenAuth := config.GitHubToken != "" || len(config.TokenCommand) > 0
 	if !hasAppAuth && !hasTokenAuth {
-		return componentsdk.NewConfigError("authentication required: provide GITHUB_TOKEN or app_id + GITHUB_APP_PRIVATE_KEY")
+		return componentsdk.NewConfigError("authentication required: provide GITHUB_TOKEN, token_command, or app_id + GITHUB_APP_PRIVATE_KEY (or their secret_store references)")
+	}
+
+	var signer *collector.Signer
+	if config.SigningKey != "" {
+		var err error
+		if signer, err = collector.NewSigner(config.SigningKey); err != nil {
+			return componentsdk.NewConfigError("%v", err)
+		}
 	}
 
 	// Create collector and collect posture
@@ -55,19 +125,53 @@ func run(ctx componentsdk.CollectorContext) error {
 	if err != nil {
 		return componentsdk.NewConfigError("creating collector: %v", err)
 	}
+	// The state lock Collect takes is held until the notification baseline
+	// and remediation tickets are written.
+	defer func() { _ = c.Close() }()
 	posture, err := c.Collect(ctx.Context(), ctx.Level())
+	if errors.Is(err, collector.ErrTooSoon) {
+		return componentsdk.NewConfigError("%v", err)
+	}
+	if errors.Is(err, collector.ErrDegraded) {
+		return componentsdk.NewAuthError("collecting posture: %v", err)
+	}
 	if err != nil {
 		return componentsdk.NewNetworkError("collecting posture: %v", err)
 	}
 
+	// The optional notification, incidents, and remediation tickets name
+	// repositories in the clear. The artifacts matter more, so a failure
+	// here only warns.
+	if err := c.Notify(ctx.Context(), posture); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	if err := c.FileRemediation(ctx.Context(), posture); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	// Everything from here on is emitted, so redact_repo_names applies.
+	c.Redact(posture)
+
+	// Inside GitHub Actions, also surface the scores in the job summary and
+	// as step outputs; likewise only a warning on failure.
+	if err := collector.WriteActionsSummary(posture, os.Getenv); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+
 	// Transform to normalized vcs-posture format
 	normalized := posture.ToVCSPosture()
 
+	// output_fields trims only the detailed artifact; the normalized one
+	// keeps its schema.
+	detailed, err := collector.ProjectFields(posture, config.OutputFields)
+	if err != nil {
+		return componentsdk.NewConfigError("applying output_fields: %v", err)
+	}
+
 	// Emit both detailed and normalized artifacts
-	return ctx.Emit([]componentsdk.CollectedArtifact{
+	artifacts := []componentsdk.CollectedArtifact{
 		{
 			// Detailed GitHub-specific output
-			Data: posture,
+			Data: detailed,
 			Path: "artifacts/github.json",
 		},
 		{
@@ -76,49 +180,149 @@ func run(ctx componentsdk.CollectorContext) error {
 			Schema: "evidencepack/vcs-posture@v1",
 			Path:   "artifacts/github.vcs-posture.json",
 		},
-	})
+	}
+	// The inventory follows output_fields, so a dropped section stays out.
+	inventory, err := collector.ProjectedInventory(posture, config.OutputFields)
+	if err != nil {
+		return componentsdk.NewConfigError("applying output_fields to the inventory: %v", err)
+	}
+	if inventory != nil {
+		// Per-repository facts, typed apart from the aggregate posture
+		artifacts = append(artifacts, componentsdk.CollectedArtifact{
+			Data:   inventory,
+			Schema: collector.InventorySchema,
+			Path:   "artifacts/github.inventory.json",
+		})
+	}
+	if config.OCSFOutput {
+		// Policy evaluation results as OCSF events for SIEMs and lakes
+		artifacts = append(artifacts, componentsdk.CollectedArtifact{
+			Data: posture.ToOCSFFindings(Version),
+			Path: "artifacts/github.ocsf.json",
+		})
+	}
+	if signer != nil {
+		if artifacts, err = attest(signer, artifacts); err != nil {
+			return err
+		}
+	}
+	// The detailed artifact grows most with the org, and the typed ones
+	// must stay readable as their schema; compress it after signing, so
+	// what decompresses is exactly the signed bytes and the attestation
+	// subject keeps the uncompressed path.
+	data, compressed, err := collector.CompressArtifact(artifacts[0].Path, artifacts[0].Data, config.CompressOutputOverBytes)
+	if err != nil {
+		return err
+	}
+	if compressed {
+		artifacts[0].Data = data
+		artifacts[0].Path = collector.CompressedPath(artifacts[0].Path)
+	}
+	return ctx.Emit(artifacts)
 }
 
-// getString safely extracts a string from config map
-func getString(cfg map[string]any, key string) string {
-	if cfg == nil {
-		return ""
+// attest signs each artifact, replacing its data with the exact bytes
+// signed, and appends the attestation artifact.
+func attest(signer *collector.Signer, artifacts []componentsdk.CollectedArtifact) ([]componentsdk.CollectedArtifact, error) {
+	subjects := make([]collector.AttestedArtifact, 0, len(artifacts))
+	for i, a := range artifacts {
+		raw, subject, err := signer.Sign(a.Path, a.Data)
+		if err != nil {
+			return nil, err
+		}
+		artifacts[i].Data = raw
+		subjects = append(subjects, subject)
+	}
+	return append(artifacts, componentsdk.CollectedArtifact{
+		Data: signer.Attestation(subjects...),
+		Path: "artifacts/github.attestation.json",
+	}), nil
+}
+
+// describeConfig writes the collector's config description to stdout.
+func describeConfig() error {
+	desc, err := collector.DescribeConfig()
+	if err != nil {
+		return err
 	}
-	if v, ok := cfg[key].(string); ok {
-		return v
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(desc)
+}
+
+// printMappings writes the control mapping table to stdout.
+func printMappings() error {
+	table, err := collector.ControlMappings()
+	if err != nil {
+		return err
 	}
-	return ""
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(table)
 }
 
-// getInt64 safely extracts an int64 from config map
-func getInt64(cfg map[string]any, key string) int64 {
-	if cfg == nil {
-		return 0
+// compare writes the comparison of two github.json artifacts to stdout and
+// reports whether posture regressed.
+func compare(baselinePath, currentPath string) (bool, error) {
+	load := func(path string) (*collector.OrgPosture, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		posture, err := collector.LoadPosture(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return posture, nil
+	}
+	baseline, err := load(baselinePath)
+	if err != nil {
+		return false, err
 	}
-	switch v := cfg[key].(type) {
-	case int64:
-		return v
-	case int:
-		return int64(v)
-	case float64:
-		return int64(v)
+	current, err := load(currentPath)
+	if err != nil {
+		return false, err
+	}
+	cmp, err := collector.Compare(baseline, current)
+	if err != nil {
+		return false, err
 	}
-	return 0
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return cmp.Regressed, enc.Encode(cmp)
 }
 
-// getStringSlice safely extracts a string slice from config map
-func getStringSlice(cfg map[string]any, key string) []string {
-	if cfg == nil {
-		return nil
-	}
-	if v, ok := cfg[key].([]any); ok {
-		result := make([]string, 0, len(v))
-		for _, item := range v {
-			if s, ok := item.(string); ok {
-				result = append(result, s)
-			}
+// doctor writes the diagnostic report for the collector's configured
+// account to stdout and reports whether any check failed.
+func doctor() (bool, error) {
+	raw := map[string]any{}
+	if path := os.Getenv("EPACK_COLLECTOR_CONFIG"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return false, err
+		}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return false, fmt.Errorf("%s: %w", path, err)
 		}
-		return result
 	}
-	return nil
+	config, err := collector.ConfigFromMap(raw, os.Getenv)
+	if err != nil {
+		return false, err
+	}
+	if config.Organization == "" {
+		return false, errors.New("organization is required")
+	}
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err := collector.LoadStoredSecrets(ctx, &config); err != nil {
+		return false, fmt.Errorf("loading secrets: %w", err)
+	}
+	c, err := collector.New(config)
+	if err != nil {
+		return false, err
+	}
+	report := c.Doctor(ctx)
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return report.Status == collector.DoctorFail, enc.Encode(report)
 }
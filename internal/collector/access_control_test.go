@@ -2,6 +2,7 @@ package collector
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	"github.com/locktivity/epack-collector-github/internal/github"
@@ -91,3 +92,106 @@ func indexOf(s, sub string) int {
 	}
 	return -1
 }
+
+func TestAccessControl_SecurityManagers(t *testing.T) {
+	mock := newAccessControlMock()
+	mock.securityManagers = []string{"sec-ops", "appsec"}
+
+	c := NewWithClient(Config{Organization: "test-org"}, mock)
+
+	posture, err := c.Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+
+	ac := posture.AccessControl
+	if ac.SecurityManagersConfigured == nil || !*ac.SecurityManagersConfigured {
+		t.Errorf("SecurityManagersConfigured = %v, want true", ac.SecurityManagersConfigured)
+	}
+	if len(ac.SecurityManagerTeams) != 2 || ac.SecurityManagerTeams[0] != "appsec" {
+		t.Errorf("SecurityManagerTeams = %v, want [appsec sec-ops]", ac.SecurityManagerTeams)
+	}
+}
+
+func TestAccessControl_SecurityManagersNoneAndUnavailable(t *testing.T) {
+	mock := newAccessControlMock()
+	c := NewWithClient(Config{Organization: "test-org"}, mock)
+
+	posture, err := c.Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if got := posture.AccessControl.SecurityManagersConfigured; got == nil || *got {
+		t.Errorf("SecurityManagersConfigured = %v, want false", got)
+	}
+
+	mock.securityManagersErr = github.ErrNotFound
+	posture, err = c.Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if posture.AccessControl.SecurityManagersConfigured != nil {
+		t.Error("SecurityManagersConfigured should be nil when the role is unavailable")
+	}
+	if posture.Diagnostics == nil || !anyContains(posture.Diagnostics.Warnings, "access_control.security_managers") {
+		t.Errorf("expected a security_managers warning, got %+v", posture.Diagnostics)
+	}
+}
+
+func TestAccessControl_CustomRoles(t *testing.T) {
+	mock := newAccessControlMock()
+	mock.customOrgRoles = []github.CustomRole{
+		{Name: "security-auditor", Permissions: []string{"read_audit_logs"}},
+		{Name: "ci-admin", Permissions: []string{"write_organization_actions_secrets", "read_audit_logs"}},
+	}
+	mock.customRepoRoles = []github.CustomRole{
+		{Name: "release-manager", BaseRole: "maintain", Permissions: []string{"edit_repo_protections", "bypass_branch_protection"}},
+	}
+
+	c := NewWithClient(Config{Organization: "test-org"}, mock)
+
+	posture, err := c.Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+
+	cr := posture.AccessControl.CustomRoles
+	if cr == nil {
+		t.Fatal("CustomRoles = nil, want populated")
+	}
+	if cr.OrganizationRoles != 2 || cr.RepositoryRoles != 1 || cr.RiskyRoles != 2 || !cr.RiskyPermissionsGranted {
+		t.Errorf("CustomRoles = %+v, want 2 org, 1 repo, 2 risky", cr)
+	}
+	if len(cr.Risky) != 2 || cr.Risky[0].Name != "ci-admin" ||
+		!slices.Equal(cr.Risky[1].Permissions, []string{"bypass_branch_protection", "edit_repo_protections"}) {
+		t.Errorf("Risky = %+v", cr.Risky)
+	}
+}
+
+func TestAccessControl_CustomRolesUnavailableAndDenied(t *testing.T) {
+	mock := newAccessControlMock()
+	mock.customOrgRolesErr = github.ErrNotFound
+	mock.customRepoRolesErr = github.ErrFeatureUnavailable
+
+	c := NewWithClient(Config{Organization: "test-org"}, mock)
+
+	posture, err := c.Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if cr := posture.AccessControl.CustomRoles; cr == nil || cr.OrganizationRoles != 0 || cr.RiskyPermissionsGranted {
+		t.Errorf("CustomRoles = %+v, want zero counts when the plan lacks custom roles", cr)
+	}
+
+	mock.customRepoRolesErr = github.ErrPermissionDenied
+	posture, err = c.Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if posture.AccessControl.CustomRoles != nil {
+		t.Error("CustomRoles should be omitted when a role list is denied")
+	}
+	if posture.Diagnostics == nil || !anyContains(posture.Diagnostics.PermissionErrors, "organization_custom_roles:read") {
+		t.Errorf("expected a custom_roles permission diagnostic, got %+v", posture.Diagnostics)
+	}
+}
@@ -0,0 +1,70 @@
+package collector
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// collectActionsSecurity gathers org-level Actions enforcement and, when
+// configured, the workflow credential scan (audit+).
+func (c *Collector) collectActionsSecurity(p *collectionPass) {
+	as := &ActionsSecurity{}
+	if p.rulesetsRead {
+		as.RequiredWorkflows = requiredWorkflows(p)
+	}
+	if c.config.ScanWorkflowCredentials {
+		as.HardcodedCredentials = c.hardcodedCredentials(p)
+	}
+	if as.RequiredWorkflows != nil || as.HardcodedCredentials != nil {
+		p.posture.ActionsSecurity = as
+	}
+}
+
+// requiredWorkflows reports the workflows org rulesets require and the
+// in-scope repositories whose default branch must pass at least one. Audit
+// emits the workflows and counts; internal adds the repositories not covered.
+func requiredWorkflows(p *collectionPass) *RequiredWorkflows {
+	rw := &RequiredWorkflows{}
+	var requiring []github.Ruleset
+	for _, rs := range p.rulesets {
+		if rs.Enforcement == github.RulesetEnforcementDisabled {
+			continue
+		}
+		required := false
+		for _, rule := range rs.Rules {
+			for _, wf := range rule.Workflows {
+				required = true
+				row := RequiredWorkflowRow{
+					Ruleset:      rs.Name,
+					Enforcement:  rs.Enforcement,
+					Path:         wf.Path,
+					RepositoryID: wf.RepositoryID,
+					Ref:          wf.Ref,
+				}
+				for repo := range p.metrics.repos.all() {
+					if matchRuleset(rs, repo) == rulesetApplies {
+						row.AppliesToRepos++
+					}
+				}
+				rw.Workflows = append(rw.Workflows, row)
+			}
+		}
+		if required {
+			requiring = append(requiring, rs)
+		}
+	}
+	slices.SortFunc(rw.Workflows, func(a, b RequiredWorkflowRow) int {
+		return cmp.Or(cmp.Compare(a.Ruleset, b.Ruleset), cmp.Compare(a.Path, b.Path))
+	})
+
+	cov := coverageOf(p, requiring)
+	rw.TotalRepos = cov.TotalRepos
+	rw.CoveredRepos = cov.EnforcedRepos
+	rw.EvaluateOnlyRepos = cov.EvaluateOnlyRepos
+	rw.IndeterminateRepos = cov.IndeterminateRepos
+	rw.Coverage = cov.Coverage
+	rw.UncoveredRepos = cov.uncovered
+	return rw
+}
@@ -0,0 +1,123 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// actionsOutputs are the step outputs set when running inside GitHub
+// Actions, in order.
+func actionsOutputs(p *OrgPosture) [][2]string {
+	permissionErrors := 0
+	if p.Diagnostics != nil {
+		permissionErrors = len(p.Diagnostics.PermissionErrors)
+	}
+	sf := p.SecurityFeatures
+	return [][2]string{
+		{"branch_protection_coverage", strconv.Itoa(p.Posture.BranchProtectionCoverage)},
+		{"security_features_coverage", strconv.Itoa(p.Posture.SecurityFeaturesCoverage)},
+		{"vulnerability_alerts", strconv.Itoa(sf.VulnerabilityAlerts)},
+		{"code_scanning", strconv.Itoa(sf.CodeScanning)},
+		{"secret_scanning", strconv.Itoa(sf.SecretScanning)},
+		{"secret_scanning_push_protection", strconv.Itoa(sf.SecretScanningPushProtection)},
+		{"dependabot_security_updates", strconv.Itoa(sf.DependabotSecurityUpdates)},
+		{"skipped_repository_count", strconv.Itoa(p.Scope.SkippedRepositoryCount)},
+		{"permission_errors", strconv.Itoa(permissionErrors)},
+	}
+}
+
+// actionsSummary renders the Markdown job summary: the headline scores, the
+// per-rule and per-feature coverage, and any diagnostics.
+func actionsSummary(p *OrgPosture) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## GitHub posture: %s\n\n", p.Organization)
+	fmt.Fprintf(&b, "Collected %s at `%s` level.\n\n", p.CollectedAt, p.CollectedAtLevel)
+
+	table := func(title string, rows [][2]string) {
+		fmt.Fprintf(&b, "### %s\n\n| Metric | Coverage |\n|---|---:|\n", title)
+		for _, r := range rows {
+			fmt.Fprintf(&b, "| %s | %s%% |\n", r[0], r[1])
+		}
+		b.WriteString("\n")
+	}
+	pct := strconv.Itoa
+	table("Posture", [][2]string{
+		{"Branch protection", pct(p.Posture.BranchProtectionCoverage)},
+		{"Security features", pct(p.Posture.SecurityFeaturesCoverage)},
+		{"Repositories in scope", pct(p.Scope.RepositoriesCoverage)},
+	})
+	bp := p.BranchProtectionRules
+	table("Branch protection rules", [][2]string{
+		{"Pull request required", pct(bp.PullRequestRequired)},
+		{"Approving reviews", pct(bp.ApprovingReviews)},
+		{"Code owner reviews", pct(bp.CodeOwnerReviews)},
+		{"Status checks", pct(bp.StatusChecks)},
+		{"Signed commits", pct(bp.SignedCommits)},
+		{"Admin enforcement", pct(bp.AdminEnforcement)},
+		{"Force pushes allowed", pct(bp.ForcePushesAllowed)},
+		{"Deletions allowed", pct(bp.DeletionsAllowed)},
+	})
+	sf := p.SecurityFeatures
+	table("Security features", [][2]string{
+		{"Vulnerability alerts", pct(sf.VulnerabilityAlerts)},
+		{"Code scanning", pct(sf.CodeScanning)},
+		{"Secret scanning", pct(sf.SecretScanning)},
+		{"Push protection", pct(sf.SecretScanningPushProtection)},
+		{"Dependabot security updates", pct(sf.DependabotSecurityUpdates)},
+	})
+
+	if d := p.Diagnostics; d != nil && len(d.PermissionErrors)+len(d.Warnings) > 0 {
+		b.WriteString("### Diagnostics\n\n")
+		for _, e := range d.PermissionErrors {
+			fmt.Fprintf(&b, "- :warning: %s\n", e)
+		}
+		for _, w := range d.Warnings {
+			fmt.Fprintf(&b, "- %s\n", w)
+		}
+		b.WriteString("\n")
+	}
+	if p.Scope.SkippedRepositoryCount > 0 {
+		fmt.Fprintf(&b, "%d repositories couldn't be read and count as not enabled.\n", p.Scope.SkippedRepositoryCount)
+	}
+	return b.String()
+}
+
+// WriteActionsSummary appends a Markdown posture summary to the job summary
+// and sets step outputs when running inside GitHub Actions (GITHUB_ACTIONS is
+// "true"); elsewhere it does nothing. getenv is os.Getenv outside tests.
+func WriteActionsSummary(p *OrgPosture, getenv func(string) string) error {
+	if getenv("GITHUB_ACTIONS") != "true" {
+		return nil
+	}
+	if path := getenv("GITHUB_STEP_SUMMARY"); path != "" {
+		if err := appendFile(path, actionsSummary(p)); err != nil {
+			return fmt.Errorf("writing job summary: %w", err)
+		}
+	}
+	if path := getenv("GITHUB_OUTPUT"); path != "" {
+		var b strings.Builder
+		for _, o := range actionsOutputs(p) {
+			fmt.Fprintf(&b, "%s=%s\n", o[0], o[1])
+		}
+		if err := appendFile(path, b.String()); err != nil {
+			return fmt.Errorf("setting step outputs: %w", err)
+		}
+	}
+	return nil
+}
+
+// appendFile appends s to the file at path, as the Actions runner expects
+// for its command files.
+func appendFile(path, s string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(s); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteActionsSummary(t *testing.T) {
+	posture := &OrgPosture{
+		Organization:     "test-org",
+		CollectedAtLevel: "trust",
+		Posture:          Posture{BranchProtectionCoverage: 80, SecurityFeaturesCoverage: 45},
+		Diagnostics:      &Diagnostics{PermissionErrors: []string{"surface members skipped: permission denied"}},
+	}
+	dir := t.TempDir()
+	env := map[string]string{
+		"GITHUB_ACTIONS":      "true",
+		"GITHUB_STEP_SUMMARY": filepath.Join(dir, "summary.md"),
+		"GITHUB_OUTPUT":       filepath.Join(dir, "output"),
+	}
+	if err := os.WriteFile(env["GITHUB_OUTPUT"], []byte("earlier=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteActionsSummary(posture, func(k string) string { return env[k] }); err != nil {
+		t.Fatalf("WriteActionsSummary() error: %v", err)
+	}
+	summary, _ := os.ReadFile(env["GITHUB_STEP_SUMMARY"])
+	for _, want := range []string{"## GitHub posture: test-org", "| Branch protection | 80% |", "surface members skipped"} {
+		if !strings.Contains(string(summary), want) {
+			t.Errorf("summary missing %q:\n%s", want, summary)
+		}
+	}
+	output, _ := os.ReadFile(env["GITHUB_OUTPUT"])
+	for _, want := range []string{"earlier=1\n", "branch_protection_coverage=80\n", "security_features_coverage=45\n", "permission_errors=1\n"} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("outputs missing %q:\n%s", want, output)
+		}
+	}
+
+	// Outside Actions nothing is written.
+	env["GITHUB_ACTIONS"] = ""
+	env["GITHUB_STEP_SUMMARY"] = filepath.Join(dir, "other.md")
+	if err := WriteActionsSummary(posture, func(k string) string { return env[k] }); err != nil {
+		t.Fatalf("WriteActionsSummary() error: %v", err)
+	}
+	if _, err := os.Stat(env["GITHUB_STEP_SUMMARY"]); !os.IsNotExist(err) {
+		t.Error("summary written outside GitHub Actions")
+	}
+}
@@ -0,0 +1,254 @@
+package collector
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/locktivity/epack-collector-github/internal/state"
+)
+
+// Incident service endpoints. The Collector's pagerDutyURL and opsgenieURL
+// override them in tests.
+const (
+	DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	DefaultOpsgenieAlertsURL  = "https://api.opsgenie.com/v2/alerts"
+)
+
+// alertTwoFactorKey is the alert_thresholds key that alerts when
+// organization 2FA enforcement is turned off. Its value is ignored.
+const alertTwoFactorKey = "access_control.two_factor_required"
+
+// alertBreach is one breached threshold, raised as one incident.
+type alertBreach struct {
+	Key      string // alert_thresholds key
+	Summary  string
+	Details  map[string]any
+	Baseline int // the metric before the breach, 0 for 2FA
+}
+
+// alerting reports whether an incident service is configured.
+func (c *Collector) alerting() bool {
+	return c.config.PagerDutyRoutingKey != "" || c.config.OpsgenieAPIKey != ""
+}
+
+// validateAlertThresholds checks that each alert_thresholds key names a
+// compared coverage metric (see comparedMetrics) or alertTwoFactorKey, with a
+// non-negative point drop, and that incidents have a baseline to compare
+// against.
+func validateAlertThresholds(config Config) error {
+	for key, points := range config.AlertThresholds {
+		if key == alertTwoFactorKey {
+			continue
+		}
+		if !slices.ContainsFunc(comparedMetrics, func(m comparedMetric) bool { return m.path == key }) {
+			return fmt.Errorf("alert_thresholds: unknown metric %q", key)
+		}
+		if points < 0 {
+			return fmt.Errorf("alert_thresholds.%s: must be at least 0, got %d", key, points)
+		}
+	}
+	if config.PagerDutyRoutingKey == "" && config.OpsgenieAPIKey == "" {
+		return nil
+	}
+	if config.StateDir == "" {
+		return errors.New("incident alerting requires state_dir")
+	}
+	if len(config.AlertThresholds) == 0 {
+		return errors.New("incident alerting requires alert_thresholds")
+	}
+	return nil
+}
+
+// alertBreaches lists the thresholds breached between baseline and current,
+// sorted by key: a metric that regressed by more than its threshold's points,
+// or 2FA enforcement that was on and is now off.
+func alertBreaches(thresholds map[string]int, baseline, current *OrgPosture, comparison *PostureComparison) []alertBreach {
+	var breaches []alertBreach
+	for _, key := range slices.Sorted(maps.Keys(thresholds)) {
+		if key == alertTwoFactorKey {
+			before, after := baseline.AccessControl.TwoFactorRequired, current.AccessControl.TwoFactorRequired
+			// Unknown (nil) on either side is a permission gap, not a change.
+			if before != nil && after != nil && *before && !*after {
+				breaches = append(breaches, alertBreach{
+					Key:     key,
+					Summary: fmt.Sprintf("GitHub %s: organization 2FA enforcement was turned off", current.Organization),
+				})
+			}
+			continue
+		}
+		for _, m := range comparison.Metrics {
+			if m.Metric != key || !m.Regressed || abs(m.Delta) <= thresholds[key] {
+				continue
+			}
+			breaches = append(breaches, alertBreach{
+				Key:     key,
+				Summary: fmt.Sprintf("GitHub %s: %s moved %d%% → %d%%", current.Organization, m.Metric, m.Baseline, m.Current),
+				Details: map[string]any{
+					"baseline": m.Baseline, "current": m.Current, "threshold_points": thresholds[key],
+					"baseline_collected_at": comparison.BaselineAt,
+				},
+				Baseline: m.Baseline,
+			})
+		}
+	}
+	return breaches
+}
+
+// incidentCleared reports whether the condition behind an open incident has
+// cleared in current: the metric is back within its threshold of the value
+// before the breach, or 2FA enforcement is on again. An incident for a key
+// no longer in thresholds is cleared too, as nothing watches it.
+func incidentCleared(thresholds map[string]int, key string, incident state.Incident, current *OrgPosture) bool {
+	points, ok := thresholds[key]
+	if !ok {
+		return true
+	}
+	if key == alertTwoFactorKey {
+		after := current.AccessControl.TwoFactorRequired
+		return after != nil && *after
+	}
+	for _, m := range comparedMetrics {
+		if m.path != key {
+			continue
+		}
+		if m.lowerIsBetter {
+			return m.value(current) <= incident.Baseline+points
+		}
+		return m.value(current) >= incident.Baseline-points
+	}
+	return true
+}
+
+// updateIncidents raises an incident for each threshold breached between
+// baseline and current, and resolves the open incidents whose condition has
+// cleared (see incidentCleared). Open incidents are kept in state_dir, so one
+// is resolved however many runs later its condition clears. comparison is
+// nil when there is no baseline, which can still resolve.
+func (c *Collector) updateIncidents(ctx context.Context, store *state.Store, baseline, current *OrgPosture, comparison *PostureComparison) error {
+	account := c.config.Organization
+	open, err := store.LoadIncidents(account)
+	if err != nil {
+		return err
+	}
+	if open == nil {
+		open = make(map[string]state.Incident)
+	}
+	var errs []error
+	if comparison != nil {
+		for _, breach := range alertBreaches(c.config.AlertThresholds, baseline, current, comparison) {
+			if err := c.openIncident(ctx, current, breach); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			// A breach of an open incident updates it; clearing is still
+			// measured from before the first breach.
+			if _, ok := open[breach.Key]; !ok {
+				open[breach.Key] = state.Incident{Baseline: breach.Baseline}
+			}
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(open)) {
+		if !incidentCleared(c.config.AlertThresholds, key, open[key], current) {
+			continue
+		}
+		if err := c.resolveIncident(ctx, current, key); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(open, key)
+	}
+	if err := store.SaveIncidents(account, open); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// alertDedupKey identifies the incident for one threshold, so repeated runs
+// update the open incident instead of opening another, and resolve it.
+func alertDedupKey(org, key string) string {
+	return "epack-collector-github/" + org + "/" + key
+}
+
+// openIncident raises breach with each configured incident service.
+func (c *Collector) openIncident(ctx context.Context, p *OrgPosture, breach alertBreach) error {
+	dedup := alertDedupKey(p.Organization, breach.Key)
+	var errs []error
+	if key := c.config.PagerDutyRoutingKey; key != "" {
+		endpoint := cmp.Or(c.pagerDutyURL, DefaultPagerDutyEventsURL)
+		err := c.sendJSON(ctx, http.MethodPost, endpoint, nil, map[string]any{
+			"routing_key":  key,
+			"event_action": "trigger",
+			"dedup_key":    dedup,
+			"payload": map[string]any{
+				"summary":        breach.Summary,
+				"source":         "github.com/" + p.Organization,
+				"severity":       "error",
+				"component":      breach.Key,
+				"custom_details": breach.Details,
+			},
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("opening PagerDuty incident: %w", err))
+		}
+	}
+	if key := c.config.OpsgenieAPIKey; key != "" {
+		endpoint := cmp.Or(c.opsgenieURL, DefaultOpsgenieAlertsURL)
+		err := c.sendJSON(ctx, http.MethodPost, endpoint, http.Header{"Authorization": {"GenieKey " + key}}, map[string]any{
+			"message":  breach.Summary,
+			"alias":    dedup,
+			"source":   "epack-collector-github",
+			"entity":   p.Organization,
+			"tags":     []string{"github-posture", breach.Key},
+			"details":  stringDetails(breach.Details),
+			"priority": "P2",
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("opening Opsgenie alert: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// resolveIncident resolves the incident for threshold key with each
+// configured incident service.
+func (c *Collector) resolveIncident(ctx context.Context, p *OrgPosture, key string) error {
+	dedup := alertDedupKey(p.Organization, key)
+	var errs []error
+	if routingKey := c.config.PagerDutyRoutingKey; routingKey != "" {
+		endpoint := cmp.Or(c.pagerDutyURL, DefaultPagerDutyEventsURL)
+		err := c.sendJSON(ctx, http.MethodPost, endpoint, nil, map[string]any{
+			"routing_key":  routingKey,
+			"event_action": "resolve",
+			"dedup_key":    dedup,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("resolving PagerDuty incident: %w", err))
+		}
+	}
+	if apiKey := c.config.OpsgenieAPIKey; apiKey != "" {
+		endpoint := cmp.Or(c.opsgenieURL, DefaultOpsgenieAlertsURL) + "/" + url.PathEscape(dedup) + "/close?identifierType=alias"
+		err := c.sendJSON(ctx, http.MethodPost, endpoint, http.Header{"Authorization": {"GenieKey " + apiKey}}, map[string]any{
+			"source": "epack-collector-github",
+			"note":   key + " is back within its alert threshold",
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("closing Opsgenie alert: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stringDetails converts details to the string map Opsgenie accepts.
+func stringDetails(details map[string]any) map[string]string {
+	out := make(map[string]string, len(details))
+	for k, v := range details {
+		out[k] = fmt.Sprint(v)
+	}
+	return out
+}
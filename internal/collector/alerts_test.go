@@ -0,0 +1,139 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNotify_Incidents(t *testing.T) {
+	type request struct {
+		path string
+		auth string
+		body map[string]any
+	}
+	var pagerDuty, opsgenie []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+		req := request{path: r.URL.RequestURI(), auth: r.Header.Get("Authorization"), body: body}
+		switch {
+		case r.URL.Path == "/pagerduty":
+			pagerDuty = append(pagerDuty, req)
+		case strings.HasPrefix(r.URL.Path, "/opsgenie"):
+			opsgenie = append(opsgenie, req)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	config := Config{
+		Organization: "test-org",
+		StateDir:     t.TempDir(),
+		AlertThresholds: map[string]int{
+			"posture.branch_protection_coverage": 10,
+			"posture.security_features_coverage": 10,
+			alertTwoFactorKey:                    0,
+		},
+		PagerDutyRoutingKey: "routing-key",
+		OpsgenieAPIKey:      "genie-key",
+	}
+	if err := validateAlertThresholds(config); err != nil {
+		t.Fatalf("validateAlertThresholds() error: %v", err)
+	}
+	c := NewWithClient(config, &mockGitHubClient{})
+	c.pagerDutyURL = srv.URL + "/pagerduty"
+	c.opsgenieURL = srv.URL + "/opsgenie"
+
+	run := func(bp, sf int, twoFactor bool) {
+		t.Helper()
+		p := NewOrgPosture("test-org")
+		p.Posture = Posture{BranchProtectionCoverage: bp, SecurityFeaturesCoverage: sf}
+		p.AccessControl.TwoFactorRequired = &twoFactor
+		if err := c.Notify(context.Background(), p); err != nil {
+			t.Fatalf("Notify() error: %v", err)
+		}
+	}
+
+	run(90, 80, true)
+	// Branch protection drops 20 points (breach), security features 10 (at
+	// the threshold, not over it), and 2FA enforcement is turned off.
+	run(70, 70, false)
+
+	if len(pagerDuty) != 2 || len(opsgenie) != 2 {
+		t.Fatalf("incidents: PagerDuty %d, Opsgenie %d; want 2 each", len(pagerDuty), len(opsgenie))
+	}
+	if got := pagerDuty[0].body["dedup_key"]; got != "epack-collector-github/test-org/access_control.two_factor_required" {
+		t.Errorf("first dedup_key = %v, want the 2FA key", got)
+	}
+	if got := pagerDuty[1].body["dedup_key"]; got != "epack-collector-github/test-org/posture.branch_protection_coverage" {
+		t.Errorf("second dedup_key = %v, want the branch protection key", got)
+	}
+	if pagerDuty[1].body["routing_key"] != "routing-key" || pagerDuty[1].body["event_action"] != "trigger" {
+		t.Errorf("PagerDuty event = %v", pagerDuty[1].body)
+	}
+	if opsgenie[1].auth != "GenieKey genie-key" || opsgenie[1].body["alias"] != pagerDuty[1].body["dedup_key"] {
+		t.Errorf("Opsgenie alert = %v (auth %q)", opsgenie[1].body, opsgenie[1].auth)
+	}
+
+	// Nothing changed since: no incidents.
+	run(70, 70, false)
+	if len(pagerDuty) != 2 {
+		t.Errorf("unchanged run opened %d more incidents", len(pagerDuty)-2)
+	}
+
+	// A run capped by max_repositories opens nothing and doesn't become the
+	// baseline, so the next full run compares against 70.
+	p := NewOrgPosture("test-org")
+	p.Posture = Posture{BranchProtectionCoverage: 10, SecurityFeaturesCoverage: 10}
+	p.Scope.Truncated = true
+	if err := c.Notify(context.Background(), p); err != nil {
+		t.Fatalf("Notify() error: %v", err)
+	}
+	run(70, 70, false)
+	if len(pagerDuty) != 2 {
+		t.Errorf("truncated run and the run after it opened %d more incidents", len(pagerDuty)-2)
+	}
+
+	// Branch protection recovers to within 10 points of the 90 it dropped
+	// from, and 2FA enforcement is back on: both incidents resolve.
+	run(82, 70, true)
+	if len(pagerDuty) != 4 || len(opsgenie) != 4 {
+		t.Fatalf("resolves: PagerDuty %d, Opsgenie %d; want 4 each", len(pagerDuty), len(opsgenie))
+	}
+	for _, req := range pagerDuty[2:] {
+		if req.body["event_action"] != "resolve" {
+			t.Errorf("PagerDuty event = %v, want a resolve", req.body)
+		}
+	}
+	if got := pagerDuty[3].body["dedup_key"]; got != pagerDuty[1].body["dedup_key"] {
+		t.Errorf("resolve dedup_key = %v, want the branch protection incident's", got)
+	}
+	if got, want := opsgenie[3].path, "/opsgenie/epack-collector-github%2Ftest-org%2Fposture.branch_protection_coverage/close?identifierType=alias"; got != want {
+		t.Errorf("Opsgenie close = %s, want %s", got, want)
+	}
+
+	// Once resolved, they aren't resolved again.
+	run(82, 70, true)
+	if len(pagerDuty) != 4 {
+		t.Errorf("run after resolving sent %d more events", len(pagerDuty)-4)
+	}
+}
+
+func TestValidateAlertThresholds(t *testing.T) {
+	for _, config := range []Config{
+		{AlertThresholds: map[string]int{"posture.unknown": 5}},
+		{AlertThresholds: map[string]int{"posture.branch_protection_coverage": -1}},
+		{AlertThresholds: map[string]int{"posture.branch_protection_coverage": 5}, PagerDutyRoutingKey: "k"},
+		{StateDir: "/tmp/state", OpsgenieAPIKey: "k"},
+	} {
+		if err := validateAlertThresholds(config); err == nil {
+			t.Errorf("validateAlertThresholds(%+v) should fail", config)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package collector
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// Attestation signs the emitted artifacts so downstream systems can verify
+// they are unmodified and came from a holder of the signing key. Each
+// subject's digest and signature cover the artifact's compact JSON encoding,
+// which a verifier recovers by stripping insignificant whitespace from the
+// artifact data.
+type Attestation struct {
+	Algorithm string             `json:"algorithm"` // ed25519, ecdsa-p256-sha256, rsa-pkcs1v15-sha256
+	KeyID     string             `json:"key_id"`    // sha256 of the DER public key
+	PublicKey string             `json:"public_key"`
+	Subjects  []AttestedArtifact `json:"subjects"`
+}
+
+// AttestedArtifact is one signed artifact.
+type AttestedArtifact struct {
+	Path      string `json:"path"`
+	Digest    string `json:"digest"`    // sha256:<hex>
+	Signature string `json:"signature"` // base64
+}
+
+// Signer signs artifacts with a configured private key.
+type Signer struct {
+	key       crypto.Signer
+	algorithm string
+	keyID     string
+	publicKey string
+}
+
+// NewSigner parses a PEM private key (PKCS#8, SEC 1 EC, or PKCS#1 RSA). Ed25519,
+// ECDSA P-256, and RSA keys are supported.
+func NewSigner(keyPEM string) (*Signer, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, errors.New("signing key: no PEM block found")
+	}
+	key, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("signing key: %w", err)
+	}
+
+	s := &Signer{key: key}
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		s.algorithm = "ed25519"
+	case *ecdsa.PrivateKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("signing key: only the P-256 ECDSA curve is supported")
+		}
+		s.algorithm = "ecdsa-p256-sha256"
+	case *rsa.PrivateKey:
+		s.algorithm = "rsa-pkcs1v15-sha256"
+	default:
+		return nil, fmt.Errorf("signing key: unsupported key type %T", key)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, fmt.Errorf("signing key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+	s.keyID = "sha256:" + hex.EncodeToString(sum[:])
+	s.publicKey = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	return s, nil
+}
+
+// parsePrivateKey tries each supported DER private-key encoding.
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		}
+		return nil, fmt.Errorf("unsupported key type %T", key)
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, errors.New("unrecognized private key encoding")
+}
+
+// Sign encodes data as compact JSON and signs it. It returns the encoded
+// bytes, which must be emitted as-is (e.g. as a json.RawMessage) so the
+// attestation matches the artifact.
+func (s *Signer) Sign(path string, data any) (json.RawMessage, AttestedArtifact, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, AttestedArtifact{}, err
+	}
+
+	digest := sha256.Sum256(raw)
+	var sig []byte
+	if s.algorithm == "ed25519" {
+		sig, err = s.key.Sign(rand.Reader, raw, crypto.Hash(0))
+	} else {
+		sig, err = s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, AttestedArtifact{}, fmt.Errorf("signing %s: %w", path, err)
+	}
+	return raw, AttestedArtifact{
+		Path:      path,
+		Digest:    "sha256:" + hex.EncodeToString(digest[:]),
+		Signature: base64.StdEncoding.EncodeToString(sig),
+	}, nil
+}
+
+// Attestation returns the attestation for the signed subjects.
+func (s *Signer) Attestation(subjects ...AttestedArtifact) *Attestation {
+	return &Attestation{
+		Algorithm: s.algorithm,
+		KeyID:     s.keyID,
+		PublicKey: s.publicKey,
+		Subjects:  subjects,
+	}
+}
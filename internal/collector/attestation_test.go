@@ -0,0 +1,112 @@
+package collector
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"testing"
+)
+
+func pkcs8PEM(t *testing.T, key any) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalPKCS8PrivateKey() error: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func TestSigner_Ed25519(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewSigner(pkcs8PEM(t, priv))
+	if err != nil {
+		t.Fatalf("NewSigner() error: %v", err)
+	}
+
+	posture := NewOrgPosture("test-org")
+	raw, subject, err := signer.Sign("artifacts/github.json", posture)
+	if err != nil {
+		t.Fatalf("Sign() error: %v", err)
+	}
+
+	// A verifier sees the artifact indented inside the envelope and
+	// recovers the signed bytes by compacting it.
+	var indented, compact bytes.Buffer
+	if err := json.Indent(&indented, raw, "    ", "  "); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Compact(&compact, indented.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(compact.Bytes())
+	if want := "sha256:" + hex.EncodeToString(sum[:]); subject.Digest != want {
+		t.Errorf("Digest = %s, want %s", subject.Digest, want)
+	}
+	sig, err := base64.StdEncoding.DecodeString(subject.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pub, compact.Bytes(), sig) {
+		t.Error("signature does not verify against the compacted artifact")
+	}
+
+	att := signer.Attestation(subject)
+	if att.Algorithm != "ed25519" || len(att.Subjects) != 1 || att.PublicKey == "" {
+		t.Errorf("Attestation() = %+v", att)
+	}
+}
+
+func TestSigner_ECDSA(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewSigner(string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})))
+	if err != nil {
+		t.Fatalf("NewSigner() error: %v", err)
+	}
+
+	raw, subject, err := signer.Sign("artifacts/github.json", map[string]int{"a": 1})
+	if err != nil {
+		t.Fatalf("Sign() error: %v", err)
+	}
+	sig, _ := base64.StdEncoding.DecodeString(subject.Signature)
+	sum := sha256.Sum256(raw)
+	if !ecdsa.VerifyASN1(&key.PublicKey, sum[:], sig) {
+		t.Error("ECDSA signature does not verify")
+	}
+	if signer.Attestation().Algorithm != "ecdsa-p256-sha256" {
+		t.Errorf("Algorithm = %s", signer.Attestation().Algorithm)
+	}
+}
+
+func TestNewSigner_Invalid(t *testing.T) {
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, keyPEM := range map[string]string{
+		"not PEM":          "not a key",
+		"garbage DER":      string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("x")})),
+		"unsupported P384": pkcs8PEM(t, p384),
+	} {
+		if _, err := NewSigner(keyPEM); err == nil {
+			t.Errorf("%s: NewSigner() should fail", name)
+		}
+	}
+}
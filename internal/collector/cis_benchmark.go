@@ -0,0 +1,176 @@
+package collector
+
+import "fmt"
+
+// CISBenchmarkVersion is the benchmark the cis_benchmark section evaluates.
+const CISBenchmarkVersion = "CIS GitHub Benchmark v1.0.0"
+
+// CIS recommendation statuses.
+const (
+	CISPass    = "pass"
+	CISFail    = "fail"
+	CISUnknown = "unknown"
+)
+
+// cisRecommendation is one automatable benchmark recommendation. evaluate
+// returns the status and, for per-repository recommendations, the count of
+// compliant repositories out of evaluated.
+type cisRecommendation struct {
+	id       string
+	title    string
+	evidence string // dotted path of the metric the result is based on
+	evaluate func(p *OrgPosture, m *metricsAggregator) cisResult
+}
+
+// cisResult is one evaluation outcome.
+type cisResult struct {
+	status               string
+	compliant, evaluated int
+	perRepo              bool
+	note                 string
+}
+
+// cisRecommendations lists, in benchmark order, the recommendations automatable from the data the
+// collector gathers: branch protection, org settings, and security features.
+// Recommendations needing data the collector doesn't read (e.g. Actions
+// policies, per-repository administrators) are not evaluated.
+var cisRecommendations = []cisRecommendation{
+	{"1.1.3", "Ensure any change to code receives approval of two strongly authenticated users", "branch_protection_rules.required_review_counts",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.reviewCountAtLeast(2), m.reposIn(MetricBranchProtection))
+		}},
+	{"1.1.4", "Ensure previous approvals are dismissed when updates are introduced to a code change proposal", "branch_protection_rules.dismiss_stale_reviews",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.dismissStaleReviews, m.reposIn(MetricBranchProtection))
+		}},
+	{"1.1.7", "Ensure code owner's review is required when a change affects owned code", "branch_protection_rules.code_owner_reviews",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.requireCodeOwnerReviews, m.reposIn(MetricBranchProtection))
+		}},
+	{"1.1.9", "Ensure all checks have passed before merging new code", "branch_protection_rules.status_checks",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.requireStatusChecks, m.reposIn(MetricBranchProtection))
+		}},
+	{"1.1.11", "Ensure all open comments are resolved before allowing code change merging", "branch_protection_rules.conversation_resolution",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.requireConversationResolution, m.reposIn(MetricBranchProtection))
+		}},
+	{"1.1.12", "Ensure verification of signed commits for new changes before merging", "branch_protection_rules.signed_commits",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.requireSignedCommits, m.reposIn(MetricBranchProtection))
+		}},
+	{"1.1.13", "Ensure linear history is required", "branch_protection_rules.linear_history",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.requireLinearHistory, m.reposIn(MetricBranchProtection))
+		}},
+	{"1.1.14", "Ensure branch protection rules are enforced for administrators", "branch_protection_rules.admin_enforcement",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.enforceAdmins, m.reposIn(MetricBranchProtection))
+		}},
+	// An unprotected default branch permits force pushes and deletion, so
+	// only protected branches whose rule denies them comply.
+	{"1.1.16", "Ensure force push code to branches is denied", "branch_protection_rules.force_pushes_allowed",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.branchProtectionEnabled-m.allowForcePushes, m.reposIn(MetricBranchProtection))
+		}},
+	{"1.1.17", "Ensure branch deletions are denied", "branch_protection_rules.deletions_allowed",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.branchProtectionEnabled-m.allowDeletions, m.reposIn(MetricBranchProtection))
+		}},
+	{"1.2.2", "Ensure repository creation is limited to specific members", "access_control.members_can_create_repositories",
+		func(p *OrgPosture, _ *metricsAggregator) cisResult {
+			v := p.AccessControl.MembersCanCreateRepositories
+			if v == nil {
+				return cisResult{status: CISUnknown, note: "requires audit level and organization administration: read"}
+			}
+			return cisBool(!*v)
+		}},
+	{"1.3.5", "Ensure the organization is requiring members to use MFA", "access_control.two_factor_required",
+		func(p *OrgPosture, _ *metricsAggregator) cisResult {
+			v := p.AccessControl.TwoFactorRequired
+			if v == nil {
+				return cisResult{status: CISUnknown, note: "requires organization administration: read"}
+			}
+			return cisBool(*v)
+		}},
+	{"1.3.8", "Ensure strict base permissions are set for repositories", "access_control.default_repository_permission",
+		func(p *OrgPosture, _ *metricsAggregator) cisResult {
+			switch perm := p.AccessControl.DefaultRepositoryPermission; perm {
+			case "":
+				return cisResult{status: CISUnknown, note: "requires audit level and organization administration: read"}
+			case "none", "read":
+				return cisBool(true)
+			default:
+				return cisResult{status: CISFail, note: fmt.Sprintf("base permission is %s", perm)}
+			}
+		}},
+	{"1.5.1", "Ensure scanners are in place to identify and prevent sensitive data in code", "security_features.secret_scanning_push_protection",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.secretScanningPushProtection, m.reposIn(MetricSecretScanning))
+		}},
+	{"1.5.4", "Ensure scanners are in place for code vulnerabilities", "security_features.code_scanning",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.codeScanningEnabled, m.reposIn(MetricCodeScanning))
+		}},
+	{"1.5.5", "Ensure scanners are in place for open-source vulnerabilities in used packages", "security_features.vulnerability_alerts",
+		func(_ *OrgPosture, m *metricsAggregator) cisResult {
+			return cisRepos(m.vulnerabilityAlertsEnabled, m.applicableIn(MetricVulnerabilityAlerts))
+		}},
+}
+
+// cisRepos passes when every evaluated repository complies.
+func cisRepos(compliant, evaluated int) cisResult {
+	r := cisResult{compliant: compliant, evaluated: evaluated, perRepo: true}
+	switch {
+	case evaluated == 0:
+		r.status = CISUnknown
+		r.note = "no repositories in scope"
+	case compliant >= evaluated:
+		r.status = CISPass
+	default:
+		r.status = CISFail
+	}
+	return r
+}
+
+// cisBool maps an org-level setting's compliance to a status.
+func cisBool(ok bool) cisResult {
+	if ok {
+		return cisResult{status: CISPass}
+	}
+	return cisResult{status: CISFail}
+}
+
+// cisBenchmark evaluates every automatable recommendation against the
+// collected posture. It runs after the surface pass, so recommendations
+// based on audit-level org settings are unknown at trust.
+func cisBenchmark(p *OrgPosture, m *metricsAggregator) *CISBenchmark {
+	out := &CISBenchmark{
+		Benchmark:       CISBenchmarkVersion,
+		Recommendations: make([]CISRecommendationResult, 0, len(cisRecommendations)),
+	}
+	for _, rec := range cisRecommendations {
+		r := rec.evaluate(p, m)
+		row := CISRecommendationResult{
+			ID:       rec.id,
+			Title:    rec.title,
+			Status:   r.status,
+			Evidence: rec.evidence,
+			Note:     r.note,
+		}
+		if r.perRepo && r.evaluated > 0 {
+			row.ReposCompliant = &r.compliant
+			row.ReposEvaluated = &r.evaluated
+		}
+		out.Recommendations = append(out.Recommendations, row)
+		switch r.status {
+		case CISPass:
+			out.Passed++
+		case CISFail:
+			out.Failed++
+		default:
+			out.Unknown++
+		}
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack/componentsdk"
+)
+
+func TestCollect_CISBenchmark(t *testing.T) {
+	repo := func(name string, rule *github.BranchProtectionRule) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		r.DefaultBranchRef.BranchProtectionRule = rule
+		return r
+	}
+	strict := &github.BranchProtectionRule{
+		RequiresApprovingReviews:     true,
+		RequiredApprovingReviewCount: 2,
+		DismissesStaleReviews:        true,
+		IsAdminEnforced:              true,
+	}
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{TwoFactorRequired: boolPtr(true)},
+		repositories: []github.Repository{
+			repo("api", strict),
+			repo("web", &github.BranchProtectionRule{
+				RequiresApprovingReviews:     true,
+				RequiredApprovingReviewCount: 2,
+				DismissesStaleReviews:        true,
+				AllowsForcePushes:            true,
+			}),
+		},
+	}
+
+	posture, err := NewWithClient(Config{Organization: "test-org", CISBenchmark: true}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	cis := posture.CISBenchmark
+	if cis == nil {
+		t.Fatal("CISBenchmark = nil, want the cis_benchmark section")
+	}
+	byID := map[string]CISRecommendationResult{}
+	for _, r := range cis.Recommendations {
+		byID[r.ID] = r
+	}
+
+	for id, want := range map[string]string{
+		"1.1.3":  CISPass,    // both require two approvals
+		"1.1.4":  CISPass,    // both dismiss stale reviews
+		"1.1.14": CISFail,    // web doesn't enforce for admins
+		"1.1.16": CISFail,    // web allows force pushes
+		"1.1.17": CISPass,    // neither allows deletions
+		"1.3.5":  CISPass,    // 2FA required
+		"1.2.2":  CISUnknown, // org settings are audit level
+	} {
+		if got := byID[id].Status; got != want {
+			t.Errorf("%s status = %q, want %q", id, got, want)
+		}
+	}
+	if r := byID["1.1.16"]; r.ReposCompliant == nil || *r.ReposCompliant != 1 || *r.ReposEvaluated != 2 {
+		t.Errorf("1.1.16 repos = %v/%v, want 1/2", r.ReposCompliant, r.ReposEvaluated)
+	}
+	if cis.Passed+cis.Failed+cis.Unknown != len(cisRecommendations) {
+		t.Errorf("status counts %d+%d+%d don't cover %d recommendations", cis.Passed, cis.Failed, cis.Unknown, len(cisRecommendations))
+	}
+
+	// Without the option the section is omitted.
+	posture, err = NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if posture.CISBenchmark != nil {
+		t.Error("CISBenchmark should be omitted unless cis_benchmark is set")
+	}
+}
+
+func TestCISRecommendations_EvidencePaths(t *testing.T) {
+	for _, rec := range cisRecommendations {
+		if !fieldByJSONPath(reflect.TypeFor[OrgPosture](), rec.evidence) {
+			t.Errorf("%s: evidence %q is not a field of the github.json artifact", rec.id, rec.evidence)
+		}
+	}
+}
@@ -4,65 +4,283 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack-collector-github/internal/state"
 	"github.com/locktivity/epack/componentsdk"
+	"golang.org/x/sync/errgroup"
 )
 
 // Collector collects GitHub organization security posture.
 type Collector struct {
 	client github.GitHubClient
 	config Config
+
+	// matcher and scopes hold the include/exclude patterns and per-metric
+	// scopes compiled by New; Collect compiles them when the Collector came
+	// from NewWithClient.
+	matcher *RepoMatcher
+	scopes  metricScopes
+
+	// store keeps the snapshot between runs (nil unless StateDir is set).
+	store *state.Store
+
+	// lock is the account's state lock, taken by Collect and held until
+	// Close so the posture baseline Notify saves is written under it too.
+	lock *state.Lock
+
+	// notifier posts notifications, incidents, and Jira tickets (nil unless
+	// one of them is configured; requests then fall back to
+	// http.DefaultClient). pagerDutyURL and opsgenieURL override the
+	// incident endpoints in tests.
+	notifier     *http.Client
+	pagerDutyURL string
+	opsgenieURL  string
+
+	// reportMu serializes status/progress callbacks, which the concurrent
+	// collection phases may issue at the same time. It also guards the last
+	// report, which the heartbeat repeats.
+	reportMu    sync.Mutex
+	lastReport  time.Time
+	lastMessage string
+
+	// progressDiag is the running collection's diagnostics, counted in
+	// JSON progress events. It is only appended to between phases and
+	// during the sequential surface pass, never while progress is reported
+	// concurrently.
+	progressDiag *diagnostics
 }
 
 // status reports an indeterminate status update.
 func (c *Collector) status(message string) {
 	if c.config.OnStatus != nil {
+		c.reportMu.Lock()
+		defer c.reportMu.Unlock()
 		c.config.OnStatus(message)
+		c.lastReport, c.lastMessage = time.Now(), message
 	}
 }
 
-// progress reports a determinate progress update.
-func (c *Collector) progress(current, total int64, message string) {
+// progress reports a determinate progress update, as a text line or a JSON
+// ProgressEvent per progress_format. The heartbeat repeats the text line.
+func (c *Collector) progress(p Progress) {
 	if c.config.OnProgress != nil {
-		c.config.OnProgress(current, total, message)
+		c.reportMu.Lock()
+		defer c.reportMu.Unlock()
+		c.config.OnProgress(p.Processed, p.Total, c.progressMessage(p))
+		c.lastReport, c.lastMessage = time.Now(), p.String()
 	}
 }
 
+// requestCount returns the API requests issued so far, REST and GraphQL.
+func (c *Collector) requestCount() int {
+	stats := c.client.Stats()
+	return stats.RESTRequests + stats.GraphQLQueries
+}
+
 // New creates a new Collector with the given configuration.
-// It supports two authentication methods:
+// It supports three authentication methods (see authProvider):
 //   - GitHub App (recommended): Set AppID, InstallationID, and PrivateKey
-//   - Classic PAT (legacy): Set GitHubToken
+//   - Token command: Set TokenCommand
+//   - Installation token or classic PAT (legacy): Set GitHubToken
+//
+// Credentials kept in a secret store must be loaded first (see
+// LoadStoredSecrets).
 func New(config Config) (*Collector, error) {
 	var client github.GitHubClient
-	var err error
 
-	if config.AppID != 0 && config.PrivateKey != "" {
-		// GitHub App auth (recommended)
-		if config.InstallationID == 0 {
-			return nil, fmt.Errorf("installation_id is required when using GitHub App authentication")
+	if _, err := parseRepositoryList(config.Repositories, config.Organization); err != nil {
+		return nil, err
+	}
+	if err := validateOwnerType(config.OwnerType); err != nil {
+		return nil, err
+	}
+	if err := validateAPIURL(config.GitHubAPIURL); err != nil {
+		return nil, err
+	}
+	if err := validateProgressFormat(config.ProgressFormat); err != nil {
+		return nil, err
+	}
+	matcher, scopes, err := compileScopes(config)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := resolveFeatureWeights(config.FeatureWeights); err != nil {
+		return nil, err
+	}
+	if err := validateLookbackDays(config.LookbackDays); err != nil {
+		return nil, err
+	}
+	if _, err := newRepoTiers(config); err != nil {
+		return nil, err
+	}
+	if err := validateOutputFields(config.OutputFields); err != nil {
+		return nil, err
+	}
+	if err := validateSecretStore(config); err != nil {
+		return nil, err
+	}
+	if err := validateNotifications(config); err != nil {
+		return nil, err
+	}
+	if err := validateAlertThresholds(config); err != nil {
+		return nil, err
+	}
+	if err := validateComplianceSLAs(config); err != nil {
+		return nil, err
+	}
+	if err := validateRemediation(config); err != nil {
+		return nil, err
+	}
+	if err := validateMinInterval(config); err != nil {
+		return nil, err
+	}
+	if config.MaxRateLimitWaitSeconds < 0 {
+		return nil, fmt.Errorf("max_rate_limit_wait_seconds: must be at least 0, got %d", config.MaxRateLimitWaitSeconds)
+	}
+	if err := validateCaps(config); err != nil {
+		return nil, err
+	}
+	if config.DebugCapturePath != "" && config.RedactRepoNames {
+		// The capture records request paths, and those name repositories.
+		return nil, errors.New("debug_capture_path can't be combined with redact_repo_names: captured request URLs name repositories")
+	}
+
+	var store *state.Store
+	if config.StateDir != "" {
+		if store, err = openStore(config); err != nil {
+			return nil, err
 		}
-		client, err = github.NewClientFromApp(
-			config.AppID,
-			config.InstallationID,
-			[]byte(config.PrivateKey),
-		)
+	} else if config.StateEncryptionKey != "" || config.StateEncryptionKeyPrevious != "" {
+		return nil, errors.New("state_encryption_key requires state_dir")
+	}
+
+	transport, err := github.NewTransport(github.TransportConfig{
+		HTTPProxy:          config.HTTPProxy,
+		HTTPSProxy:         config.HTTPSProxy,
+		NoProxy:            config.NoProxy,
+		CABundlePath:       config.CABundlePath,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+
+		MaxRequestsPerSecond: config.MaxRequestsPerSecond,
+		Limiter:              config.RequestLimiter,
+
+		MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+		DisableHTTP2:        config.DisableHTTP2,
+
+		DebugCapturePath: config.DebugCapturePath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("configuring HTTP transport: %w", err)
+	}
+
+	var notifier *http.Client
+	if config.NotificationWebhook != "" || config.PagerDutyRoutingKey != "" || config.OpsgenieAPIKey != "" || config.RemediationTracker == RemediationTrackerJira {
+		// Webhooks, incident services, and Jira share the proxy and CA settings but not the GitHub
+		// request budget.
+		notifyTransport, err := github.NewTransport(github.TransportConfig{
+			HTTPProxy:    config.HTTPProxy,
+			HTTPSProxy:   config.HTTPSProxy,
+			NoProxy:      config.NoProxy,
+			CABundlePath: config.CABundlePath,
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to create GitHub App client: %w", err)
+			return nil, fmt.Errorf("configuring notification transport: %w", err)
 		}
-	} else if config.GitHubToken != "" {
-		// Classic PAT auth (legacy)
-		client = github.NewClient(config.GitHubToken)
-	} else {
-		return nil, fmt.Errorf("authentication required: provide app_id + private_key (recommended) or github_token")
+		notifier = &http.Client{Transport: notifyTransport, Timeout: notifyTimeout}
+	}
+
+	auth, err := authProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	authClient, err := github.NewClientWithAuth(auth, transport)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	authClient.SetMaxRateLimitWait(time.Duration(config.MaxRateLimitWaitSeconds) * time.Second)
+	if config.GitHubAPIURL != "" {
+		authClient.SetBaseURL(config.GitHubAPIURL)
 	}
+	authClient.SetCache(config.SharedCache)
+	client = authClient
 
 	return &Collector{
-		client: client,
-		config: config,
+		client:   client,
+		config:   config,
+		matcher:  matcher,
+		scopes:   scopes,
+		store:    store,
+		notifier: notifier,
 	}, nil
 }
 
+// authProvider returns the configured authentication: a GitHub App
+// (recommended), a token command, or a fixed token, in that order of
+// precedence.
+func authProvider(config Config) (github.AuthProvider, error) {
+	switch {
+	case config.AppID != 0 && config.PrivateKey != "":
+		if config.InstallationID == 0 {
+			return nil, fmt.Errorf("installation_id is required when using GitHub App authentication")
+		}
+		return github.AppAuth{AppID: config.AppID, InstallationID: config.InstallationID, PrivateKey: []byte(config.PrivateKey), BaseURL: config.GitHubAPIURL, Cache: config.SharedCache}, nil
+	case len(config.TokenCommand) > 0:
+		if config.TokenCommand[0] == "" {
+			return nil, errors.New("token_command: the program is empty")
+		}
+		return github.CommandAuth{Command: config.TokenCommand, Cache: config.SharedCache}, nil
+	case config.GitHubToken != "":
+		// Installation token from the runtime, or a classic PAT (legacy)
+		return github.TokenAuth{Token: config.GitHubToken}, nil
+	}
+	return nil, fmt.Errorf("authentication required: provide app_id + private_key (recommended), token_command, or github_token")
+}
+
+// openStore opens the state directory, encrypted when a state encryption
+// key is configured.
+func openStore(config Config) (*state.Store, error) {
+	if config.StateEncryptionKey == "" {
+		if config.StateEncryptionKeyPrevious != "" {
+			return nil, errors.New("state_encryption_key_previous requires state_encryption_key")
+		}
+		return state.Open(config.StateDir)
+	}
+	key, err := state.ParseKey(config.StateEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	var previous [][]byte
+	if config.StateEncryptionKeyPrevious != "" {
+		prev, err := state.ParseKey(config.StateEncryptionKeyPrevious)
+		if err != nil {
+			return nil, fmt.Errorf("previous %w", err)
+		}
+		previous = append(previous, prev)
+	}
+	return state.OpenEncrypted(config.StateDir, key, previous...)
+}
+
+// compileScopes compiles the include/exclude patterns and per-metric scopes.
+func compileScopes(config Config) (*RepoMatcher, metricScopes, error) {
+	matcher, err := NewRepoMatcher(config.IncludePatterns, config.ExcludePatterns, config.CaseInsensitivePatterns)
+	if err != nil {
+		return nil, nil, err
+	}
+	scopes, err := newMetricScopes(config.Scopes, config.CaseInsensitivePatterns)
+	if err != nil {
+		return nil, nil, err
+	}
+	return matcher, scopes, nil
+}
+
 // NewWithClient creates a Collector with a custom client (for testing).
 func NewWithClient(config Config, client github.GitHubClient) *Collector {
 	return &Collector{
@@ -81,6 +299,50 @@ func (c *Collector) Collect(ctx context.Context, level componentsdk.Level) (*Org
 	if c.config.Organization == "" {
 		return nil, fmt.Errorf("organization is required")
 	}
+	if err := validateOwnerType(c.config.OwnerType); err != nil {
+		return nil, err
+	}
+	timer := newPhaseTimer()
+	defer c.startHeartbeat(ctx)()
+	listed, err := c.repositoryList()
+	if err != nil {
+		return nil, err
+	}
+	matcher, scopes := c.matcher, c.scopes
+	if matcher == nil {
+		if matcher, scopes, err = compileScopes(c.config); err != nil {
+			return nil, err
+		}
+	}
+	weights, err := resolveFeatureWeights(c.config.FeatureWeights)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateLookbackDays(c.config.LookbackDays); err != nil {
+		return nil, err
+	}
+	tiers, err := newRepoTiers(c.config)
+	if err != nil {
+		return nil, err
+	}
+	store := c.store
+	if store == nil && c.config.StateDir != "" {
+		if store, err = openStore(c.config); err != nil {
+			return nil, err
+		}
+	}
+	if store != nil && c.lock == nil {
+		// Held until Close, after Notify and FileRemediation have saved
+		// their state; an overlapping run for the account fails rather than
+		// interleaving its writes.
+		if c.lock, err = store.Lock(c.config.Organization); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.claimRun(store, time.Now()); err != nil {
+		return nil, err
+	}
+	user := c.config.OwnerType == OwnerTypeUser
 
 	includePatterns := c.config.IncludePatterns
 	if len(includePatterns) == 0 {
@@ -89,50 +351,246 @@ func (c *Collector) Collect(ctx context.Context, level componentsdk.Level) (*Org
 
 	posture := NewOrgPosture(c.config.Organization)
 	posture.CollectedAtLevel = string(level)
+	posture.OwnerType = OwnerTypeOrganization
+	if user {
+		posture.OwnerType = OwnerTypeUser
+	}
 
-	metrics := &metricsAggregator{}
+	metrics := &metricsAggregator{
+		scopes:           scopes,
+		weights:          weights,
+		excludeMirrors:   !c.config.IncludeMirrors,
+		excludeTemplates: c.config.ExcludeTemplates,
+	}
+	if c.config.CoverageBreakdown || tiers != nil {
+		metrics.segments = newCoverageSegments(tiers)
+	}
+	c.progressDiag = &metrics.diag
+	// Repository changes compare whole-account inventories, which an
+	// explicit repository list doesn't produce.
+	metrics.trackInventory = store != nil && listed == nil
+	// Only the audit/internal surfaces revisit individual repositories; at
+	// trust each page is aggregated and discarded.
+	metrics.repos.retain = level.AtLeast(componentsdk.LevelAudit)
+	metrics.repos.limit = c.config.MaxInMemoryRepos
+	defer func() { _ = metrics.repos.close() }()
 
-	c.status(fmt.Sprintf("Connecting to GitHub org %s...", c.config.Organization))
+	if c.config.InsecureSkipVerify {
+		metrics.diag.tlsVerificationDisabled()
+	}
+	if user {
+		metrics.diag.userAccount()
+	}
 
-	// Core surfaces degrade rather than fail the whole run: a permission gap or
-	// transient error on org security or the repo list records a diagnostic and
-	// the collector emits whatever else it can.
-	orgSecurity, err := c.client.FetchOrgSecurity(ctx, c.config.Organization)
-	if err != nil {
-		c.degradeCore(metrics, "organization_security", "organization administration: read", err)
-		orgSecurity = &github.OrgSecurity{}
+	c.status(fmt.Sprintf("Connecting to GitHub %s %s...", posture.OwnerType, c.config.Organization))
+
+	// Identify the instance before any concurrent use of the client, so the
+	// endpoints it doesn't serve are skipped rather than read as disabled.
+	if instance, err := c.client.DetectInstance(ctx); err != nil {
+		metrics.diag.instanceUndetected(err)
+	} else {
+		metrics.instance = instance
+		posture.Instance = newGitHubInstance(instance)
+		if !instance.Supports(github.FeatureCodeScanningDefaultSetup) {
+			metrics.diag.unsupportedOnInstance("code scanning default setup", instance)
+		}
+	}
+	// Rulesets protect branches alongside classic rules, so they're read
+	// before enumeration counts each repository's effective protection.
+	if !user {
+		c.loadRulesets(ctx, metrics, level)
+	}
+	// Likewise, tiers are assigned as each repository is enumerated.
+	if tiers != nil && tiers.usesProperties() && !user {
+		c.loadTierProperties(ctx, tiers, metrics)
 	}
 
-	c.status("Fetching repositories...")
+	// The org-level REST calls run alongside GraphQL repository enumeration,
+	// and per-repo security settings are fetched as soon as each included
+	// repository arrives. Core surfaces degrade rather than fail the whole
+	// run, so the goroutines never return an error: a permission gap or
+	// transient error on org security or the repo list records a diagnostic
+	// once every phase has finished, and the collector emits whatever else it
+	// can.
+	var (
+		orgSecurity *github.OrgSecurity
+		orgErr      error
+		reposErr    error
+		fetched     fetchedSettings
+	)
+	included := make(chan github.Repository, SettingsQueueSize)
+	var discovered atomic.Int64
 
-	repoCount := 0
-	err = c.client.FetchRepositories(ctx, c.config.Organization, func(repos []github.Repository) error {
-		for _, repo := range repos {
-			metrics.processRepository(repo, includePatterns, c.config.ExcludePatterns)
+	var g errgroup.Group
+	g.Go(func() error {
+		// User accounts have no org-level security settings; those fields
+		// stay nil (unknown).
+		if user {
+			orgSecurity = &github.OrgSecurity{}
+			return nil
 		}
-		repoCount += len(repos)
-		c.status(fmt.Sprintf("Found %d repositories...", repoCount))
+		orgSecurity, orgErr = c.client.FetchOrgSecurity(ctx, c.config.Organization)
 		return nil
 	})
-	if err != nil {
-		c.degradeCore(metrics, "repositories", "metadata: read", err)
-	}
+	g.Go(func() error {
+		defer timer.begin(PhaseRepositories)()
+		defer close(included)
+		if listed != nil {
+			reposErr = c.fetchListedRepositories(ctx, metrics, listed, matcher, included, &discovered)
+		} else {
+			reposErr = c.enumerateRepositories(ctx, metrics, matcher, included, &discovered)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		defer timer.begin(PhaseSecuritySettings)()
+		fetched = c.fetchSecuritySettings(ctx, included, scopes, metrics.rulesets, metrics.segments, &discovered)
+		return nil
+	})
+	_ = g.Wait()
+	enumerated := int64(metrics.totalRepos + metrics.excludedRepos + len(metrics.skipped))
+	c.phaseFinished(PhaseRepositories, enumerated, enumerated)
+	c.phaseFinished(PhaseSecuritySettings, int64(len(fetched.repos)+len(fetched.skipped)), discovered.Load())
 
-	c.fetchSecuritySettings(ctx, metrics)
+	// A cancelled run's fetch errors are the cancellation, not a gap in
+	// permissions or data; it is reported once, below.
+	cancelled := ctx.Err() != nil
+	// An exhausted primary rate limit would zero everything still to come,
+	// so the run fails as retryable rather than emit misleading output.
+	if err := c.rateLimited(); err != nil && !cancelled {
+		return nil, err
+	}
+	if orgErr != nil {
+		if !cancelled {
+			c.degradeCore(metrics, "organization_security", "organization administration: read", orgErr)
+		}
+		orgSecurity = &github.OrgSecurity{}
+	}
+	aborted := errors.Is(reposErr, errBudgetExhausted)
+	capped := errors.Is(reposErr, errRepositoryCap)
+	if capped {
+		metrics.diag.repositoryCapReached(c.config.MaxRepositories)
+	} else if reposErr != nil && !aborted && !cancelled {
+		c.degradeCore(metrics, "repositories", "metadata: read", reposErr)
+	}
+	fetched.apply(metrics)
 
 	c.populatePosture(posture, orgSecurity, metrics, includePatterns)
+	posture.Scope.ExplicitRepositoryCount = len(listed)
+	posture.Scope.Profile = c.config.Profile
+	if capped {
+		posture.Scope.Truncated = true
+		posture.Scope.MaxRepositories = c.config.MaxRepositories
+	}
 
-	c.collectSurfaces(ctx, posture, metrics, level)
+	// Stop before the surface pass too if the GraphQL budget ran low during
+	// the scan; what was collected so far is still emitted.
+	aborted = aborted || c.budgetExhausted()
+	switch {
+	case cancelled:
+		metrics.diag.cancelled(len(fetched.repos), metrics.totalRepos)
+	case aborted:
+		metrics.diag.budgetExhausted(c.config.AbortBelowRemaining)
+	case level.AtLeast(componentsdk.LevelAudit):
+		endSurfaces := timer.begin(PhaseSurfaces)
+		c.collectSurfaces(ctx, posture, metrics, level)
+		endSurfaces()
+		c.phaseFinished(PhaseSurfaces, 0, 0)
+		if err := c.rateLimited(); err != nil && ctx.Err() == nil {
+			return nil, err
+		}
+	}
+	partial := aborted || cancelled || capped
+	if c.config.CISBenchmark && !partial {
+		// Partial data would fail recommendations on unseen repositories.
+		posture.CISBenchmark = cisBenchmark(posture, metrics)
+	}
+	if metrics.trackInventory && !partial {
+		// A partial inventory would report unseen repositories as removed.
+		c.trackRepositoryChanges(ctx, store, posture, metrics, level)
+	}
+	if store != nil && !partial {
+		// A partial run's coverage would count as a day out of compliance.
+		c.trackComplianceSLAs(store, posture, metrics, time.Now())
+	}
+	posture.CollectionStats = c.collectionStats(aborted)
+	posture.CollectionStats.Cancelled = cancelled
+	timer.stats(&posture.CollectionStats)
+	if stats := c.client.Stats(); stats.DecodeErrors > 0 {
+		detail := stats.LastDecodeError
+		if c.config.RedactRepoNames {
+			// The detail names the request path, which can name a repository.
+			detail = ""
+		}
+		metrics.diag.malformedResponses(stats.DecodeErrors, detail)
+	}
+	if store != nil {
+		if files := store.Unencrypted(); len(files) > 0 {
+			metrics.diag.stateUnencrypted(files)
+		}
+	}
 
 	// Diagnostics are assembled last so surface-collector permission errors and
 	// feature-unavailable warnings are included alongside the core ones.
 	posture.Diagnostics = metrics.toDiagnostics()
 
+	if c.config.StrictMode {
+		if violations := strictModeViolations(posture); len(violations) > 0 {
+			return nil, strictModeError(violations)
+		}
+	}
+
 	c.status("Collection complete")
 
 	return posture, nil
 }
 
+// Close releases the account's state lock taken by Collect. Call it once
+// Notify and FileRemediation are done; it is a no-op without state_dir.
+func (c *Collector) Close() error {
+	if c.lock == nil {
+		return nil
+	}
+	lock := c.lock
+	c.lock = nil
+	return lock.Unlock()
+}
+
+// errBudgetExhausted stops repository enumeration once the GraphQL rate
+// limit drops below the configured abort threshold.
+var errBudgetExhausted = errors.New("GraphQL rate limit budget exhausted")
+
+// budgetExhausted reports whether the last-seen GraphQL rate limit remaining
+// is below AbortBelowRemaining. It is false when no threshold is configured or
+// no GraphQL query has returned yet.
+func (c *Collector) budgetExhausted() bool {
+	if c.config.AbortBelowRemaining <= 0 {
+		return false
+	}
+	remaining := c.client.Stats().RateLimitRemaining
+	return remaining != nil && *remaining < c.config.AbortBelowRemaining
+}
+
+// collectionStats converts the client's GraphQL usage to the output form.
+func (c *Collector) collectionStats(aborted bool) CollectionStats {
+	stats := c.client.Stats()
+	out := CollectionStats{
+		GraphQLQueries:     stats.GraphQLQueries,
+		GraphQLCost:        stats.GraphQLCost,
+		RateLimitRemaining: stats.RateLimitRemaining,
+		Aborted:            aborted,
+		GraphQLRetries:     stats.GraphQLRetries,
+		GraphQLSplitPages:  stats.GraphQLSplitPages,
+
+		SecondaryRateLimitPauses: stats.SecondaryRateLimitPauses,
+	}
+	if !stats.RateLimitResetAt.IsZero() {
+		out.RateLimitResetAt = formatTime(stats.RateLimitResetAt)
+	}
+	c.scheduleHints(&out, stats, time.Now())
+	return out
+}
+
 // degradeCore records a diagnostic for a failed core-surface fetch instead of
 // failing the run. A permission denial names the missing permission; any other
 // error becomes an informational warning. The caller proceeds with zeroed data.
@@ -151,6 +609,12 @@ type collectionPass struct {
 	metrics *metricsAggregator
 	level   componentsdk.Level
 	org     string
+	user    bool // the account is a user, so org-only calls are skipped
+
+	// rulesets are the org's rulesets, read once per run by loadRulesets;
+	// rulesetsRead is false when they couldn't be (or for user accounts).
+	rulesets     []github.Ruleset
+	rulesetsRead bool
 }
 
 // internal reports whether the pass is collecting at internal level.
@@ -163,6 +627,12 @@ func isDenied(err error) bool {
 	return err != nil && errors.Is(err, github.ErrPermissionDenied)
 }
 
+// isUnsupported reports whether err signals an endpoint the instance doesn't
+// serve (see github.Instance.Supports).
+func isUnsupported(err error) bool {
+	return err != nil && errors.Is(err, github.ErrUnsupported)
+}
+
 // isFeatureUnavailable reports whether err signals a missing org feature
 // (e.g. Enterprise-only audit log, fine-grained-token policy).
 func isFeatureUnavailable(err error) bool {
@@ -178,33 +648,87 @@ func (c *Collector) collectSurfaces(ctx context.Context, posture *OrgPosture, me
 		return
 	}
 
+	if c.config.ProgressFormat == ProgressFormatJSON {
+		c.progress(Progress{Phase: PhaseSurfaces, Detail: "collecting audit surfaces"})
+	} else {
+		c.status(PhaseSurfaces + ": collecting audit surfaces...")
+	}
+
 	p := &collectionPass{
 		ctx:     ctx,
 		posture: posture,
 		metrics: metrics,
 		level:   level,
 		org:     c.config.Organization,
+		user:    c.config.OwnerType == OwnerTypeUser,
+
+		rulesets:     metrics.rulesets,
+		rulesetsRead: metrics.rulesetsRead,
 	}
 
-	c.augmentAccessControl(p)
+	c.augmentScope(p)
+	if !p.user {
+		c.augmentAccessControl(p)
+	}
 	c.augmentSecurityFeatures(p)
 	c.collectRepositories(p)
 	c.collectCodeowners(p)
 	c.collectWebhooks(p)
 	c.collectDeployKeys(p)
+	c.collectVulnerabilityManagement(p)
+	c.collectTriage(p)
 	c.collectActions(p)
+	c.collectSecretsManagement(p)
+	if c.config.SearchCredentialFiles {
+		c.collectSecretsHygiene(p)
+	}
+	c.collectPublicExposure(p)
+	if p.rulesetsRead {
+		p.posture.Rulesets = rulesetSummary(p.rulesets, true)
+		p.posture.Rulesets.Truncated = p.metrics.rulesetsTruncated
+		p.posture.Rulesets.RepositoryRules = repositoryRules(p)
+	}
+	c.collectSupplyChain(p)
+	c.collectActionsSecurity(p)
+	if p.user {
+		// Members, audit log, App installations, fine-grained token grants,
+		// and community controls exist only for organizations.
+		return
+	}
 	// Per-member last-activity comes from the audit log, so it runs before the
 	// member inventory and feeds it the actor→last-activity map.
 	activity := c.collectAuditLog(p)
 	c.collectApps(p)
 	c.collectTokens(p)
+	c.collectCommunityControls(p)
 	c.collectMembers(p, activity)
 }
 
+// augmentScope adds the audit-level list of skipped repositories, sorted by
+// name, and the explicit repository list when one is configured. At trust only
+// their counts are reported.
+func (c *Collector) augmentScope(p *collectionPass) {
+	if listed, err := c.repositoryList(); err == nil {
+		for _, ref := range listed {
+			p.posture.Scope.ExplicitRepositories = append(p.posture.Scope.ExplicitRepositories, ref.String())
+		}
+	}
+
+	skipped := slices.Clone(p.metrics.skipped)
+	slices.SortFunc(skipped, func(a, b SkippedRepository) int {
+		return strings.Compare(a.Repository, b.Repository)
+	})
+	p.posture.Scope.SkippedRepositories = skipped
+}
+
 // augmentAccessControl adds audit-level org access-control fields (default repo
-// permission, members-can-create-repositories) from GET /orgs/{org}. On a
-// permission denial the fields stay zero/nil and a diagnostic is recorded.
+// permission, members-can-create-repositories) from GET /orgs/{org}, the
+// security manager role assignments, and the custom role inventory. On a permission denial the fields stay
+// zero/nil and a diagnostic is recorded.
 func (c *Collector) augmentAccessControl(p *collectionPass) {
+	c.augmentSecurityManagers(p)
+	c.augmentCustomRoles(p)
+
 	settings, err := c.client.GetOrgSettings(p.ctx, p.org)
 	if err != nil {
 		if isDenied(err) {
@@ -216,21 +740,221 @@ func (c *Collector) augmentAccessControl(p *collectionPass) {
 	p.posture.AccessControl.MembersCanCreateRepositories = settings.MembersCanCreateRepositories
 }
 
-// fetchSecuritySettings fetches REST API security settings for all repositories.
-func (c *Collector) fetchSecuritySettings(ctx context.Context, metrics *metricsAggregator) {
-	total := int64(len(metrics.repos.included))
-	for i, repo := range metrics.repos.included {
+// augmentSecurityManagers records which teams hold the org's security manager
+// role, sorted by slug. The endpoint 404s where the role isn't offered, which
+// is reported as unavailable rather than as "none configured".
+func (c *Collector) augmentSecurityManagers(p *collectionPass) {
+	teams, err := c.client.ListSecurityManagerTeams(p.ctx, p.org)
+	if err != nil {
+		switch {
+		case isDenied(err):
+			p.metrics.diag.surfacePermissionDenied("access_control.security_managers", "organization_administration:read")
+		case errors.Is(err, github.ErrNotFound):
+			p.metrics.diag.surfaceUnavailable("access_control.security_managers", "security manager role not available for this organization")
+		}
+		return
+	}
+	configured := len(teams) > 0
+	p.posture.AccessControl.SecurityManagersConfigured = &configured
+	p.posture.AccessControl.SecurityManagerTeams = slices.Sorted(slices.Values(teams))
+}
+
+// enumerateRepositories pages through the org's repositories, aggregating
+// each one and sending the in-scope ones to included for the settings phase.
+func (c *Collector) enumerateRepositories(ctx context.Context, metrics *metricsAggregator, matcher *RepoMatcher, included chan<- github.Repository, discovered *atomic.Int64) error {
+	c.status("Fetching repositories...")
+
+	fetch := c.client.FetchRepositories
+	if c.config.OwnerType == OwnerTypeUser {
+		fetch = c.client.FetchUserRepositories
+	}
+
+	tracker := newProgressTracker(PhaseRepositories, c.requestCount)
+	repoCount := 0
+	return fetch(ctx, c.config.Organization, func(repos []github.Repository) error {
+		for _, repo := range repos {
+			if limit := c.config.MaxRepositories; limit > 0 && metrics.inScope() >= limit && metrics.exclusion(repo, matcher) == "" {
+				return errRepositoryCap
+			}
+			if metrics.processRepository(repo, matcher) {
+				discovered.Add(1)
+				included <- repo
+			}
+		}
+		repoCount += len(repos)
+		// The total isn't known until the last page, so this is a status.
+		c.phaseStatus(tracker.update(int64(repoCount), 0, "enumerating"))
+		if c.budgetExhausted() {
+			return errBudgetExhausted
+		}
+		return nil
+	})
+}
+
+// repoSettings is one repository's fetched REST security settings, with its
+// coverage segment keys when the breakdown is kept.
+type repoSettings struct {
+	owner, name string
+	nonPublic   bool
+	settings    *github.SecuritySettings
+	segment     repoContext
+}
+
+// fetchedSettings is the output of the settings phase. It is gathered apart
+// from the metrics aggregator, which the enumeration phase is still writing,
+// and applied once both phases have finished.
+type fetchedSettings struct {
+	repos            []repoSettings
+	skipped          []SkippedRepository
+	permissionDenied int
+	checks           checksVerification
+
+	// skippedRepos are the repositories behind skipped, left out of the
+	// security feature denominators.
+	skippedRepos []github.Repository
+
+	// vulnerabilityAlertsUnknown lists the repositories ("owner/name") whose
+	// REST vulnerability-alerts check failed.
+	vulnerabilityAlertsUnknown []string
+}
+
+// apply folds the fetched settings into the aggregator.
+func (f fetchedSettings) apply(metrics *metricsAggregator) {
+	for _, r := range f.repos {
+		metrics.addSettings(r)
+	}
+	metrics.addSkipped(f.skipped...)
+	metrics.mu.Lock()
+	for _, repo := range f.skippedRepos {
+		metrics.skipSettings(repo)
+	}
+	metrics.securitySettingsPermissionDenied += f.permissionDenied
+	if len(f.vulnerabilityAlertsUnknown) > 0 && metrics.vulnerabilityAlertsUnknown == nil {
+		metrics.vulnerabilityAlertsUnknown = make(map[string]bool, len(f.vulnerabilityAlertsUnknown))
+	}
+	for _, repo := range f.vulnerabilityAlertsUnknown {
+		metrics.vulnerabilityAlertsUnknown[repo] = true
+	}
+	metrics.checks = f.checks
+	metrics.mu.Unlock()
+	if f.checks.permissionDenied {
+		metrics.diag.surfacePermissionDenied("branch_protection_rules.checks_verified", "checks:read, statuses:read")
+	}
+}
+
+// fetchSecuritySettings fetches REST API security settings for each included
+// repository as it arrives, until the enumeration phase closes the channel.
+// Repositories GraphQL reports without vulnerability alerts are rechecked
+// over REST.
+// A repository that errors is recorded as skipped and the rest proceed; once
+// ctx is done the remaining repositories are skipped without a request.
+// Progress totals are the repositories discovered so far. Required checks are
+// verified only for repositories in the branch_protection scope, against
+// their effective protection under rulesets. With segments, each
+// repository's segment keys are carried with its settings.
+func (c *Collector) fetchSecuritySettings(ctx context.Context, included <-chan github.Repository, scopes metricScopes, rulesets []github.Ruleset, segments *coverageSegments, discovered *atomic.Int64) fetchedSettings {
+	var fetched fetchedSettings
+	var i int64
+	tracker := newProgressTracker(PhaseSecuritySettings, c.requestCount)
+	for repo := range included {
+		i++
 		owner, name := repo.Owner.Login, repo.Name
-		c.progress(int64(i+1), total, fmt.Sprintf("Checking security settings for %s", name))
+		if ctx.Err() != nil {
+			// Cancelled: keep draining so enumeration can finish, but spend
+			// no more requests. The repository is reported as skipped and
+			// left out of the feature percentages rather than counted with
+			// every feature off.
+			fetched.skipped = append(fetched.skipped, SkippedRepository{Repository: owner + "/" + name, Reason: SkipReasonCancelled})
+			fetched.skippedRepos = append(fetched.skippedRepos, repo)
+			continue
+		}
+		c.progress(tracker.update(i, discovered.Load(), fmt.Sprintf("Checking security settings for %s", name)))
+		if c.config.VerifyRequiredChecks && scopes.includes(MetricBranchProtection, name) {
+			c.verifyRequiredChecks(ctx, repo, resolveProtection(repo, rulesets), &fetched.checks)
+		}
 		settings, err := c.client.FetchSecuritySettings(ctx, owner, name)
 		if err != nil {
 			if errors.Is(err, github.ErrPermissionDenied) {
-				metrics.trackSecuritySettingsPermissionDenied()
+				fetched.permissionDenied++
 			}
+			fetched.skipped = append(fetched.skipped, SkippedRepository{
+				Repository: owner + "/" + name,
+				Reason:     skipReason(err),
+			})
+			fetched.skippedRepos = append(fetched.skippedRepos, repo)
 			continue
 		}
-		metrics.countSecuritySettings(settings)
-		metrics.repos.recordSettings(owner, name, settings)
+		if !repo.HasVulnerabilityAlertsEnabled && scopes.includes(MetricVulnerabilityAlerts, name) {
+			settings = c.checkVulnerabilityAlerts(ctx, owner, name, settings, &fetched)
+		}
+		r := repoSettings{owner: owner, name: name, nonPublic: isNonPublic(repo), settings: settings}
+		if segments != nil {
+			r.segment = segments.context(repo)
+		}
+		fetched.repos = append(fetched.repos, r)
+	}
+	return fetched
+}
+
+// checkVulnerabilityAlerts confirms a GraphQL "alerts disabled" over REST,
+// which App installations can read when GraphQL under-reports. It returns a
+// copy of settings carrying the REST state; when the REST check fails the
+// state is unknown and the repository is left out of the percentage.
+func (c *Collector) checkVulnerabilityAlerts(ctx context.Context, owner, name string, settings *github.SecuritySettings, fetched *fetchedSettings) *github.SecuritySettings {
+	enabled, err := c.client.GetVulnerabilityAlertsEnabled(ctx, owner, name)
+	if err != nil {
+		fetched.vulnerabilityAlertsUnknown = append(fetched.vulnerabilityAlertsUnknown, owner+"/"+name)
+		return settings
+	}
+	checked := *settings
+	checked.VulnerabilityAlerts = &enabled
+	return &checked
+}
+
+// checksVerification tallies, for repos whose default-branch protection
+// requires status checks, whether every required check reported on the
+// branch's latest commit.
+type checksVerification struct {
+	evaluated        int
+	staleOrMissing   int
+	permissionDenied bool
+}
+
+// verifyRequiredChecks checks one repository's required status checks, from
+// its effective protection rule, against the check runs and commit statuses
+// on its default branch head. Repos with no named required checks, and repos
+// whose checks can't be read, are not evaluated.
+func (c *Collector) verifyRequiredChecks(ctx context.Context, repo github.Repository, rule *effectiveProtection, v *checksVerification) {
+	if rule == nil || !rule.RequiresStatusChecks || len(rule.RequiredStatusCheckContexts) == 0 {
+		return
+	}
+	reported, err := c.client.ListCommitCheckContexts(ctx, repo.Owner.Login, repo.Name, repo.DefaultBranchRef.Name)
+	if err != nil {
+		v.permissionDenied = v.permissionDenied || errors.Is(err, github.ErrPermissionDenied)
+		return
+	}
+	v.evaluated++
+	for _, required := range rule.RequiredStatusCheckContexts {
+		if !slices.Contains(reported, required) {
+			v.staleOrMissing++
+			return
+		}
+	}
+}
+
+// skipReason maps a per-repo fetch error to a SkippedRepository reason code.
+func skipReason(err error) string {
+	switch {
+	case errors.Is(err, github.ErrPermissionDenied):
+		return SkipReasonPermissionDenied
+	case errors.Is(err, github.ErrNotFound):
+		return SkipReasonNotFound
+	case errors.Is(err, github.ErrRepositoryBlocked):
+		return SkipReasonBlocked
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		return SkipReasonCancelled
+	default:
+		return SkipReasonFetchError
 	}
 }
 
@@ -247,19 +971,104 @@ func (c *Collector) populatePosture(posture *OrgPosture, orgSecurity *github.Org
 		IncludePatterns:      includePatterns,
 		ExcludePatterns:      excludePatterns,
 		RepositoriesCoverage: percent(metrics.totalRepos, totalOrgRepos),
+
+		ExcludedCounts: metrics.excludedByReason,
+
+		SkippedRepositoryCount: len(metrics.skipped),
+		MetricRepositoryCounts: metrics.metricRepositoryCounts(),
 	}
 
 	posture.Posture = Posture{
-		BranchProtectionCoverage: percent(metrics.branchProtectionEnabled, metrics.totalRepos),
+		BranchProtectionCoverage: percent(metrics.branchProtectionEnabled, metrics.reposIn(MetricBranchProtection)),
 		SecurityFeaturesCoverage: metrics.securityFeaturesCoverage(),
+		SecurityFeaturesWeights: SecurityFeatureWeights{
+			VulnerabilityAlerts:          metrics.featureWeight(FeatureVulnerabilityAlerts),
+			CodeScanning:                 metrics.featureWeight(FeatureCodeScanning),
+			SecretScanning:               metrics.featureWeight(FeatureSecretScanning),
+			SecretScanningPushProtection: metrics.featureWeight(FeatureSecretScanningPushProtection),
+			DependabotSecurityUpdates:    metrics.featureWeight(FeatureDependabotSecurityUpdates),
+		},
 	}
 
 	posture.AccessControl = AccessControl{
 		TwoFactorRequired: orgSecurity.TwoFactorRequired,
 	}
+	// Partial member lists would understate the gap, so they leave it
+	// unknown, as does a token that isn't an org owner's.
+	switch counts := orgSecurity.MembersWithout2FA; {
+	case counts != nil && counts.Truncated:
+		metrics.diag.addWarning(fmt.Sprintf("access_control.members_without_2fa unknown: member list truncated at %d", github.MemberFetchCap))
+	case counts != nil:
+		posture.AccessControl.MembersWithout2FA = &MembersWithout2FA{
+			Count:   counts.Without2FA,
+			Percent: percent(counts.Without2FA, counts.Members),
+		}
+	case orgSecurity.MembersWithout2FAErr != nil:
+		metrics.diag.addWarning("access_control.members_without_2fa unknown: listing members by 2FA status needs an org owner: " + orgSecurity.MembersWithout2FAErr.Error())
+	}
 
-	posture.BranchProtectionRules = metrics.toBranchProtectionRules()
+	posture.BranchProtectionRules = metrics.toBranchProtectionRules(c.config.MinRequiredReviews)
+	if c.config.VerifyRequiredChecks {
+		posture.BranchProtectionRules.ChecksVerified = &ChecksVerified{
+			ReposEvaluated: metrics.checks.evaluated,
+			StaleOrMissing: percent(metrics.checks.staleOrMissing, metrics.checks.evaluated),
+		}
+	}
 	posture.SecurityFeatures = metrics.toSecurityFeatures()
+	posture.CoverageBreakdown = metrics.segments.toCoverageBreakdown()
+	posture.SecurityFeatures.SecretScanningNonProviderPatternsOrgDefault = orgSecurity.SecretScanningNonProviderPatternsDefault
+	posture.SecurityFeatures.SecretScanningValidityChecksOrgDefault = orgSecurity.SecretScanningValidityChecksDefault
+	posture.RepositoryHygiene = metrics.toRepositoryHygiene(c.config.FlagLegacyDefaultBranch)
+	if metrics.rulesetsRead {
+		posture.Rulesets = rulesetSummary(metrics.rulesets, false)
+		posture.Rulesets.Truncated = metrics.rulesetsTruncated
+	}
+
+	defaults := orgSecurity.NewRepoDefaults
+	posture.OrgDefaults = OrgDefaults{
+		DependabotAlerts:             defaults.DependabotAlerts,
+		DependabotSecurityUpdates:    defaults.DependabotSecurityUpdates,
+		DependencyGraph:              defaults.DependencyGraph,
+		AdvancedSecurity:             defaults.AdvancedSecurity,
+		SecretScanning:               defaults.SecretScanning,
+		SecretScanningPushProtection: defaults.SecretScanningPushProtection,
+		PushProtectionForOrg:         orgSecurity.PushProtectionForOrg,
+
+		ContactEmail:   orgSecurity.ContactEmail,
+		SecurityPolicy: orgSecurity.SecurityPolicy,
+	}
+}
+
+// validateOwnerType rejects an owner_type other than organization or user.
+// Empty means organization.
+func validateOwnerType(ownerType string) error {
+	switch ownerType {
+	case "", OwnerTypeOrganization, OwnerTypeUser:
+		return nil
+	}
+	return fmt.Errorf("invalid owner_type %q: want %q or %q", ownerType, OwnerTypeOrganization, OwnerTypeUser)
+}
+
+// validateAPIURL rejects a github_api_url that isn't an absolute HTTP(S)
+// URL. Empty means GitHub.com.
+func validateAPIURL(apiURL string) error {
+	if apiURL == "" {
+		return nil
+	}
+	u, err := url.Parse(apiURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("github_api_url: must be an http(s) URL such as https://ghe.example.com/api/v3, got %q", apiURL)
+	}
+	return nil
+}
+
+// newGitHubInstance reports a detected instance and its capability matrix.
+func newGitHubInstance(instance github.Instance) *GitHubInstance {
+	out := &GitHubInstance{Product: InstanceProductGitHub, Capabilities: instance.Capabilities()}
+	if instance.Enterprise {
+		out.Product, out.Version = InstanceProductGHES, instance.Version
+	}
+	return out
 }
 
 // percent calculates the percentage of count over total, returning 0 if total is 0.
@@ -4,7 +4,15 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/locktivity/epack-collector-github/internal/github"
 	"github.com/locktivity/epack/componentsdk"
@@ -130,6 +138,33 @@ func TestOutputJSONStructure(t *testing.T) {
 		t.Error("access_control missing required field: two_factor_required")
 	}
 
+	// Check org_defaults fields: present (null when unknown) even at trust
+	orgDefaults, ok := data["org_defaults"].(map[string]interface{})
+	if !ok {
+		t.Fatal("org_defaults is not an object")
+	}
+	for _, field := range []string{
+		"dependabot_alerts", "dependabot_security_updates", "dependency_graph",
+		"advanced_security", "secret_scanning", "secret_scanning_push_protection",
+	} {
+		if v, ok := orgDefaults[field]; !ok {
+			t.Errorf("org_defaults missing required field: %s", field)
+		} else if v != nil {
+			t.Errorf("org_defaults.%s = %v, want null when unknown", field, v)
+		}
+	}
+
+	// Check collection_stats fields
+	collectionStats, ok := data["collection_stats"].(map[string]interface{})
+	if !ok {
+		t.Fatal("collection_stats is not an object")
+	}
+	for _, field := range []string{"graphql_queries", "graphql_cost", "rate_limit_remaining", "aborted"} {
+		if _, ok := collectionStats[field]; !ok {
+			t.Errorf("collection_stats missing required field: %s", field)
+		}
+	}
+
 	// Check branch_protection_rules fields
 	bpRules, ok := data["branch_protection_rules"].(map[string]interface{})
 	if !ok {
@@ -138,6 +173,8 @@ func TestOutputJSONStructure(t *testing.T) {
 	bpFields := []string{
 		"pull_request_required", "approving_reviews", "dismiss_stale_reviews",
 		"code_owner_reviews", "status_checks", "signed_commits", "admin_enforcement",
+		"linear_history", "force_pushes_allowed", "deletions_allowed",
+		"conversation_resolution", "last_push_approval",
 	}
 	for _, field := range bpFields {
 		if _, ok := bpRules[field]; !ok {
@@ -215,6 +252,43 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestNew_GitHubAPIURL(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("X-GitHub-Enterprise-Version", "enterprise-server@3.12.0")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c, err := New(Config{Organization: "test-org", GitHubToken: "test-token", GitHubAPIURL: server.URL + "/api/v3"})
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	instance, err := c.client.DetectInstance(context.Background())
+	if err != nil || instance.Version != "3.12.0" || gotPath != "/api/v3/meta" {
+		t.Errorf("DetectInstance() = %+v, %v at %q", instance, err, gotPath)
+	}
+
+	for _, apiURL := range []string{"ghe.example.com/api/v3", "ftp://ghe.example.com", "https://"} {
+		if _, err := New(Config{Organization: "test-org", GitHubToken: "test-token", GitHubAPIURL: apiURL}); err == nil {
+			t.Errorf("New() with github_api_url %q should fail", apiURL)
+		}
+	}
+}
+
+func TestNew_CaptureWithRedaction(t *testing.T) {
+	config := Config{
+		Organization:     "test-org",
+		GitHubToken:      "test-token",
+		RedactRepoNames:  true,
+		DebugCapturePath: t.TempDir() + "/capture.ndjson",
+	}
+	if _, err := New(config); err == nil || !strings.Contains(err.Error(), "redact_repo_names") {
+		t.Errorf("New() error = %v, want debug_capture_path refused with redact_repo_names", err)
+	}
+}
+
 func TestNew_AuthErrors(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -227,7 +301,15 @@ func TestNew_AuthErrors(t *testing.T) {
 				Organization: "test-org",
 				GitHubToken:  "",
 			},
-			wantErr: "authentication required: provide app_id + private_key (recommended) or github_token",
+			wantErr: "authentication required: provide app_id + private_key (recommended), token_command, or github_token",
+		},
+		{
+			name: "token_command without a program",
+			config: Config{
+				Organization: "test-org",
+				TokenCommand: []string{""},
+			},
+			wantErr: "token_command: the program is empty",
 		},
 		{
 			name: "app auth missing installation_id",
@@ -255,6 +337,29 @@ func TestNew_AuthErrors(t *testing.T) {
 	}
 }
 
+func TestAuthProvider(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		want   any
+	}{
+		{"app", Config{AppID: 1, InstallationID: 2, PrivateKey: "key", GitHubToken: "tok"}, github.AppAuth{}},
+		{"token command", Config{TokenCommand: []string{"vault-token"}, GitHubToken: "tok"}, github.CommandAuth{}},
+		{"token", Config{GitHubToken: "tok"}, github.TokenAuth{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := authProvider(tt.config)
+			if err != nil {
+				t.Fatalf("authProvider() error: %v", err)
+			}
+			if fmt.Sprintf("%T", got) != fmt.Sprintf("%T", tt.want) {
+				t.Errorf("authProvider() = %T, want %T", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestCollect_ValidationErrors(t *testing.T) {
 	// Test Collect() validation (organization check)
 	mock := &mockGitHubClient{}
@@ -276,17 +381,34 @@ func TestCollect_ValidationErrors(t *testing.T) {
 
 // mockGitHubClient implements github.GitHubClient for testing.
 type mockGitHubClient struct {
-	orgSecurity      *github.OrgSecurity
-	orgSecurityErr   error
-	repositories     []github.Repository
-	repositoriesErr  error
-	securitySettings map[string]*github.SecuritySettings // key: "owner/repo"
-	requestedRepos   []string
+	orgSecurity         *github.OrgSecurity
+	orgSecurityErr      error
+	repositories        []github.Repository
+	repositoriesErr     error
+	userRepositories    []github.Repository
+	userRepositoriesErr error
+	securitySettings    map[string]*github.SecuritySettings // key: "owner/repo"
+	securityErrs        map[string]error                    // key: "owner/repo"
+	requestedRepos      []string
+	checkContexts       map[string][]string // key: \"owner/repo@ref\"
+	checkContextsErr    error
+	vulnerabilityAlerts map[string]bool // key: "owner/repo"; absent = disabled
+	vulnAlertsErr       error
 
 	// Audit / internal surface fixtures.
 	orgSettings    *github.OrgSettings
 	orgSettingsErr error
 
+	securityManagers    []string
+	securityManagersErr error
+
+	lookups map[string]string // key: "owner/repo" → current full name; absent = not found
+
+	customOrgRoles     []github.CustomRole
+	customOrgRolesErr  error
+	customRepoRoles    []github.CustomRole
+	customRepoRolesErr error
+
 	alertCounts      map[string]*github.AlertCounts // key: "owner/repo"
 	alertCountsErr   error
 	secretAlerts     map[string][]github.SecretScanningAlert
@@ -303,23 +425,72 @@ type mockGitHubClient struct {
 	hooksErr        error
 	deployKeys      map[string][]github.DeployKey
 	deployKeysErr   error
+	advisories      map[string][]github.RepositoryAdvisory // key: "owner/repo"
+	advisoriesErr   error
+	labels          map[string][]string       // key: "owner/repo"
+	labelledIssues  map[string][]github.Issue // key: "owner/repo"
+	createdIssues   []github.IssueRequest
+	updatedIssues   map[int]github.IssueRequest
+	labelsErr       error
 	orgRunners      []github.Runner
 	repoRunners     map[string][]github.Runner
+	runnerGroups    []github.RunnerGroup
 	actionsErr      error
 	secretNames     []string
+	repoSecrets     map[string][]github.ActionsSecret // key: "owner/repo"
+	repoSecretsErr  error
 	auditEvents     []github.AuditEvent
 	auditMore       bool
+	auditSince      string
+	instance        github.Instance
+	instanceErr     error
 	auditErr        error
 	installations   []github.Installation
 	installationErr error
 	pats            []github.PATGrant
 	patsErr         error
+	patRequests     int
+	patRequestsErr  error
+
+	autoDismissed    []github.AutoDismissedAlert
+	autoDismissedErr error
+
+	patsTruncated        bool
+	patRequestsTruncated bool
+
+	interactionLimit    *github.InteractionLimit
+	interactionLimitErr error
+	blockedUsers        []string
+	blockedUsersErr     error
+	projects            []github.Project
+	projectsErr         error
+	rulesets            []github.Ruleset
+	rulesetsErr         error
+	rulesetsTruncated   bool
+	propertyValues      map[string]map[string][]string // repository name -> property -> values
+	propertyValuesErr   error
+	propertyValuesTrunc bool
+	workflowFiles       map[string]string   // "repositoryID:path" -> content
+	workflowPaths       map[string][]string // "owner/repo" -> workflow file paths
+	workflowPathsErr    error
+	releases            map[string][]github.Release // "owner/repo" -> newest first
+	releasesErr         error
+	attested            map[string]bool // digest -> has attestations
+	attestationsErr     error
+
+	codeSearch    map[string]*github.CodeSearchResult // key: query
+	codeSearchErr error
+
+	codeSearchErrs map[string]error // key: query
+
+	stats github.QueryStats
 }
 
 type codeownersFixture struct {
 	present bool
 	path    string
 	hash    string
+	team    string
 }
 
 func (m *mockGitHubClient) FetchOrgSecurity(ctx context.Context, org string) (*github.OrgSecurity, error) {
@@ -336,15 +507,48 @@ func (m *mockGitHubClient) FetchRepositories(ctx context.Context, org string, ca
 	return callback(m.repositories)
 }
 
+func (m *mockGitHubClient) FetchUserRepositories(ctx context.Context, login string, callback func([]github.Repository) error) error {
+	if m.userRepositoriesErr != nil {
+		return m.userRepositoriesErr
+	}
+	return callback(m.userRepositories)
+}
+
+func (m *mockGitHubClient) FetchRepository(ctx context.Context, owner, name string) (*github.Repository, error) {
+	for _, repo := range m.repositories {
+		if repo.Owner.Login == owner && repo.Name == name {
+			return &repo, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: repository %s/%s", github.ErrNotFound, owner, name)
+}
+
 func (m *mockGitHubClient) FetchSecuritySettings(ctx context.Context, owner, repo string) (*github.SecuritySettings, error) {
 	key := owner + "/" + repo
 	m.requestedRepos = append(m.requestedRepos, key)
+	if err, ok := m.securityErrs[key]; ok {
+		return nil, err
+	}
 	if settings, ok := m.securitySettings[key]; ok {
 		return settings, nil
 	}
 	return &github.SecuritySettings{}, nil
 }
 
+func (m *mockGitHubClient) GetVulnerabilityAlertsEnabled(ctx context.Context, owner, repo string) (bool, error) {
+	if m.vulnAlertsErr != nil {
+		return false, m.vulnAlertsErr
+	}
+	return m.vulnerabilityAlerts[owner+"/"+repo], nil
+}
+
+func (m *mockGitHubClient) ListCommitCheckContexts(ctx context.Context, owner, repo, ref string) ([]string, error) {
+	if m.checkContextsErr != nil {
+		return nil, m.checkContextsErr
+	}
+	return m.checkContexts[owner+"/"+repo+"@"+ref], nil
+}
+
 func (m *mockGitHubClient) GetOrgSettings(ctx context.Context, org string) (*github.OrgSettings, error) {
 	if m.orgSettingsErr != nil {
 		return nil, m.orgSettingsErr
@@ -355,6 +559,34 @@ func (m *mockGitHubClient) GetOrgSettings(ctx context.Context, org string) (*git
 	return &github.OrgSettings{}, nil
 }
 
+func (m *mockGitHubClient) LookupRepository(ctx context.Context, owner, name string) (string, error) {
+	if fullName, ok := m.lookups[owner+"/"+name]; ok {
+		return fullName, nil
+	}
+	return "", github.ErrNotFound
+}
+
+func (m *mockGitHubClient) ListSecurityManagerTeams(ctx context.Context, org string) ([]string, error) {
+	if m.securityManagersErr != nil {
+		return nil, m.securityManagersErr
+	}
+	return m.securityManagers, nil
+}
+
+func (m *mockGitHubClient) ListCustomOrgRoles(ctx context.Context, org string) ([]github.CustomRole, error) {
+	if m.customOrgRolesErr != nil {
+		return nil, m.customOrgRolesErr
+	}
+	return m.customOrgRoles, nil
+}
+
+func (m *mockGitHubClient) ListCustomRepoRoles(ctx context.Context, org string) ([]github.CustomRole, error) {
+	if m.customRepoRolesErr != nil {
+		return nil, m.customRepoRolesErr
+	}
+	return m.customRepoRoles, nil
+}
+
 func (m *mockGitHubClient) GetOpenAlertCounts(ctx context.Context, owner, repo string) (*github.AlertCounts, error) {
 	if m.alertCountsErr != nil {
 		return &github.AlertCounts{}, m.alertCountsErr
@@ -396,15 +628,16 @@ func (m *mockGitHubClient) GetOrgMembership(ctx context.Context, org string) (*g
 	return &github.OrgMembership{}, nil
 }
 
-func (m *mockGitHubClient) GetCodeownersInfo(ctx context.Context, owner, repo string, wantHash bool) (bool, string, string, error) {
+func (m *mockGitHubClient) GetCodeownersInfo(ctx context.Context, owner, repo string, detail bool) (*github.CodeownersInfo, error) {
 	if m.codeownersErr != nil {
-		return false, "", "", m.codeownersErr
+		return nil, m.codeownersErr
 	}
 	f := m.codeowners[owner+"/"+repo]
-	if !wantHash {
-		return f.present, f.path, "", nil
+	info := &github.CodeownersInfo{Present: f.present, Path: f.path}
+	if detail {
+		info.Hash, info.OwningTeam = f.hash, f.team
 	}
-	return f.present, f.path, f.hash, nil
+	return info, nil
 }
 
 func (m *mockGitHubClient) ListOrgHooks(ctx context.Context, org string) ([]github.Hook, error) {
@@ -428,6 +661,68 @@ func (m *mockGitHubClient) ListRepoDeployKeys(ctx context.Context, owner, repo s
 	return m.deployKeys[owner+"/"+repo], nil
 }
 
+func (m *mockGitHubClient) ListRepoLabels(ctx context.Context, owner, repo string) ([]string, error) {
+	if m.labelsErr != nil {
+		return nil, m.labelsErr
+	}
+	return m.labels[owner+"/"+repo], nil
+}
+
+func (m *mockGitHubClient) ListOpenIssuesWithLabel(ctx context.Context, owner, repo, label string) ([]github.Issue, error) {
+	return m.labelledIssues[owner+"/"+repo], nil
+}
+
+func (m *mockGitHubClient) ListOrgAutoDismissedAlerts(ctx context.Context, org string) ([]github.AutoDismissedAlert, bool, error) {
+	if m.autoDismissedErr != nil {
+		return nil, false, m.autoDismissedErr
+	}
+	return m.autoDismissed, false, nil
+}
+
+func (m *mockGitHubClient) CreateIssue(ctx context.Context, owner, repo string, issue github.IssueRequest) error {
+	m.createdIssues = append(m.createdIssues, issue)
+	return nil
+}
+
+func (m *mockGitHubClient) UpdateIssue(ctx context.Context, owner, repo string, number int, issue github.IssueRequest) error {
+	if m.updatedIssues == nil {
+		m.updatedIssues = map[int]github.IssueRequest{}
+	}
+	m.updatedIssues[number] = issue
+	return nil
+}
+
+func (m *mockGitHubClient) ListRepoActionsSecrets(ctx context.Context, owner, repo string) ([]github.ActionsSecret, error) {
+	if m.repoSecretsErr != nil {
+		return nil, m.repoSecretsErr
+	}
+	return m.repoSecrets[owner+"/"+repo], nil
+}
+
+func (m *mockGitHubClient) SearchCode(ctx context.Context, query string) (*github.CodeSearchResult, error) {
+	if m.codeSearchErr != nil {
+		return nil, m.codeSearchErr
+	}
+	if err := m.codeSearchErrs[query]; err != nil {
+		return nil, err
+	}
+	if r, ok := m.codeSearch[query]; ok {
+		return r, nil
+	}
+	return &github.CodeSearchResult{}, nil
+}
+
+func (m *mockGitHubClient) Stats() github.QueryStats {
+	return m.stats
+}
+
+func (m *mockGitHubClient) ListRepoSecurityAdvisories(ctx context.Context, owner, repo string) ([]github.RepositoryAdvisory, error) {
+	if m.advisoriesErr != nil {
+		return nil, m.advisoriesErr
+	}
+	return m.advisories[owner+"/"+repo], nil
+}
+
 func (m *mockGitHubClient) ListOrgRunners(ctx context.Context, org string) ([]github.Runner, error) {
 	if m.actionsErr != nil {
 		return nil, m.actionsErr
@@ -442,6 +737,13 @@ func (m *mockGitHubClient) ListRepoRunners(ctx context.Context, owner, repo stri
 	return m.repoRunners[owner+"/"+repo], nil
 }
 
+func (m *mockGitHubClient) ListRunnerGroups(ctx context.Context, org string) ([]github.RunnerGroup, error) {
+	if m.actionsErr != nil {
+		return nil, m.actionsErr
+	}
+	return m.runnerGroups, nil
+}
+
 func (m *mockGitHubClient) ListOrgActionsSecretNames(ctx context.Context, org string) ([]string, error) {
 	if m.actionsErr != nil {
 		return nil, m.actionsErr
@@ -449,7 +751,12 @@ func (m *mockGitHubClient) ListOrgActionsSecretNames(ctx context.Context, org st
 	return m.secretNames, nil
 }
 
+func (m *mockGitHubClient) DetectInstance(ctx context.Context) (github.Instance, error) {
+	return m.instance, m.instanceErr
+}
+
 func (m *mockGitHubClient) GetOrgAuditLog(ctx context.Context, org, sinceISO string, maxEvents int) ([]github.AuditEvent, bool, error) {
+	m.auditSince = sinceISO
 	if m.auditErr != nil {
 		return nil, false, m.auditErr
 	}
@@ -467,7 +774,73 @@ func (m *mockGitHubClient) ListOrgPATs(ctx context.Context, org string) ([]githu
 	if m.patsErr != nil {
 		return nil, false, m.patsErr
 	}
-	return m.pats, false, nil
+	return m.pats, m.patsTruncated, nil
+}
+
+func (m *mockGitHubClient) CountOrgPATRequests(ctx context.Context, org string) (int, bool, error) {
+	return m.patRequests, m.patRequestsTruncated, m.patRequestsErr
+}
+
+func (m *mockGitHubClient) GetOrgInteractionLimit(ctx context.Context, org string) (*github.InteractionLimit, error) {
+	return m.interactionLimit, m.interactionLimitErr
+}
+
+func (m *mockGitHubClient) ListOrgBlockedUsers(ctx context.Context, org string) ([]string, error) {
+	if m.blockedUsersErr != nil {
+		return nil, m.blockedUsersErr
+	}
+	return m.blockedUsers, nil
+}
+
+func (m *mockGitHubClient) ListOrgRulesets(ctx context.Context, org string) ([]github.Ruleset, bool, error) {
+	if m.rulesetsErr != nil {
+		return nil, false, m.rulesetsErr
+	}
+	return m.rulesets, m.rulesetsTruncated, nil
+}
+
+func (m *mockGitHubClient) ListRepositoryPropertyValues(ctx context.Context, org string) (map[string]map[string][]string, bool, error) {
+	if m.propertyValuesErr != nil {
+		return nil, false, m.propertyValuesErr
+	}
+	return m.propertyValues, m.propertyValuesTrunc, nil
+}
+
+func (m *mockGitHubClient) GetWorkflowFile(ctx context.Context, repositoryID int64, path, ref string) ([]byte, error) {
+	content, ok := m.workflowFiles[fmt.Sprintf("%d:%s", repositoryID, path)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", github.ErrNotFound, path)
+	}
+	return []byte(content), nil
+}
+
+func (m *mockGitHubClient) ListWorkflowFiles(ctx context.Context, owner, repo string) ([]string, error) {
+	if m.workflowPathsErr != nil {
+		return nil, m.workflowPathsErr
+	}
+	return m.workflowPaths[owner+"/"+repo], nil
+}
+
+func (m *mockGitHubClient) ListRecentReleases(ctx context.Context, owner, repo string, limit int) ([]github.Release, error) {
+	if m.releasesErr != nil {
+		return nil, m.releasesErr
+	}
+	releases := m.releases[owner+"/"+repo]
+	return releases[:min(limit, len(releases))], nil
+}
+
+func (m *mockGitHubClient) HasAttestations(ctx context.Context, owner, repo, digest string) (bool, error) {
+	if m.attestationsErr != nil {
+		return false, m.attestationsErr
+	}
+	return m.attested[digest], nil
+}
+
+func (m *mockGitHubClient) ListOrgProjects(ctx context.Context, org string) ([]github.Project, error) {
+	if m.projectsErr != nil {
+		return nil, m.projectsErr
+	}
+	return m.projects, nil
 }
 
 func TestCollect_EmptyOrganization(t *testing.T) {
@@ -499,6 +872,44 @@ func TestCollect_EmptyOrganization(t *testing.T) {
 	}
 }
 
+func TestCollect_MembersWithout2FA(t *testing.T) {
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{
+			TwoFactorRequired: boolPtr(false),
+			MembersWithout2FA: &github.MemberTwoFactorCounts{Without2FA: 3, Members: 40},
+		},
+	}
+	posture, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := posture.AccessControl.MembersWithout2FA; got == nil || *got != (MembersWithout2FA{Count: 3, Percent: 7}) {
+		t.Errorf("MembersWithout2FA = %+v, want 3 members, 7%%", got)
+	}
+
+	// Unknown without an owner's view of the members.
+	mock.orgSecurity.MembersWithout2FA = nil
+	mock.orgSecurity.MembersWithout2FAErr = fmt.Errorf("%w: members", github.ErrPermissionDenied)
+	posture, _ = NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if posture.AccessControl.MembersWithout2FA != nil {
+		t.Errorf("MembersWithout2FA = %+v, want nil when unknown", posture.AccessControl.MembersWithout2FA)
+	}
+	if d := posture.Diagnostics; d == nil || !anyContains(d.Warnings, "needs an org owner") {
+		t.Errorf("Diagnostics = %+v, want a warning for the refused 2FA filter", d)
+	}
+
+	// And unknown from truncated member lists.
+	mock.orgSecurity.MembersWithout2FA = &github.MemberTwoFactorCounts{Without2FA: 3, Members: 50000, Truncated: true}
+	mock.orgSecurity.MembersWithout2FAErr = nil
+	posture, _ = NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if posture.AccessControl.MembersWithout2FA != nil {
+		t.Errorf("MembersWithout2FA = %+v, want nil when truncated", posture.AccessControl.MembersWithout2FA)
+	}
+	if d := posture.Diagnostics; d == nil || !anyContains(d.Warnings, "member list truncated") {
+		t.Errorf("Diagnostics = %+v, want a truncation warning", d)
+	}
+}
+
 func TestCollect_FullOrganization(t *testing.T) {
 	mock := &mockGitHubClient{
 		orgSecurity: &github.OrgSecurity{
@@ -596,31 +1007,619 @@ func TestCollect_FullOrganization(t *testing.T) {
 		t.Errorf("TwoFactorRequired = %v, want true", posture.AccessControl.TwoFactorRequired)
 	}
 
-	// Check branch protection (2/3 repos have it = 66%)
-	if posture.Posture.BranchProtectionCoverage != 66 {
-		t.Errorf("BranchProtectionCoverage = %d, want 66", posture.Posture.BranchProtectionCoverage)
+	// Check branch protection (2/3 repos have it = 66%)
+	if posture.Posture.BranchProtectionCoverage != 66 {
+		t.Errorf("BranchProtectionCoverage = %d, want 66", posture.Posture.BranchProtectionCoverage)
+	}
+
+	// Check individual branch protection rules
+	if posture.BranchProtectionRules.ApprovingReviews != 66 { // 2/3
+		t.Errorf("ApprovingReviews = %d, want 66", posture.BranchProtectionRules.ApprovingReviews)
+	}
+	if posture.BranchProtectionRules.DismissStaleReviews != 33 { // 1/3
+		t.Errorf("DismissStaleReviews = %d, want 33", posture.BranchProtectionRules.DismissStaleReviews)
+	}
+	if posture.BranchProtectionRules.StatusChecks != 66 { // 2/3
+		t.Errorf("StatusChecks = %d, want 66", posture.BranchProtectionRules.StatusChecks)
+	}
+
+	// Check security features
+	if posture.SecurityFeatures.VulnerabilityAlerts != 66 { // 2/3
+		t.Errorf("VulnerabilityAlerts = %d, want 66", posture.SecurityFeatures.VulnerabilityAlerts)
+	}
+	if posture.SecurityFeatures.SecretScanning != 66 { // 2/3
+		t.Errorf("SecretScanning = %d, want 66", posture.SecurityFeatures.SecretScanning)
+	}
+	if posture.SecurityFeatures.CodeScanning != 33 { // 1/3
+		t.Errorf("CodeScanning = %d, want 33", posture.SecurityFeatures.CodeScanning)
+	}
+}
+
+func TestCollect_BranchProtectionHistoryRules(t *testing.T) {
+	repo := func(name string, bp *github.BranchProtectionRule) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		r.DefaultBranchRef.Name = "main"
+		r.DefaultBranchRef.BranchProtectionRule = bp
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			repo("repo1", &github.BranchProtectionRule{RequiresLinearHistory: true, RequiresConversationResolution: true}),
+			repo("repo2", &github.BranchProtectionRule{AllowsForcePushes: true, AllowsDeletions: true, RequireLastPushApproval: true}),
+			repo("repo3", &github.BranchProtectionRule{AllowsForcePushes: true}),
+			repo("repo4", nil),
+		},
+	}
+
+	posture, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rules := posture.BranchProtectionRules
+	if rules.LinearHistory != 25 {
+		t.Errorf("LinearHistory = %d, want 25", rules.LinearHistory)
+	}
+	if rules.ForcePushesAllowed != 50 {
+		t.Errorf("ForcePushesAllowed = %d, want 50", rules.ForcePushesAllowed)
+	}
+	if rules.DeletionsAllowed != 25 {
+		t.Errorf("DeletionsAllowed = %d, want 25", rules.DeletionsAllowed)
+	}
+	if rules.ConversationResolution != 25 {
+		t.Errorf("ConversationResolution = %d, want 25", rules.ConversationResolution)
+	}
+	if rules.LastPushApproval != 25 {
+		t.Errorf("LastPushApproval = %d, want 25", rules.LastPushApproval)
+	}
+}
+
+func TestCollect_RequiredReviewCounts(t *testing.T) {
+	repo := func(name string, bp *github.BranchProtectionRule) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		r.DefaultBranchRef.Name = "main"
+		r.DefaultBranchRef.BranchProtectionRule = bp
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			repo("repo1", &github.BranchProtectionRule{RequiresApprovingReviews: true, RequiredApprovingReviewCount: 1}),
+			repo("repo2", &github.BranchProtectionRule{RequiresApprovingReviews: true, RequiredApprovingReviewCount: 2}),
+			repo("repo3", &github.BranchProtectionRule{RequiresApprovingReviews: true, RequiredApprovingReviewCount: 4}),
+			repo("repo4", nil),
+		},
+	}
+
+	posture, err := NewWithClient(Config{Organization: "test-org", MinRequiredReviews: 2}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts := posture.BranchProtectionRules.RequiredReviewCounts
+	if counts.One != 25 || counts.Two != 25 || counts.ThreeOrMore != 25 {
+		t.Errorf("RequiredReviewCounts = %+v, want 25/25/25", counts)
+	}
+	check := posture.BranchProtectionRules.MinRequiredReviews
+	if check == nil {
+		t.Fatal("MinRequiredReviews should be set when configured")
+	}
+	if check.Threshold != 2 || check.Compliant != 50 {
+		t.Errorf("MinRequiredReviews = %+v, want threshold 2, compliant 50", check)
+	}
+
+	posture, err = NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if posture.BranchProtectionRules.MinRequiredReviews != nil {
+		t.Error("MinRequiredReviews should be omitted when not configured")
+	}
+}
+
+func TestCollect_ChecksVerified(t *testing.T) {
+	repo := func(name string, contexts ...string) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		r.DefaultBranchRef.Name = "main"
+		r.DefaultBranchRef.BranchProtectionRule = &github.BranchProtectionRule{
+			RequiresStatusChecks:        len(contexts) > 0,
+			RequiredStatusCheckContexts: contexts,
+		}
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			repo("fresh", "build", "ci/lint"),
+			repo("missing", "build", "deploy-gate"),
+			repo("no-runs", "build"),
+			repo("unprotected"),
+		},
+		checkContexts: map[string][]string{
+			"test-org/fresh@main":   {"build", "ci/lint", "extra"},
+			"test-org/missing@main": {"build"},
+		},
+	}
+
+	posture, err := NewWithClient(Config{Organization: "test-org", VerifyRequiredChecks: true}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := posture.BranchProtectionRules.ChecksVerified
+	if got == nil {
+		t.Fatal("ChecksVerified should be set when verify_required_checks is configured")
+	}
+	if got.ReposEvaluated != 3 || got.StaleOrMissing != 66 {
+		t.Errorf("ChecksVerified = %+v, want 3 evaluated, 66%% stale or missing", got)
+	}
+
+	mock.checkContextsErr = fmt.Errorf("%w: check-runs (status 403)", github.ErrPermissionDenied)
+	posture, err = NewWithClient(Config{Organization: "test-org", VerifyRequiredChecks: true}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := posture.BranchProtectionRules.ChecksVerified; got == nil || got.ReposEvaluated != 0 {
+		t.Errorf("ChecksVerified = %+v, want 0 evaluated when checks are unreadable", got)
+	}
+	if posture.Diagnostics == nil || !anyContains(posture.Diagnostics.PermissionErrors, "checks:read") {
+		t.Errorf("expected a checks permission diagnostic, got %+v", posture.Diagnostics)
+	}
+
+	posture, err = NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if posture.BranchProtectionRules.ChecksVerified != nil {
+		t.Error("ChecksVerified should be omitted when not configured")
+	}
+}
+
+func TestCollect_SecretScanningExtendedSettings(t *testing.T) {
+	repo := func(name string) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		r.DefaultBranchRef.Name = "main"
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{
+			SecretScanningNonProviderPatternsDefault: boolPtr(true),
+			SecretScanningValidityChecksDefault:      boolPtr(false),
+		},
+		repositories: []github.Repository{
+			repo("repo1"),
+			repo("repo2"),
+		},
+		securitySettings: map[string]*github.SecuritySettings{
+			"test-org/repo1": {SecretScanning: true, SecretScanningNonProviderPatterns: true, SecretScanningValidityChecks: true},
+			"test-org/repo2": {SecretScanning: true, SecretScanningValidityChecks: true},
+		},
+	}
+
+	posture, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	features := posture.SecurityFeatures
+	if features.SecretScanningNonProviderPatterns != 50 {
+		t.Errorf("SecretScanningNonProviderPatterns = %d, want 50", features.SecretScanningNonProviderPatterns)
+	}
+	if features.SecretScanningNonProviderPatternsOrgDefault == nil || !*features.SecretScanningNonProviderPatternsOrgDefault {
+		t.Errorf("SecretScanningNonProviderPatternsOrgDefault = %v, want true", features.SecretScanningNonProviderPatternsOrgDefault)
+	}
+	if features.SecretScanningValidityChecks != 100 {
+		t.Errorf("SecretScanningValidityChecks = %d, want 100", features.SecretScanningValidityChecks)
+	}
+	if features.SecretScanningValidityChecksOrgDefault == nil || *features.SecretScanningValidityChecksOrgDefault {
+		t.Errorf("SecretScanningValidityChecksOrgDefault = %v, want false", features.SecretScanningValidityChecksOrgDefault)
+	}
+	// Not part of the composite score: two of five features on two repos.
+	if posture.Posture.SecurityFeaturesCoverage != 20 {
+		t.Errorf("SecurityFeaturesCoverage = %d, want 20", posture.Posture.SecurityFeaturesCoverage)
+	}
+}
+
+// pipelinedClient blocks the second repository page until the first page's
+// settings have been fetched, and blocks org security until enumeration has
+// started, so Collect only completes if the phases overlap.
+type pipelinedClient struct {
+	*mockGitHubClient
+	enumerating   chan struct{}
+	firstSettings chan struct{}
+	once          bool
+}
+
+func (p *pipelinedClient) FetchOrgSecurity(ctx context.Context, org string) (*github.OrgSecurity, error) {
+	select {
+	case <-p.enumerating:
+	case <-time.After(5 * time.Second):
+		return nil, errors.New("org security was not fetched concurrently with enumeration")
+	}
+	return p.mockGitHubClient.FetchOrgSecurity(ctx, org)
+}
+
+func (p *pipelinedClient) FetchRepositories(ctx context.Context, org string, callback func([]github.Repository) error) error {
+	close(p.enumerating)
+	for i, repo := range p.repositories {
+		if i == 1 {
+			select {
+			case <-p.firstSettings:
+			case <-time.After(5 * time.Second):
+				return errors.New("settings were not fetched while enumeration was in progress")
+			}
+		}
+		if err := callback([]github.Repository{repo}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *pipelinedClient) FetchSecuritySettings(ctx context.Context, owner, repo string) (*github.SecuritySettings, error) {
+	if !p.once {
+		p.once = true
+		close(p.firstSettings)
+	}
+	return p.mockGitHubClient.FetchSecuritySettings(ctx, owner, repo)
+}
+
+func TestCollect_PhasesOverlap(t *testing.T) {
+	repo := func(name string) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		return r
+	}
+	client := &pipelinedClient{
+		mockGitHubClient: &mockGitHubClient{
+			orgSecurity:  &github.OrgSecurity{TwoFactorRequired: boolPtr(true)},
+			repositories: []github.Repository{repo("repo1"), repo("repo2")},
+			securitySettings: map[string]*github.SecuritySettings{
+				"test-org/repo1": {SecretScanning: true},
+				"test-org/repo2": {SecretScanning: true},
+			},
+		},
+		enumerating:   make(chan struct{}),
+		firstSettings: make(chan struct{}),
+	}
+
+	posture, err := NewWithClient(Config{Organization: "test-org"}, client).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if posture.Diagnostics != nil {
+		t.Fatalf("phases did not overlap: %+v", posture.Diagnostics)
+	}
+	if posture.AccessControl.TwoFactorRequired == nil || !*posture.AccessControl.TwoFactorRequired {
+		t.Errorf("TwoFactorRequired = %v, want true", posture.AccessControl.TwoFactorRequired)
+	}
+	if posture.SecurityFeatures.SecretScanning != 100 {
+		t.Errorf("SecretScanning = %d, want 100", posture.SecurityFeatures.SecretScanning)
+	}
+	if len(client.requestedRepos) != 2 {
+		t.Errorf("requested settings for %v, want both repos", client.requestedRepos)
+	}
+}
+
+func TestCollect_RepositoryList(t *testing.T) {
+	repo := func(owner, name string, alerts bool) github.Repository {
+		r := github.Repository{Name: name, HasVulnerabilityAlertsEnabled: alerts}
+		r.Owner.Login = owner
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			repo("test-org", "api", true),
+			repo("test-org", "web", false),
+			repo("other-org", "shared", true),
+			repo("test-org", "unlisted", false),
+		},
+		// Org-wide enumeration must not run when a list is configured.
+		repositoriesErr: errors.New("FetchRepositories called"),
+	}
+	config := Config{
+		Organization: "test-org",
+		Repositories: []string{"api", "test-org/web", "other-org/shared", "test-org/API", "test-org/missing"},
+	}
+
+	posture, err := NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if posture.Diagnostics != nil {
+		t.Errorf("unexpected diagnostics: %+v", posture.Diagnostics)
+	}
+	if got, want := posture.SecurityFeatures.VulnerabilityAlerts, 66; got != want {
+		t.Errorf("VulnerabilityAlerts = %d, want %d (2 of the 3 listed repos found)", got, want)
+	}
+	if got := posture.Scope.ExplicitRepositoryCount; got != 4 {
+		t.Errorf("ExplicitRepositoryCount = %d, want 4 (duplicate dropped)", got)
+	}
+	if posture.Scope.ExplicitRepositories != nil {
+		t.Error("trust output should not name the listed repositories")
+	}
+	if posture.Scope.SkippedRepositoryCount != 1 {
+		t.Errorf("SkippedRepositoryCount = %d, want 1 (missing repo)", posture.Scope.SkippedRepositoryCount)
+	}
+	if want := []string{"test-org/api", "test-org/web", "other-org/shared"}; !slices.Equal(mock.requestedRepos, want) {
+		t.Errorf("requested settings for %v, want %v", mock.requestedRepos, want)
+	}
+
+	mock.requestedRepos = nil
+	posture, err = NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"test-org/api", "test-org/web", "other-org/shared", "test-org/missing"}; !slices.Equal(posture.Scope.ExplicitRepositories, want) {
+		t.Errorf("ExplicitRepositories = %v, want %v", posture.Scope.ExplicitRepositories, want)
+	}
+	want := []SkippedRepository{{Repository: "test-org/missing", Reason: SkipReasonNotFound}}
+	if !slices.Equal(posture.Scope.SkippedRepositories, want) {
+		t.Errorf("SkippedRepositories = %+v, want %+v", posture.Scope.SkippedRepositories, want)
+	}
+}
+
+func TestParseRepositoryList_Invalid(t *testing.T) {
+	for _, entry := range []string{"", "/repo", "owner/", "a/b/c"} {
+		if _, err := parseRepositoryList([]string{entry}, "test-org"); err == nil {
+			t.Errorf("parseRepositoryList(%q) = nil error, want error", entry)
+		}
+	}
+	if _, err := New(Config{Organization: "test-org", GitHubToken: "t", Repositories: []string{"a/b/c"}}); err == nil {
+		t.Error("New() should reject an invalid repositories entry")
+	}
+}
+
+// budgetClient returns one repository per page and lowers the reported
+// GraphQL rate limit remaining by 100 after each page. Stats are read by
+// the settings phase while enumeration pages, so they're guarded.
+type budgetClient struct {
+	*mockGitHubClient
+	mu        sync.Mutex
+	remaining int
+	pages     int
+}
+
+func (b *budgetClient) Stats() github.QueryStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stats
+}
+
+func (b *budgetClient) FetchRepositories(ctx context.Context, org string, callback func([]github.Repository) error) error {
+	for _, repo := range b.repositories {
+		b.mu.Lock()
+		b.pages++
+		b.remaining -= 100
+		remaining := b.remaining
+		b.stats = github.QueryStats{GraphQLQueries: b.pages, GraphQLCost: b.pages, RateLimitRemaining: &remaining}
+		b.mu.Unlock()
+		if err := callback([]github.Repository{repo}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestCollect_AbortBelowRemaining(t *testing.T) {
+	repo := func(name string) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		return r
+	}
+	client := &budgetClient{
+		mockGitHubClient: &mockGitHubClient{
+			orgSecurity:  &github.OrgSecurity{},
+			repositories: []github.Repository{repo("repo1"), repo("repo2"), repo("repo3"), repo("repo4")},
+			orgSettings:  &github.OrgSettings{},
+		},
+		remaining: 500,
+	}
+
+	// Remaining drops to 400, then 300: enumeration stops after the second page.
+	config := Config{Organization: "test-org", AbortBelowRemaining: 350}
+	posture, err := NewWithClient(config, client).Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if client.pages != 2 {
+		t.Errorf("fetched %d pages, want 2", client.pages)
+	}
+	stats := posture.CollectionStats
+	if !stats.Aborted {
+		t.Error("CollectionStats.Aborted = false, want true")
+	}
+	if stats.GraphQLQueries != 2 || stats.GraphQLCost != 2 {
+		t.Errorf("GraphQL queries/cost = %d/%d, want 2/2", stats.GraphQLQueries, stats.GraphQLCost)
+	}
+	if stats.RateLimitRemaining == nil || *stats.RateLimitRemaining != 300 {
+		t.Errorf("RateLimitRemaining = %v, want 300", stats.RateLimitRemaining)
+	}
+	if len(client.requestedRepos) != 2 {
+		t.Errorf("requested settings for %v, want only the repos found before the abort", client.requestedRepos)
+	}
+	if posture.Repositories != nil || posture.Codeowners != nil {
+		t.Error("audit surfaces should be skipped once the budget is exhausted")
+	}
+	if posture.Diagnostics == nil || len(posture.Diagnostics.Warnings) != 1 {
+		t.Errorf("expected a single budget warning, got %+v", posture.Diagnostics)
+	}
+
+	// No threshold: the full scan completes.
+	client = &budgetClient{mockGitHubClient: client.mockGitHubClient, remaining: 500}
+	posture, err = NewWithClient(Config{Organization: "test-org"}, client).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if client.pages != 4 || posture.CollectionStats.Aborted {
+		t.Errorf("pages = %d, aborted = %v; want 4, false", client.pages, posture.CollectionStats.Aborted)
+	}
+}
+
+// cancelClient cancels the run once the first repository's security
+// settings have been fetched.
+type cancelClient struct {
+	*mockGitHubClient
+	cancel context.CancelFunc
+}
+
+func (c *cancelClient) FetchSecuritySettings(ctx context.Context, owner, repo string) (*github.SecuritySettings, error) {
+	defer c.cancel()
+	return c.mockGitHubClient.FetchSecuritySettings(ctx, owner, repo)
+}
+
+func TestCollect_CancelledMidScan(t *testing.T) {
+	repo := func(name string) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		return r
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &cancelClient{
+		mockGitHubClient: &mockGitHubClient{
+			orgSecurity:  &github.OrgSecurity{},
+			repositories: []github.Repository{repo("repo1"), repo("repo2"), repo("repo3")},
+			orgSettings:  &github.OrgSettings{},
+			securitySettings: map[string]*github.SecuritySettings{
+				"test-org/repo1": {SecretScanning: true, DependabotSecurityUpdates: true},
+			},
+		},
+		cancel: cancel,
+	}
+
+	posture, err := NewWithClient(Config{Organization: "test-org"}, client).Collect(ctx, componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(client.requestedRepos) != 1 {
+		t.Errorf("requested settings for %v, want only the repo before cancellation", client.requestedRepos)
+	}
+	if !posture.CollectionStats.Cancelled || posture.CollectionStats.Aborted {
+		t.Errorf("CollectionStats = %+v, want cancelled, not aborted", posture.CollectionStats)
+	}
+	if posture.Scope.SkippedRepositoryCount != 2 {
+		t.Errorf("SkippedRepositoryCount = %d, want the 2 unchecked repos", posture.Scope.SkippedRepositoryCount)
+	}
+	// The unchecked repos are left out of the percentages, not counted off.
+	sf := posture.SecurityFeatures
+	if sf.SecretScanning != 100 || sf.DependabotSecurityUpdates != 100 {
+		t.Errorf("SecretScanning = %d, DependabotSecurityUpdates = %d, want 100 over the checked repo", sf.SecretScanning, sf.DependabotSecurityUpdates)
+	}
+	for _, metric := range []string{"secret_scanning", "dependabot_security_updates"} {
+		if got := sf.ApplicableRepos[metric]; got != 1 {
+			t.Errorf("ApplicableRepos[%s] = %d, want 1", metric, got)
+		}
+	}
+	if posture.Repositories != nil {
+		t.Error("audit surfaces should be skipped once cancelled")
+	}
+	if d := posture.Diagnostics; d == nil || len(d.Warnings) != 1 || !strings.Contains(d.Warnings[0], "cancelled") || len(d.PermissionErrors) != 0 {
+		t.Errorf("expected a single cancellation warning, got %+v", d)
+	}
+}
+
+func TestCollect_SkippedRepositories(t *testing.T) {
+	repo := func(name string) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity:  &github.OrgSecurity{},
+		repositories: []github.Repository{repo("ok"), repo("takedown"), repo("denied"), repo("flaky")},
+		securitySettings: map[string]*github.SecuritySettings{
+			"test-org/ok": {SecretScanning: true},
+		},
+		securityErrs: map[string]error{
+			"test-org/takedown": fmt.Errorf("%w: /repos/test-org/takedown (status 451)", github.ErrRepositoryBlocked),
+			"test-org/denied":   fmt.Errorf("%w: /repos/test-org/denied (status 403)", github.ErrPermissionDenied),
+			"test-org/flaky":    errors.New("/repos/test-org/flaky returned status 502"),
+		},
+	}
+
+	// Trust: count only, no repository names.
+	posture, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if posture.Scope.SkippedRepositoryCount != 3 {
+		t.Errorf("SkippedRepositoryCount = %d, want 3", posture.Scope.SkippedRepositoryCount)
+	}
+	if posture.Scope.SkippedRepositories != nil {
+		t.Errorf("SkippedRepositories should be omitted at trust, got %v", posture.Scope.SkippedRepositories)
+	}
+	// Skipped repos leave the denominators: the one healthy repo is 100%.
+	sf := posture.SecurityFeatures
+	if sf.SecretScanning != 100 {
+		t.Errorf("SecretScanning = %d, want 100 (skipped repos excluded)", sf.SecretScanning)
+	}
+	for _, metric := range []string{"secret_scanning", "code_scanning", "dependabot_security_updates"} {
+		if got := sf.ApplicableRepos[metric]; got != 1 {
+			t.Errorf("ApplicableRepos[%s] = %d, want 1", metric, got)
+		}
+	}
+
+	// Audit: named, sorted, with reason codes.
+	mock.requestedRepos = nil
+	posture, err = NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []SkippedRepository{
+		{Repository: "test-org/denied", Reason: SkipReasonPermissionDenied},
+		{Repository: "test-org/flaky", Reason: SkipReasonFetchError},
+		{Repository: "test-org/takedown", Reason: SkipReasonBlocked},
+	}
+	got := posture.Scope.SkippedRepositories
+	if len(got) != len(want) {
+		t.Fatalf("SkippedRepositories = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SkippedRepositories[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCollect_OrgDefaults(t *testing.T) {
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{
+			NewRepoDefaults: github.NewRepoDefaults{
+				DependabotAlerts: boolPtr(true),
+				SecretScanning:   boolPtr(false),
+			},
+			PushProtectionForOrg: boolPtr(false),
+			SecurityPolicy:       boolPtr(true),
+		},
 	}
 
-	// Check individual branch protection rules
-	if posture.BranchProtectionRules.ApprovingReviews != 66 { // 2/3
-		t.Errorf("ApprovingReviews = %d, want 66", posture.BranchProtectionRules.ApprovingReviews)
+	posture, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
-	if posture.BranchProtectionRules.DismissStaleReviews != 33 { // 1/3
-		t.Errorf("DismissStaleReviews = %d, want 33", posture.BranchProtectionRules.DismissStaleReviews)
+
+	defaults := posture.OrgDefaults
+	if defaults.DependabotAlerts == nil || !*defaults.DependabotAlerts {
+		t.Errorf("DependabotAlerts = %v, want true", defaults.DependabotAlerts)
 	}
-	if posture.BranchProtectionRules.StatusChecks != 66 { // 2/3
-		t.Errorf("StatusChecks = %d, want 66", posture.BranchProtectionRules.StatusChecks)
+	if defaults.SecretScanning == nil || *defaults.SecretScanning {
+		t.Errorf("SecretScanning = %v, want false", defaults.SecretScanning)
 	}
-
-	// Check security features
-	if posture.SecurityFeatures.VulnerabilityAlerts != 66 { // 2/3
-		t.Errorf("VulnerabilityAlerts = %d, want 66", posture.SecurityFeatures.VulnerabilityAlerts)
+	if defaults.AdvancedSecurity != nil {
+		t.Errorf("AdvancedSecurity = %v, want nil (unknown)", defaults.AdvancedSecurity)
 	}
-	if posture.SecurityFeatures.SecretScanning != 66 { // 2/3
-		t.Errorf("SecretScanning = %d, want 66", posture.SecurityFeatures.SecretScanning)
+	if defaults.PushProtectionForOrg == nil || *defaults.PushProtectionForOrg {
+		t.Errorf("PushProtectionForOrg = %v, want false", defaults.PushProtectionForOrg)
 	}
-	if posture.SecurityFeatures.CodeScanning != 33 { // 1/3
-		t.Errorf("CodeScanning = %d, want 33", posture.SecurityFeatures.CodeScanning)
+	if defaults.SecurityPolicy == nil || !*defaults.SecurityPolicy || defaults.ContactEmail != nil {
+		t.Errorf("SecurityPolicy/ContactEmail = %v/%v, want true/nil", defaults.SecurityPolicy, defaults.ContactEmail)
 	}
 }
 
@@ -1263,3 +2262,563 @@ func TestVCSPostureJSONStructure(t *testing.T) {
 		}
 	}
 }
+
+func TestCollect_MetricScopes(t *testing.T) {
+	repo := func(name string, protected bool) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		if protected {
+			r.DefaultBranchRef.BranchProtectionRule = &github.BranchProtectionRule{RequiresApprovingReviews: true}
+		}
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			repo("api", true),
+			repo("web", true),
+			repo("docs-site", false),
+			repo("docs-handbook", false),
+		},
+		securitySettings: map[string]*github.SecuritySettings{
+			"test-org/api":       {SecretScanning: true},
+			"test-org/docs-site": {SecretScanning: true},
+		},
+	}
+	config := Config{
+		Organization: "test-org",
+		Scopes: map[string]MetricScope{
+			MetricBranchProtection: {Exclude: []string{"docs-*"}},
+			MetricSecretScanning:   {Include: []string{"*"}},
+		},
+	}
+
+	posture, err := NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := posture.Posture.BranchProtectionCoverage; got != 100 {
+		t.Errorf("BranchProtectionCoverage = %d, want 100 (docs repos scoped out)", got)
+	}
+	if got := posture.BranchProtectionRules.ApprovingReviews; got != 100 {
+		t.Errorf("ApprovingReviews = %d, want 100", got)
+	}
+	if got := posture.SecurityFeatures.SecretScanning; got != 50 {
+		t.Errorf("SecretScanning = %d, want 50 (all four repos)", got)
+	}
+	want := map[string]int{MetricBranchProtection: 2, MetricSecretScanning: 4}
+	if !maps.Equal(posture.Scope.MetricRepositoryCounts, want) {
+		t.Errorf("MetricRepositoryCounts = %v, want %v", posture.Scope.MetricRepositoryCounts, want)
+	}
+}
+
+func TestNew_InvalidMetricScope(t *testing.T) {
+	for name, scopes := range map[string]map[string]MetricScope{
+		"unknown family":  {"branch_protections": {}},
+		"invalid pattern": {MetricCodeScanning: {Exclude: []string{"re:("}}},
+	} {
+		if _, err := New(Config{Organization: "test-org", GitHubToken: "t", Scopes: scopes}); err == nil {
+			t.Errorf("%s: New() should reject scopes %v", name, scopes)
+		}
+	}
+}
+
+func TestCollect_RepositoryChanges(t *testing.T) {
+	repo := func(name string, archived bool) github.Repository {
+		r := github.Repository{Name: name, IsArchived: archived}
+		r.Owner.Login = "test-org"
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			repo("api", false), repo("web", false), repo("legacy", false),
+			repo("handoff", false), repo("scratch", false), repo("old-name", false),
+		},
+	}
+	config := Config{Organization: "test-org", StateDir: t.TempDir(), IncludePatterns: []string{"api", "web"}}
+
+	posture, err := collectAndClose(context.Background(), config, mock, componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("first Collect() error: %v", err)
+	}
+	if posture.RepositoryChanges != nil {
+		t.Errorf("first run RepositoryChanges = %+v, want nil (no snapshot yet)", posture.RepositoryChanges)
+	}
+
+	mock.repositories = []github.Repository{
+		repo("api", false), repo("web", true), repo("legacy", false),
+		repo("new-name", false), repo("fresh", false),
+	}
+	mock.lookups = map[string]string{
+		"test-org/handoff":  "acme/handoff",
+		"test-org/old-name": "test-org/new-name",
+	}
+
+	posture, err = collectAndClose(context.Background(), config, mock, componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("second Collect() error: %v", err)
+	}
+	rc := posture.RepositoryChanges
+	if rc == nil {
+		t.Fatal("RepositoryChanges = nil, want changes since the first run")
+	}
+	if rc.Created != 1 || rc.Archived != 1 || rc.Renamed != 1 || rc.TransferredOut != 1 || rc.Deleted != 1 || rc.Removed != 0 {
+		t.Errorf("RepositoryChanges counts = %+v", rc)
+	}
+	want := []RepositoryChangeRow{
+		{Repository: "test-org/web", Change: RepoChangeArchived},
+		{Repository: "test-org/fresh", Change: RepoChangeCreated},
+		{Repository: "test-org/scratch", Change: RepoChangeDeleted},
+		{Repository: "test-org/old-name", Change: RepoChangeRenamed, NewName: "test-org/new-name"},
+		{Repository: "test-org/handoff", Change: RepoChangeTransferredOut, NewName: "acme/handoff"},
+	}
+	if !slices.Equal(rc.Changes, want) {
+		t.Errorf("Changes = %+v, want %+v", rc.Changes, want)
+	}
+
+	// At trust only the counts are emitted.
+	posture, err = collectAndClose(context.Background(), config, mock, componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("third Collect() error: %v", err)
+	}
+	if rc := posture.RepositoryChanges; rc == nil || rc.Changes != nil || rc.Created != 0 {
+		t.Errorf("trust RepositoryChanges = %+v, want zero counts and no names", rc)
+	}
+}
+
+func TestCollect_FeatureWeights(t *testing.T) {
+	repo := func(name string) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity:  &github.OrgSecurity{},
+		repositories: []github.Repository{repo("api"), repo("web")},
+		securitySettings: map[string]*github.SecuritySettings{
+			"test-org/api": {SecretScanning: true, SecretScanningPushProtection: true},
+		},
+	}
+
+	// Equal weights: 2 of 10 feature/repo pairs.
+	posture, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := posture.Posture.SecurityFeaturesCoverage; got != 20 {
+		t.Errorf("default SecurityFeaturesCoverage = %d, want 20", got)
+	}
+	if got := posture.Posture.SecurityFeaturesWeights.CodeScanning; got != DefaultFeatureWeight {
+		t.Errorf("default CodeScanning weight = %v, want %v", got, DefaultFeatureWeight)
+	}
+
+	// Push protection at 3: (1 + 3) / (2 * 7).
+	config := Config{
+		Organization:   "test-org",
+		FeatureWeights: map[string]float64{FeatureSecretScanningPushProtection: 3},
+	}
+	posture, err = NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := posture.Posture.SecurityFeaturesCoverage; got != 28 {
+		t.Errorf("weighted SecurityFeaturesCoverage = %d, want 28", got)
+	}
+	if got := posture.Posture.SecurityFeaturesWeights.SecretScanningPushProtection; got != 3 {
+		t.Errorf("emitted push protection weight = %v, want 3", got)
+	}
+}
+
+func TestNew_InvalidFeatureWeights(t *testing.T) {
+	for name, weights := range map[string]map[string]float64{
+		"unknown feature": {"secret_scaning": 2},
+		"negative":        {FeatureCodeScanning: -1},
+		"all zero": {
+			FeatureVulnerabilityAlerts: 0, FeatureCodeScanning: 0, FeatureSecretScanning: 0,
+			FeatureSecretScanningPushProtection: 0, FeatureDependabotSecurityUpdates: 0,
+		},
+	} {
+		if _, err := New(Config{Organization: "test-org", GitHubToken: "t", FeatureWeights: weights}); err == nil {
+			t.Errorf("%s: New() should reject feature_weights %v", name, weights)
+		}
+	}
+}
+
+func TestCollect_VulnerabilityAlertsRESTFallback(t *testing.T) {
+	repo := func(name string, alerts bool) github.Repository {
+		r := github.Repository{Name: name, HasVulnerabilityAlertsEnabled: alerts}
+		r.Owner.Login = "test-org"
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			repo("graphql-on", true),
+			repo("rest-on", false), // GraphQL under-reports; REST confirms
+			repo("off", false),
+			repo("also-off", false),
+		},
+		vulnerabilityAlerts: map[string]bool{"test-org/rest-on": true},
+	}
+
+	posture := collectWith(t, mock, componentsdk.LevelAudit)
+	if got := posture.SecurityFeatures.VulnerabilityAlerts; got != 50 {
+		t.Errorf("VulnerabilityAlerts = %d, want 50 (GraphQL 1 + REST 1 of 4)", got)
+	}
+	for _, row := range posture.SecurityFeatures.PerRepo {
+		if row.Repository == "test-org/rest-on" && !row.VulnerabilityAlerts {
+			t.Error("per-repo row for rest-on should report vulnerability alerts enabled")
+		}
+	}
+
+	// A failed REST check leaves the state unknown: the repositories drop
+	// out of the denominator and a diagnostic says so.
+	mock.vulnAlertsErr = fmt.Errorf("%w: vulnerability alerts", github.ErrPermissionDenied)
+	posture = collectWith(t, mock, componentsdk.LevelTrust)
+	if got := posture.SecurityFeatures.VulnerabilityAlerts; got != 100 {
+		t.Errorf("VulnerabilityAlerts with REST denied = %d, want 100 (1 of 1 known)", got)
+	}
+	if got := posture.SecurityFeatures.ApplicableRepos["vulnerability_alerts"]; got != 1 {
+		t.Errorf("ApplicableRepos[vulnerability_alerts] = %d, want 1", got)
+	}
+	if posture.Diagnostics == nil || !anyContains(posture.Diagnostics.PermissionErrors, "vulnerability alerts unconfirmed on 3/4 repos") {
+		t.Errorf("missing unconfirmed-alerts diagnostic: %+v", posture.Diagnostics)
+	}
+}
+
+func collectWith(t *testing.T, client github.GitHubClient, level componentsdk.Level) *OrgPosture {
+	t.Helper()
+	posture, err := NewWithClient(Config{Organization: "test-org"}, client).Collect(context.Background(), level)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	return posture
+}
+
+func TestCollect_StrictMode(t *testing.T) {
+	repo := github.Repository{Name: "api"}
+	repo.Owner.Login = "test-org"
+	degraded := func() *mockGitHubClient {
+		return &mockGitHubClient{
+			orgSecurity:  &github.OrgSecurity{}, // 2FA unknown: not an org owner
+			repositories: []github.Repository{repo},
+			securityErrs: map[string]error{"test-org/api": fmt.Errorf("%w: settings", github.ErrPermissionDenied)},
+		}
+	}
+
+	// Default: degrade and explain.
+	if _, err := NewWithClient(Config{Organization: "test-org"}, degraded()).Collect(context.Background(), componentsdk.LevelTrust); err != nil {
+		t.Fatalf("non-strict Collect() error: %v", err)
+	}
+
+	_, err := NewWithClient(Config{Organization: "test-org", StrictMode: true}, degraded()).Collect(context.Background(), componentsdk.LevelTrust)
+	if !errors.Is(err, ErrDegraded) {
+		t.Fatalf("strict Collect() error = %v, want ErrDegraded", err)
+	}
+	for _, want := range []string{"two_factor_required unknown", "got 403 on 1/1 repos when fetching security settings"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("strict error %q lacks %q", err, want)
+		}
+	}
+
+	healthy := &mockGitHubClient{
+		orgSecurity:  &github.OrgSecurity{TwoFactorRequired: boolPtr(true)},
+		repositories: []github.Repository{repo},
+	}
+	if _, err := NewWithClient(Config{Organization: "test-org", StrictMode: true}, healthy).Collect(context.Background(), componentsdk.LevelTrust); err != nil {
+		t.Errorf("strict Collect() with full permissions error: %v", err)
+	}
+}
+
+func TestCollect_AdvancedSecurity(t *testing.T) {
+	repo := func(name, visibility string) github.Repository {
+		r := github.Repository{Name: name, Visibility: visibility}
+		r.Owner.Login = "test-org"
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			repo("oss", "PUBLIC"),
+			repo("api", "PRIVATE"),
+			repo("billing", "PRIVATE"),
+			repo("portal", "INTERNAL"),
+		},
+		securitySettings: map[string]*github.SecuritySettings{
+			"test-org/oss":    {AdvancedSecurity: true}, // not counted: public
+			"test-org/api":    {AdvancedSecurity: true},
+			"test-org/portal": {AdvancedSecurity: true},
+		},
+	}
+
+	posture := collectWith(t, mock, componentsdk.LevelAudit)
+	if got := posture.SecurityFeatures.AdvancedSecurity; got != 66 {
+		t.Errorf("AdvancedSecurity = %d, want 66 (2 of 3 non-public repos)", got)
+	}
+	for _, row := range posture.SecurityFeatures.PerRepo {
+		if want := row.Repository != "test-org/billing"; row.AdvancedSecurity != want {
+			t.Errorf("%s AdvancedSecurity = %v, want %v", row.Repository, row.AdvancedSecurity, want)
+		}
+	}
+}
+
+func TestCollect_ApplicableRepos(t *testing.T) {
+	repo := func(name, visibility string) github.Repository {
+		r := github.Repository{Name: name, Visibility: visibility}
+		r.Owner.Login = "test-org"
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			repo("oss", "PUBLIC"),
+			repo("api", "PRIVATE"),
+			repo("billing", "PRIVATE"),
+			repo("portal", "INTERNAL"),
+			repo("legacy", "PRIVATE"),
+		},
+		securitySettings: map[string]*github.SecuritySettings{
+			"test-org/oss":     {AnalysisReported: true, SecretScanning: true, CodeScanningEnabled: true},
+			"test-org/api":     {AnalysisReported: true, AdvancedSecurity: true, SecretScanning: true, CodeScanningEnabled: true},
+			"test-org/billing": {AnalysisReported: true, SecretProtection: true}, // code scanning unavailable
+			"test-org/portal":  {AnalysisReported: true},                         // neither available
+			"test-org/legacy":  {},                                               // withheld: counted
+		},
+	}
+
+	sf := collectWith(t, mock, componentsdk.LevelTrust).SecurityFeatures
+	want := map[string]int{"code_scanning": 3, "secret_scanning": 4, "dependabot_security_updates": 5, "advanced_security": 4}
+	for metric, n := range want {
+		if got := sf.ApplicableRepos[metric]; got != n {
+			t.Errorf("ApplicableRepos[%s] = %d, want %d", metric, got, n)
+		}
+	}
+	if sf.CodeScanning != 66 || sf.SecretScanning != 50 {
+		t.Errorf("CodeScanning/SecretScanning = %d/%d, want 66/50 over applicable repos", sf.CodeScanning, sf.SecretScanning)
+	}
+}
+
+func TestCollect_MalformedResponsesWarning(t *testing.T) {
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		stats:       github.QueryStats{DecodeErrors: 3, LastDecodeError: "decoding /repos/test-org/secret-repo: unexpected EOF"},
+	}
+	posture := collectWith(t, mock, componentsdk.LevelTrust)
+	if posture.Diagnostics == nil || !anyContains(posture.Diagnostics.Warnings, "3 GitHub API responses could not be decoded") ||
+		!anyContains(posture.Diagnostics.Warnings, "secret-repo") {
+		t.Fatalf("Diagnostics = %+v, want a malformed-response warning with the last error", posture.Diagnostics)
+	}
+
+	redacted, err := NewWithClient(Config{Organization: "test-org", RedactRepoNames: true}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if anyContains(redacted.Diagnostics.Warnings, "secret-repo") {
+		t.Errorf("redacted run leaked a repository name: %v", redacted.Diagnostics.Warnings)
+	}
+}
+
+func TestCollect_CoverageBreakdown(t *testing.T) {
+	repo := func(name, language string, actions bool) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		if language != "" {
+			r.PrimaryLanguage = &struct{ Name string }{Name: language}
+		}
+		if actions {
+			r.GitHubActionsConfig = &github.GitObjectRef{Oid: "abc"}
+		}
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			repo("api", "Go", true), repo("worker", "Go", true), repo("docs", "", false), repo("handbook", "", false),
+		},
+		securitySettings: map[string]*github.SecuritySettings{
+			"test-org/api":    {CodeScanningEnabled: true},
+			"test-org/worker": {CodeScanningEnabled: true},
+		},
+	}
+	// Not requested, the breakdown is left out.
+	if posture := collectWith(t, mock, componentsdk.LevelTrust); posture.CoverageBreakdown != nil {
+		t.Errorf("CoverageBreakdown = %+v, want nil unless configured", posture.CoverageBreakdown)
+	}
+
+	config := Config{Organization: "test-org", CoverageBreakdown: true}
+	posture, err := NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	// Org-wide code scanning is 50%, but every repo with CI has it.
+	if got := posture.SecurityFeatures.CodeScanning; got != 50 {
+		t.Errorf("SecurityFeatures.CodeScanning = %d, want 50", got)
+	}
+	b := posture.CoverageBreakdown
+	if b == nil {
+		t.Fatal("CoverageBreakdown = nil, want the breakdown")
+	}
+	if b.WithCI.Repos != 2 || b.WithCI.CodeScanning != 100 {
+		t.Errorf("WithCI = %+v, want 2 repos at 100%% code scanning", b.WithCI)
+	}
+	if b.WithoutCI.Repos != 2 || b.WithoutCI.CodeScanning != 0 {
+		t.Errorf("WithoutCI = %+v, want 2 repos at 0%% code scanning", b.WithoutCI)
+	}
+	if b.ByLanguage["Go"].CodeScanning != 100 || b.ByLanguage[NoLanguage].Repos != 2 {
+		t.Errorf("ByLanguage = %+v", b.ByLanguage)
+	}
+	if b.CISystems[github.CISystemGitHubActions] != 2 {
+		t.Errorf("CISystems = %v, want 2 github_actions", b.CISystems)
+	}
+	if b.ByClass[RepoClassService].Repos != 2 || b.ByClass[RepoClassService].CodeScanning != 100 || b.ByClass[RepoClassDocs].Repos != 2 {
+		t.Errorf("ByClass = %+v, want 2 services at 100%% code scanning and 2 docs", b.ByClass)
+	}
+}
+
+func TestCollect_Tiers(t *testing.T) {
+	repo := func(name string, protected bool) github.Repository {
+		r := github.Repository{Name: name}
+		r.Owner.Login = "test-org"
+		if protected {
+			r.DefaultBranchRef.Name = "main"
+			r.DefaultBranchRef.BranchProtectionRule = &github.BranchProtectionRule{RequiresApprovingReviews: true}
+		}
+		return r
+	}
+	mock := &mockGitHubClient{
+		orgSecurity:    &github.OrgSecurity{},
+		repositories:   []github.Repository{repo("payments", true), repo("ledger", false), repo("docs", false)},
+		propertyValues: map[string]map[string][]string{"ledger": {"tier": {"critical"}}},
+	}
+	config := Config{Organization: "test-org", Tiers: map[string]TierRule{
+		TierCritical: {Include: []string{"payments"}, Properties: map[string][]string{"tier": {"critical"}}, Thresholds: map[string]int{"branch_protection": 100}},
+		TierHigh:     {Include: []string{"api-*"}, Thresholds: map[string]int{"branch_protection": 80}},
+	}}
+	posture, err := NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	b := posture.CoverageBreakdown
+	critical := b.ByTier[TierCritical]
+	if critical.Repos != 2 || critical.BranchProtection != 50 || len(critical.Thresholds) != 1 || critical.Thresholds[0].Met == nil || *critical.Thresholds[0].Met {
+		t.Errorf("ByTier[critical] = %+v, want 2 repos at 50%% branch protection, missing its threshold", critical)
+	}
+	// The empty high tier is reported for its threshold, which is unknown.
+	if high, ok := b.ByTier[TierHigh]; !ok || high.Repos != 0 || high.Thresholds[0].Met != nil {
+		t.Errorf("ByTier[high] = %+v, %v", high, ok)
+	}
+	if b.ByTier[TierNormal].Repos != 1 {
+		t.Errorf("ByTier[normal] = %+v, want 1 repo", b.ByTier[TierNormal])
+	}
+	if b.TierThresholdsMet == nil || *b.TierThresholdsMet {
+		t.Errorf("TierThresholdsMet = %v, want false", b.TierThresholdsMet)
+	}
+
+	// Without the custom properties, tiers fall back to patterns and their
+	// thresholds are unknown.
+	mock.propertyValuesErr = github.ErrPermissionDenied
+	posture, err = NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if got := posture.CoverageBreakdown.ByTier[TierCritical]; got.Repos != 1 || got.Thresholds[0].Met != nil {
+		t.Errorf("ByTier[critical] = %+v, want payments alone, threshold unknown", got)
+	}
+	if got := posture.CoverageBreakdown.TierThresholdsMet; got != nil {
+		t.Errorf("TierThresholdsMet = %v, want unknown", *got)
+	}
+	if posture.Diagnostics == nil || !anyContains(posture.Diagnostics.PermissionErrors, "organization_custom_properties:read") {
+		t.Errorf("Diagnostics = %+v, want a custom properties permission error", posture.Diagnostics)
+	}
+
+	// Truncated property values leave the tiers incomplete too.
+	mock.propertyValuesErr = nil
+	mock.propertyValuesTrunc = true
+	posture, err = NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if got := posture.CoverageBreakdown.ByTier[TierCritical]; got.Repos != 2 || got.Thresholds[0].Met != nil {
+		t.Errorf("ByTier[critical] = %+v, want threshold unknown", got)
+	}
+	if posture.Diagnostics == nil || !anyContains(posture.Diagnostics.Warnings, "custom property values truncated") {
+		t.Errorf("Diagnostics = %+v, want a truncation warning", posture.Diagnostics)
+	}
+}
+
+func TestCollect_MirrorAndTemplateExclusion(t *testing.T) {
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			{Name: "api"},
+			{Name: "upstream-mirror", IsMirror: true},
+			{Name: "service-template", IsTemplate: true},
+			{Name: "old", IsArchived: true, IsMirror: true},
+			{Name: "scratch"},
+		},
+	}
+	collect := func(config Config) *OrgPosture {
+		t.Helper()
+		config.Organization = "test-org"
+		posture, err := NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelTrust)
+		if err != nil {
+			t.Fatalf("Collect() error: %v", err)
+		}
+		return posture
+	}
+
+	// By default mirrors are excluded and templates included.
+	posture := collect(Config{ExcludePatterns: []string{"scratch"}})
+	want := map[string]int{ExcludeReasonArchived: 1, ExcludeReasonMirror: 1, ExcludeReasonPattern: 1}
+	if got := posture.Scope.ExcludedCounts; !maps.Equal(got, want) {
+		t.Errorf("ExcludedCounts = %v, want %v", got, want)
+	}
+	if got := posture.Scope.RepositoriesCoverage; got != 40 {
+		t.Errorf("RepositoriesCoverage = %d, want 40 (api and the template)", got)
+	}
+
+	posture = collect(Config{IncludeMirrors: true, ExcludeTemplates: true})
+	want = map[string]int{ExcludeReasonArchived: 1, ExcludeReasonTemplate: 1}
+	if got := posture.Scope.ExcludedCounts; !maps.Equal(got, want) {
+		t.Errorf("ExcludedCounts with include_mirrors, exclude_templates = %v, want %v", got, want)
+	}
+}
+
+func TestCollect_MaxRepositories(t *testing.T) {
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			{Name: "api"},
+			{Name: "web"},
+			{Name: "old", IsArchived: true},
+			{Name: "worker"},
+		},
+	}
+	collect := func(limit int) *OrgPosture {
+		t.Helper()
+		posture, err := NewWithClient(Config{Organization: "test-org", MaxRepositories: limit}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+		if err != nil {
+			t.Fatalf("Collect() error: %v", err)
+		}
+		return posture
+	}
+
+	posture := collect(2)
+	if !posture.Scope.Truncated || posture.Scope.MaxRepositories != 2 {
+		t.Errorf("Scope truncated = %v, max_repositories = %d, want true, 2", posture.Scope.Truncated, posture.Scope.MaxRepositories)
+	}
+	if got := len(mock.requestedRepos); got != 2 {
+		t.Errorf("security settings fetched for %d repositories, want 2", got)
+	}
+	if posture.Diagnostics == nil || len(posture.Diagnostics.Warnings) == 0 {
+		t.Error("expected a truncation warning")
+	}
+
+	// Only excluded repositories past the cap don't truncate.
+	mock.requestedRepos = nil
+	mock.repositories = mock.repositories[:3]
+	if posture := collect(2); posture.Scope.Truncated {
+		t.Error("Scope.Truncated = true with only an archived repository past the cap")
+	}
+}
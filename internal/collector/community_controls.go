@@ -0,0 +1,46 @@
+package collector
+
+import "slices"
+
+// collectCommunityControls gathers the org's moderation controls for public
+// repositories: the active interaction limit and blocked users. Audit emits
+// the limit and the blocked-user count; internal adds the blocked logins.
+// Each half degrades on its own; the section is omitted only when neither
+// could be read.
+func (c *Collector) collectCommunityControls(p *collectionPass) {
+	controls := &CommunityControls{}
+	known := false
+
+	limit, err := c.client.GetOrgInteractionLimit(p.ctx, p.org)
+	if err != nil {
+		if isDenied(err) {
+			p.metrics.diag.surfacePermissionDenied("community_controls.interaction_limit", "organization_administration:read")
+		}
+	} else {
+		known = true
+		active := limit != nil
+		controls.InteractionLimitActive = &active
+		if active {
+			controls.InteractionLimit = limit.Limit
+			controls.InteractionLimitExpiresAt = limit.ExpiresAt
+		}
+	}
+
+	blocked, err := c.client.ListOrgBlockedUsers(p.ctx, p.org)
+	if err != nil {
+		if isDenied(err) {
+			p.metrics.diag.surfacePermissionDenied("community_controls.blocked_users", "organization_user_blocking:read")
+		}
+	} else {
+		known = true
+		count := len(blocked)
+		controls.BlockedUserCount = &count
+		if p.internal() {
+			controls.BlockedUsers = slices.Sorted(slices.Values(blocked))
+		}
+	}
+
+	if known {
+		p.posture.CommunityControls = controls
+	}
+}
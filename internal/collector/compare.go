@@ -0,0 +1,211 @@
+package collector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// comparedMetric is one coverage percentage Compare reports, by its dotted
+// path in github.json. For lowerIsBetter metrics (shares of repositories with
+// a risky setting) an increase is the regression.
+type comparedMetric struct {
+	path          string
+	value         func(*OrgPosture) int
+	lowerIsBetter bool
+}
+
+var comparedMetrics = []comparedMetric{
+	{path: "posture.branch_protection_coverage", value: func(p *OrgPosture) int { return p.Posture.BranchProtectionCoverage }},
+	{path: "posture.security_features_coverage", value: func(p *OrgPosture) int { return p.Posture.SecurityFeaturesCoverage }},
+
+	{path: "branch_protection_rules.pull_request_required", value: func(p *OrgPosture) int { return p.BranchProtectionRules.PullRequestRequired }},
+	{path: "branch_protection_rules.approving_reviews", value: func(p *OrgPosture) int { return p.BranchProtectionRules.ApprovingReviews }},
+	{path: "branch_protection_rules.dismiss_stale_reviews", value: func(p *OrgPosture) int { return p.BranchProtectionRules.DismissStaleReviews }},
+	{path: "branch_protection_rules.code_owner_reviews", value: func(p *OrgPosture) int { return p.BranchProtectionRules.CodeOwnerReviews }},
+	{path: "branch_protection_rules.status_checks", value: func(p *OrgPosture) int { return p.BranchProtectionRules.StatusChecks }},
+	{path: "branch_protection_rules.signed_commits", value: func(p *OrgPosture) int { return p.BranchProtectionRules.SignedCommits }},
+	{path: "branch_protection_rules.admin_enforcement", value: func(p *OrgPosture) int { return p.BranchProtectionRules.AdminEnforcement }},
+	{path: "branch_protection_rules.linear_history", value: func(p *OrgPosture) int { return p.BranchProtectionRules.LinearHistory }},
+	{path: "branch_protection_rules.force_pushes_allowed", value: func(p *OrgPosture) int { return p.BranchProtectionRules.ForcePushesAllowed }, lowerIsBetter: true},
+	{path: "branch_protection_rules.deletions_allowed", value: func(p *OrgPosture) int { return p.BranchProtectionRules.DeletionsAllowed }, lowerIsBetter: true},
+	{path: "branch_protection_rules.conversation_resolution", value: func(p *OrgPosture) int { return p.BranchProtectionRules.ConversationResolution }},
+	{path: "branch_protection_rules.last_push_approval", value: func(p *OrgPosture) int { return p.BranchProtectionRules.LastPushApproval }},
+
+	{path: "security_features.vulnerability_alerts", value: func(p *OrgPosture) int { return p.SecurityFeatures.VulnerabilityAlerts }},
+	{path: "security_features.code_scanning", value: func(p *OrgPosture) int { return p.SecurityFeatures.CodeScanning }},
+	{path: "security_features.secret_scanning", value: func(p *OrgPosture) int { return p.SecurityFeatures.SecretScanning }},
+	{path: "security_features.secret_scanning_push_protection", value: func(p *OrgPosture) int { return p.SecurityFeatures.SecretScanningPushProtection }},
+	{path: "security_features.dependabot_security_updates", value: func(p *OrgPosture) int { return p.SecurityFeatures.DependabotSecurityUpdates }},
+	{path: "security_features.secret_scanning_non_provider_patterns", value: func(p *OrgPosture) int { return p.SecurityFeatures.SecretScanningNonProviderPatterns }},
+	{path: "security_features.secret_scanning_validity_checks", value: func(p *OrgPosture) int { return p.SecurityFeatures.SecretScanningValidityChecks }},
+	{path: "security_features.advanced_security", value: func(p *OrgPosture) int { return p.SecurityFeatures.AdvancedSecurity }},
+
+	{path: "repository_hygiene.private_forking_allowed", value: func(p *OrgPosture) int { return p.RepositoryHygiene.PrivateForkingAllowed }, lowerIsBetter: true},
+}
+
+// PostureComparison is the difference between two github.json snapshots of
+// the same account, printed by --compare. Regressed is set when any metric
+// moved the wrong way or any repository lost a control.
+type PostureComparison struct {
+	Organization string `json:"organization"`
+	BaselineAt   string `json:"baseline_collected_at"`
+	CurrentAt    string `json:"current_collected_at"`
+
+	// Metrics lists the coverage percentages that changed.
+	Metrics     []MetricDelta `json:"metrics"`
+	Regressions int           `json:"regressions"`
+
+	// NewlyFailing lists repositories, present in both snapshots, that lost
+	// branch protection or a security feature. It needs per-repo detail
+	// (audit level and above) in both.
+	NewlyFailing []RepoRegression `json:"newly_failing,omitempty"`
+
+	Regressed bool `json:"regressed"`
+}
+
+// MetricDelta is one changed percentage.
+type MetricDelta struct {
+	Metric    string `json:"metric"`
+	Baseline  int    `json:"baseline"`
+	Current   int    `json:"current"`
+	Delta     int    `json:"delta"`
+	Regressed bool   `json:"regressed"`
+}
+
+// RepoRegression names a repository and the controls it lost.
+type RepoRegression struct {
+	Repository string   `json:"repository"`
+	Lost       []string `json:"lost"`
+}
+
+// LoadPosture decodes a github.json artifact, as emitted or compressed (see
+// CompressArtifact).
+func LoadPosture(data []byte) (*OrgPosture, error) {
+	var compressed CompressedArtifact
+	if json.Unmarshal(data, &compressed) == nil && compressed.Encoding == CompressedEncoding {
+		gz, err := base64.StdEncoding.DecodeString(compressed.Content)
+		if err != nil {
+			return nil, fmt.Errorf("decoding compressed artifact: %w", err)
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(gz))
+		if err != nil {
+			return nil, fmt.Errorf("decoding compressed artifact: %w", err)
+		}
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("decoding compressed artifact: %w", err)
+		}
+	}
+	var posture OrgPosture
+	if err := json.Unmarshal(data, &posture); err != nil {
+		return nil, err
+	}
+	if posture.SchemaVersion == "" {
+		return nil, fmt.Errorf("not a github.json artifact: schema_version missing")
+	}
+	return &posture, nil
+}
+
+// Compare reports how current differs from baseline. Snapshots of different
+// accounts are rejected, since their deltas would mean nothing.
+func Compare(baseline, current *OrgPosture) (*PostureComparison, error) {
+	if !strings.EqualFold(baseline.Organization, current.Organization) {
+		return nil, fmt.Errorf("snapshots are of different accounts: %s and %s", baseline.Organization, current.Organization)
+	}
+	cmp := &PostureComparison{
+		Organization: current.Organization,
+		BaselineAt:   baseline.CollectedAt,
+		CurrentAt:    current.CollectedAt,
+		Metrics:      []MetricDelta{},
+	}
+	for _, m := range comparedMetrics {
+		before, after := m.value(baseline), m.value(current)
+		if before == after {
+			continue
+		}
+		regressed := after < before
+		if m.lowerIsBetter {
+			regressed = !regressed
+		}
+		if regressed {
+			cmp.Regressions++
+		}
+		cmp.Metrics = append(cmp.Metrics, MetricDelta{
+			Metric: m.path, Baseline: before, Current: after, Delta: after - before, Regressed: regressed,
+		})
+	}
+	cmp.NewlyFailing = newlyFailing(baseline, current)
+	cmp.Regressed = cmp.Regressions > 0 || len(cmp.NewlyFailing) > 0
+	return cmp, nil
+}
+
+// newlyFailing finds repositories that had a control in baseline and lack it
+// in current, sorted by name.
+func newlyFailing(baseline, current *OrgPosture) []RepoRegression {
+	before := repoControls(baseline)
+	var out []RepoRegression
+	for repo, after := range repoControls(current) {
+		var lost []string
+		for _, control := range repoControlNames {
+			was, wasKnown := before[repo][control]
+			now, known := after[control]
+			if wasKnown && known && was && !now {
+				lost = append(lost, control)
+			}
+		}
+		if len(lost) > 0 {
+			out = append(out, RepoRegression{Repository: repo, Lost: lost})
+		}
+	}
+	slices.SortFunc(out, func(a, b RepoRegression) int { return strings.Compare(a.Repository, b.Repository) })
+	return out
+}
+
+// repoControlNames are the per-repository controls compared between
+// snapshots and ticketed by remediation, in report order.
+var repoControlNames = []string{
+	"branch_protection",
+	"vulnerability_alerts",
+	"code_scanning",
+	"secret_scanning",
+	"secret_scanning_push_protection",
+	"dependabot_security_updates",
+}
+
+// repoControls returns, for each repository with per-repo detail, whether
+// each of its known controls is on. Branch protection is known only when
+// the repositories inventory is present (audit level and above); a row's
+// unknown and not-applicable controls are left out.
+func repoControls(p *OrgPosture) map[string]map[string]bool {
+	controls := map[string]map[string]bool{}
+	set := func(repo, control string, on bool) {
+		if controls[repo] == nil {
+			controls[repo] = map[string]bool{}
+		}
+		controls[repo][control] = on
+	}
+	if p.Repositories != nil {
+		for _, r := range p.Repositories.PerRepo {
+			set(r.Name, "branch_protection", r.BranchProtection != nil)
+		}
+	}
+	for _, r := range p.SecurityFeatures.PerRepo {
+		for control, on := range map[string]bool{
+			"vulnerability_alerts":            r.VulnerabilityAlerts,
+			"code_scanning":                   r.CodeScanning,
+			"secret_scanning":                 r.SecretScanning,
+			"secret_scanning_push_protection": r.SecretScanningPushProtection,
+			"dependabot_security_updates":     r.DependabotSecurityUpdates,
+		} {
+			if !slices.Contains(r.Unknown, control) && !slices.Contains(r.NotApplicable, control) {
+				set(r.Repository, control, on)
+			}
+		}
+	}
+	return controls
+}
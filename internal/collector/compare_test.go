@@ -0,0 +1,116 @@
+package collector
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+func TestCompare(t *testing.T) {
+	baseline := &OrgPosture{
+		SchemaVersion:         "1.0.0",
+		Organization:          "test-org",
+		Posture:               Posture{BranchProtectionCoverage: 80, SecurityFeaturesCoverage: 50},
+		BranchProtectionRules: BranchProtectionRules{ForcePushesAllowed: 10},
+		SecurityFeatures: SecurityFeatures{PerRepo: []SecurityFeaturesRow{
+			{Repository: "test-org/api", SecretScanning: true, CodeScanning: true},
+			{Repository: "test-org/web", SecretScanning: true},
+		}},
+		Repositories: &Repositories{PerRepo: []RepoRow{
+			{Name: "test-org/api", BranchProtection: &BranchProtectionDetail{}},
+			{Name: "test-org/web"},
+		}},
+	}
+	current := &OrgPosture{
+		SchemaVersion:         "1.0.0",
+		Organization:          "test-org",
+		Posture:               Posture{BranchProtectionCoverage: 60, SecurityFeaturesCoverage: 70},
+		BranchProtectionRules: BranchProtectionRules{ForcePushesAllowed: 20},
+		SecurityFeatures: SecurityFeatures{PerRepo: []SecurityFeaturesRow{
+			{Repository: "test-org/api", CodeScanning: true},
+			{Repository: "test-org/web", SecretScanning: true, CodeScanning: true},
+			{Repository: "test-org/new"},
+		}},
+		Repositories: &Repositories{PerRepo: []RepoRow{
+			{Name: "test-org/api"},
+			{Name: "test-org/web", BranchProtection: &BranchProtectionDetail{}},
+		}},
+	}
+
+	cmp, err := Compare(baseline, current)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+	wantMetrics := []MetricDelta{
+		{Metric: "posture.branch_protection_coverage", Baseline: 80, Current: 60, Delta: -20, Regressed: true},
+		{Metric: "posture.security_features_coverage", Baseline: 50, Current: 70, Delta: 20},
+		{Metric: "branch_protection_rules.force_pushes_allowed", Baseline: 10, Current: 20, Delta: 10, Regressed: true},
+	}
+	if !reflect.DeepEqual(cmp.Metrics, wantMetrics) {
+		t.Errorf("Metrics = %+v, want %+v", cmp.Metrics, wantMetrics)
+	}
+	wantFailing := []RepoRegression{{Repository: "test-org/api", Lost: []string{"branch_protection", "secret_scanning"}}}
+	if !reflect.DeepEqual(cmp.NewlyFailing, wantFailing) {
+		t.Errorf("NewlyFailing = %+v, want %+v", cmp.NewlyFailing, wantFailing)
+	}
+	if cmp.Regressions != 2 || !cmp.Regressed {
+		t.Errorf("Regressions = %d, Regressed = %v; want 2, true", cmp.Regressions, cmp.Regressed)
+	}
+
+	if cmp, _ := Compare(baseline, baseline); cmp.Regressed || len(cmp.Metrics) != 0 {
+		t.Errorf("self-comparison = %+v, want no changes", cmp)
+	}
+	other := *current
+	other.Organization = "other-org"
+	if _, err := Compare(baseline, &other); err == nil {
+		t.Error("Compare() of different accounts should fail")
+	}
+}
+
+func TestLoadPosture(t *testing.T) {
+	posture := &OrgPosture{SchemaVersion: "1.0.0", Organization: "test-org"}
+	raw, _ := json.Marshal(posture)
+
+	if got, err := LoadPosture(raw); err != nil || got.Organization != "test-org" {
+		t.Errorf("LoadPosture(plain) = %+v, %v", got, err)
+	}
+	compressed, _, _ := CompressArtifact("artifacts/github.json", json.RawMessage(raw), 1)
+	data, _ := json.Marshal(compressed)
+	if got, err := LoadPosture(data); err != nil || got.Organization != "test-org" {
+		t.Errorf("LoadPosture(compressed) = %+v, %v", got, err)
+	}
+	if _, err := LoadPosture([]byte(`{"organization":"test-org"}`)); err == nil {
+		t.Error("LoadPosture() should reject a document without schema_version")
+	}
+}
+
+func TestControlStates(t *testing.T) {
+	private := github.Repository{Name: "api", Visibility: "PRIVATE"}
+	tests := []struct {
+		name              string
+		repo              github.Repository
+		settings          *github.SecuritySettings
+		alertsUnconfirmed bool
+		wantUnknown       []string
+		wantNotApplicable []string
+	}{
+		{"settings unread", private, nil, false,
+			[]string{"vulnerability_alerts", "code_scanning", "secret_scanning", "secret_scanning_push_protection", "dependabot_security_updates"}, nil},
+		{"analysis withheld", private, &github.SecuritySettings{CodeScanningPermissionDenied: true}, true,
+			[]string{"vulnerability_alerts", "code_scanning", "secret_scanning", "secret_scanning_push_protection", "dependabot_security_updates"}, nil},
+		{"no GHAS", private, &github.SecuritySettings{AnalysisReported: true}, false,
+			nil, []string{"code_scanning", "secret_scanning", "secret_scanning_push_protection"}},
+		{"GHAS", private, &github.SecuritySettings{AnalysisReported: true, AdvancedSecurity: true}, false, nil, nil},
+		{"public", github.Repository{Name: "web", Visibility: "PUBLIC"}, &github.SecuritySettings{AnalysisReported: true}, false, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			unknown, notApplicable := controlStates(tt.repo, tt.settings, tt.alertsUnconfirmed)
+			if !reflect.DeepEqual(unknown, tt.wantUnknown) || !reflect.DeepEqual(notApplicable, tt.wantNotApplicable) {
+				t.Errorf("controlStates() = %v, %v; want %v, %v", unknown, notApplicable, tt.wantUnknown, tt.wantNotApplicable)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/state"
+)
+
+// DefaultSLAWindowDays is the trailing window compliance SLAs are measured
+// over when sla_window_days is unset: about a quarter.
+const DefaultSLAWindowDays = 90
+
+// historyRetentionDays is how many days of metric history the store keeps,
+// enough for a year-long SLA window.
+const historyRetentionDays = 366
+
+// historyDateFormat is the day key of the metric history.
+const historyDateFormat = "2006-01-02"
+
+// validateComplianceSLAs checks that each compliance_slas key names a
+// compared metric with a percentage threshold, and that the history it's
+// measured over can be kept.
+func validateComplianceSLAs(config Config) error {
+	for key, threshold := range config.ComplianceSLAs {
+		if !slices.ContainsFunc(comparedMetrics, func(m comparedMetric) bool { return m.path == key }) {
+			return fmt.Errorf("compliance_slas: unknown metric %q", key)
+		}
+		if threshold < 0 || threshold > 100 {
+			return fmt.Errorf("compliance_slas.%s: must be between 0 and 100, got %d", key, threshold)
+		}
+	}
+	if config.SLAWindowDays < 0 || config.SLAWindowDays > historyRetentionDays {
+		return fmt.Errorf("sla_window_days: must be between 1 and %d (0 for the default of %d), got %d", historyRetentionDays, DefaultSLAWindowDays, config.SLAWindowDays)
+	}
+	if len(config.ComplianceSLAs) > 0 && config.StateDir == "" {
+		return errors.New("compliance_slas requires state_dir")
+	}
+	return nil
+}
+
+// trackComplianceSLAs records today's coverage metrics in the store's daily
+// history, replacing an earlier run's from the same day, and measures the
+// configured compliance SLAs over the trailing window. Every compared metric
+// is recorded, so an SLA added later has history to measure. Store failures
+// are warnings: they never fail the run. History that can't be read (under
+// the wrong key, or corrupt) is left as it is, since saving would reset every
+// SLA to today's run alone.
+func (c *Collector) trackComplianceSLAs(store *state.Store, posture *OrgPosture, metrics *metricsAggregator, now time.Time) {
+	account := c.config.Organization
+	history, err := store.LoadHistory(account)
+	if err != nil {
+		metrics.diag.historyUnavailable(err)
+		return
+	}
+
+	today := state.MetricDay{Date: now.UTC().Format(historyDateFormat), Metrics: map[string]int{}}
+	for _, m := range comparedMetrics {
+		today.Metrics[m.path] = m.value(posture)
+	}
+	oldest := now.UTC().AddDate(0, 0, 1-historyRetentionDays).Format(historyDateFormat)
+	history = slices.DeleteFunc(history, func(d state.MetricDay) bool {
+		return d.Date < oldest || d.Date == today.Date
+	})
+	history = append(history, today)
+	if err := store.SaveHistory(account, history); err != nil {
+		metrics.diag.historyUnavailable(err)
+	}
+
+	if len(c.config.ComplianceSLAs) > 0 {
+		posture.ComplianceSLAs = complianceSLAs(c.config.ComplianceSLAs, c.slaWindowDays(), history, now)
+	}
+}
+
+// slaWindowDays returns SLAWindowDays, or DefaultSLAWindowDays when unset.
+func (c *Collector) slaWindowDays() int {
+	if c.config.SLAWindowDays > 0 {
+		return c.config.SLAWindowDays
+	}
+	return DefaultSLAWindowDays
+}
+
+// complianceSLAs measures each SLA over the history's days within the
+// trailing window ending today. A day is compliant when the metric met its
+// threshold: at least it, or at most it for metrics where lower is better.
+// Days without a complete run aren't observed, and aren't counted either way.
+func complianceSLAs(slas map[string]int, windowDays int, history []state.MetricDay, now time.Time) *ComplianceSLAs {
+	since := now.UTC().AddDate(0, 0, 1-windowDays).Format(historyDateFormat)
+	out := &ComplianceSLAs{WindowDays: windowDays, Since: since}
+	for _, m := range comparedMetrics {
+		threshold, ok := slas[m.path]
+		if !ok {
+			continue
+		}
+		row := ComplianceSLARow{Metric: m.path, Threshold: threshold, LowerIsBetter: m.lowerIsBetter}
+		for _, day := range history {
+			value, recorded := day.Metrics[m.path]
+			if day.Date < since || !recorded {
+				continue
+			}
+			row.DaysObserved++
+			if (!m.lowerIsBetter && value >= threshold) || (m.lowerIsBetter && value <= threshold) {
+				row.DaysCompliant++
+			}
+		}
+		row.CompliancePct = percent(row.DaysCompliant, row.DaysObserved)
+		out.SLAs = append(out.SLAs, row)
+	}
+	slices.SortFunc(out.SLAs, func(a, b ComplianceSLARow) int { return strings.Compare(a.Metric, b.Metric) })
+	return out
+}
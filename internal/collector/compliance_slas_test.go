@@ -0,0 +1,212 @@
+package collector
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack-collector-github/internal/state"
+	"github.com/locktivity/epack/componentsdk"
+)
+
+func TestComplianceSLAs(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	day := func(date string, protected, forking int) state.MetricDay {
+		return state.MetricDay{Date: date, Metrics: map[string]int{
+			"posture.branch_protection_coverage":         protected,
+			"repository_hygiene.private_forking_allowed": forking,
+		}}
+	}
+	history := []state.MetricDay{
+		day("2026-02-28", 10, 90), // before the window
+		day("2026-03-01", 95, 0),
+		day("2026-03-02", 89, 5),
+		day("2026-03-15", 90, 20),
+		{Date: "2026-03-20", Metrics: map[string]int{}}, // metric not yet recorded
+		day("2026-03-31", 97, 10),
+	}
+	slas := map[string]int{
+		"posture.branch_protection_coverage":         90,
+		"repository_hygiene.private_forking_allowed": 10,
+	}
+	got := complianceSLAs(slas, 31, history, now)
+	want := &ComplianceSLAs{
+		WindowDays: 31,
+		Since:      "2026-03-01",
+		SLAs: []ComplianceSLARow{
+			{Metric: "posture.branch_protection_coverage", Threshold: 90, DaysObserved: 4, DaysCompliant: 3, CompliancePct: 75},
+			{Metric: "repository_hygiene.private_forking_allowed", Threshold: 10, LowerIsBetter: true, DaysObserved: 4, DaysCompliant: 3, CompliancePct: 75},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("complianceSLAs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCollect_ComplianceSLAsRecordHistory(t *testing.T) {
+	mock := &mockGitHubClient{
+		orgSecurity:  &github.OrgSecurity{},
+		repositories: []github.Repository{{Name: "api"}},
+	}
+	config := Config{
+		Organization:   "test-org",
+		StateDir:       t.TempDir(),
+		ComplianceSLAs: map[string]int{"posture.branch_protection_coverage": 90},
+	}
+	store, err := state.Open(config.StateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(historyDateFormat)
+	if err := store.SaveHistory("test-org", []state.MetricDay{
+		{Date: yesterday, Metrics: map[string]int{"posture.branch_protection_coverage": 100}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two runs on the same day count as one observed day: the later one.
+	for range 2 {
+		posture, err := collectAndClose(context.Background(), config, mock, componentsdk.LevelTrust)
+		if err != nil {
+			t.Fatalf("Collect() error: %v", err)
+		}
+		sla := posture.ComplianceSLAs
+		if sla == nil || sla.WindowDays != DefaultSLAWindowDays || len(sla.SLAs) != 1 {
+			t.Fatalf("ComplianceSLAs = %+v", sla)
+		}
+		if row := sla.SLAs[0]; row.DaysObserved != 2 || row.DaysCompliant != 1 || row.CompliancePct != 50 {
+			t.Errorf("SLA row = %+v, want 1 of 2 days compliant", row)
+		}
+	}
+	history, err := store.LoadHistory("test-org")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("history = %+v, %v; want yesterday and today", history, err)
+	}
+	if len(history[1].Metrics) != len(comparedMetrics) {
+		t.Errorf("today's history records %d metrics, want every compared metric", len(history[1].Metrics))
+	}
+
+	if _, err := New(Config{Organization: "test-org", GitHubToken: "t", ComplianceSLAs: map[string]int{"posture.branch_protection_coverage": 90}}); err == nil {
+		t.Error("New() accepted compliance_slas without state_dir")
+	}
+	if _, err := New(Config{Organization: "test-org", GitHubToken: "t", StateDir: t.TempDir(), ComplianceSLAs: map[string]int{"posture.nope": 90}}); err == nil {
+		t.Error("New() accepted an unknown compliance_slas metric")
+	}
+}
+
+func TestCollect_CorruptHistoryKept(t *testing.T) {
+	mock := &mockGitHubClient{
+		orgSecurity:  &github.OrgSecurity{},
+		repositories: []github.Repository{{Name: "api"}},
+	}
+	config := Config{
+		Organization:   "test-org",
+		StateDir:       t.TempDir(),
+		ComplianceSLAs: map[string]int{"posture.branch_protection_coverage": 90},
+	}
+	path := filepath.Join(config.StateDir, "test-org.history.json")
+	corrupt := []byte(`[{"date": "2026-01-01", "metrics": {`)
+	if err := os.WriteFile(path, corrupt, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	posture, err := NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if posture.ComplianceSLAs != nil {
+		t.Errorf("ComplianceSLAs = %+v, want none without history", posture.ComplianceSLAs)
+	}
+	if posture.Diagnostics == nil || !anyContains(posture.Diagnostics.Warnings, "compliance_slas: metric history") {
+		t.Errorf("missing history warning: %+v", posture.Diagnostics)
+	}
+	if data, err := os.ReadFile(path); err != nil || !bytes.Equal(data, corrupt) {
+		t.Errorf("history file = %q, %v; want it left as it was", data, err)
+	}
+}
+
+func TestCollect_EncryptedState(t *testing.T) {
+	mock := &mockGitHubClient{
+		orgSecurity:  &github.OrgSecurity{},
+		repositories: []github.Repository{{Name: "api"}},
+	}
+	key := func(b byte) string {
+		return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, state.KeySize))
+	}
+	config := Config{
+		Organization:       "test-org",
+		StateDir:           t.TempDir(),
+		StateEncryptionKey: key(1),
+		ComplianceSLAs:     map[string]int{"posture.branch_protection_coverage": 90},
+	}
+	if _, err := collectAndClose(context.Background(), config, mock, componentsdk.LevelTrust); err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+
+	// Under a key that can't read it, the history is reported, not replaced.
+	wrong := config
+	wrong.StateEncryptionKey = key(2)
+	posture, err := collectAndClose(context.Background(), wrong, mock, componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() with the wrong key error: %v", err)
+	}
+	if posture.Diagnostics == nil || len(posture.Diagnostics.Warnings) == 0 {
+		t.Error("expected a history warning under the wrong key")
+	}
+	store, err := state.OpenEncrypted(config.StateDir, bytes.Repeat([]byte{1}, state.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history, err := store.LoadHistory("test-org"); err != nil || len(history) != 1 {
+		t.Errorf("history under the original key = %+v, %v; want today's", history, err)
+	}
+	// So are the repository snapshot and the notification baseline.
+	if snap, err := store.Load("test-org"); err != nil || snap == nil || len(snap.Repositories) != 1 {
+		t.Errorf("snapshot under the original key = %+v, %v; want the first run's", snap, err)
+	}
+	if err := store.SavePosture("test-org", []byte(`{"organization":"test-org"}`)); err != nil {
+		t.Fatal(err)
+	}
+	wrongStore, err := NewWithClient(wrong, mock).stateStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := swapBaseline(wrongStore, "test-org", posture); err == nil {
+		t.Error("swapBaseline() under the wrong key should fail")
+	}
+	if data, err := store.LoadPosture("test-org"); err != nil || string(data) != `{"organization":"test-org"}` {
+		t.Errorf("baseline under the original key = %q, %v; want it kept", data, err)
+	}
+
+	// Plaintext state read by an encrypting store is reported.
+	plainDir := t.TempDir()
+	plain, err := state.Open(plainDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.SaveHistory("test-org", nil); err != nil {
+		t.Fatal(err)
+	}
+	migrated := config
+	migrated.StateDir = plainDir
+	posture, err = collectAndClose(context.Background(), migrated, mock, componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() over plaintext state error: %v", err)
+	}
+	if posture.Diagnostics == nil || !anyContains(posture.Diagnostics.Warnings, "test-org.history.json read unencrypted") {
+		t.Errorf("missing unencrypted-state warning: %+v", posture.Diagnostics)
+	}
+
+	if _, err := New(Config{Organization: "test-org", GitHubToken: "t", StateEncryptionKey: key(1)}); err == nil {
+		t.Error("New() accepted state_encryption_key without state_dir")
+	}
+	if _, err := New(Config{Organization: "test-org", GitHubToken: "t", StateDir: t.TempDir(), StateEncryptionKey: "short"}); err == nil {
+		t.Error("New() accepted a malformed state_encryption_key")
+	}
+}
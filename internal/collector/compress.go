@@ -0,0 +1,66 @@
+package collector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// CompressedEncoding is the encoding of a CompressedArtifact.
+const CompressedEncoding = "gzip+base64"
+
+// CompressedArtifact carries an artifact's JSON gzip-compressed and
+// base64-encoded, for detail documents too large for the runner's message
+// limit. The collector protocol carries only JSON, so the compressed bytes
+// travel as a string.
+type CompressedArtifact struct {
+	Encoding string `json:"encoding"`
+	// Path is where the artifact would have been emitted uncompressed; its
+	// attestation subject, if signed, keeps this path.
+	Path string `json:"path"`
+	// Size is the uncompressed size in bytes.
+	Size    int    `json:"size"`
+	Content string `json:"content"`
+}
+
+// CompressedPath returns the path a compressed artifact is emitted at.
+func CompressedPath(path string) string {
+	return path + ".gz.json"
+}
+
+// CompressArtifact encodes data as compact JSON (or takes it as-is when it is
+// already a json.RawMessage, e.g. signed bytes) and compresses it when the
+// encoding exceeds overBytes. It reports whether it compressed; otherwise
+// data is returned unchanged.
+func CompressArtifact(path string, data any, overBytes int) (any, bool, error) {
+	if overBytes <= 0 {
+		return data, false, nil
+	}
+	raw, ok := data.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(data); err != nil {
+			return nil, false, err
+		}
+	}
+	if len(raw) <= overBytes {
+		return data, false, nil
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(raw); err != nil {
+		return nil, false, fmt.Errorf("compressing %s: %w", path, err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, false, fmt.Errorf("compressing %s: %w", path, err)
+	}
+	return &CompressedArtifact{
+		Encoding: CompressedEncoding,
+		Path:     path,
+		Size:     len(raw),
+		Content:  base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, true, nil
+}
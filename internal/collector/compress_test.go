@@ -0,0 +1,48 @@
+package collector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCompressArtifact(t *testing.T) {
+	posture := &OrgPosture{Organization: strings.Repeat("test-org", 100)}
+	raw, _ := json.Marshal(posture)
+
+	if data, compressed, err := CompressArtifact("artifacts/github.json", posture, 0); err != nil || compressed || data != posture {
+		t.Errorf("threshold 0 = %v, %v; want data unchanged", compressed, err)
+	}
+	if _, compressed, _ := CompressArtifact("artifacts/github.json", posture, len(raw)); compressed {
+		t.Error("compressed a document at the threshold")
+	}
+
+	// Signed bytes are compressed as-is.
+	data, compressed, err := CompressArtifact("artifacts/github.json", json.RawMessage(raw), 100)
+	if err != nil || !compressed {
+		t.Fatalf("CompressArtifact() = %v, %v; want compressed", compressed, err)
+	}
+	c := data.(*CompressedArtifact)
+	if c.Encoding != CompressedEncoding || c.Path != "artifacts/github.json" || c.Size != len(raw) {
+		t.Errorf("CompressedArtifact = %+v", c)
+	}
+	if len(c.Content) >= len(raw) {
+		t.Errorf("content is %d bytes, want smaller than %d", len(c.Content), len(raw))
+	}
+	gz, err := base64.StdEncoding.DecodeString(c.Content)
+	if err != nil {
+		t.Fatalf("content not base64: %v", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		t.Fatalf("content not gzip: %v", err)
+	}
+	got, _ := io.ReadAll(zr)
+	if !bytes.Equal(got, raw) {
+		t.Error("decompressed content differs from the original bytes")
+	}
+}
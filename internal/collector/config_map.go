@@ -0,0 +1,261 @@
+package collector
+
+// ConfigFromMap builds a Config from the component's config map, filling in
+// the settings of any profile first (see ApplyProfile). secret looks up the
+// named secrets (GITHUB_TOKEN, GITHUB_APP_PRIVATE_KEY, VAULT_TOKEN,
+// VAULT_SECRET_ID, SIGNING_KEY, NOTIFICATION_WEBHOOK_URL, PAGERDUTY_ROUTING_KEY,
+// OPSGENIE_API_KEY, JIRA_API_TOKEN, STATE_ENCRYPTION_KEY,
+// STATE_ENCRYPTION_KEY_PREVIOUS). Keys of the wrong type are ignored; the
+// progress callbacks are left unset.
+func ConfigFromMap(raw map[string]any, secret func(name string) string) (Config, error) {
+	cfg, err := ApplyProfile(raw)
+	if err != nil {
+		return Config{}, err
+	}
+	config := Config{
+		Organization:            getString(cfg, "organization"),
+		OwnerType:               getString(cfg, "owner_type"),
+		GitHubAPIURL:            getString(cfg, "github_api_url"),
+		IncludeMirrors:          getBool(cfg, "include_mirrors"),
+		ExcludeTemplates:        getBool(cfg, "exclude_templates"),
+		Profile:                 getString(cfg, "profile"),
+		GitHubToken:             secret("GITHUB_TOKEN"),
+		AppID:                   getInt64(cfg, "app_id"),
+		InstallationID:          getInt64(cfg, "installation_id"),
+		PrivateKey:              secret("GITHUB_APP_PRIVATE_KEY"),
+		TokenCommand:            getStringSlice(cfg, "token_command"),
+		IncludePatterns:         getStringSlice(cfg, "include_patterns"),
+		ExcludePatterns:         getStringSlice(cfg, "exclude_patterns"),
+		CaseInsensitivePatterns: getBool(cfg, "case_insensitive_patterns"),
+		SecretStore:             getString(cfg, "secret_store"),
+		GitHubTokenRef:          getString(cfg, "github_token_ref"),
+		PrivateKeyRef:           getString(cfg, "private_key_ref"),
+		VaultAddress:            getString(cfg, "vault_address"),
+		VaultToken:              secret("VAULT_TOKEN"),
+		VaultAuthMethod:         getString(cfg, "vault_auth_method"),
+		VaultAuthMount:          getString(cfg, "vault_auth_mount"),
+		VaultRole:               getString(cfg, "vault_role"),
+		VaultJWTPath:            getString(cfg, "vault_jwt_path"),
+		VaultSecretID:           secret("VAULT_SECRET_ID"),
+		Repositories:            getStringSlice(cfg, "repositories"),
+		Scopes:                  getScopes(cfg, "scopes"),
+		CoverageBreakdown:       getBool(cfg, "coverage_breakdown"),
+		Tiers:                   getTiers(cfg, "tiers"),
+		FeatureWeights:          getFloatMap(cfg, "feature_weights"),
+		RedactRepoNames:         getBool(cfg, "redact_repo_names"),
+		StateDir:                getString(cfg, "state_dir"),
+
+		FlagLegacyDefaultBranch: getBool(cfg, "flag_legacy_default_branch"),
+		MinRequiredReviews:      int(getInt64(cfg, "min_required_reviews")),
+		VerifyRequiredChecks:    getBool(cfg, "verify_required_checks"),
+		MaxInMemoryRepos:        int(getInt64(cfg, "max_in_memory_repos")),
+		LookbackDays:            getIntMap(cfg, "lookback_days"),
+		AdvisoryLookbackDays:    int(getInt64(cfg, "advisory_lookback_days")),
+		CollectTriage:           getBool(cfg, "collect_triage"),
+		CollectReleases:         getBool(cfg, "collect_releases"),
+		MaxReleaseRepos:         int(getInt64(cfg, "max_release_repos")),
+		ScanWorkflowCredentials: getBool(cfg, "scan_workflow_credentials"),
+		SearchCredentialFiles:   getBool(cfg, "search_credential_files"),
+		TriageStaleDays:         int(getInt64(cfg, "triage_stale_days")),
+		SecretRotationDays:      int(getInt64(cfg, "secret_rotation_days")),
+		SecretHotspotCount:      int(getInt64(cfg, "secret_hotspot_count")),
+		MaxRepositories:         int(getInt64(cfg, "max_repositories")),
+		ListCaps:                getIntMap(cfg, "list_caps"),
+		MaxRateLimitWaitSeconds: int(getInt64(cfg, "max_rate_limit_wait_seconds")),
+		AbortBelowRemaining:     int(getInt64(cfg, "abort_below_remaining")),
+		MinIntervalMinutes:      int(getInt64(cfg, "min_interval_minutes")),
+		StrictMode:              getBool(cfg, "strict_mode"),
+		CISBenchmark:            getBool(cfg, "cis_benchmark"),
+
+		HTTPProxy:          getString(cfg, "http_proxy"),
+		HTTPSProxy:         getString(cfg, "https_proxy"),
+		NoProxy:            getString(cfg, "no_proxy"),
+		CABundlePath:       getString(cfg, "ca_bundle_path"),
+		InsecureSkipVerify: getBool(cfg, "insecure_skip_verify"),
+
+		MaxRequestsPerSecond: getFloat64(cfg, "max_requests_per_second"),
+		MaxIdleConnsPerHost:  int(getInt64(cfg, "max_idle_conns_per_host")),
+		DisableHTTP2:         getBool(cfg, "disable_http2"),
+		DebugCapturePath:     getString(cfg, "debug_capture_path"),
+		HeartbeatInterval:    int(getInt64(cfg, "heartbeat_interval")),
+		ProgressFormat:       getString(cfg, "progress_format"),
+
+		CompressOutputOverBytes: int(getInt64(cfg, "compress_output_over_bytes")),
+		OutputFields:            getStringSlice(cfg, "output_fields"),
+		OCSFOutput:              getBool(cfg, "ocsf_output"),
+
+		NotificationWebhook:    secret("NOTIFICATION_WEBHOOK_URL"),
+		NotificationFormat:     getString(cfg, "notification_format"),
+		NotifyOnlyOnRegression: getBool(cfg, "notify_only_on_regression"),
+		AlertThresholds:        getIntMap(cfg, "alert_thresholds"),
+		ComplianceSLAs:         getIntMap(cfg, "compliance_slas"),
+		SLAWindowDays:          int(getInt64(cfg, "sla_window_days")),
+		PagerDutyRoutingKey:    secret("PAGERDUTY_ROUTING_KEY"),
+		OpsgenieAPIKey:         secret("OPSGENIE_API_KEY"),
+
+		RemediationTracker:    getString(cfg, "remediation_tracker"),
+		RemediationGroupBy:    getString(cfg, "remediation_group_by"),
+		RemediationRepository: getString(cfg, "remediation_repository"),
+		JiraURL:               getString(cfg, "jira_url"),
+		JiraProject:           getString(cfg, "jira_project"),
+		JiraEmail:             getString(cfg, "jira_email"),
+		JiraAPIToken:          secret("JIRA_API_TOKEN"),
+
+		SigningKey: secret("SIGNING_KEY"),
+
+		StateEncryptionKey:         secret("STATE_ENCRYPTION_KEY"),
+		StateEncryptionKeyPrevious: secret("STATE_ENCRYPTION_KEY_PREVIOUS"),
+	}
+	return config, nil
+}
+
+// getString safely extracts a string from config map
+func getString(cfg map[string]any, key string) string {
+	if cfg == nil {
+		return ""
+	}
+	if v, ok := cfg[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// getInt64 safely extracts an int64 from config map
+func getInt64(cfg map[string]any, key string) int64 {
+	if cfg == nil {
+		return 0
+	}
+	switch v := cfg[key].(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// getFloat64 safely extracts a float64 from config map
+func getFloat64(cfg map[string]any, key string) float64 {
+	if cfg == nil {
+		return 0
+	}
+	switch v := cfg[key].(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+// getBool safely extracts a bool from config map
+func getBool(cfg map[string]any, key string) bool {
+	if cfg == nil {
+		return false
+	}
+	if v, ok := cfg[key].(bool); ok {
+		return v
+	}
+	return false
+}
+
+// getStringSlice safely extracts a string slice from config map
+func getStringSlice(cfg map[string]any, key string) []string {
+	if cfg == nil {
+		return nil
+	}
+	if v, ok := cfg[key].([]any); ok {
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// getFloatMap safely extracts a map of numbers from config map, e.g.
+// {"secret_scanning_push_protection": 2}. Non-numeric values are dropped.
+func getFloatMap(cfg map[string]any, key string) map[string]float64 {
+	v, ok := cfg[key].(map[string]any)
+	if !ok {
+		return nil
+	}
+	result := make(map[string]float64, len(v))
+	for k, raw := range v {
+		switch raw.(type) {
+		case float64, int64, int:
+			result[k] = getFloat64(v, k)
+		}
+	}
+	return result
+}
+
+// getIntMap safely extracts a map of integers from config map, e.g.
+// {"default": 30}. Non-numeric values are dropped.
+func getIntMap(cfg map[string]any, key string) map[string]int {
+	floats := getFloatMap(cfg, key)
+	if floats == nil {
+		return nil
+	}
+	result := make(map[string]int, len(floats))
+	for k, v := range floats {
+		result[k] = int(v)
+	}
+	return result
+}
+
+// getScopes safely extracts per-metric scopes from config map, e.g.
+// {"branch_protection": {"exclude": ["docs-*"]}}
+func getScopes(cfg map[string]any, key string) map[string]MetricScope {
+	v, ok := cfg[key].(map[string]any)
+	if !ok {
+		return nil
+	}
+	scopes := make(map[string]MetricScope, len(v))
+	for family, raw := range v {
+		scope, _ := raw.(map[string]any)
+		scopes[family] = MetricScope{
+			Include: getStringSlice(scope, "include"),
+			Exclude: getStringSlice(scope, "exclude"),
+		}
+	}
+	return scopes
+}
+
+// getTiers safely extracts repository tiers from config map, e.g.
+// {"critical": {"properties": {"tier": ["critical"]}, "thresholds":
+// {"branch_protection": 100}}}. A property value may be a string or a list.
+func getTiers(cfg map[string]any, key string) map[string]TierRule {
+	v, ok := cfg[key].(map[string]any)
+	if !ok {
+		return nil
+	}
+	tiers := make(map[string]TierRule, len(v))
+	for name, raw := range v {
+		tier, _ := raw.(map[string]any)
+		rule := TierRule{
+			Include:    getStringSlice(tier, "include"),
+			Exclude:    getStringSlice(tier, "exclude"),
+			Thresholds: getIntMap(tier, "thresholds"),
+		}
+		if props, ok := tier["properties"].(map[string]any); ok {
+			rule.Properties = make(map[string][]string, len(props))
+			for property, value := range props {
+				if one, ok := value.(string); ok {
+					rule.Properties[property] = []string{one}
+				} else {
+					rule.Properties[property] = getStringSlice(props, property)
+				}
+			}
+		}
+		tiers[name] = rule
+	}
+	return tiers
+}
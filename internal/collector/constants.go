@@ -3,8 +3,46 @@ package collector
 // Pattern constants.
 const DefaultIncludePattern = "*"
 
-// Security features count for coverage calculation.
-const NumSecurityFeatures = 5
-
 // Percentage constants.
 const MaxPercentage = 100
+
+// LegacyDefaultBranch is the default-branch name flagged by the repository
+// hygiene check when FlagLegacyDefaultBranch is set.
+const LegacyDefaultBranch = "master"
+
+// SettingsQueueSize is how many included repositories the enumeration phase
+// may queue ahead of the per-repo security settings phase.
+const SettingsQueueSize = 100
+
+// RepoCacheMemoryLimit is the default number of included repositories kept in
+// memory for the audit/internal surfaces before the rest spill to disk.
+const RepoCacheMemoryLimit = 5000
+
+// Account types for Config.OwnerType.
+const (
+	OwnerTypeOrganization = "organization"
+	OwnerTypeUser         = "user"
+)
+
+// Products for GitHubInstance.Product.
+const (
+	InstanceProductGitHub = "github.com"
+	InstanceProductGHES   = "ghes"
+)
+
+// Reason codes for Scope.ExcludedCounts, in the order they're checked.
+const (
+	ExcludeReasonArchived = "archived"
+	ExcludeReasonMirror   = "mirror"
+	ExcludeReasonTemplate = "template"
+	ExcludeReasonPattern  = "pattern"
+)
+
+// Reason codes for Scope.SkippedRepositories.
+const (
+	SkipReasonPermissionDenied = "permission_denied"
+	SkipReasonNotFound         = "not_found"
+	SkipReasonBlocked          = "blocked"
+	SkipReasonFetchError       = "fetch_error"
+	SkipReasonCancelled        = "cancelled"
+)
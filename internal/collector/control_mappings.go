@@ -0,0 +1,36 @@
+package collector
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+//go:embed control_mappings.json
+var controlMappingsJSON []byte
+
+// ControlMappingTable relates emitted metrics to compliance framework
+// controls, so GRC consumers don't maintain the mapping by hand. It is
+// printed by --print-mappings.
+type ControlMappingTable struct {
+	Frameworks map[string]string `json:"frameworks"` // key → framework name and version
+	Mappings   []ControlMapping  `json:"mappings"`
+}
+
+// ControlMapping lists the controls one metric provides evidence for. Metric
+// is a dotted path into the github.json artifact; a section path (e.g.
+// "members") covers every field beneath it.
+type ControlMapping struct {
+	Metric    string   `json:"metric"`
+	SOC2      []string `json:"soc2,omitempty"`
+	ISO27001  []string `json:"iso27001,omitempty"`
+	NIST80053 []string `json:"nist_800_53,omitempty"`
+}
+
+// ControlMappings returns the embedded control mapping table.
+func ControlMappings() (*ControlMappingTable, error) {
+	var table ControlMappingTable
+	if err := json.Unmarshal(controlMappingsJSON, &table); err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
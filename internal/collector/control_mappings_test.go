@@ -0,0 +1,57 @@
+package collector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fieldByJSONPath resolves a dotted json-tag path against t.
+func fieldByJSONPath(t reflect.Type, path string) bool {
+	for _, part := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		found := false
+		for i := range t.NumField() {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if name == part {
+				t, found = t.Field(i).Type, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func TestControlMappings(t *testing.T) {
+	table, err := ControlMappings()
+	if err != nil {
+		t.Fatalf("ControlMappings() error: %v", err)
+	}
+	for _, key := range []string{"soc2", "iso27001", "nist_800_53"} {
+		if table.Frameworks[key] == "" {
+			t.Errorf("framework %s is not named", key)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, m := range table.Mappings {
+		if seen[m.Metric] {
+			t.Errorf("duplicate mapping for %s", m.Metric)
+		}
+		seen[m.Metric] = true
+		if !fieldByJSONPath(reflect.TypeFor[OrgPosture](), m.Metric) {
+			t.Errorf("mapping for %s names no field in the posture output", m.Metric)
+		}
+		if len(m.SOC2)+len(m.ISO27001)+len(m.NIST80053) == 0 {
+			t.Errorf("mapping for %s lists no controls", m.Metric)
+		}
+	}
+}
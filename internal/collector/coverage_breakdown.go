@@ -0,0 +1,202 @@
+package collector
+
+import "github.com/locktivity/epack-collector-github/internal/github"
+
+// NoLanguage keys the coverage_breakdown.by_language segment of repositories
+// without a detected primary language (typically docs or config only).
+const NoLanguage = "none"
+
+// coverageSegments tallies security feature coverage per repository context:
+// whether CI is configured, the primary language, and the repository class
+// (see classifyRepo), and the configured tier (see newRepoTiers). Unlike the org-wide
+// percentages it ignores metric scopes, so every segment is evaluated over
+// the same in-scope repositories. It is only kept when coverage_breakdown or
+// tiers are configured.
+type coverageSegments struct {
+	withCI, withoutCI segmentCounts
+	byLanguage        map[string]*segmentCounts
+	byClass           map[string]*segmentCounts
+	ciSystems         map[string]int
+
+	// tiers, when configured, assigns each repository its tier.
+	tiers  *repoTiers
+	byTier map[string]*segmentCounts
+}
+
+// repoContext is one repository's segment keys. It travels with the
+// repository's fetched settings (see repoSettings) rather than being kept
+// per repository here, so memory stays bounded on large orgs.
+type repoContext struct {
+	language string
+	class    string
+	tier     string
+	hasCI    bool
+}
+
+// segmentCounts is the enabled-feature tally of one segment.
+type segmentCounts struct {
+	repos                        int
+	branchProtection             int
+	vulnerabilityAlerts          int
+	codeScanning                 int
+	secretScanning               int
+	secretScanningPushProtection int
+	dependabotSecurityUpdates    int
+}
+
+// newCoverageSegments returns empty tallies, split by tier when tiers are
+// configured.
+func newCoverageSegments(tiers *repoTiers) *coverageSegments {
+	return &coverageSegments{
+		byLanguage: make(map[string]*segmentCounts),
+		byClass:    make(map[string]*segmentCounts),
+		ciSystems:  make(map[string]int),
+		tiers:      tiers,
+		byTier:     make(map[string]*segmentCounts),
+	}
+}
+
+// context returns the segment keys of a repository.
+func (s *coverageSegments) context(repo github.Repository) repoContext {
+	ctx := repoContext{language: NoLanguage, class: classifyRepo(repo), hasCI: len(repo.CISystems()) > 0}
+	if repo.PrimaryLanguage != nil && repo.PrimaryLanguage.Name != "" {
+		ctx.language = repo.PrimaryLanguage.Name
+	}
+	if s.tiers != nil {
+		ctx.tier = s.tiers.assign(repo)
+	}
+	return ctx
+}
+
+// addRepo counts an in-scope repository and its GraphQL-reported features.
+// protected is whether its default branch has effective protection.
+func (s *coverageSegments) addRepo(repo github.Repository, protected bool) {
+	for _, system := range repo.CISystems() {
+		s.ciSystems[system]++
+	}
+	for _, seg := range s.segments(s.context(repo)) {
+		seg.repos++
+		if protected {
+			seg.branchProtection++
+		}
+		if repo.HasVulnerabilityAlertsEnabled {
+			seg.vulnerabilityAlerts++
+		}
+	}
+}
+
+// addSettings counts a repository's REST security settings in the segments
+// of ctx.
+func (s *coverageSegments) addSettings(ctx repoContext, settings *github.SecuritySettings) {
+	for _, seg := range s.segments(ctx) {
+		if settings.CodeScanningEnabled {
+			seg.codeScanning++
+		}
+		if settings.SecretScanning {
+			seg.secretScanning++
+		}
+		if settings.SecretScanningPushProtection {
+			seg.secretScanningPushProtection++
+		}
+		if settings.DependabotSecurityUpdates {
+			seg.dependabotSecurityUpdates++
+		}
+		// Only set when GraphQL reported alerts disabled, so never double
+		// counts.
+		if settings.VulnerabilityAlerts != nil && *settings.VulnerabilityAlerts {
+			seg.vulnerabilityAlerts++
+		}
+	}
+}
+
+// segments returns the tallies a repository context counts towards.
+func (s *coverageSegments) segments(ctx repoContext) []*segmentCounts {
+	lang := s.byLanguage[ctx.language]
+	if lang == nil {
+		lang = &segmentCounts{}
+		s.byLanguage[ctx.language] = lang
+	}
+	class := s.byClass[ctx.class]
+	if class == nil {
+		class = &segmentCounts{}
+		s.byClass[ctx.class] = class
+	}
+	segs := []*segmentCounts{&s.withoutCI, lang, class}
+	if ctx.hasCI {
+		segs[0] = &s.withCI
+	}
+	if ctx.tier != "" {
+		tier := s.byTier[ctx.tier]
+		if tier == nil {
+			tier = &segmentCounts{}
+			s.byTier[ctx.tier] = tier
+		}
+		segs = append(segs, tier)
+	}
+	return segs
+}
+
+// toCoverageBreakdown converts the tallies to percentages, nil when the
+// breakdown wasn't requested.
+func (s *coverageSegments) toCoverageBreakdown() *CoverageBreakdown {
+	if s == nil {
+		return nil
+	}
+	breakdown := &CoverageBreakdown{
+		WithCI:    s.withCI.toSegment(),
+		WithoutCI: s.withoutCI.toSegment(),
+		CISystems: s.ciSystems,
+	}
+	if len(s.byLanguage) > 0 {
+		breakdown.ByLanguage = make(map[string]CoverageSegment, len(s.byLanguage))
+		for lang, counts := range s.byLanguage {
+			breakdown.ByLanguage[lang] = counts.toSegment()
+		}
+	}
+	if len(s.byClass) > 0 {
+		breakdown.ByClass = make(map[string]CoverageSegment, len(s.byClass))
+		for class, counts := range s.byClass {
+			breakdown.ByClass[class] = counts.toSegment()
+		}
+	}
+	if s.tiers != nil {
+		// Every tier with a threshold is reported, even when empty.
+		breakdown.ByTier = make(map[string]TierCoverage)
+		met, known := true, true
+		for _, tier := range tierNames {
+			counts := s.byTier[tier]
+			if counts == nil && s.tiers.thresholds[tier] == nil {
+				continue
+			}
+			if counts == nil {
+				counts = &segmentCounts{}
+			}
+			coverage := tierCoverage(*counts, s.tiers.thresholds[tier], s.tiers.incomplete)
+			for _, threshold := range coverage.Thresholds {
+				if threshold.Met == nil {
+					known = false
+				} else {
+					met = met && *threshold.Met
+				}
+			}
+			breakdown.ByTier[tier] = coverage
+		}
+		// A missed threshold fails the tiers whatever else is unknown.
+		if len(s.tiers.thresholds) > 0 && (known || !met) {
+			breakdown.TierThresholdsMet = &met
+		}
+	}
+	return breakdown
+}
+
+func (c segmentCounts) toSegment() CoverageSegment {
+	return CoverageSegment{
+		Repos:                        c.repos,
+		BranchProtection:             percent(c.branchProtection, c.repos),
+		VulnerabilityAlerts:          percent(c.vulnerabilityAlerts, c.repos),
+		CodeScanning:                 percent(c.codeScanning, c.repos),
+		SecretScanning:               percent(c.secretScanning, c.repos),
+		SecretScanningPushProtection: percent(c.secretScanningPushProtection, c.repos),
+		DependabotSecurityUpdates:    percent(c.dependabotSecurityUpdates, c.repos),
+	}
+}
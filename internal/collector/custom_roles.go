@@ -0,0 +1,78 @@
+package collector
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// riskyRolePermissions are the fine-grained permissions that let a custom
+// role holder weaken or route around the org's security controls.
+var riskyRolePermissions = []string{
+	// Repository roles.
+	"bypass_branch_protection",
+	"edit_repo_protections",
+	"delete_alerts_code_scanning",
+	"resolve_secret_scanning_alerts",
+	"manage_deploy_keys",
+	"manage_webhooks",
+	// Organization roles.
+	"write_organization_custom_org_role",
+	"write_organization_custom_repo_role",
+	"write_organization_actions_secrets",
+	"write_organization_actions_settings",
+	"manage_organization_webhooks",
+	"write_organization_network_configurations",
+}
+
+// augmentCustomRoles inventories the org's custom organization and repository
+// roles. Orgs on plans without custom roles get a 404 (or a feature-disabled
+// 403), which counts as no roles of that kind; a permission denial on either
+// list leaves the section out, since partial counts would understate risk.
+func (c *Collector) augmentCustomRoles(p *collectionPass) {
+	orgRoles, orgErr := c.client.ListCustomOrgRoles(p.ctx, p.org)
+	repoRoles, repoErr := c.client.ListCustomRepoRoles(p.ctx, p.org)
+
+	if isDenied(orgErr) {
+		p.metrics.diag.surfacePermissionDenied("access_control.custom_roles", "organization_custom_org_roles:read")
+	}
+	if isDenied(repoErr) {
+		p.metrics.diag.surfacePermissionDenied("access_control.custom_roles", "organization_custom_roles:read")
+	}
+	if !customRolesKnown(orgErr) || !customRolesKnown(repoErr) {
+		return
+	}
+
+	cr := &CustomRoles{OrganizationRoles: len(orgRoles), RepositoryRoles: len(repoRoles)}
+	addRisky := func(kind string, roles []github.CustomRole) {
+		for _, role := range roles {
+			var risky []string
+			for _, perm := range role.Permissions {
+				if slices.Contains(riskyRolePermissions, perm) {
+					risky = append(risky, perm)
+				}
+			}
+			if len(risky) == 0 {
+				continue
+			}
+			slices.Sort(risky)
+			cr.Risky = append(cr.Risky, RiskyRoleRow{Name: role.Name, Kind: kind, Permissions: risky})
+		}
+	}
+	addRisky("organization", orgRoles)
+	addRisky("repository", repoRoles)
+	slices.SortFunc(cr.Risky, func(a, b RiskyRoleRow) int {
+		return strings.Compare(a.Kind+"/"+a.Name, b.Kind+"/"+b.Name)
+	})
+	cr.RiskyRoles = len(cr.Risky)
+	cr.RiskyPermissionsGranted = cr.RiskyRoles > 0
+	p.posture.AccessControl.CustomRoles = cr
+}
+
+// customRolesKnown reports whether a custom-role list call succeeded or
+// failed only because the org's plan doesn't offer custom roles.
+func customRolesKnown(err error) bool {
+	return err == nil || errors.Is(err, github.ErrNotFound) || isFeatureUnavailable(err)
+}
@@ -0,0 +1,126 @@
+package collector
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ConfigDescription is the machine-readable description of the collector's
+// configuration emitted by --describe-config.
+type ConfigDescription struct {
+	Options []ConfigOption `json:"options"`
+	Secrets []SecretOption `json:"secrets"`
+}
+
+// ConfigOption describes one config key.
+type ConfigOption struct {
+	Key         string   `json:"key"`
+	Type        string   `json:"type"` // JSON Schema type name
+	Required    bool     `json:"required,omitempty"`
+	Default     any      `json:"default,omitempty"`
+	Enables     []string `json:"enables,omitempty"` // output sections the key turns on or shapes
+	Description string   `json:"description"`
+}
+
+// SecretOption describes one epack secret the collector reads.
+type SecretOption struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// levelOption is the level key, which the Component SDK reads rather than
+// Config.
+var levelOption = ConfigOption{
+	Key:         "level",
+	Type:        "string",
+	Default:     "trust",
+	Description: "Collection level: trust, audit, or internal (cumulative)",
+}
+
+// DescribeConfig builds the config description from Config's struct tags.
+// It fails if a tag is malformed, so a bad tag surfaces in tests rather than
+// as a silently wrong description.
+func DescribeConfig() (*ConfigDescription, error) {
+	desc := &ConfigDescription{Options: []ConfigOption{levelOption}}
+
+	t := reflect.TypeFor[Config]()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if key == "" || key == "-" {
+			continue
+		}
+		description := field.Tag.Get("describe")
+
+		if secret := field.Tag.Get("secret"); secret != "" {
+			desc.Secrets = append(desc.Secrets, SecretOption{Name: secret, Description: description})
+			continue
+		}
+
+		typ, err := jsonType(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("config key %s: %w", key, err)
+		}
+		opt := ConfigOption{
+			Key:         key,
+			Type:        typ,
+			Required:    field.Tag.Get("required") == "true",
+			Description: description,
+		}
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if opt.Default, err = parseDefault(typ, def); err != nil {
+				return nil, fmt.Errorf("config key %s: default %q: %w", key, def, err)
+			}
+		}
+		if enables := field.Tag.Get("enables"); enables != "" {
+			opt.Enables = strings.Split(enables, ",")
+		}
+		desc.Options = append(desc.Options, opt)
+	}
+	return desc, nil
+}
+
+// jsonType maps a Config field's Go type to its JSON Schema type name.
+func jsonType(t reflect.Type) (string, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return "string", nil
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Int, reflect.Int64:
+		return "integer", nil
+	case reflect.Float64:
+		return "number", nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return "array", nil
+		}
+	case reflect.Map:
+		if t.Key().Kind() == reflect.String {
+			return "object", nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %s", t)
+}
+
+// parseDefault converts a default tag to a typed value. String defaults are
+// kept verbatim (including "$ENV" placeholders).
+func parseDefault(typ, def string) (any, error) {
+	switch typ {
+	case "boolean":
+		return strconv.ParseBool(def)
+	case "integer":
+		return strconv.ParseInt(def, 10, 64)
+	case "number":
+		return strconv.ParseFloat(def, 64)
+	case "array":
+		var v []string
+		err := json.Unmarshal([]byte(def), &v)
+		return v, err
+	default:
+		return def, nil
+	}
+}
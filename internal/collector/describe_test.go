@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDescribeConfig(t *testing.T) {
+	desc, err := DescribeConfig()
+	if err != nil {
+		t.Fatalf("DescribeConfig() error: %v", err)
+	}
+
+	options := map[string]ConfigOption{}
+	for _, opt := range desc.Options {
+		if _, dup := options[opt.Key]; dup {
+			t.Errorf("duplicate config key %s", opt.Key)
+		}
+		if opt.Description == "" {
+			t.Errorf("config key %s has no describe tag", opt.Key)
+		}
+		options[opt.Key] = opt
+	}
+
+	if !options["organization"].Required {
+		t.Error("organization should be required")
+	}
+	if _, ok := options["level"]; !ok {
+		t.Error("level (read by the SDK) should be described")
+	}
+	for _, key := range []string{"github_token", "private_key", "vault_token", "vault_secret_id", "signing_key", "notification_webhook", "pagerduty_routing_key", "opsgenie_api_key", "jira_api_token", "state_encryption_key", "state_encryption_key_previous"} {
+		if _, ok := options[key]; ok {
+			t.Errorf("%s is a secret and should not be listed as a config key", key)
+		}
+	}
+	var secrets []string
+	for _, s := range desc.Secrets {
+		secrets = append(secrets, s.Name)
+	}
+	wantSecrets := []string{"GITHUB_TOKEN", "GITHUB_APP_PRIVATE_KEY", "VAULT_TOKEN", "VAULT_SECRET_ID", "STATE_ENCRYPTION_KEY", "STATE_ENCRYPTION_KEY_PREVIOUS", "SIGNING_KEY", "NOTIFICATION_WEBHOOK_URL", "PAGERDUTY_ROUTING_KEY", "OPSGENIE_API_KEY", "JIRA_API_TOKEN"}
+	if !slices.Equal(secrets, wantSecrets) {
+		t.Errorf("secrets = %v, want %v", secrets, wantSecrets)
+	}
+
+	// Defaults documented in tags must match the defaults the code applies.
+	wantDefaults := map[string]any{
+		"owner_type":             OwnerTypeOrganization,
+		"max_in_memory_repos":    int64(RepoCacheMemoryLimit),
+		"advisory_lookback_days": int64(AdvisoryLookbackDays),
+		"triage_stale_days":      int64(TriageStaleDays),
+		"secret_rotation_days":   int64(SecretRotationDays),
+		"max_release_repos":      int64(ReleaseRepoCap),
+	}
+	for key, want := range wantDefaults {
+		if got := options[key].Default; got != want {
+			t.Errorf("%s default = %v (%T), want %v (%T)", key, got, got, want, want)
+		}
+	}
+	if def, ok := options["include_patterns"].Default.([]string); !ok || len(def) != 1 || def[0] != DefaultIncludePattern {
+		t.Errorf("include_patterns default = %v, want [%s]", options["include_patterns"].Default, DefaultIncludePattern)
+	}
+}
@@ -1,19 +1,37 @@
 package collector
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
 
 // diagnostics accumulates non-fatal collection problems: permission denials
-// (which skip a surface) and feature-unavailable warnings.
+// (which skip a surface) and feature-unavailable warnings. It may be written
+// from concurrent goroutines.
 type diagnostics struct {
+	mu               sync.Mutex
 	permissionErrors []string
 	warnings         []string
 }
 
 // addPermissionError records a pre-formatted permission-error string.
 func (d *diagnostics) addPermissionError(msg string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	d.permissionErrors = append(d.permissionErrors, msg)
 }
 
+// addWarning records a pre-formatted warning string.
+func (d *diagnostics) addWarning(msg string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.warnings = append(d.warnings, msg)
+}
+
 // surfacePermissionDenied records that an audit/internal surface was skipped
 // because the App (or PAT) lacks a permission. The surface's pointer field
 // stays nil (omitempty keeps it out of the artifact) and this message lands in
@@ -27,18 +45,112 @@ func (d *diagnostics) surfacePermissionDenied(surface, missingPerm string) {
 // policy for PAT inventory). Informational, not an error: it lands in
 // Diagnostics.Warnings and never fails the run.
 func (d *diagnostics) surfaceUnavailable(surface, requirement string) {
-	d.warnings = append(d.warnings, fmt.Sprintf("surface %s skipped: %s", surface, requirement))
+	d.addWarning(fmt.Sprintf("surface %s skipped: %s", surface, requirement))
+}
+
+// unsupportedOnInstance records that a collection was skipped because the
+// instance doesn't serve its endpoints (an Enterprise Server release older
+// than the capability matrix requires), so the missing data isn't read as
+// the feature being disabled.
+func (d *diagnostics) unsupportedOnInstance(surface string, instance github.Instance) {
+	d.addWarning(fmt.Sprintf("%s skipped: not supported on GHES %s", surface, instance.Release()))
+}
+
+// instanceUndetected records that the instance couldn't be identified, so
+// every endpoint is tried.
+func (d *diagnostics) instanceUndetected(err error) {
+	d.addWarning("instance detection failed, assuming all endpoints are served: " + err.Error())
+}
+
+// malformedResponses records that REST responses couldn't be decoded
+// (malformed, truncated, or over the size cap), so the data they carried is
+// missing rather than zero. detail describes the last one, when set.
+func (d *diagnostics) malformedResponses(count int, detail string) {
+	msg := fmt.Sprintf("transport: %d GitHub API responses could not be decoded and were skipped", count)
+	if detail != "" {
+		msg += " (last: " + detail + ")"
+	}
+	d.addWarning(msg)
 }
 
 // memberNamesIncomplete records that display names are missing from some
 // member rows for a reason other than the user not setting one, so consumers
 // don't read an absent name as "not set".
 func (d *diagnostics) memberNamesIncomplete(reason string) {
-	d.warnings = append(d.warnings, "members: display names incomplete: "+reason)
+	d.addWarning("members: display names incomplete: " + reason)
+}
+
+// tlsVerificationDisabled records that the run skipped TLS certificate
+// verification, so the artifact's provenance is visibly weaker.
+func (d *diagnostics) tlsVerificationDisabled() {
+	d.addWarning("transport: TLS certificate verification disabled (insecure_skip_verify)")
+}
+
+// budgetExhausted records that collection stopped early to preserve the
+// shared token's GraphQL rate limit, so the artifact is partial.
+func (d *diagnostics) budgetExhausted(threshold int) {
+	d.addWarning(fmt.Sprintf(
+		"collection stopped early: GraphQL rate limit remaining fell below abort_below_remaining (%d); output is partial", threshold))
+}
+
+// repositoryCapReached records that enumeration stopped at max_repositories,
+// so the metrics cover only the first repositories enumerated.
+func (d *diagnostics) repositoryCapReached(limit int) {
+	d.addWarning(fmt.Sprintf(
+		"collection truncated: stopped after max_repositories (%d) in-scope repositories; output is partial", limit))
+}
+
+// cancelled records that the run's context was cancelled before collection
+// finished, so the artifact is partial: repositories not yet checked are
+// listed as skipped and the audit surfaces are omitted.
+func (d *diagnostics) cancelled(checked, total int) {
+	d.addWarning(fmt.Sprintf(
+		"collection cancelled: security settings checked for %d of %d repositories; output is partial", checked, total))
+}
+
+// userAccount records that the run targets a user account, so the
+// organization-only metrics are unknown or omitted rather than failing.
+func (d *diagnostics) userAccount() {
+	d.addWarning("owner_type user: organization-only metrics skipped (2FA enforcement, org defaults, members, audit log, apps, tokens, org webhooks, runners, and secrets)")
+}
+
+// snapshotUnavailable records that the snapshot store couldn't be read or
+// written, so repository_changes is missing or will be next run.
+func (d *diagnostics) snapshotUnavailable(err error) {
+	d.addWarning("repository_changes: snapshot store: " + err.Error())
+}
+
+// stateUnencrypted records that an encrypting state store read files in
+// plaintext, which it has now encrypted.
+func (d *diagnostics) stateUnencrypted(files []string) {
+	d.addWarning(fmt.Sprintf("state: %s read unencrypted despite state_encryption_key and re-encrypted; expected only on the first encrypted run", strings.Join(files, ", ")))
+}
+
+// historyUnavailable records that the metric history couldn't be read or
+// written, so compliance_slas miss today or earlier days.
+func (d *diagnostics) historyUnavailable(err error) {
+	d.addWarning("compliance_slas: metric history: " + err.Error())
+}
+
+// counts returns how many permission errors and warnings were recorded so
+// far.
+func (d *diagnostics) counts() (permissionErrors, warnings int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.permissionErrors), len(d.warnings)
+}
+
+// lists returns copies of the permission errors and warnings recorded so far.
+func (d *diagnostics) lists() (permissionErrors, warnings []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.permissionErrors), slices.Clone(d.warnings)
 }
 
 // build returns the output Diagnostics, or nil when there's nothing to report.
 func (d *diagnostics) build() *Diagnostics {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	if len(d.permissionErrors) == 0 && len(d.warnings) == 0 {
 		return nil
 	}
@@ -0,0 +1,227 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// Doctor check statuses, worst last.
+const (
+	DoctorPass = "pass"
+	DoctorWarn = "warn"
+	DoctorFail = "fail"
+)
+
+// doctorSlowRequest is the request latency above which the doctor warns: a
+// full collection issues thousands of requests, so slowness compounds.
+const doctorSlowRequest = 2 * time.Second
+
+// doctorLowRemaining is the GraphQL rate limit remaining below which the
+// doctor warns when abort_below_remaining is unset.
+const doctorLowRemaining = 500
+
+// DoctorReport is the result of Collector.Doctor: one row per check, and the
+// worst of their statuses.
+type DoctorReport struct {
+	Organization string        `json:"organization"`
+	OwnerType    string        `json:"owner_type"`
+	Status       string        `json:"status"`
+	Checks       []DoctorCheck `json:"checks"`
+}
+
+// DoctorCheck is one diagnostic check. LatencyMS is the time its request
+// took, omitted for checks that send none.
+type DoctorCheck struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Detail    string `json:"detail"`
+	LatencyMS *int64 `json:"latency_ms,omitempty"`
+}
+
+// doctorProbe is a permission probe: one org-level read standing in for the
+// surfaces that need the same permission.
+type doctorProbe struct {
+	name       string
+	permission string
+	probe      func(ctx context.Context, c *Collector) error
+}
+
+// doctorProbes are the permission probes run for organizations. User
+// accounts have no org-level permissions to probe.
+var doctorProbes = []doctorProbe{
+	{"organization_settings", "organization_administration:read", func(ctx context.Context, c *Collector) error {
+		_, err := c.client.GetOrgSettings(ctx, c.config.Organization)
+		return err
+	}},
+	{"rulesets", "organization_administration:read", func(ctx context.Context, c *Collector) error {
+		_, _, err := c.client.ListOrgRulesets(ctx, c.config.Organization)
+		return err
+	}},
+	{"members", "members:read", func(ctx context.Context, c *Collector) error {
+		_, err := c.client.GetOrgMembership(ctx, c.config.Organization)
+		return err
+	}},
+	{"webhooks", "organization_hooks:read", func(ctx context.Context, c *Collector) error {
+		_, err := c.client.ListOrgHooks(ctx, c.config.Organization)
+		return err
+	}},
+	{"actions", "organization_self_hosted_runners:read", func(ctx context.Context, c *Collector) error {
+		_, err := c.client.ListOrgRunners(ctx, c.config.Organization)
+		return err
+	}},
+}
+
+// errFirstPage stops the doctor's repository fetch after one page.
+var errFirstPage = errors.New("first page read")
+
+// Doctor runs an end-to-end diagnostic of the configured account without
+// collecting: it validates the credentials, probes the org-level permissions
+// the audit and internal surfaces need, reads one page of repositories and
+// one repository's security settings, and reports request latency and the
+// rate limit left. Failures are reported as rows, never returned; a fail
+// means a collection would fail too, a warn that it would degrade.
+func (c *Collector) Doctor(ctx context.Context) *DoctorReport {
+	user := c.config.OwnerType == OwnerTypeUser
+	report := &DoctorReport{Organization: c.config.Organization, OwnerType: OwnerTypeOrganization}
+	if user {
+		report.OwnerType = OwnerTypeUser
+	}
+	var slowest time.Duration
+	timed := func(call func() error) (time.Duration, error) {
+		start := time.Now()
+		err := call()
+		elapsed := time.Since(start)
+		slowest = max(slowest, elapsed)
+		return elapsed, err
+	}
+
+	var instance github.Instance
+	elapsed, err := timed(func() (err error) {
+		instance, err = c.client.DetectInstance(ctx)
+		return err
+	})
+	if err != nil {
+		report.add("authentication", DoctorFail, fmt.Sprintf("credentials rejected or GitHub unreachable: %v", err), elapsed)
+		// Nothing else can succeed.
+		report.finish()
+		return report
+	}
+	report.add("authentication", DoctorPass, "credentials accepted by "+instance.String(), elapsed)
+
+	if !user {
+		for _, p := range doctorProbes {
+			elapsed, err := timed(func() error { return p.probe(ctx, c) })
+			name := "permission." + p.name
+			switch {
+			case err == nil:
+				report.add(name, DoctorPass, "granted: "+p.permission, elapsed)
+			case isDenied(err):
+				report.add(name, DoctorWarn, "missing "+p.permission+"; its surfaces will be skipped", elapsed)
+			case isUnsupported(err) || isFeatureUnavailable(err):
+				report.add(name, DoctorWarn, fmt.Sprintf("unavailable: %v", err), elapsed)
+			default:
+				report.add(name, DoctorWarn, fmt.Sprintf("probe failed: %v", err), elapsed)
+			}
+		}
+	}
+
+	var page []github.Repository
+	elapsed, err = timed(func() error {
+		first := func(repos []github.Repository) error {
+			page = repos
+			return errFirstPage
+		}
+		if user {
+			return c.client.FetchUserRepositories(ctx, c.config.Organization, first)
+		}
+		return c.client.FetchRepositories(ctx, c.config.Organization, first)
+	})
+	switch {
+	case err != nil && !errors.Is(err, errFirstPage):
+		detail := fmt.Sprintf("listing repositories failed: %v", err)
+		if isDenied(err) {
+			detail = "missing metadata:read: " + detail
+		}
+		report.add("repositories", DoctorFail, detail, elapsed)
+	case len(page) == 0:
+		report.add("repositories", DoctorWarn, "no repositories visible to the credentials", elapsed)
+	default:
+		report.add("repositories", DoctorPass, fmt.Sprintf("%d repositories on the first page", len(page)), elapsed)
+	}
+
+	if len(page) == 0 {
+		report.add("security_settings", DoctorWarn, "skipped: no repository to read", 0)
+	} else {
+		repo := page[0]
+		elapsed, err := timed(func() error {
+			_, err := c.client.FetchSecuritySettings(ctx, repo.Owner.Login, repo.Name)
+			return err
+		})
+		switch {
+		case err == nil:
+			report.add("security_settings", DoctorPass, "read for "+repo.Owner.Login+"/"+repo.Name, elapsed)
+		case isDenied(err):
+			report.add("security_settings", DoctorWarn, "missing administration:read; security features will read as disabled", elapsed)
+		default:
+			report.add("security_settings", DoctorFail, fmt.Sprintf("reading security settings failed: %v", err), elapsed)
+		}
+	}
+
+	latency := fmt.Sprintf("slowest request took %dms", slowest.Milliseconds())
+	if slowest > doctorSlowRequest {
+		report.add("latency", DoctorWarn, latency+"; a full collection will be slow", 0)
+	} else {
+		report.add("latency", DoctorPass, latency, 0)
+	}
+
+	c.doctorRateLimit(report)
+	report.finish()
+	return report
+}
+
+// doctorRateLimit reports the GraphQL rate limit left after the doctor's
+// requests, warning below abort_below_remaining (or doctorLowRemaining).
+func (c *Collector) doctorRateLimit(report *DoctorReport) {
+	stats := c.client.Stats()
+	if stats.RateLimitRemaining == nil {
+		report.add("rate_limit", DoctorWarn, "unknown: no GraphQL query returned a rate limit", 0)
+		return
+	}
+	threshold := c.config.AbortBelowRemaining
+	if threshold <= 0 {
+		threshold = doctorLowRemaining
+	}
+	detail := fmt.Sprintf("%d GraphQL points remaining", *stats.RateLimitRemaining)
+	if !stats.RateLimitResetAt.IsZero() {
+		detail += ", resets at " + formatTime(stats.RateLimitResetAt)
+	}
+	if *stats.RateLimitRemaining < threshold {
+		report.add("rate_limit", DoctorWarn, fmt.Sprintf("%s; below %d, a collection may stop early", detail, threshold), 0)
+		return
+	}
+	report.add("rate_limit", DoctorPass, detail, 0)
+}
+
+// add appends a check. A zero elapsed omits its latency.
+func (r *DoctorReport) add(name, status, detail string, elapsed time.Duration) {
+	check := DoctorCheck{Name: name, Status: status, Detail: detail}
+	if elapsed > 0 {
+		ms := elapsed.Milliseconds()
+		check.LatencyMS = &ms
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// finish sets the report's status to the worst of its checks'.
+func (r *DoctorReport) finish() {
+	r.Status = DoctorPass
+	for _, check := range r.Checks {
+		if check.Status == DoctorFail || (check.Status == DoctorWarn && r.Status == DoctorPass) {
+			r.Status = check.Status
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+func TestDoctor(t *testing.T) {
+	remaining := 4200
+	mock := richMock()
+	mock.stats = github.QueryStats{RateLimitRemaining: &remaining}
+	mock.hooksErr = fmt.Errorf("%w: /orgs/test-org/hooks (status 403)", github.ErrPermissionDenied)
+
+	report := NewWithClient(Config{Organization: "test-org"}, mock).Doctor(context.Background())
+	statuses := map[string]string{}
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	want := map[string]string{
+		"authentication":                   DoctorPass,
+		"permission.organization_settings": DoctorPass,
+		"permission.rulesets":              DoctorPass,
+		"permission.members":               DoctorPass,
+		"permission.webhooks":              DoctorWarn,
+		"permission.actions":               DoctorPass,
+		"repositories":                     DoctorPass,
+		"security_settings":                DoctorPass,
+		"latency":                          DoctorPass,
+		"rate_limit":                       DoctorPass,
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("check %s = %q, want %q", name, statuses[name], status)
+		}
+	}
+	if len(report.Checks) != len(want) {
+		t.Errorf("got %d checks, want %d: %+v", len(report.Checks), len(want), report.Checks)
+	}
+	if report.Status != DoctorWarn {
+		t.Errorf("Status = %q, want warn", report.Status)
+	}
+	// One repository's settings are read, not the page's.
+	if len(mock.requestedRepos) != 1 || mock.requestedRepos[0] != "test-org/repo1" {
+		t.Errorf("security settings requested for %v, want [test-org/repo1]", mock.requestedRepos)
+	}
+}
+
+func TestDoctor_Failures(t *testing.T) {
+	// Unreachable or rejected credentials stop the diagnostic.
+	mock := richMock()
+	mock.instanceErr = errors.New("/meta returned status 401")
+	report := NewWithClient(Config{Organization: "test-org"}, mock).Doctor(context.Background())
+	if report.Status != DoctorFail || len(report.Checks) != 1 || report.Checks[0].Name != "authentication" {
+		t.Errorf("rejected credentials: status %q, checks %+v", report.Status, report.Checks)
+	}
+
+	// A failed repository listing fails the report; user accounts skip the
+	// org permission probes.
+	mock = richMock()
+	mock.userRepositoriesErr = errors.New("graphql: timeout")
+	report = NewWithClient(Config{Organization: "octocat", OwnerType: OwnerTypeUser}, mock).Doctor(context.Background())
+	if report.Status != DoctorFail {
+		t.Errorf("Status = %q, want fail", report.Status)
+	}
+	for _, check := range report.Checks {
+		switch check.Name {
+		case "permission.organization_settings", "permission.rulesets", "permission.members", "permission.webhooks", "permission.actions":
+			t.Errorf("user account probed %s", check.Name)
+		case "repositories":
+			if check.Status != DoctorFail {
+				t.Errorf("repositories = %q, want fail", check.Status)
+			}
+		case "security_settings", "rate_limit":
+			if check.Status != DoctorWarn {
+				t.Errorf("%s = %q, want warn", check.Name, check.Status)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package collector
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Security features that make up posture.security_features_coverage, named
+// as in the security_features section.
+const (
+	FeatureVulnerabilityAlerts          = "vulnerability_alerts"
+	FeatureCodeScanning                 = "code_scanning"
+	FeatureSecretScanning               = "secret_scanning"
+	FeatureSecretScanningPushProtection = "secret_scanning_push_protection"
+	FeatureDependabotSecurityUpdates    = "dependabot_security_updates"
+)
+
+// DefaultFeatureWeight is the weight of a feature not listed in
+// Config.FeatureWeights.
+const DefaultFeatureWeight = 1.0
+
+// coverageFeatures lists the features in the coverage score.
+var coverageFeatures = []string{
+	FeatureVulnerabilityAlerts,
+	FeatureCodeScanning,
+	FeatureSecretScanning,
+	FeatureSecretScanningPushProtection,
+	FeatureDependabotSecurityUpdates,
+}
+
+// resolveFeatureWeights returns the weight of every coverage feature:
+// DefaultFeatureWeight overridden by the configured ones. Weights must be
+// non-negative and finite, and at least one must be positive.
+func resolveFeatureWeights(configured map[string]float64) (map[string]float64, error) {
+	weights := make(map[string]float64, len(coverageFeatures))
+	for _, feature := range coverageFeatures {
+		weights[feature] = DefaultFeatureWeight
+	}
+	for _, feature := range slices.Sorted(maps.Keys(configured)) {
+		w := configured[feature]
+		if _, ok := weights[feature]; !ok {
+			return nil, fmt.Errorf("unknown feature_weights entry %q (want one of %s)", feature, strings.Join(coverageFeatures, ", "))
+		}
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, fmt.Errorf("invalid feature_weights entry %q: weight must be a non-negative number", feature)
+		}
+		weights[feature] = w
+	}
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+	if sum == 0 {
+		return nil, fmt.Errorf("feature_weights: at least one weight must be positive")
+	}
+	return weights, nil
+}
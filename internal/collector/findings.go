@@ -65,7 +65,7 @@ func (c *Collector) collectFindings(p *collectionPass) {
 	permissionDenied := false
 	featureOff := false
 
-	for _, repo := range p.metrics.repos.included {
+	for repo := range p.metrics.repos.all() {
 		owner := repo.Owner.Login
 		name := repo.Name
 
@@ -81,9 +81,9 @@ func (c *Collector) collectFindings(p *collectionPass) {
 			}
 		}
 
-		keptS, dropS, truncS := Truncate(secrets, FindingsCap, lessSecretScanningAlert)
-		keptC, dropC, truncC := Truncate(code, FindingsCap, lessCodeScanningAlert)
-		keptD, dropD, truncD := Truncate(deps, FindingsCap, lessDependabotAlert)
+		keptS, dropS, truncS := Truncate(secrets, c.listCap(ListFindings), lessSecretScanningAlert)
+		keptC, dropC, truncC := Truncate(code, c.listCap(ListFindings), lessCodeScanningAlert)
+		keptD, dropD, truncD := Truncate(deps, c.listCap(ListFindings), lessDependabotAlert)
 
 		findings.SecretScanning = append(findings.SecretScanning, keptS...)
 		findings.CodeScanning = append(findings.CodeScanning, keptC...)
@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack/componentsdk"
+)
+
+func hygieneRepo(name, vis, branch string, forking bool) github.Repository {
+	r := github.Repository{Name: name, Visibility: vis, ForkingAllowed: forking}
+	r.Owner.Login = "test-org"
+	r.DefaultBranchRef.Name = branch
+	return r
+}
+
+func TestRepositoryHygiene_ForkingAndBranchNames(t *testing.T) {
+	mock := &mockGitHubClient{
+		orgSecurity: &github.OrgSecurity{},
+		repositories: []github.Repository{
+			hygieneRepo("a", "PRIVATE", "main", true),
+			hygieneRepo("b", "PRIVATE", "master", false),
+			hygieneRepo("c", "INTERNAL", "main", false),
+			hygieneRepo("d", "PUBLIC", "master", true),
+		},
+	}
+
+	c := NewWithClient(Config{Organization: "test-org", FlagLegacyDefaultBranch: true}, mock)
+	posture, err := c.Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+
+	h := posture.RepositoryHygiene
+	// 1 of 3 non-public repos allows forking; the public fork flag is ignored.
+	if h.PrivateForkingAllowed != 33 {
+		t.Errorf("PrivateForkingAllowed = %d, want 33", h.PrivateForkingAllowed)
+	}
+	if h.DefaultBranchNames["main"] != 50 || h.DefaultBranchNames["master"] != 50 {
+		t.Errorf("DefaultBranchNames = %v, want main=50 master=50", h.DefaultBranchNames)
+	}
+	if h.LegacyDefaultBranch == nil || *h.LegacyDefaultBranch != 50 {
+		t.Errorf("LegacyDefaultBranch = %v, want 50", h.LegacyDefaultBranch)
+	}
+}
+
+func TestRepositoryHygiene_LegacyFlagOffByDefault(t *testing.T) {
+	mock := &mockGitHubClient{
+		orgSecurity:  &github.OrgSecurity{},
+		repositories: []github.Repository{hygieneRepo("a", "PRIVATE", "master", false)},
+	}
+
+	c := NewWithClient(Config{Organization: "test-org"}, mock)
+	posture, err := c.Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if posture.RepositoryHygiene.LegacyDefaultBranch != nil {
+		t.Errorf("LegacyDefaultBranch = %v, want nil when not configured", *posture.RepositoryHygiene.LegacyDefaultBranch)
+	}
+}
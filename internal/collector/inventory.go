@@ -0,0 +1,157 @@
+package collector
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+// InventorySchema is the semantic type of the repository inventory artifact,
+// versioned apart from the detailed posture (SchemaVersion) so processors
+// subscribed to one aren't broken by changes to the other.
+const (
+	InventorySchema        = "evidencepack/repository-inventory@v1"
+	InventorySchemaVersion = "1.0.0"
+)
+
+// RepositoryInventory is the per-repository facts of a run, one row per
+// in-scope repository, emitted beside the aggregate posture for processors
+// that track repositories rather than coverage. Truncated is set when rows
+// are missing: enumeration stopped at max_repositories, or the repositories
+// list was capped.
+type RepositoryInventory struct {
+	SchemaVersion    string                `json:"schema_version"`
+	CollectedAt      string                `json:"collected_at"`
+	CollectedAtLevel string                `json:"collected_at_level"`
+	Provider         string                `json:"provider"`
+	Organization     string                `json:"organization"`
+	Truncated        bool                  `json:"truncated,omitempty"`
+	Repositories     []InventoryRepository `json:"repositories"`
+}
+
+// InventoryRepository is one repository's facts. The metadata and branch
+// protection come from the repositories surface, the security features from
+// security_features; either is absent when its surface wasn't collected
+// (missing permission).
+type InventoryRepository struct {
+	Name            string   `json:"name"` // owner/name
+	Visibility      string   `json:"visibility,omitempty"`
+	Archived        bool     `json:"archived"`
+	IsTemplate      bool     `json:"is_template"`
+	DefaultBranch   string   `json:"default_branch,omitempty"`
+	PrimaryLanguage string   `json:"primary_language,omitempty"`
+	Class           string   `json:"class,omitempty"`
+	OwningTeam      string   `json:"owning_team,omitempty"`
+	CISystems       []string `json:"ci_systems,omitempty"`
+	PushedAt        string   `json:"pushed_at,omitempty"`
+
+	// BranchProtected is whether the default branch has effective
+	// protection; RequiredApprovingReviews its approving-review count.
+	BranchProtected          *bool `json:"branch_protected,omitempty"`
+	RequiredApprovingReviews *int  `json:"required_approving_reviews,omitempty"`
+
+	SecurityFeatures *InventorySecurityFeatures `json:"security_features,omitempty"`
+}
+
+// InventorySecurityFeatures is one repository's security feature switches.
+type InventorySecurityFeatures struct {
+	VulnerabilityAlerts          bool `json:"vulnerability_alerts"`
+	CodeScanning                 bool `json:"code_scanning"`
+	SecretScanning               bool `json:"secret_scanning"`
+	SecretScanningPushProtection bool `json:"secret_scanning_push_protection"`
+	DependabotSecurityUpdates    bool `json:"dependabot_security_updates"`
+}
+
+// ToRepositoryInventory extracts the per-repository facts from detailed
+// output into the repository inventory document, sorted by name. It returns
+// nil when the posture has no per-repository detail (trust level, or both
+// surfaces skipped), since an empty inventory would read as an empty
+// organization.
+func (o *OrgPosture) ToRepositoryInventory() *RepositoryInventory {
+	byName := map[string]*InventoryRepository{}
+	row := func(name string) *InventoryRepository {
+		r, ok := byName[name]
+		if !ok {
+			r = &InventoryRepository{Name: name}
+			byName[name] = r
+		}
+		return r
+	}
+	if o.Repositories != nil {
+		for _, repo := range o.Repositories.PerRepo {
+			r := row(repo.Name)
+			r.Visibility = repo.Visibility
+			r.Archived = repo.Archived
+			r.IsTemplate = repo.IsTemplate
+			r.DefaultBranch = repo.DefaultBranch
+			r.PrimaryLanguage = repo.PrimaryLanguage
+			r.Class = repo.Class
+			r.OwningTeam = repo.OwningTeam
+			r.CISystems = repo.CISystems
+			r.PushedAt = repo.PushedAt
+			protected := repo.BranchProtection != nil
+			r.BranchProtected = &protected
+			if protected && repo.BranchProtection.RequiresApprovingReviews {
+				reviews := repo.BranchProtection.RequiredApprovingReviewCount
+				r.RequiredApprovingReviews = &reviews
+			}
+		}
+	}
+	for _, repo := range o.SecurityFeatures.PerRepo {
+		row(repo.Repository).SecurityFeatures = &InventorySecurityFeatures{
+			VulnerabilityAlerts:          repo.VulnerabilityAlerts,
+			CodeScanning:                 repo.CodeScanning,
+			SecretScanning:               repo.SecretScanning,
+			SecretScanningPushProtection: repo.SecretScanningPushProtection,
+			DependabotSecurityUpdates:    repo.DependabotSecurityUpdates,
+		}
+	}
+	if len(byName) == 0 {
+		return nil
+	}
+
+	inventory := &RepositoryInventory{
+		SchemaVersion:    InventorySchemaVersion,
+		CollectedAt:      o.CollectedAt,
+		CollectedAtLevel: o.CollectedAtLevel,
+		Provider:         "github",
+		Organization:     o.Organization,
+		Truncated:        o.inventoryTruncated(),
+		Repositories:     make([]InventoryRepository, 0, len(byName)),
+	}
+	for _, r := range byName {
+		inventory.Repositories = append(inventory.Repositories, *r)
+	}
+	slices.SortFunc(inventory.Repositories, func(a, b InventoryRepository) int { return strings.Compare(a.Name, b.Name) })
+	return inventory
+}
+
+// inventoryTruncated reports whether the inventory is missing rows:
+// enumeration stopped at max_repositories, or the repositories list was
+// capped.
+func (o *OrgPosture) inventoryTruncated() bool {
+	return o.Scope.Truncated || (o.Repositories != nil && o.Repositories.Truncated)
+}
+
+// ProjectedInventory returns the repository inventory of posture after
+// output_fields (see ProjectFields), so a section dropped from the detailed
+// artifact doesn't ship in the inventory either. Truncation is still judged
+// on the whole posture, since the scope section may be dropped.
+func ProjectedInventory(posture *OrgPosture, fields []string) (*RepositoryInventory, error) {
+	projected := posture
+	if len(fields) > 0 {
+		doc, err := ProjectFields(posture, fields)
+		if err != nil {
+			return nil, err
+		}
+		projected = &OrgPosture{}
+		if err := json.Unmarshal(doc.(json.RawMessage), projected); err != nil {
+			return nil, err
+		}
+	}
+	inventory := projected.ToRepositoryInventory()
+	if inventory != nil {
+		inventory.Truncated = posture.inventoryTruncated()
+	}
+	return inventory, nil
+}
@@ -0,0 +1,84 @@
+package collector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/locktivity/epack/componentsdk"
+)
+
+func TestToRepositoryInventory(t *testing.T) {
+	// Trust has no per-repository detail, so no inventory.
+	trust, err := NewWithClient(Config{Organization: "test-org"}, richMock()).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if inv := trust.ToRepositoryInventory(); inv != nil {
+		t.Errorf("trust inventory = %+v, want nil", inv)
+	}
+
+	posture, err := NewWithClient(Config{Organization: "test-org"}, richMock()).Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	inv := posture.ToRepositoryInventory()
+	if inv == nil {
+		t.Fatal("audit inventory is nil")
+	}
+	if inv.SchemaVersion != InventorySchemaVersion || inv.Provider != "github" || inv.Organization != "test-org" || inv.CollectedAtLevel != "audit" {
+		t.Errorf("inventory header = %+v", inv)
+	}
+	if len(inv.Repositories) != 2 || inv.Repositories[0].Name != "test-org/repo1" || inv.Repositories[1].Name != "test-org/repo2" {
+		t.Fatalf("repositories = %+v, want test-org/repo1 and test-org/repo2", inv.Repositories)
+	}
+	repo1 := inv.Repositories[0]
+	if repo1.Visibility != "PRIVATE" || repo1.BranchProtected == nil || repo1.SecurityFeatures == nil {
+		t.Errorf("repo1 = %+v, want visibility, branch protection, and security features", repo1)
+	}
+
+	// A skipped repositories surface leaves the security features only.
+	posture.Repositories = nil
+	inv = posture.ToRepositoryInventory()
+	if len(inv.Repositories) != 2 || inv.Repositories[0].BranchProtected != nil || inv.Repositories[0].SecurityFeatures == nil {
+		t.Errorf("without repositories surface: %+v", inv.Repositories)
+	}
+
+	posture.Scope.Truncated = true
+	if !posture.ToRepositoryInventory().Truncated {
+		t.Error("inventory of a capped run not marked truncated")
+	}
+}
+
+func TestProjectedInventory(t *testing.T) {
+	posture, err := NewWithClient(Config{Organization: "test-org"}, richMock()).Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	posture.Scope.Truncated = true
+
+	// Unprojected, the inventory is the posture's own.
+	inv, err := ProjectedInventory(posture, nil)
+	if err != nil || !reflect.DeepEqual(inv, posture.ToRepositoryInventory()) {
+		t.Errorf("ProjectedInventory(no fields) = %+v, %v", inv, err)
+	}
+
+	// Dropping the security features drops them from the inventory too.
+	inv, err = ProjectedInventory(posture, []string{"-security_features"})
+	if err != nil {
+		t.Fatalf("ProjectedInventory() error: %v", err)
+	}
+	if len(inv.Repositories) != 2 || inv.Repositories[0].SecurityFeatures != nil || inv.Repositories[0].Visibility != "PRIVATE" {
+		t.Errorf("without security_features: %+v", inv.Repositories)
+	}
+
+	// Selecting only the posture scores leaves nothing per repository, even
+	// with scope (and its truncation) dropped.
+	if inv, err := ProjectedInventory(posture, []string{"posture"}); err != nil || inv != nil {
+		t.Errorf("ProjectedInventory(posture only) = %+v, %v; want nil", inv, err)
+	}
+	inv, err = ProjectedInventory(posture, []string{"repositories"})
+	if err != nil || inv == nil || !inv.Truncated || inv.Organization != "test-org" {
+		t.Errorf("ProjectedInventory(repositories only) = %+v, %v; want truncated test-org rows", inv, err)
+	}
+}
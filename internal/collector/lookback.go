@@ -0,0 +1,91 @@
+package collector
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Time-based metrics whose window Config.LookbackDays can set, named as
+// their posture section.
+const (
+	LookbackAuditLog                = "audit_log"
+	LookbackVulnerabilityManagement = "vulnerability_management"
+)
+
+// LookbackDefault is the Config.LookbackDays key that sets the window of
+// every time-based metric without its own entry.
+const LookbackDefault = "default"
+
+// defaultLookbackDays is each time-based metric's built-in window.
+var defaultLookbackDays = map[string]int{
+	LookbackAuditLog:                AuditLogWindowDays,
+	LookbackVulnerabilityManagement: AdvisoryLookbackDays,
+}
+
+// validateLookbackDays rejects unknown keys and negative windows in
+// lookback_days. Zero means "not set".
+func validateLookbackDays(configured map[string]int) error {
+	for _, key := range slices.Sorted(maps.Keys(configured)) {
+		if _, ok := defaultLookbackDays[key]; !ok && key != LookbackDefault {
+			known := append(slices.Sorted(maps.Keys(defaultLookbackDays)), LookbackDefault)
+			return fmt.Errorf("unknown lookback_days entry %q (want one of %s)", key, strings.Join(known, ", "))
+		}
+		if configured[key] < 0 {
+			return fmt.Errorf("invalid lookback_days entry %q: days must not be negative", key)
+		}
+	}
+	return nil
+}
+
+// lookbackDays resolves a metric's window: its own lookback_days entry, then
+// a metric-specific legacy key (advisory_lookback_days), then
+// lookback_days.default, then the built-in window.
+func lookbackDays(config Config, metric string) int {
+	if days := config.LookbackDays[metric]; days > 0 {
+		return days
+	}
+	if metric == LookbackVulnerabilityManagement && config.AdvisoryLookbackDays > 0 {
+		return config.AdvisoryLookbackDays
+	}
+	if days := config.LookbackDays[LookbackDefault]; days > 0 {
+		return days
+	}
+	return defaultLookbackDays[metric]
+}
+
+// timeWindow is the trailing window a time-based metric counts over, ending
+// at collection time.
+type timeWindow struct {
+	days  int
+	start time.Time
+}
+
+// newTimeWindow returns the window of the given days ending at now.
+func newTimeWindow(days int, now time.Time) timeWindow {
+	return timeWindow{days: days, start: now.UTC().AddDate(0, 0, -days)}
+}
+
+// window returns a metric's lookback window ending now.
+func (c *Collector) window(metric string) timeWindow {
+	return newTimeWindow(lookbackDays(c.config, metric), time.Now())
+}
+
+// contains reports whether t falls within the window.
+func (w timeWindow) contains(t time.Time) bool {
+	return !t.Before(w.start)
+}
+
+// containsTimestamp reports whether an RFC 3339 timestamp falls within the
+// window. Unparseable timestamps are outside it.
+func (w timeWindow) containsTimestamp(ts string) bool {
+	t, err := time.Parse(time.RFC3339, ts)
+	return err == nil && w.contains(t)
+}
+
+// startDate is the window's first day (UTC), as search qualifiers take it.
+func (w timeWindow) startDate() string {
+	return w.start.Format(time.DateOnly)
+}
@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/locktivity/epack/componentsdk"
+)
+
+func TestLookbackDays_Precedence(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		metric string
+		want   int
+	}{
+		{"built-in audit log", Config{}, LookbackAuditLog, AuditLogWindowDays},
+		{"built-in advisories", Config{}, LookbackVulnerabilityManagement, AdvisoryLookbackDays},
+		{"default entry", Config{LookbackDays: map[string]int{LookbackDefault: 30}}, LookbackAuditLog, 30},
+		{"legacy key beats default", Config{AdvisoryLookbackDays: 90, LookbackDays: map[string]int{LookbackDefault: 30}}, LookbackVulnerabilityManagement, 90},
+		{"metric entry beats legacy key", Config{AdvisoryLookbackDays: 90, LookbackDays: map[string]int{LookbackVulnerabilityManagement: 180}}, LookbackVulnerabilityManagement, 180},
+		{"zero entry is unset", Config{LookbackDays: map[string]int{LookbackAuditLog: 0, LookbackDefault: 14}}, LookbackAuditLog, 14},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lookbackDays(tt.config, tt.metric); got != tt.want {
+				t.Errorf("lookbackDays(%s) = %d, want %d", tt.metric, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateLookbackDays(t *testing.T) {
+	if err := validateLookbackDays(map[string]int{LookbackDefault: 30, LookbackAuditLog: 14}); err != nil {
+		t.Errorf("valid lookback_days rejected: %v", err)
+	}
+	if err := validateLookbackDays(map[string]int{"bypasses": 30}); err == nil || !strings.Contains(err.Error(), `"bypasses"`) {
+		t.Errorf("unknown key error = %v, want it to name the key", err)
+	}
+	if err := validateLookbackDays(map[string]int{LookbackAuditLog: -1}); err == nil {
+		t.Error("negative window accepted")
+	}
+	if _, err := New(Config{Organization: "org", GitHubToken: "t", LookbackDays: map[string]int{"bogus": 1}}); err == nil {
+		t.Error("New() accepted an unknown lookback_days key")
+	}
+}
+
+func TestTimeWindow(t *testing.T) {
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	w := newTimeWindow(7, now)
+
+	if got := w.startDate(); got != "2024-03-03" {
+		t.Errorf("startDate() = %q, want 2024-03-03", got)
+	}
+	if !w.containsTimestamp("2024-03-05T00:00:00Z") || !w.containsTimestamp("2024-03-03T12:00:00Z") {
+		t.Error("timestamps inside the window should be contained")
+	}
+	if w.containsTimestamp("2024-03-01T00:00:00Z") || w.containsTimestamp("not a time") {
+		t.Error("old and unparseable timestamps should be outside the window")
+	}
+}
+
+func TestCollect_LookbackDays(t *testing.T) {
+	mock := richMock()
+	config := Config{Organization: "test-org", LookbackDays: map[string]int{LookbackDefault: 30}}
+	posture, err := NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if posture.AuditLog == nil || posture.AuditLog.WindowDays != 30 {
+		t.Fatalf("AuditLog = %+v, want window_days 30", posture.AuditLog)
+	}
+	want := time.Now().UTC().AddDate(0, 0, -30).Format(time.DateOnly)
+	if mock.auditSince != want {
+		t.Errorf("audit log queried since %q, want %q", mock.auditSince, want)
+	}
+	if vm := posture.VulnerabilityManagement; vm == nil || vm.LookbackDays != 30 {
+		t.Errorf("VulnerabilityManagement = %+v, want lookback_days 30", vm)
+	}
+}
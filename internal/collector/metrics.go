@@ -2,29 +2,81 @@ package collector
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 
 	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack-collector-github/internal/state"
 )
 
-// metricsAggregator collects repository metrics during iteration.
+// metricsAggregator collects repository metrics during iteration. Its
+// recording methods (processRepository, addSettings, addSkipped, and diag's)
+// may be called from concurrent goroutines; the configuration fields
+// (exclusions, scopes, weights, instance, trackInventory, rulesets) are set
+// before any of them and only read after. The conversions (to*, reposIn,
+// and the rest) read the totals once every recording goroutine has been
+// joined.
 type metricsAggregator struct {
-	// Scope tracking
-	totalRepos    int
-	excludedRepos int
+	// mu guards the counts, inventory, repos, segments, checks, and skipped.
+	// diag has its own lock.
+	mu sync.Mutex
+
+	// Scope tracking. excludedByReason splits excludedRepos by
+	// ExcludeReason; mirrors and templates are excluded per the config.
+	totalRepos       int
+	excludedRepos    int
+	excludedByReason map[string]int
+	excludeMirrors   bool
+	excludeTemplates bool
+
+	// scopes narrows individual metric families; familyRepos counts the
+	// in-scope repositories of each scoped family (see reposIn).
+	scopes      metricScopes
+	familyRepos map[string]int
+
+	// weights are the security feature weights for the coverage score.
+	weights map[string]float64
+
+	// instance is the detected GitHub deployment; collections it doesn't
+	// serve are skipped with a warning.
+	instance github.Instance
+
+	// inventory lists every repository seen, archived and pattern-excluded
+	// ones included, when trackInventory is set (for repository_changes).
+	trackInventory bool
+	inventory      []state.Repository
+
+	// rulesets are the org's rulesets, read before enumeration by
+	// loadRulesets; rulesetsRead is false when they couldn't be (or for user
+	// accounts). Branch protection is counted off their merge with classic
+	// rules (see protection). rulesetsTruncated is set when the org has more
+	// than github.RulesetFetchCap.
+	rulesets          []github.Ruleset
+	rulesetsRead      bool
+	rulesetsTruncated bool
 
 	// repos holds the included repositories and their REST security settings,
 	// captured for the audit/internal surface pass.
 	repos repoCache
 
 	// Branch protection counts
-	branchProtectionEnabled int
-	requirePullRequest      int
-	requireApprovingReviews int
-	dismissStaleReviews     int
-	requireCodeOwnerReviews int
-	requireStatusChecks     int
-	requireSignedCommits    int
-	enforceAdmins           int
+	branchProtectionEnabled       int
+	requirePullRequest            int
+	requireApprovingReviews       int
+	dismissStaleReviews           int
+	requireCodeOwnerReviews       int
+	requireStatusChecks           int
+	requireSignedCommits          int
+	enforceAdmins                 int
+	requireLinearHistory          int
+	allowForcePushes              int
+	allowDeletions                int
+	requireConversationResolution int
+	requireLastPushApproval       int
+
+	// requiredReviewCounts counts repos by required approving-review count
+	// (only repos that require approving reviews are counted).
+	requiredReviewCounts map[int]int
 
 	// Security feature counts
 	vulnerabilityAlertsEnabled       int
@@ -33,41 +85,250 @@ type metricsAggregator struct {
 	secretScanningPushProtection     int
 	dependabotSecurityUpdatesEnabled int
 
+	// Not part of the composite coverage score.
+	secretScanningNonProviderPatterns int
+	secretScanningValidityChecks      int
+
+	// Advanced Security is counted over private and internal repositories.
+	nonPublicRepos          int
+	advancedSecurityEnabled int
+
+	// Private and internal repositories where GitHub reports the product a
+	// scanning feature needs as off, so the feature can't be enabled there.
+	// They are left out of that feature's denominator (see applicableIn).
+	codeScanningUnavailable   int
+	secretScanningUnavailable int
+
+	// segments splits coverage by CI presence, language, class, and tier;
+	// nil unless coverage_breakdown or tiers are configured.
+	segments *coverageSegments
+
+	// Repository hygiene counts
+	privateRepos          int
+	privateForkingAllowed int
+	defaultBranchNames    map[string]int
+
+	// checks is the required-check verification tally (VerifyRequiredChecks).
+	checks checksVerification
+
+	// skipped lists in-scope repos whose per-repo settings couldn't be read.
+	// skippedIn counts those among each family's repositories, and
+	// skippedNonPublic the private and internal ones: their settings are
+	// unknown, so they leave the REST-derived denominators (see applicableIn).
+	// For vulnerability alerts only those GraphQL reported off are counted,
+	// since the REST recheck never ran.
+	skipped          []SkippedRepository
+	skippedIn        map[string]int
+	skippedNonPublic int
+
 	// Permission error tracking
 	securitySettingsPermissionDenied int
 	codeScanningPermissionDenied     int
 	codeScanningErrorMessages        map[string]int // Track unique error messages and their counts
+	vulnerabilityAlertsUnknown       map[string]bool
 
 	// diag accumulates surface-level permission errors and feature-unavailable
 	// warnings recorded during the surface pass.
 	diag diagnostics
 }
 
-// processRepository processes a single repository and updates metrics.
-func (m *metricsAggregator) processRepository(repo github.Repository, includePatterns, excludePatterns []string) {
-	if repo.IsArchived {
-		m.excludedRepos++
-		return
+// processRepository processes a single repository and updates metrics. It
+// reports whether the repository is in scope.
+func (m *metricsAggregator) processRepository(repo github.Repository, matcher *RepoMatcher) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.trackInventory {
+		m.inventory = append(m.inventory, state.Repository{
+			Name:     repo.Owner.Login + "/" + repo.Name,
+			Archived: repo.IsArchived,
+		})
 	}
 
-	if !ShouldIncludeRepo(repo.Name, includePatterns, excludePatterns) {
+	if reason := m.exclusion(repo, matcher); reason != "" {
 		m.excludedRepos++
-		return
+		if m.excludedByReason == nil {
+			m.excludedByReason = make(map[string]int)
+		}
+		m.excludedByReason[reason]++
+		return false
 	}
 
 	m.totalRepos++
+	if isNonPublic(repo) {
+		m.nonPublicRepos++
+	}
+	if m.segments != nil {
+		m.segments.addRepo(repo, m.protection(repo) != nil)
+	}
 	m.repos.add(repo)
+	for family := range m.scopes {
+		if m.scopes.includes(family, repo.Name) {
+			if m.familyRepos == nil {
+				m.familyRepos = make(map[string]int)
+			}
+			m.familyRepos[family]++
+		}
+	}
 
-	m.countBranchProtection(repo)
-
-	if repo.HasVulnerabilityAlertsEnabled {
+	if m.scopes.includes(MetricBranchProtection, repo.Name) {
+		m.countBranchProtection(repo)
+	}
+	if m.scopes.includes(MetricRepositoryHygiene, repo.Name) {
+		m.countHygiene(repo)
+	}
+	if repo.HasVulnerabilityAlertsEnabled && m.scopes.includes(MetricVulnerabilityAlerts, repo.Name) {
 		m.vulnerabilityAlertsEnabled++
 	}
+	return true
+}
+
+// addSettings folds one repository's fetched REST security settings into the
+// counts, the coverage segments, and the surface pass's cache.
+func (m *metricsAggregator) addSettings(r repoSettings) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.countSecuritySettings(r.name, r.nonPublic, r.settings)
+	if m.segments != nil {
+		m.segments.addSettings(r.segment, r.settings)
+	}
+	m.repos.recordSettings(r.owner, r.name, r.settings)
+}
+
+// addSkipped records in-scope repositories whose settings couldn't be read.
+func (m *metricsAggregator) addSkipped(rows ...SkippedRepository) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.skipped = append(m.skipped, rows...)
+}
+
+// skipSettings takes an in-scope repository whose REST settings couldn't be
+// read out of the denominators of the metrics derived from them. The caller
+// holds mu (see fetchedSettings.apply).
+func (m *metricsAggregator) skipSettings(repo github.Repository) {
+	if m.skippedIn == nil {
+		m.skippedIn = make(map[string]int)
+	}
+	for _, family := range []string{MetricCodeScanning, MetricSecretScanning, MetricDependabotSecurityUpdates} {
+		if m.scopes.includes(family, repo.Name) {
+			m.skippedIn[family]++
+		}
+	}
+	if !repo.HasVulnerabilityAlertsEnabled && m.scopes.includes(MetricVulnerabilityAlerts, repo.Name) {
+		m.skippedIn[MetricVulnerabilityAlerts]++
+	}
+	if isNonPublic(repo) {
+		m.skippedNonPublic++
+	}
+}
+
+// inScope returns how many in-scope repositories have been processed so far.
+func (m *metricsAggregator) inScope() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.totalRepos
+}
+
+// reposIn returns the number of in-scope repositories a metric family is
+// evaluated over: all of them unless the family is scoped.
+func (m *metricsAggregator) reposIn(family string) int {
+	if _, ok := m.scopes[family]; !ok {
+		return m.totalRepos
+	}
+	return m.familyRepos[family]
+}
+
+// metricRepositoryCounts returns reposIn for each scoped family, or nil when
+// no scopes are configured.
+func (m *metricsAggregator) metricRepositoryCounts() map[string]int {
+	if len(m.scopes) == 0 {
+		return nil
+	}
+	counts := make(map[string]int, len(m.scopes))
+	for family := range m.scopes {
+		counts[family] = m.reposIn(family)
+	}
+	return counts
+}
+
+// applicableIn returns the repositories a security feature metric is
+// evaluated over: the family's in-scope repositories less those skipped
+// (their settings couldn't be read), less those where a scanning feature is
+// unavailable, and for vulnerability alerts less those whose state couldn't
+// be confirmed. Repositories whose settings GitHub withheld stay in, since
+// availability is unknown there.
+func (m *metricsAggregator) applicableIn(family string) int {
+	repos := m.reposIn(family) - m.skippedIn[family]
+	switch family {
+	case MetricCodeScanning:
+		repos -= m.codeScanningUnavailable
+	case MetricSecretScanning:
+		repos -= m.secretScanningUnavailable
+	case MetricVulnerabilityAlerts:
+		repos -= len(m.vulnerabilityAlertsUnknown)
+	}
+	return repos
+}
+
+// scanningUnavailable reports whether code scanning and secret scanning can't
+// be enabled on a repository: it is private or internal and GitHub reports
+// the product each needs as off. A feature reported on counts as available
+// whatever the product flags say.
+func scanningUnavailable(nonPublic bool, settings *github.SecuritySettings) (codeScanning, secretScanning bool) {
+	if !nonPublic || !settings.AnalysisReported || settings.AdvancedSecurity {
+		return false, false
+	}
+	return !settings.CodeSecurity && !settings.CodeScanningEnabled, !settings.SecretProtection && !settings.SecretScanning
+}
+
+// isNonPublic reports whether a repository is private or internal.
+func isNonPublic(repo github.Repository) bool {
+	switch strings.ToUpper(repo.Visibility) {
+	case "PRIVATE", "INTERNAL":
+		return true
+	}
+	return false
+}
+
+// countHygiene counts the fork policy on non-public repositories and the
+// default-branch name. Repos without a default branch (empty) are not counted
+// in the name distribution.
+func (m *metricsAggregator) countHygiene(repo github.Repository) {
+	if isNonPublic(repo) {
+		m.privateRepos++
+		if repo.ForkingAllowed {
+			m.privateForkingAllowed++
+		}
+	}
+
+	if name := repo.DefaultBranchRef.Name; name != "" {
+		if m.defaultBranchNames == nil {
+			m.defaultBranchNames = make(map[string]int)
+		}
+		m.defaultBranchNames[name]++
+	}
+}
+
+// exclusion returns why a repository is out of scope, or "" when it's in
+// scope. Mirrors can't be remediated in place (their content is overwritten
+// from upstream), so they're excluded unless include_mirrors is set.
+func (m *metricsAggregator) exclusion(repo github.Repository, matcher *RepoMatcher) string {
+	switch {
+	case repo.IsArchived:
+		return ExcludeReasonArchived
+	case repo.IsMirror && m.excludeMirrors:
+		return ExcludeReasonMirror
+	case repo.IsTemplate && m.excludeTemplates:
+		return ExcludeReasonTemplate
+	case !matcher.Match(repo.Name):
+		return ExcludeReasonPattern
+	}
+	return ""
 }
 
-// countBranchProtection counts branch protection features for a repository.
+// countBranchProtection counts the effective branch protection features of a
+// repository's default branch.
 func (m *metricsAggregator) countBranchProtection(repo github.Repository) {
-	bp := repo.DefaultBranchRef.BranchProtectionRule
+	bp := m.protection(repo)
 	if bp == nil {
 		return
 	}
@@ -77,6 +338,10 @@ func (m *metricsAggregator) countBranchProtection(repo github.Repository) {
 	if bp.RequiresApprovingReviews {
 		m.requirePullRequest++
 		m.requireApprovingReviews++
+		if m.requiredReviewCounts == nil {
+			m.requiredReviewCounts = make(map[int]int)
+		}
+		m.requiredReviewCounts[bp.RequiredApprovingReviewCount]++
 	}
 	if bp.DismissesStaleReviews {
 		m.dismissStaleReviews++
@@ -93,31 +358,67 @@ func (m *metricsAggregator) countBranchProtection(repo github.Repository) {
 	if bp.IsAdminEnforced {
 		m.enforceAdmins++
 	}
+	if bp.RequiresLinearHistory {
+		m.requireLinearHistory++
+	}
+	if bp.AllowsForcePushes {
+		m.allowForcePushes++
+	}
+	if bp.AllowsDeletions {
+		m.allowDeletions++
+	}
+	if bp.RequiresConversationResolution {
+		m.requireConversationResolution++
+	}
+	if bp.RequireLastPushApproval {
+		m.requireLastPushApproval++
+	}
 }
 
-// countSecuritySettings updates security feature counts from REST API settings.
-func (m *metricsAggregator) countSecuritySettings(settings *github.SecuritySettings) {
-	if settings.CodeScanningEnabled {
-		m.codeScanningEnabled++
+// countSecuritySettings updates security feature counts from a repository's
+// REST API settings, skipping the families the repository is scoped out of.
+// nonPublic marks private and internal repositories, where Advanced Security
+// is counted and where code and secret scanning need a paid product. The
+// caller holds mu (see addSettings).
+func (m *metricsAggregator) countSecuritySettings(name string, nonPublic bool, settings *github.SecuritySettings) {
+	if nonPublic && settings.AdvancedSecurity {
+		m.advancedSecurityEnabled++
+	}
+	codeScanning, secretScanning := scanningUnavailable(nonPublic, settings)
+	if codeScanning && m.scopes.includes(MetricCodeScanning, name) {
+		m.codeScanningUnavailable++
+	}
+	if secretScanning && m.scopes.includes(MetricSecretScanning, name) {
+		m.secretScanningUnavailable++
 	}
 	if settings.CodeScanningPermissionDenied {
 		m.codeScanningPermissionDenied++
 		m.trackCodeScanningError(settings.CodeScanningErrorMessage)
 	}
-	if settings.SecretScanning {
-		m.secretScanningEnabled++
+	if settings.CodeScanningEnabled && m.scopes.includes(MetricCodeScanning, name) {
+		m.codeScanningEnabled++
 	}
-	if settings.SecretScanningPushProtection {
-		m.secretScanningPushProtection++
+	if m.scopes.includes(MetricSecretScanning, name) {
+		if settings.SecretScanning {
+			m.secretScanningEnabled++
+		}
+		if settings.SecretScanningPushProtection {
+			m.secretScanningPushProtection++
+		}
+		if settings.SecretScanningNonProviderPatterns {
+			m.secretScanningNonProviderPatterns++
+		}
+		if settings.SecretScanningValidityChecks {
+			m.secretScanningValidityChecks++
+		}
 	}
-	if settings.DependabotSecurityUpdates {
+	if settings.DependabotSecurityUpdates && m.scopes.includes(MetricDependabotSecurityUpdates, name) {
 		m.dependabotSecurityUpdatesEnabled++
 	}
-}
-
-// trackSecuritySettingsPermissionDenied increments the permission denied counter.
-func (m *metricsAggregator) trackSecuritySettingsPermissionDenied() {
-	m.securitySettingsPermissionDenied++
+	// Only set when GraphQL reported alerts disabled, so never double counts.
+	if settings.VulnerabilityAlerts != nil && *settings.VulnerabilityAlerts {
+		m.vulnerabilityAlertsEnabled++
+	}
 }
 
 // trackCodeScanningError records a code scanning error message.
@@ -146,39 +447,134 @@ func (m *metricsAggregator) codeScanningErrors() []string {
 	return errors
 }
 
-// securityFeaturesCoverage calculates the average coverage across all security features.
+// securityFeaturesCoverage calculates the weighted coverage across all
+// security features: each feature's enabled and evaluated repository counts
+// are scaled by its weight before pooling. With equal weights and no scopes
+// this is the plain average of the feature percentages. When families are
+// scoped, each feature contributes over its own repositories; scanning
+// features contribute only over the repositories they're available on.
 func (m *metricsAggregator) securityFeaturesCoverage() int {
-	if m.totalRepos == 0 {
+	features := []struct {
+		name               string
+		enabled, evaluated int
+	}{
+		{FeatureVulnerabilityAlerts, m.vulnerabilityAlertsEnabled, m.applicableIn(MetricVulnerabilityAlerts)},
+		{FeatureCodeScanning, m.codeScanningEnabled, m.applicableIn(MetricCodeScanning)},
+		{FeatureSecretScanning, m.secretScanningEnabled, m.applicableIn(MetricSecretScanning)},
+		{FeatureSecretScanningPushProtection, m.secretScanningPushProtection, m.applicableIn(MetricSecretScanning)},
+		{FeatureDependabotSecurityUpdates, m.dependabotSecurityUpdatesEnabled, m.applicableIn(MetricDependabotSecurityUpdates)},
+	}
+	var total, evaluated float64
+	for _, f := range features {
+		w := m.featureWeight(f.name)
+		total += w * float64(f.enabled)
+		evaluated += w * float64(f.evaluated)
+	}
+	if evaluated == 0 {
 		return 0
 	}
-	total := m.vulnerabilityAlertsEnabled + m.codeScanningEnabled +
-		m.secretScanningEnabled + m.secretScanningPushProtection +
-		m.dependabotSecurityUpdatesEnabled
-	return (total * MaxPercentage) / (m.totalRepos * NumSecurityFeatures)
+	return int(total * MaxPercentage / evaluated)
+}
+
+// featureWeight returns a coverage feature's weight (DefaultFeatureWeight
+// when none were resolved).
+func (m *metricsAggregator) featureWeight(feature string) float64 {
+	if w, ok := m.weights[feature]; ok {
+		return w
+	}
+	return DefaultFeatureWeight
+}
+
+// reviewCountAtLeast returns how many repos require at least n approving reviews.
+func (m *metricsAggregator) reviewCountAtLeast(n int) int {
+	total := 0
+	for count, repos := range m.requiredReviewCounts {
+		if count >= n {
+			total += repos
+		}
+	}
+	return total
 }
 
-// toBranchProtectionRules converts counts to percentages.
-func (m *metricsAggregator) toBranchProtectionRules() BranchProtectionRules {
-	return BranchProtectionRules{
-		PullRequestRequired: percent(m.requirePullRequest, m.totalRepos),
-		ApprovingReviews:    percent(m.requireApprovingReviews, m.totalRepos),
-		DismissStaleReviews: percent(m.dismissStaleReviews, m.totalRepos),
-		CodeOwnerReviews:    percent(m.requireCodeOwnerReviews, m.totalRepos),
-		StatusChecks:        percent(m.requireStatusChecks, m.totalRepos),
-		SignedCommits:       percent(m.requireSignedCommits, m.totalRepos),
-		AdminEnforcement:    percent(m.enforceAdmins, m.totalRepos),
+// toBranchProtectionRules converts counts to percentages. minReviews, when
+// positive, adds the min-required-reviews policy check.
+func (m *metricsAggregator) toBranchProtectionRules(minReviews int) BranchProtectionRules {
+	repos := m.reposIn(MetricBranchProtection)
+	rules := BranchProtectionRules{
+		PullRequestRequired: percent(m.requirePullRequest, repos),
+		ApprovingReviews:    percent(m.requireApprovingReviews, repos),
+		DismissStaleReviews: percent(m.dismissStaleReviews, repos),
+		CodeOwnerReviews:    percent(m.requireCodeOwnerReviews, repos),
+		StatusChecks:        percent(m.requireStatusChecks, repos),
+		SignedCommits:       percent(m.requireSignedCommits, repos),
+		AdminEnforcement:    percent(m.enforceAdmins, repos),
+		LinearHistory:       percent(m.requireLinearHistory, repos),
+		ForcePushesAllowed:  percent(m.allowForcePushes, repos),
+		DeletionsAllowed:    percent(m.allowDeletions, repos),
+
+		ConversationResolution: percent(m.requireConversationResolution, repos),
+		LastPushApproval:       percent(m.requireLastPushApproval, repos),
+
+		RequiredReviewCounts: ReviewCountDistribution{
+			One:         percent(m.requiredReviewCounts[1], repos),
+			Two:         percent(m.requiredReviewCounts[2], repos),
+			ThreeOrMore: percent(m.reviewCountAtLeast(3), repos),
+		},
 	}
+	if minReviews > 0 {
+		rules.MinRequiredReviews = &PolicyCheck{
+			Threshold: minReviews,
+			Compliant: percent(m.reviewCountAtLeast(minReviews), repos),
+		}
+	}
+	return rules
 }
 
-// toSecurityFeatures converts counts to percentages.
+// toSecurityFeatures converts counts to percentages, each over the
+// repositories the feature applies to. ApplicableRepos reports those
+// denominators.
 func (m *metricsAggregator) toSecurityFeatures() SecurityFeatures {
+	applicable := map[string]int{
+		"vulnerability_alerts":                  m.applicableIn(MetricVulnerabilityAlerts),
+		"code_scanning":                         m.applicableIn(MetricCodeScanning),
+		"secret_scanning":                       m.applicableIn(MetricSecretScanning),
+		"secret_scanning_push_protection":       m.applicableIn(MetricSecretScanning),
+		"dependabot_security_updates":           m.applicableIn(MetricDependabotSecurityUpdates),
+		"secret_scanning_non_provider_patterns": m.applicableIn(MetricSecretScanning),
+		"secret_scanning_validity_checks":       m.applicableIn(MetricSecretScanning),
+		"advanced_security":                     m.nonPublicRepos - m.skippedNonPublic,
+	}
 	return SecurityFeatures{
-		VulnerabilityAlerts:          percent(m.vulnerabilityAlertsEnabled, m.totalRepos),
-		CodeScanning:                 percent(m.codeScanningEnabled, m.totalRepos),
-		SecretScanning:               percent(m.secretScanningEnabled, m.totalRepos),
-		SecretScanningPushProtection: percent(m.secretScanningPushProtection, m.totalRepos),
-		DependabotSecurityUpdates:    percent(m.dependabotSecurityUpdatesEnabled, m.totalRepos),
+		VulnerabilityAlerts:          percent(m.vulnerabilityAlertsEnabled, applicable["vulnerability_alerts"]),
+		CodeScanning:                 percent(m.codeScanningEnabled, applicable["code_scanning"]),
+		SecretScanning:               percent(m.secretScanningEnabled, applicable["secret_scanning"]),
+		SecretScanningPushProtection: percent(m.secretScanningPushProtection, applicable["secret_scanning_push_protection"]),
+		DependabotSecurityUpdates:    percent(m.dependabotSecurityUpdatesEnabled, applicable["dependabot_security_updates"]),
+
+		SecretScanningNonProviderPatterns: percent(m.secretScanningNonProviderPatterns, applicable["secret_scanning_non_provider_patterns"]),
+		SecretScanningValidityChecks:      percent(m.secretScanningValidityChecks, applicable["secret_scanning_validity_checks"]),
+
+		AdvancedSecurity: percent(m.advancedSecurityEnabled, applicable["advanced_security"]),
+		ApplicableRepos:  applicable,
+	}
+}
+
+// toRepositoryHygiene converts hygiene counts to percentages. The legacy-branch
+// share is only reported when flagLegacy is set.
+func (m *metricsAggregator) toRepositoryHygiene(flagLegacy bool) RepositoryHygiene {
+	names := make(map[string]int, len(m.defaultBranchNames))
+	for name, count := range m.defaultBranchNames {
+		names[name] = percent(count, m.reposIn(MetricRepositoryHygiene))
+	}
+	hygiene := RepositoryHygiene{
+		PrivateForkingAllowed: percent(m.privateForkingAllowed, m.privateRepos),
+		DefaultBranchNames:    names,
+	}
+	if flagLegacy {
+		legacy := percent(m.defaultBranchNames[LegacyDefaultBranch], m.reposIn(MetricRepositoryHygiene))
+		hygiene.LegacyDefaultBranch = &legacy
 	}
+	return hygiene
 }
 
 // toDiagnostics combines the trust-pass permission counters (security settings,
@@ -197,9 +593,16 @@ func (m *metricsAggregator) toDiagnostics() *Diagnostics {
 	for _, e := range m.codeScanningErrors() {
 		out.addPermissionError(e)
 	}
+	if n := len(m.vulnerabilityAlertsUnknown); n > 0 {
+		out.addPermissionError(fmt.Sprintf(
+			"vulnerability alerts unconfirmed on %d/%d repos: GraphQL reported disabled and the REST check failed (grant administration:read); left out of the percentage as unknown",
+			n, m.totalRepos,
+		))
+	}
 
-	out.permissionErrors = append(out.permissionErrors, m.diag.permissionErrors...)
-	out.warnings = append(out.warnings, m.diag.warnings...)
+	permissionErrors, warnings := m.diag.lists()
+	out.permissionErrors = append(out.permissionErrors, permissionErrors...)
+	out.warnings = append(out.warnings, warnings...)
 
 	return out.build()
 }
@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// TestMetricsAggregator_Concurrent records repositories, settings, skips,
+// and diagnostics from many goroutines at once; run with -race (as CI does)
+// it also checks the recording methods don't race.
+func TestMetricsAggregator_Concurrent(t *testing.T) {
+	matcher, err := NewRepoMatcher(nil, nil, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := &metricsAggregator{excludeMirrors: true}
+
+	const workers, perWorker = 8, 50
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Go(func() {
+			for i := range perWorker {
+				repo := github.Repository{Name: fmt.Sprintf("repo-%d-%d", w, i), Visibility: "PRIVATE", IsArchived: i%10 == 0}
+				repo.Owner.Login = "test-org"
+				repo.HasVulnerabilityAlertsEnabled = true
+				repo.DefaultBranchRef.Name = "main"
+				repo.DefaultBranchRef.BranchProtectionRule = &github.BranchProtectionRule{RequiresApprovingReviews: true, RequiredApprovingReviewCount: 1}
+				if !metrics.processRepository(repo, matcher) {
+					continue
+				}
+				if i%5 == 1 {
+					metrics.addSkipped(SkippedRepository{Repository: "test-org/" + repo.Name, Reason: "timeout"})
+					metrics.diag.surfaceUnavailable(repo.Name, "test")
+					continue
+				}
+				metrics.addSettings(repoSettings{owner: "test-org", name: repo.Name, nonPublic: true, settings: &github.SecuritySettings{SecretScanning: true}})
+				_ = metrics.inScope()
+			}
+		})
+	}
+	wg.Wait()
+
+	// Per worker: 5 archived, 45 in scope, 10 of them skipped.
+	if metrics.totalRepos != workers*45 || metrics.excludedRepos != workers*5 {
+		t.Errorf("total %d excluded %d, want %d and %d", metrics.totalRepos, metrics.excludedRepos, workers*45, workers*5)
+	}
+	if len(metrics.skipped) != workers*10 || metrics.secretScanningEnabled != workers*35 {
+		t.Errorf("skipped %d, secret scanning %d, want %d and %d", len(metrics.skipped), metrics.secretScanningEnabled, workers*10, workers*35)
+	}
+	if metrics.branchProtectionEnabled != workers*45 || metrics.requiredReviewCounts[1] != workers*45 || metrics.defaultBranchNames["main"] != workers*45 {
+		t.Errorf("branch protection %d, one review %d, main %d", metrics.branchProtectionEnabled, metrics.requiredReviewCounts[1], metrics.defaultBranchNames["main"])
+	}
+	if _, warnings := metrics.diag.counts(); warnings != workers*10 {
+		t.Errorf("warnings = %d, want %d", warnings, workers*10)
+	}
+	if got := metrics.toSecurityFeatures().SecretScanning; got != 77 {
+		t.Errorf("secret scanning = %d%%, want 77%%", got)
+	}
+}
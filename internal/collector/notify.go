@@ -0,0 +1,288 @@
+package collector
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/state"
+)
+
+// Notification formats accepted by notification_format.
+const (
+	NotificationFormatSlack = "slack"
+	NotificationFormatTeams = "teams"
+)
+
+// notifyTimeout bounds one webhook, incident, or ticket request, and
+// notifyMaxReplyBytes the reply read.
+const (
+	notifyTimeout       = 30 * time.Second
+	notifyMaxReplyBytes = 4 << 20
+)
+
+// Notification message limits, so a broad regression stays a concise alert.
+const (
+	notifyTopRegressions = 5
+	notifyTopViolations  = 10
+)
+
+// validateNotifications checks the notification options. The webhook must be
+// HTTPS, and regression-only notifications need a baseline from state_dir.
+func validateNotifications(config Config) error {
+	switch config.NotificationFormat {
+	case "", NotificationFormatSlack, NotificationFormatTeams:
+	default:
+		return fmt.Errorf("notification_format: must be %s or %s, got %q", NotificationFormatSlack, NotificationFormatTeams, config.NotificationFormat)
+	}
+	if config.NotificationWebhook == "" {
+		return nil
+	}
+	// The URL carries the webhook's credential, so it never appears in
+	// errors.
+	if u, err := url.Parse(config.NotificationWebhook); err != nil || u.Scheme != "https" || u.Host == "" {
+		return errors.New("NOTIFICATION_WEBHOOK_URL: must be an https URL")
+	}
+	if config.NotifyOnlyOnRegression && config.StateDir == "" {
+		return errors.New("notify_only_on_regression requires state_dir")
+	}
+	return nil
+}
+
+// Notify posts a concise posture summary (scores, top regressions, and newly
+// failing repositories) to the configured Slack or Teams webhook, and opens
+// and resolves incidents for alert_thresholds (see updateIncidents).
+// Regressions are measured against the posture the previous run saved in
+// state_dir; this run's posture becomes the next baseline unless collection
+// was partial. A partial run is posted without a comparison and neither opens
+// nor resolves incidents. Without a webhook or incident service it does
+// nothing.
+func (c *Collector) Notify(ctx context.Context, posture *OrgPosture) error {
+	alerting := c.alerting()
+	if c.config.NotificationWebhook == "" && !alerting {
+		return nil
+	}
+	var errs []error
+	var comparison *PostureComparison
+	store, err := c.stateStore()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	baseline, err := swapBaseline(store, c.config.Organization, posture)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if baseline != nil && !partialRun(posture) {
+		if comparison, err = Compare(baseline, posture); err != nil {
+			errs = append(errs, fmt.Errorf("notification baseline: %w", err))
+		}
+	}
+	if c.config.NotificationWebhook != "" && (!c.config.NotifyOnlyOnRegression || (comparison != nil && comparison.Regressed)) {
+		if err := c.postNotification(ctx, notificationPayload(c.config.NotificationFormat, posture, comparison)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if alerting && store != nil && !partialRun(posture) {
+		if err := c.updateIncidents(ctx, store, baseline, posture, comparison); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// stateStore returns the collector's state store, opening it when Collect
+// hasn't, or nil without a state directory.
+func (c *Collector) stateStore() (*state.Store, error) {
+	if c.store != nil || c.config.StateDir == "" {
+		return c.store, nil
+	}
+	return openStore(c.config)
+}
+
+// swapBaseline returns the posture saved by account's previous run and saves
+// posture in its place. It returns nil when there is no store or no earlier
+// posture.
+func swapBaseline(store *state.Store, account string, posture *OrgPosture) (*OrgPosture, error) {
+	if store == nil {
+		return nil, nil
+	}
+
+	// A baseline that can't be read (under the wrong key, or corrupt) is
+	// kept rather than replaced.
+	var baseline *OrgPosture
+	data, err := store.LoadPosture(account)
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		if baseline, err = LoadPosture(data); err != nil {
+			return nil, fmt.Errorf("notification baseline: %w", err)
+		}
+	}
+
+	// A partial run would report unseen repositories as regressions next
+	// time, so the previous baseline stays.
+	if partialRun(posture) {
+		return baseline, nil
+	}
+	current, err := json.Marshal(posture)
+	if err != nil {
+		return baseline, err
+	}
+	return baseline, store.SavePosture(account, current)
+}
+
+// partialRun reports whether collection stopped early or was capped by
+// max_repositories, leaving repositories unseen.
+func partialRun(p *OrgPosture) bool {
+	return p.CollectionStats.Aborted || p.CollectionStats.Cancelled || p.Scope.Truncated
+}
+
+// notificationText renders the summary as Markdown, which both Slack (mrkdwn)
+// and Teams render. comparison may be nil on the first run.
+func notificationText(format string, p *OrgPosture, comparison *PostureComparison) string {
+	bold := "**"
+	if format != NotificationFormatTeams {
+		bold = "*"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%sGitHub posture: %s%s (%s level)\n", bold, p.Organization, bold, p.CollectedAtLevel)
+	fmt.Fprintf(&b, "Branch protection %d%% · Security features %d%%\n",
+		p.Posture.BranchProtectionCoverage, p.Posture.SecurityFeaturesCoverage)
+
+	if comparison == nil {
+		b.WriteString("No earlier run to compare with.\n")
+		return b.String()
+	}
+	var regressions []MetricDelta
+	for _, m := range comparison.Metrics {
+		if m.Regressed {
+			regressions = append(regressions, m)
+		}
+	}
+	if len(regressions) == 0 && len(comparison.NewlyFailing) == 0 {
+		fmt.Fprintf(&b, "No regressions since %s.\n", comparison.BaselineAt)
+		return b.String()
+	}
+	if len(regressions) > 0 {
+		// Largest moves first.
+		slices.SortStableFunc(regressions, func(a, b MetricDelta) int {
+			return cmp.Compare(abs(b.Delta), abs(a.Delta))
+		})
+		fmt.Fprintf(&b, "\n%sRegressions since %s%s\n", bold, comparison.BaselineAt, bold)
+		for _, m := range regressions[:min(len(regressions), notifyTopRegressions)] {
+			fmt.Fprintf(&b, "- %s: %d%% → %d%% (%+d)\n", m.Metric, m.Baseline, m.Current, m.Delta)
+		}
+		if n := len(regressions) - notifyTopRegressions; n > 0 {
+			fmt.Fprintf(&b, "- and %d more\n", n)
+		}
+	}
+	if failing := comparison.NewlyFailing; len(failing) > 0 {
+		fmt.Fprintf(&b, "\n%sNew violations%s\n", bold, bold)
+		for _, r := range failing[:min(len(failing), notifyTopViolations)] {
+			fmt.Fprintf(&b, "- %s: lost %s\n", r.Repository, strings.Join(r.Lost, ", "))
+		}
+		if n := len(failing) - notifyTopViolations; n > 0 {
+			fmt.Fprintf(&b, "- and %d more repositories\n", n)
+		}
+	}
+	return b.String()
+}
+
+// notificationPayload wraps the summary in the webhook's message format: a
+// Slack message, or a Teams connector MessageCard.
+func notificationPayload(format string, p *OrgPosture, comparison *PostureComparison) any {
+	text := notificationText(format, p, comparison)
+	if format == NotificationFormatTeams {
+		return map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  "GitHub posture: " + p.Organization,
+			"text":     text,
+		}
+	}
+	return map[string]string{"text": text}
+}
+
+// postNotification posts payload to the webhook.
+func (c *Collector) postNotification(ctx context.Context, payload any) error {
+	if err := c.sendJSON(ctx, http.MethodPost, c.config.NotificationWebhook, nil, payload); err != nil {
+		return fmt.Errorf("posting notification: %w", err)
+	}
+	return nil
+}
+
+// sendJSON sends payload to endpoint with the extra headers, discarding the
+// reply.
+func (c *Collector) sendJSON(ctx context.Context, method, endpoint string, header http.Header, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		// The parse error would quote the URL.
+		return errors.New("invalid URL")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return c.send(req, header, nil)
+}
+
+// getJSON fetches endpoint with the extra headers and decodes the reply into
+// out.
+func (c *Collector) getJSON(ctx context.Context, endpoint string, header http.Header, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return errors.New("invalid URL")
+	}
+	return c.send(req, header, out)
+}
+
+// send issues a notification, incident, or ticket request, failing on a
+// non-2xx reply, and decodes the reply into out when it is set. Errors omit
+// the request URL, which can carry a credential.
+func (c *Collector) send(req *http.Request, header http.Header, out any) error {
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	client := c.notifier
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()                // LINT-ALLOW: reply from a notification or ticket service, not GitHub data.
+	reply := io.LimitReader(resp.Body, notifyMaxReplyBytes) // LINT-ALLOW: reply from a notification or ticket service, not GitHub data.
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		_, _ = io.Copy(io.Discard, reply)
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, reply)
+		return nil
+	}
+	return json.NewDecoder(reply).Decode(out)
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
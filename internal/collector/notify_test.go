@@ -0,0 +1,100 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNotify(t *testing.T) {
+	var posts []map[string]string
+	status := http.StatusOK
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decoding payload: %v", err)
+		}
+		posts = append(posts, payload)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	config := Config{
+		Organization:           "test-org",
+		StateDir:               t.TempDir(),
+		NotificationWebhook:    srv.URL + "/hooks/secret-token",
+		NotifyOnlyOnRegression: true,
+	}
+	if err := validateNotifications(config); err != nil {
+		t.Fatalf("validateNotifications() error: %v", err)
+	}
+	c := NewWithClient(config, &mockGitHubClient{})
+	c.notifier = srv.Client()
+
+	run := func(bp int, failing []SecurityFeaturesRow) error {
+		p := NewOrgPosture("test-org")
+		p.CollectedAtLevel = "audit"
+		p.Posture.BranchProtectionCoverage = bp
+		p.SecurityFeatures.PerRepo = failing
+		return c.Notify(context.Background(), p)
+	}
+
+	// The first run only saves the baseline: nothing to regress from.
+	if err := run(90, []SecurityFeaturesRow{{Repository: "test-org/api", SecretScanning: true}}); err != nil {
+		t.Fatalf("Notify() error: %v", err)
+	}
+	if len(posts) != 0 {
+		t.Fatalf("first run posted %d notifications, want 0", len(posts))
+	}
+
+	if err := run(70, []SecurityFeaturesRow{{Repository: "test-org/api"}}); err != nil {
+		t.Fatalf("Notify() error: %v", err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("regressed run posted %d notifications, want 1", len(posts))
+	}
+	text := posts[0]["text"]
+	for _, want := range []string{"*GitHub posture: test-org*", "posture.branch_protection_coverage: 90% → 70% (-20)", "test-org/api: lost secret_scanning"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("notification missing %q:\n%s", want, text)
+		}
+	}
+
+	// Unchanged posture: no regression, no post.
+	if err := run(70, []SecurityFeaturesRow{{Repository: "test-org/api"}}); err != nil {
+		t.Fatalf("Notify() error: %v", err)
+	}
+	if len(posts) != 1 {
+		t.Errorf("unchanged run posted, want no notification")
+	}
+
+	// Webhook failures are reported without the URL.
+	status = http.StatusForbidden
+	c.config.NotifyOnlyOnRegression = false
+	c.config.NotificationFormat = NotificationFormatTeams
+	err := run(70, nil)
+	if err == nil || strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("Notify() error = %v, want a failure without the webhook URL", err)
+	}
+	if got := posts[len(posts)-1]; got["@type"] != "MessageCard" || !strings.Contains(got["text"], "**GitHub posture: test-org**") {
+		t.Errorf("teams payload = %v", got)
+	}
+}
+
+func TestValidateNotifications(t *testing.T) {
+	for _, config := range []Config{
+		{NotificationFormat: "discord"},
+		{NotificationWebhook: "http://hooks.example.com/x"},
+		{NotificationWebhook: "https://hooks.example.com/x", NotifyOnlyOnRegression: true},
+	} {
+		err := validateNotifications(config)
+		if err == nil {
+			t.Errorf("validateNotifications(%+v) should fail", config)
+		} else if strings.Contains(err.Error(), "hooks.example.com") {
+			t.Errorf("validateNotifications() error %q reveals the webhook URL", err)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package collector
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// OCSFVersion is the OCSF schema version the compliance findings follow.
+const OCSFVersion = "1.1.0"
+
+// OCSF Compliance Finding identifiers (class 2003 in the Findings category).
+const (
+	ocsfCategoryFindings    = 2
+	ocsfClassCompliance     = 2003
+	ocsfActivityCreate      = 1
+	ocsfStatusNew           = 1
+	ocsfSeverityInformation = 1
+	ocsfSeverityMedium      = 3
+)
+
+// OCSF compliance statuses and their IDs.
+const (
+	ocsfCompliancePass    = "Pass"
+	ocsfComplianceFail    = "Fail"
+	ocsfComplianceUnknown = "Unknown"
+)
+
+var ocsfComplianceStatusIDs = map[string]int{
+	ocsfComplianceUnknown: 0,
+	ocsfCompliancePass:    1,
+	ocsfComplianceFail:    3,
+}
+
+// ocsfRepoStandard names the collector's own per-repository controls as a
+// standard, alongside the CIS benchmark.
+const ocsfRepoStandard = "epack GitHub repository controls"
+
+// OCSFFinding is one OCSF Compliance Finding event: the result of one policy
+// evaluation, a CIS recommendation for the account or one control for one
+// repository. Only the attributes the collector can fill are emitted.
+type OCSFFinding struct {
+	ActivityID  int             `json:"activity_id"`
+	CategoryUID int             `json:"category_uid"`
+	ClassUID    int             `json:"class_uid"`
+	TypeUID     int             `json:"type_uid"`
+	Time        int64           `json:"time"`
+	SeverityID  int             `json:"severity_id"`
+	StatusID    int             `json:"status_id"`
+	Message     string          `json:"message"`
+	Metadata    OCSFMetadata    `json:"metadata"`
+	FindingInfo OCSFFindingInfo `json:"finding_info"`
+	Compliance  OCSFCompliance  `json:"compliance"`
+	Resources   []OCSFResource  `json:"resources"`
+	Unmapped    map[string]any  `json:"unmapped,omitempty"`
+}
+
+// OCSFMetadata identifies the producing product and schema version.
+type OCSFMetadata struct {
+	Version string      `json:"version"`
+	Product OCSFProduct `json:"product"`
+}
+
+// OCSFProduct is the collector as the OCSF event producer.
+type OCSFProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+	Version    string `json:"version,omitempty"`
+}
+
+// OCSFFindingInfo identifies a finding. UID is stable across runs, so
+// repeated runs update rather than duplicate a finding downstream.
+type OCSFFindingInfo struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	CreatedTime int64  `json:"created_time"`
+}
+
+// OCSFCompliance is the evaluated control, its standard, the framework
+// requirements it maps to (see ControlMappings), and the outcome.
+type OCSFCompliance struct {
+	Control      string   `json:"control"`
+	Standards    []string `json:"standards"`
+	Requirements []string `json:"requirements,omitempty"`
+	Status       string   `json:"status"`
+	StatusID     int      `json:"status_id"`
+}
+
+// OCSFResource is the organization, user account, or repository evaluated.
+type OCSFResource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+	Name string `json:"name"`
+}
+
+// ToOCSFFindings transforms the policy evaluation results of a posture into
+// OCSF Compliance Finding events, for SIEMs and security lakes that ingest
+// OCSF: one per CIS recommendation (when cis_benchmark is set), and one per
+// applicable control of each repository with per-repository detail. version is
+// the collector's, reported as the product version.
+func (o *OrgPosture) ToOCSFFindings(version string) []OCSFFinding {
+	at := time.Now()
+	if t, err := time.Parse(time.RFC3339, o.CollectedAt); err == nil {
+		at = t
+	}
+	requirements := ocsfRequirements()
+	newFinding := func(uid, title, status string, resource OCSFResource) OCSFFinding {
+		severity := ocsfSeverityInformation
+		if status == ocsfComplianceFail {
+			severity = ocsfSeverityMedium
+		}
+		return OCSFFinding{
+			ActivityID:  ocsfActivityCreate,
+			CategoryUID: ocsfCategoryFindings,
+			ClassUID:    ocsfClassCompliance,
+			TypeUID:     ocsfClassCompliance*100 + ocsfActivityCreate,
+			Time:        at.UnixMilli(),
+			SeverityID:  severity,
+			StatusID:    ocsfStatusNew,
+			Message:     title + ": " + status,
+			Metadata: OCSFMetadata{
+				Version: OCSFVersion,
+				Product: OCSFProduct{Name: "epack-collector-github", VendorName: "Locktivity", Version: version},
+			},
+			FindingInfo: OCSFFindingInfo{UID: uid, Title: title, CreatedTime: at.UnixMilli()},
+			Compliance:  OCSFCompliance{Status: status, StatusID: ocsfComplianceStatusIDs[status]},
+			Resources:   []OCSFResource{resource},
+		}
+	}
+
+	var findings []OCSFFinding
+	if o.CISBenchmark != nil {
+		account := OCSFResource{Type: "GitHub Organization", UID: o.Organization, Name: o.Organization}
+		if o.OwnerType == OwnerTypeUser {
+			account.Type = "GitHub User Account"
+		}
+		for _, r := range o.CISBenchmark.Recommendations {
+			f := newFinding("github/"+o.Organization+"/cis/"+r.ID, r.Title, ocsfCISStatus(r.Status), account)
+			f.Compliance.Control = r.ID
+			f.Compliance.Standards = []string{o.CISBenchmark.Benchmark}
+			f.Compliance.Requirements = requirements(r.Evidence)
+			unmapped := map[string]any{"evidence": r.Evidence}
+			if r.ReposCompliant != nil && r.ReposEvaluated != nil {
+				unmapped["repos_compliant"] = *r.ReposCompliant
+				unmapped["repos_evaluated"] = *r.ReposEvaluated
+			}
+			if r.Note != "" {
+				unmapped["note"] = r.Note
+			}
+			f.Unmapped = unmapped
+			findings = append(findings, f)
+		}
+	}
+
+	// A control that couldn't be read is reported as unknown rather than
+	// failed; one that doesn't apply to the repository isn't reported.
+	controls := repoControls(o)
+	unreadable := map[string][]string{}
+	for _, r := range o.SecurityFeatures.PerRepo {
+		if len(r.Unknown) > 0 {
+			unreadable[r.Repository] = r.Unknown
+			if controls[r.Repository] == nil {
+				controls[r.Repository] = map[string]bool{}
+			}
+		}
+	}
+	for _, repo := range slices.Sorted(maps.Keys(controls)) {
+		resource := OCSFResource{Type: "GitHub Repository", UID: repo, Name: repo}
+		for _, control := range repoControlNames {
+			on, known := controls[repo][control]
+			status := ocsfComplianceFail
+			switch {
+			case known && on:
+				status = ocsfCompliancePass
+			case !known && slices.Contains(unreadable[repo], control):
+				status = ocsfComplianceUnknown
+			case !known:
+				continue
+			}
+			f := newFinding("github/"+repo+"/"+control, control+" enabled on "+repo, status, resource)
+			f.Compliance.Control = control
+			f.Compliance.Standards = []string{ocsfRepoStandard}
+			f.Compliance.Requirements = requirements(repoControlMetric(control))
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// ocsfCISStatus maps a CIS recommendation status to an OCSF compliance one.
+func ocsfCISStatus(status string) string {
+	switch status {
+	case CISPass:
+		return ocsfCompliancePass
+	case CISFail:
+		return ocsfComplianceFail
+	}
+	return ocsfComplianceUnknown
+}
+
+// repoControlMetric is the aggregate metric a per-repository control feeds,
+// for its control mappings.
+func repoControlMetric(control string) string {
+	if control == "branch_protection" {
+		return "posture.branch_protection_coverage"
+	}
+	return "security_features." + control
+}
+
+// ocsfRequirements returns a lookup of the framework controls mapped to a
+// metric path, as "framework:control" (e.g. "soc2:CC8.1"). A mapping of a
+// section covers every metric beneath it. The embedded table always parses
+// (see TestControlMappings), so a failure maps nothing.
+func ocsfRequirements() func(metric string) []string {
+	table, err := ControlMappings()
+	if err != nil {
+		return func(string) []string { return nil }
+	}
+	return func(metric string) []string {
+		var out []string
+		for _, m := range table.Mappings {
+			if metric != m.Metric && !strings.HasPrefix(metric, m.Metric+".") {
+				continue
+			}
+			for _, framework := range []struct {
+				key      string
+				controls []string
+			}{{"soc2", m.SOC2}, {"iso27001", m.ISO27001}, {"nist_800_53", m.NIST80053}} {
+				for _, control := range framework.controls {
+					out = append(out, framework.key+":"+control)
+				}
+			}
+		}
+		return out
+	}
+}
@@ -0,0 +1,87 @@
+package collector
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestToOCSFFindings(t *testing.T) {
+	compliant, evaluated := 1, 2
+	posture := &OrgPosture{
+		CollectedAt:  "2026-03-02T00:00:00Z",
+		Organization: "test-org",
+		OwnerType:    OwnerTypeOrganization,
+		CISBenchmark: &CISBenchmark{
+			Benchmark: CISBenchmarkVersion,
+			Recommendations: []CISRecommendationResult{
+				{ID: "1.1.3", Title: "Ensure two approvals", Status: CISFail, Evidence: "branch_protection_rules.approving_reviews", ReposCompliant: &compliant, ReposEvaluated: &evaluated},
+				{ID: "1.3.5", Title: "Ensure 2FA", Status: CISUnknown, Evidence: "access_control.two_factor_required"},
+			},
+		},
+		SecurityFeatures: SecurityFeatures{PerRepo: []SecurityFeaturesRow{
+			{Repository: "test-org/repo1", CodeScanning: true},
+			{Repository: "test-org/repo2", Unknown: []string{"vulnerability_alerts"}, NotApplicable: []string{"code_scanning", "secret_scanning", "secret_scanning_push_protection"}},
+		}},
+	}
+
+	findings := posture.ToOCSFFindings("1.2.3")
+	// Two recommendations, the five security features of repo1, and repo2's
+	// unreadable and applicable features (branch protection is unknown
+	// without the repositories inventory).
+	if len(findings) != 9 {
+		t.Fatalf("got %d findings, want 9: %+v", len(findings), findings)
+	}
+	byUID := map[string]OCSFFinding{}
+	for _, f := range findings {
+		if f.ClassUID != 2003 || f.CategoryUID != 2 || f.TypeUID != 200301 || f.Metadata.Version != OCSFVersion {
+			t.Errorf("%s: class %d category %d type %d version %q", f.FindingInfo.UID, f.ClassUID, f.CategoryUID, f.TypeUID, f.Metadata.Version)
+		}
+		if f.Time != 1772409600000 || f.Metadata.Product.Version != "1.2.3" {
+			t.Errorf("%s: time %d, product %+v", f.FindingInfo.UID, f.Time, f.Metadata.Product)
+		}
+		byUID[f.FindingInfo.UID] = f
+	}
+
+	cis := byUID["github/test-org/cis/1.1.3"]
+	if cis.Compliance.Status != "Fail" || cis.Compliance.StatusID != 3 || cis.SeverityID != 3 {
+		t.Errorf("1.1.3 compliance = %+v, severity %d", cis.Compliance, cis.SeverityID)
+	}
+	if cis.Compliance.Control != "1.1.3" || !slices.Equal(cis.Compliance.Standards, []string{CISBenchmarkVersion}) {
+		t.Errorf("1.1.3 control/standards = %+v", cis.Compliance)
+	}
+	if !slices.Contains(cis.Compliance.Requirements, "soc2:CC8.1") {
+		t.Errorf("1.1.3 requirements = %v, want soc2:CC8.1 among them", cis.Compliance.Requirements)
+	}
+	if cis.Unmapped["repos_compliant"] != 1 || cis.Resources[0].Type != "GitHub Organization" {
+		t.Errorf("1.1.3 unmapped %v, resources %+v", cis.Unmapped, cis.Resources)
+	}
+	if unknown := byUID["github/test-org/cis/1.3.5"]; unknown.Compliance.Status != "Unknown" || unknown.SeverityID != 1 {
+		t.Errorf("1.3.5 compliance = %+v, severity %d", unknown.Compliance, unknown.SeverityID)
+	}
+
+	pass := byUID["github/test-org/repo1/code_scanning"]
+	if pass.Compliance.Status != "Pass" || pass.Compliance.StatusID != 1 || pass.SeverityID != 1 {
+		t.Errorf("code_scanning compliance = %+v, severity %d", pass.Compliance, pass.SeverityID)
+	}
+	if pass.Resources[0] != (OCSFResource{Type: "GitHub Repository", UID: "test-org/repo1", Name: "test-org/repo1"}) {
+		t.Errorf("code_scanning resources = %+v", pass.Resources)
+	}
+	if fail := byUID["github/test-org/repo1/secret_scanning"]; fail.Compliance.Status != "Fail" || len(fail.Compliance.Requirements) == 0 {
+		t.Errorf("secret_scanning compliance = %+v", fail.Compliance)
+	}
+
+	if unknown := byUID["github/test-org/repo2/vulnerability_alerts"]; unknown.Compliance.Status != "Unknown" || unknown.Compliance.StatusID != 0 || unknown.SeverityID != 1 {
+		t.Errorf("unreadable vulnerability_alerts compliance = %+v, severity %d", unknown.Compliance, unknown.SeverityID)
+	}
+	if _, ok := byUID["github/test-org/repo2/code_scanning"]; ok {
+		t.Error("code_scanning reported for a repository it doesn't apply to")
+	}
+	if fail := byUID["github/test-org/repo2/dependabot_security_updates"]; fail.Compliance.Status != "Fail" {
+		t.Errorf("repo2 dependabot_security_updates compliance = %+v", fail.Compliance)
+	}
+
+	// Without evaluations there's nothing to emit.
+	if got := (&OrgPosture{Organization: "test-org"}).ToOCSFFindings("dev"); len(got) != 0 {
+		t.Errorf("empty posture: got %d findings", len(got))
+	}
+}
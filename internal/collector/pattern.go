@@ -1,22 +1,57 @@
 package collector
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 )
 
-// MatchesPattern checks if a name matches a glob pattern.
-// Supports * (any characters) and ? (single character) wildcards.
-func MatchesPattern(name, pattern string) bool {
-	if pattern == "*" {
+// Pattern syntax prefixes. A pattern without a prefix is a glob.
+const (
+	PatternPrefixGlob  = "glob:"
+	PatternPrefixRegex = "re:"
+)
+
+// MatchesPattern checks if a name matches a pattern. Patterns are globs
+// supporting * (any characters) and ? (single character) wildcards, optionally
+// prefixed with "glob:"; a "re:" prefix selects a Go regular expression, which
+// matches anywhere in the name unless anchored with ^ and $. When
+// caseInsensitive is set, letter case is ignored for both syntaxes. An invalid
+// regular expression matches nothing; NewRepoMatcher rejects it instead.
+func MatchesPattern(name, pattern string, caseInsensitive bool) bool {
+	if pattern == "*" || pattern == PatternPrefixGlob+"*" {
 		return true
 	}
 
-	// Convert glob pattern to regex
+	re, err := compilePattern(pattern, caseInsensitive)
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(name)
+}
+
+// compilePattern converts a pattern (see MatchesPattern) to a regular
+// expression.
+func compilePattern(pattern string, caseInsensitive bool) (*regexp.Regexp, error) {
+	var expr string
+	if rest, ok := strings.CutPrefix(pattern, PatternPrefixRegex); ok {
+		expr = rest
+	} else {
+		expr = globToRegex(strings.TrimPrefix(pattern, PatternPrefixGlob))
+	}
+	if caseInsensitive {
+		expr = "(?i)" + expr
+	}
+	return regexp.Compile(expr)
+}
+
+// globToRegex converts a glob to an anchored regular expression.
+func globToRegex(glob string) string {
 	var regexPattern strings.Builder
 	regexPattern.WriteString("^")
 
-	for _, char := range pattern {
+	for _, char := range glob {
 		switch char {
 		case '*':
 			regexPattern.WriteString(".*")
@@ -32,28 +67,22 @@ func MatchesPattern(name, pattern string) bool {
 	}
 
 	regexPattern.WriteString("$")
-
-	re, err := regexp.Compile(regexPattern.String())
-	if err != nil {
-		return false
-	}
-
-	return re.MatchString(name)
+	return regexPattern.String()
 }
 
 // ShouldIncludeRepo determines if a repository should be included based on
 // include and exclude patterns. Exclude patterns take precedence.
-func ShouldIncludeRepo(repoName string, includePatterns, excludePatterns []string) bool {
+func ShouldIncludeRepo(repoName string, includePatterns, excludePatterns []string, caseInsensitive bool) bool {
 	// Check if excluded first (exclusions take precedence)
 	for _, pattern := range excludePatterns {
-		if MatchesPattern(repoName, pattern) {
+		if MatchesPattern(repoName, pattern, caseInsensitive) {
 			return false
 		}
 	}
 
 	// Check if included
 	for _, pattern := range includePatterns {
-		if MatchesPattern(repoName, pattern) {
+		if MatchesPattern(repoName, pattern, caseInsensitive) {
 			return true
 		}
 	}
@@ -61,3 +90,57 @@ func ShouldIncludeRepo(repoName string, includePatterns, excludePatterns []strin
 	// If no include patterns matched, don't include
 	return false
 }
+
+// RepoMatcher applies precompiled include and exclude patterns, so a run
+// compiles each pattern once rather than once per repository.
+type RepoMatcher struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// NewRepoMatcher compiles include and exclude patterns (see MatchesPattern).
+// Empty include patterns default to DefaultIncludePattern. It fails on the
+// first invalid pattern, naming the config key it came from.
+func NewRepoMatcher(includePatterns, excludePatterns []string, caseInsensitive bool) (*RepoMatcher, error) {
+	if len(includePatterns) == 0 {
+		includePatterns = []string{DefaultIncludePattern}
+	}
+	include, err := compilePatterns("include_patterns", includePatterns, caseInsensitive)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compilePatterns("exclude_patterns", excludePatterns, caseInsensitive)
+	if err != nil {
+		return nil, err
+	}
+	return &RepoMatcher{include: include, exclude: exclude}, nil
+}
+
+// compilePatterns compiles each pattern of one config key.
+func compilePatterns(key string, patterns []string, caseInsensitive bool) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := compilePattern(pattern, caseInsensitive)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %w", key, pattern, err)
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// Match reports whether a repository name is in scope. Exclude patterns take
+// precedence.
+func (m *RepoMatcher) Match(name string) bool {
+	for _, re := range m.exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	for _, re := range m.include {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
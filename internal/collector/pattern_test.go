@@ -1,6 +1,9 @@
 package collector
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestMatchesPattern(t *testing.T) {
 	tests := []struct {
@@ -21,11 +24,19 @@ func TestMatchesPattern(t *testing.T) {
 		{"single char wildcard no match", "repo12", "repo?", false},
 		{"special chars escaped", "repo.name", "repo.name", true},
 		{"special chars escaped no match", "repoXname", "repo.name", false},
+		{"glob prefix", "my-repo", "glob:my-*", true},
+		{"glob prefix no match", "other-repo", "glob:my-*", false},
+		{"glob is case-sensitive", "My-Repo", "my-*", false},
+		{"regex prefix", "svc-payments-api", `re:^svc-[a-z]+-(api|web)$`, true},
+		{"regex prefix no match", "svc-payments-worker", `re:^svc-[a-z]+-(api|web)$`, false},
+		{"regex unanchored", "legacy-svc-api", "re:svc-", true},
+		{"regex not escaped as glob", "repo-1", `re:repo-\d`, true},
+		{"invalid regex matches nothing", "repo", "re:(", false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := MatchesPattern(tt.repoName, tt.pattern)
+			got := MatchesPattern(tt.repoName, tt.pattern, false)
 			if got != tt.want {
 				t.Errorf("MatchesPattern(%q, %q) = %v, want %v", tt.repoName, tt.pattern, got, tt.want)
 			}
@@ -33,12 +44,36 @@ func TestMatchesPattern(t *testing.T) {
 	}
 }
 
+func TestMatchesPattern_CaseInsensitive(t *testing.T) {
+	tests := []struct {
+		name     string
+		repoName string
+		pattern  string
+		want     bool
+	}{
+		{"glob", "My-Repo", "my-*", true},
+		{"glob prefix", "FRONTEND-app", "glob:frontend-*", true},
+		{"regex", "SVC-Payments", "re:^svc-payments$", true},
+		{"regex no match", "SVC-Billing", "re:^svc-payments$", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MatchesPattern(tt.repoName, tt.pattern, true)
+			if got != tt.want {
+				t.Errorf("MatchesPattern(%q, %q, true) = %v, want %v", tt.repoName, tt.pattern, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestShouldIncludeRepo(t *testing.T) {
 	tests := []struct {
 		name            string
 		repoName        string
 		includePatterns []string
 		excludePatterns []string
+		caseInsensitive bool
 		want            bool
 	}{
 		{
@@ -76,11 +111,26 @@ func TestShouldIncludeRepo(t *testing.T) {
 			excludePatterns: []string{"*-archive", "test-*"},
 			want:            false,
 		},
+		{
+			name:            "regex exclude",
+			repoName:        "sandbox-42",
+			includePatterns: []string{"*"},
+			excludePatterns: []string{`re:^sandbox-\d+$`},
+			want:            false,
+		},
+		{
+			name:            "case-insensitive exclude",
+			repoName:        "Repo-ARCHIVE",
+			includePatterns: []string{"*"},
+			excludePatterns: []string{"*-archive"},
+			caseInsensitive: true,
+			want:            false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := ShouldIncludeRepo(tt.repoName, tt.includePatterns, tt.excludePatterns)
+			got := ShouldIncludeRepo(tt.repoName, tt.includePatterns, tt.excludePatterns, tt.caseInsensitive)
 			if got != tt.want {
 				t.Errorf("ShouldIncludeRepo(%q, %v, %v) = %v, want %v",
 					tt.repoName, tt.includePatterns, tt.excludePatterns, got, tt.want)
@@ -88,3 +138,32 @@ func TestShouldIncludeRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRepoMatcher(t *testing.T) {
+	m, err := NewRepoMatcher(nil, []string{`re:^sandbox-\d+$`}, true)
+	if err != nil {
+		t.Fatalf("NewRepoMatcher() returned error: %v", err)
+	}
+	for name, want := range map[string]bool{
+		"api":        true, // empty include defaults to "*"
+		"Sandbox-7":  false,
+		"sandbox-x7": true,
+	} {
+		if got := m.Match(name); got != want {
+			t.Errorf("Match(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestNewRepoMatcher_InvalidPattern(t *testing.T) {
+	_, err := NewRepoMatcher([]string{"*"}, []string{"re:(unclosed"}, false)
+	if err == nil {
+		t.Fatal("NewRepoMatcher() should reject an invalid regular expression")
+	}
+	if !strings.Contains(err.Error(), `exclude_patterns entry "re:(unclosed"`) {
+		t.Errorf("error = %q, want it to name the key and pattern", err)
+	}
+	if _, err := New(Config{Organization: "test-org", GitHubToken: "t", IncludePatterns: []string{"re:["}}); err == nil {
+		t.Error("New() should reject an invalid include pattern")
+	}
+}
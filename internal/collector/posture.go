@@ -1,7 +1,12 @@
 // Package collector provides GitHub organization posture collection functionality.
 package collector
 
-import "time"
+import (
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"golang.org/x/time/rate"
+)
 
 // SchemaVersion is the version of the output schema.
 const SchemaVersion = "1.0.0"
@@ -13,14 +18,263 @@ type StatusFunc func(message string)
 type ProgressFunc func(current, total int64, message string)
 
 // Config holds the collector configuration passed via stdin.
+//
+// The describe, default, and enables struct tags feed DescribeConfig (the
+// --describe-config output); secret marks a field supplied as an epack secret
+// rather than a config key. Keep them in sync when adding an option.
+//
+// Profile is informational here: its settings are merged into the raw config
+// by ApplyProfile before the Config is built.
 type Config struct {
-	Organization    string   `json:"organization"`
-	GitHubToken     string   `json:"github_token"`    // Classic PAT (legacy)
-	AppID           int64    `json:"app_id"`          // GitHub App ID (recommended)
-	InstallationID  int64    `json:"installation_id"` // GitHub App installation ID
-	PrivateKey      string   `json:"private_key"`     // GitHub App private key (PEM)
-	IncludePatterns []string `json:"include_patterns"`
-	ExcludePatterns []string `json:"exclude_patterns"`
+	Organization            string   `json:"organization" required:"true" describe:"GitHub organization name (the user login when owner_type is user)"`
+	OwnerType               string   `json:"owner_type" default:"organization" describe:"Account type: organization or user"`
+	GitHubAPIURL            string   `json:"github_api_url" default:"https://api.github.com" describe:"REST API root of the GitHub instance: https://api.github.com, or https://HOST/api/v3 for GitHub Enterprise Server"`
+	Profile                 string   `json:"profile" enables:"scope.profile" describe:"Built-in collection profile presetting options for a framework: soc2, iso27001, nist-ssdf, or cis-github"`
+	GitHubToken             string   `json:"github_token" secret:"GITHUB_TOKEN" describe:"GitHub API token (installation token or classic PAT)"`
+	AppID                   int64    `json:"app_id" describe:"GitHub App ID (recommended auth)"`
+	InstallationID          int64    `json:"installation_id" describe:"GitHub App installation ID"`
+	PrivateKey              string   `json:"private_key" secret:"GITHUB_APP_PRIVATE_KEY" describe:"GitHub App private key (PEM)"`
+	TokenCommand            []string `json:"token_command" describe:"Command (program and arguments, run without a shell) printing a GitHub token, re-run before it expires"`
+	IncludePatterns         []string `json:"include_patterns" default:"[\"*\"]" enables:"scope" describe:"Patterns for repositories to include (glob, or re: for a regular expression)"`
+	ExcludePatterns         []string `json:"exclude_patterns" default:"[]" enables:"scope" describe:"Patterns for repositories to exclude (glob, or re: for a regular expression)"`
+	CaseInsensitivePatterns bool     `json:"case_insensitive_patterns" default:"false" enables:"scope" describe:"Match include/exclude patterns ignoring letter case"`
+
+	// Mirrors are excluded from scope unless IncludeMirrors is set; templates
+	// are included unless ExcludeTemplates is set.
+	IncludeMirrors   bool `json:"include_mirrors" default:"false" describe:"Include mirror repositories, which are excluded by default since they can't be remediated in place"`
+	ExcludeTemplates bool `json:"exclude_templates" default:"false" describe:"Exclude template repositories from scope"`
+
+	// SecretStore, when set, names the secret manager GitHubTokenRef and
+	// PrivateKeyRef are read from (see LoadStoredSecrets), in place of the
+	// GITHUB_TOKEN and GITHUB_APP_PRIVATE_KEY secrets.
+	SecretStore    string `json:"secret_store" describe:"Secret manager holding the GitHub credentials: vault, aws, or gcp"`
+	GitHubTokenRef string `json:"github_token_ref" describe:"GitHub token in secret_store: a Vault path, Secrets Manager name or ARN, or Secret Manager resource name, with #field selecting a JSON field"`
+	PrivateKeyRef  string `json:"private_key_ref" describe:"GitHub App private key in secret_store, referenced like github_token_ref"`
+	VaultAddress   string `json:"vault_address" describe:"Vault server URL (default VAULT_ADDR)"`
+	VaultToken     string `json:"vault_token" secret:"VAULT_TOKEN" describe:"Vault token reading the secret_store references"`
+
+	// VaultAuthMethod, when not token, logs in to Vault as VaultRole for a
+	// short-lived token, in place of VAULT_TOKEN.
+	VaultAuthMethod string `json:"vault_auth_method" default:"token" describe:"Vault auth method: token, kubernetes, approle, or jwt"`
+	VaultAuthMount  string `json:"vault_auth_mount" describe:"Mount path of the Vault auth method (default the method name)"`
+	VaultRole       string `json:"vault_role" describe:"Vault role to log in as: the Kubernetes or JWT role, or the AppRole role ID"`
+	VaultJWTPath    string `json:"vault_jwt_path" describe:"File holding the JWT for the kubernetes or jwt auth method (default the pod's service account token for kubernetes)"`
+	VaultSecretID   string `json:"vault_secret_id" secret:"VAULT_SECRET_ID" describe:"AppRole secret ID for the approle auth method"`
+
+	// Scopes narrows individual metric families (see metricFamilies) to a
+	// subset of the in-scope repositories, e.g. excluding docs repos from
+	// branch protection while still checking them for secret scanning.
+	Scopes map[string]MetricScope `json:"scopes" enables:"scope.metric_repository_counts" describe:"Per-metric-family include/exclude patterns, keyed by branch_protection, vulnerability_alerts, code_scanning, secret_scanning, dependabot_security_updates, or repository_hygiene"`
+
+	// CoverageBreakdown splits the coverage percentages by CI presence,
+	// language, and repository class into coverage_breakdown. Tiers implies
+	// it.
+	CoverageBreakdown bool `json:"coverage_breakdown" default:"false" enables:"coverage_breakdown" describe:"Split coverage percentages by CI presence, primary language, and repository class"`
+
+	// Tiers assigns repositories to the critical, high, and normal tiers
+	// (see TierRule), reported with per-tier thresholds in
+	// coverage_breakdown.by_tier.
+	Tiers map[string]TierRule `json:"tiers" enables:"coverage_breakdown.by_tier" describe:"Repository tiers keyed by critical, high, or normal: include/exclude patterns or custom property values assigning repositories, and minimum coverage percentages per metric"`
+
+	// FeatureWeights overrides the weight of individual security features in
+	// posture.security_features_coverage (see coverageFeatures).
+	FeatureWeights map[string]float64 `json:"feature_weights" describe:"Per-feature weights for posture.security_features_coverage, keyed by vulnerability_alerts, code_scanning, secret_scanning, secret_scanning_push_protection, or dependabot_security_updates (default 1 each)"`
+
+	// CISBenchmark evaluates the automatable CIS GitHub Benchmark
+	// recommendations against the collected data.
+	CISBenchmark bool `json:"cis_benchmark" default:"false" enables:"cis_benchmark" describe:"Evaluate the automatable CIS GitHub Benchmark recommendations"`
+
+	// StateDir is where the snapshot compared by the next run is kept. Setting
+	// it enables repository_changes.
+	StateDir string `json:"state_dir" enables:"repository_changes" describe:"Directory for the snapshot kept between runs"`
+
+	// StateEncryptionKey, base64 of a 32-byte key, encrypts every file in
+	// StateDir. Files under StateEncryptionKeyPrevious are still read and
+	// re-encrypted with the current key, for rotation.
+	StateEncryptionKey         string `json:"state_encryption_key" secret:"STATE_ENCRYPTION_KEY" describe:"Base64 AES-256 key encrypting the files in state_dir"`
+	StateEncryptionKeyPrevious string `json:"state_encryption_key_previous" secret:"STATE_ENCRYPTION_KEY_PREVIOUS" describe:"Previous state_dir encryption key, still read during a key rotation"`
+
+	// Repositories narrows collection to an explicit list of "owner/name" (or
+	// bare "name", owned by Organization) entries, skipping org-wide
+	// enumeration. Repositories outside the org are allowed; include/exclude
+	// patterns still apply to the listed names.
+	Repositories []string `json:"repositories" enables:"scope.explicit_repositories" describe:"Collect only these repositories instead of enumerating the org"`
+
+	// RedactRepoNames replaces repository names in every per-repo field with
+	// stable pseudonyms (see redactRepoNames); aggregates are unchanged.
+	RedactRepoNames bool `json:"redact_repo_names" default:"false" describe:"Replace repository names in per-repo output with stable hashes"`
+
+	// FlagLegacyDefaultBranch reports the share of in-scope repositories whose
+	// default branch is still named "master".
+	FlagLegacyDefaultBranch bool `json:"flag_legacy_default_branch" default:"false" enables:"repository_hygiene.legacy_default_branch" describe:"Report the share of repositories whose default branch is master"`
+
+	// MinRequiredReviews, when positive, evaluates the share of in-scope
+	// repositories requiring at least this many approving reviews.
+	MinRequiredReviews int `json:"min_required_reviews" default:"0" enables:"branch_protection_rules.min_required_reviews" describe:"Minimum approving reviews to evaluate (0 = off)"`
+
+	// VerifyRequiredChecks checks that each repository's required status
+	// checks actually reported on the latest default-branch commit.
+	VerifyRequiredChecks bool `json:"verify_required_checks" default:"false" enables:"branch_protection_rules.checks_verified" describe:"Verify required status checks ran on the latest default-branch commit"`
+
+	// MaxInMemoryRepos bounds how many repositories the audit/internal
+	// surfaces keep in memory before spilling to a temporary file
+	// (0 = RepoCacheMemoryLimit). The file is unencrypted, even with
+	// state_encryption_key, and lives in the OS temporary directory.
+	MaxInMemoryRepos int `json:"max_in_memory_repos" default:"5000" describe:"Repositories kept in memory before spilling to a temporary file; the spill is plaintext JSON (names and settings, mode 0600) in the OS temporary directory until the run ends"`
+
+	// LookbackDays sets the window of time-based metrics, keyed by metric
+	// (see defaultLookbackDays) or "default" for all of them.
+	LookbackDays map[string]int `json:"lookback_days" describe:"Lookback windows in days, keyed by audit_log, vulnerability_management, or default for every time-based metric without its own entry"`
+
+	// AdvisoryLookbackDays is the window for the vulnerability_management
+	// advisory counts (0 = AdvisoryLookbackDays). lookback_days
+	// .vulnerability_management takes precedence.
+	AdvisoryLookbackDays int `json:"advisory_lookback_days" default:"365" enables:"vulnerability_management" describe:"Window for repository security advisory counts"`
+
+	// CollectTriage enables the audit-level triage surface (security labels
+	// and stale security issues). TriageStaleDays is its age threshold
+	// (0 = TriageStaleDays).
+	CollectTriage   bool `json:"collect_triage" default:"false" enables:"triage" describe:"Collect security labels and stale security issues"`
+	TriageStaleDays int  `json:"triage_stale_days" default:"30" enables:"triage" describe:"Age after which an open security issue is stale"`
+
+	// CollectReleases enables the audit-level release checks
+	// (supply_chain.attestations and supply_chain.releases), which list each
+	// in-scope repository's recent releases and look up attestations per
+	// asset. MaxReleaseRepos bounds how many repositories are checked
+	// (0 = ReleaseRepoCap).
+	CollectReleases bool `json:"collect_releases" default:"false" enables:"supply_chain.releases" describe:"Check recent releases for attestations, signature and checksum assets, and immutability"`
+	MaxReleaseRepos int  `json:"max_release_repos" default:"200" enables:"supply_chain.releases" describe:"Most in-scope repositories whose releases are checked"`
+
+	// SecretRotationDays is the age after which a repository Actions secret
+	// that hasn't been updated counts as stale (0 = SecretRotationDays).
+	SecretRotationDays int `json:"secret_rotation_days" default:"90" enables:"secrets_management.repository_secrets" describe:"Age after which a repository Actions secret is stale"`
+
+	// SecretHotspotCount is how many repositories the audit-level secret
+	// scanning hot-spot list keeps (0 = SecretHotspotCount).
+	SecretHotspotCount int `json:"secret_hotspot_count" default:"10" enables:"security_features.secret_scanning_hotspots" describe:"Number of repositories listed by open secret scanning alerts"`
+
+	// MaxRepositories stops enumeration once this many in-scope repositories
+	// were found (0 = no limit), bounding a run pointed at a far larger
+	// account than intended. ListCaps overrides, by list name, the most rows
+	// the per-repository, per-member, findings, and audit-log lists emit.
+	MaxRepositories int            `json:"max_repositories" default:"0" enables:"scope.truncated" describe:"Stop after this many in-scope repositories, emitting partial output (0 = no limit)"`
+	ListCaps        map[string]int `json:"list_caps" describe:"Most rows emitted per list, by list name (repositories, members, findings, audit_log); truncated lists are marked truncated"`
+
+	// MaxRateLimitWaitSeconds is how long a request waits for an exhausted
+	// primary rate limit to reset; a later reset fails the run with
+	// ErrRateLimited (0 = fail at once).
+	MaxRateLimitWaitSeconds int `json:"max_rate_limit_wait_seconds" default:"0" describe:"Seconds to wait for an exhausted primary rate limit to reset before failing the run as retryable (0 = fail at once)"`
+
+	// AbortBelowRemaining stops collection gracefully, emitting partial
+	// output, once the GraphQL rate limit remaining drops below it (0 = off).
+	AbortBelowRemaining int `json:"abort_below_remaining" default:"0" enables:"collection_stats.aborted" describe:"Stop collection when the GraphQL rate limit remaining drops below this (0 = never)"`
+
+	// MinIntervalMinutes refuses a run (ErrTooSoon) starting sooner than
+	// this after the previous run's start, recorded in StateDir (0 = off).
+	MinIntervalMinutes int `json:"min_interval_minutes" default:"0" describe:"Refuse to run within this many minutes of the previous run's start (needs state_dir; 0 = no limit)"`
+
+	// StrictMode fails the run, instead of degrading, when missing
+	// permissions leave metrics unknown or incomplete (see ErrDegraded).
+	StrictMode bool `json:"strict_mode" default:"false" describe:"Fail collection instead of emitting data degraded by missing permissions"`
+
+	// Outbound proxy and TLS settings for GitHub API calls (see
+	// github.TransportConfig). Proxies default to the environment.
+	HTTPProxy          string `json:"http_proxy" default:"$HTTP_PROXY" describe:"Proxy URL for plain-HTTP requests"`
+	HTTPSProxy         string `json:"https_proxy" default:"$HTTPS_PROXY" describe:"Proxy URL for HTTPS requests"`
+	NoProxy            string `json:"no_proxy" default:"$NO_PROXY" describe:"Hosts, domains, or CIDRs that bypass the proxy"`
+	CABundlePath       string `json:"ca_bundle_path" describe:"PEM file of additional trusted root CAs"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify" default:"false" describe:"Disable TLS certificate verification (troubleshooting only)"`
+
+	// MaxRequestsPerSecond throttles all GitHub calls client-side so a shared
+	// token leaves headroom for other integrations (0 = unlimited).
+	MaxRequestsPerSecond float64 `json:"max_requests_per_second" default:"0" describe:"Client-side cap on GitHub API requests per second (0 = unlimited)"`
+	// RequestLimiter, when set, additionally throttles every GitHub call, so
+	// several collectors can share one budget (see pkg/batch).
+	RequestLimiter *rate.Limiter `json:"-"`
+	// SharedCache, when set, shares App installation tokens, token command
+	// output, and instance detection with the other collectors using it
+	// (see pkg/batch).
+	SharedCache *github.Cache `json:"-"`
+
+	// Connection tuning (see github.TransportConfig): keep-alive pool size
+	// per host, and an HTTP/1.1 fallback for proxies that break HTTP/2.
+	MaxIdleConnsPerHost int  `json:"max_idle_conns_per_host" default:"16" describe:"Idle keep-alive connections kept for reuse per host"`
+	DisableHTTP2        bool `json:"disable_http2" default:"false" describe:"Use HTTP/1.1 instead of HTTP/2 for GitHub API calls"`
+
+	// DebugCapturePath appends the sanitized metadata of every GitHub
+	// request to an NDJSON file (see github.TransportConfig), for support
+	// escalations about unexpected API behavior.
+	DebugCapturePath string `json:"debug_capture_path" describe:"NDJSON file each GitHub request's method, sanitized URL, status, rate-limit headers, and timing is appended to (never bodies or credentials; not with redact_repo_names)"`
+
+	// SigningKey, when set, signs the emitted artifacts and adds an
+	// attestation artifact (see Attestation).
+	SigningKey string `json:"signing_key" secret:"SIGNING_KEY" describe:"PEM private key (Ed25519, ECDSA P-256, or RSA) used to sign the emitted artifacts"`
+
+	// HeartbeatInterval, in seconds, re-reports the current status after
+	// that long without an update, so a runner watching for output doesn't
+	// take a long API wait for a hang (0 = off).
+	HeartbeatInterval int `json:"heartbeat_interval" default:"0" describe:"Seconds without a status update after which a liveness update is sent (0 = off)"`
+
+	// ProgressFormat selects the progress message format: text lines, or
+	// JSON ProgressEvents for runners rendering per-phase progress.
+	ProgressFormat string `json:"progress_format" default:"text" describe:"Progress message format: text or json (machine-readable per-phase events)"`
+
+	// CompressOutputOverBytes, when positive, emits the detailed artifact
+	// gzip-compressed (see CompressedArtifact) once its JSON exceeds this
+	// many bytes, to stay under the runner's message size limit.
+	CompressOutputOverBytes int `json:"compress_output_over_bytes" default:"0" describe:"Compress the detailed artifact when its JSON exceeds this many bytes (0 = never)"`
+
+	// ScanWorkflowCredentials scans the workflow files of repositories with
+	// GitHub Actions for hardcoded credentials (audit+), reporting counts.
+	ScanWorkflowCredentials bool `json:"scan_workflow_credentials" default:"false" enables:"actions_security.hardcoded_credentials" describe:"Scan workflow files for hardcoded credentials, reporting counts only"`
+
+	// SearchCredentialFiles counts common credential file names (id_rsa,
+	// .env, *.pem) in in-scope repositories with code search (audit+),
+	// paced at github.CodeSearchInterval.
+	SearchCredentialFiles bool `json:"search_credential_files" default:"false" enables:"secrets_hygiene.search_hits" describe:"Count credential-like file names (id_rsa, .env, *.pem) with rate-limited code search, reporting counts only"`
+
+	// OutputFields selects or drops sections of the detailed artifact, as
+	// dotted paths; a "-" prefix drops (see ProjectFields).
+	OutputFields []string `json:"output_fields" describe:"Sections of the detailed artifact to emit (e.g. posture, security_features.per_repo); prefix with - to drop a section"`
+
+	// OCSFOutput also emits the policy evaluation results as OCSF
+	// Compliance Finding events (see OrgPosture.ToOCSFFindings).
+	OCSFOutput bool `json:"ocsf_output" default:"false" describe:"Also emit policy evaluation results as OCSF Compliance Finding events (artifacts/github.ocsf.json)"`
+
+	// NotificationWebhook, when set, posts a posture summary to a Slack or
+	// Microsoft Teams incoming webhook after collection (see Notify).
+	// NotifyOnlyOnRegression posts only when posture regressed since the
+	// posture saved in StateDir by the previous run.
+	NotificationWebhook    string `json:"notification_webhook" secret:"NOTIFICATION_WEBHOOK_URL" describe:"Slack or Microsoft Teams incoming webhook URL that receives a posture summary after collection"`
+	NotificationFormat     string `json:"notification_format" default:"slack" describe:"Notification message format: slack or teams"`
+	NotifyOnlyOnRegression bool   `json:"notify_only_on_regression" default:"false" describe:"Post the notification only when posture regressed since the previous run (requires state_dir)"`
+
+	// AlertThresholds, keyed by compared metric (see comparedMetrics), is how
+	// many points a coverage percentage may regress between runs before an
+	// incident is opened; the access_control.two_factor_required key alerts
+	// when 2FA enforcement is turned off. Incidents go to PagerDuty and/or
+	// Opsgenie and need StateDir for the baseline (see alertBreaches).
+	AlertThresholds     map[string]int `json:"alert_thresholds" describe:"Points each coverage metric (e.g. posture.branch_protection_coverage) may drop since the previous run before an incident is opened; access_control.two_factor_required alerts when 2FA enforcement is turned off"`
+	PagerDutyRoutingKey string         `json:"pagerduty_routing_key" secret:"PAGERDUTY_ROUTING_KEY" describe:"PagerDuty Events API v2 routing key for alert_thresholds incidents"`
+	OpsgenieAPIKey      string         `json:"opsgenie_api_key" secret:"OPSGENIE_API_KEY" describe:"Opsgenie API key for alert_thresholds alerts"`
+
+	// ComplianceSLAs, keyed by compared metric, is the threshold a day's
+	// coverage must meet (at least, or at most where lower is better) to
+	// count toward the metric's compliance SLA over the trailing
+	// SLAWindowDays. The daily history lives in StateDir.
+	ComplianceSLAs map[string]int `json:"compliance_slas" enables:"compliance_slas" describe:"Threshold each coverage metric (e.g. posture.branch_protection_coverage) must meet for a day to count as compliant; reports the share of compliant days (requires state_dir)"`
+	SLAWindowDays  int            `json:"sla_window_days" default:"90" describe:"Trailing days compliance_slas are measured over"`
+
+	// RemediationTracker, when set, files or updates a ticket per current
+	// violation, grouped per RemediationGroupBy, as issues in
+	// RemediationRepository or in a Jira Cloud project (see FileRemediation).
+	RemediationTracker    string `json:"remediation_tracker" describe:"Tracker for remediation tickets: github or jira (unset = off)"`
+	RemediationGroupBy    string `json:"remediation_group_by" default:"rule" describe:"One remediation ticket per rule or per repository"`
+	RemediationRepository string `json:"remediation_repository" describe:"owner/name of the repository receiving GitHub Issues remediation tickets"`
+	JiraURL               string `json:"jira_url" describe:"Jira Cloud site URL for remediation tickets (e.g. https://example.atlassian.net)"`
+	JiraProject           string `json:"jira_project" describe:"Jira project key for remediation tickets"`
+	JiraEmail             string `json:"jira_email" describe:"Jira account email used with JIRA_API_TOKEN"`
+	JiraAPIToken          string `json:"jira_api_token" secret:"JIRA_API_TOKEN" describe:"Jira API token for remediation tickets"`
 
 	// Progress callbacks (optional, set by main to report status)
 	OnStatus   StatusFunc   `json:"-"`
@@ -35,11 +289,22 @@ type OrgPosture struct {
 	CollectedAt           string                `json:"collected_at"`
 	CollectedAtLevel      string                `json:"collected_at_level"`
 	Organization          string                `json:"organization"`
+	OwnerType             string                `json:"owner_type"`
+	Instance              *GitHubInstance       `json:"instance,omitempty"`
 	Scope                 Scope                 `json:"scope"`
 	Posture               Posture               `json:"posture"`
 	AccessControl         AccessControl         `json:"access_control"`
 	BranchProtectionRules BranchProtectionRules `json:"branch_protection_rules"`
 	SecurityFeatures      SecurityFeatures      `json:"security_features"`
+	RepositoryHygiene     RepositoryHygiene     `json:"repository_hygiene"`
+	OrgDefaults           OrgDefaults           `json:"org_defaults"`
+
+	// CoverageBreakdown is present when coverage_breakdown or tiers are
+	// configured.
+	CoverageBreakdown *CoverageBreakdown `json:"coverage_breakdown,omitempty"`
+
+	// Rulesets is present when the org's rulesets could be read.
+	Rulesets *Rulesets `json:"rulesets,omitempty"`
 
 	// Audit / internal surfaces (nil at trust; omitempty keeps trust stable).
 	Members      *Members      `json:"members,omitempty"`
@@ -52,9 +317,91 @@ type OrgPosture struct {
 	Apps         *Apps         `json:"apps,omitempty"`
 	Tokens       *Tokens       `json:"tokens,omitempty"`
 
+	// ThirdPartyApps is present whenever apps is.
+	ThirdPartyApps *ThirdPartyApps `json:"third_party_apps,omitempty"`
+
+	VulnerabilityManagement *VulnerabilityManagement `json:"vulnerability_management,omitempty"`
+	Triage                  *Triage                  `json:"triage,omitempty"`
+	SecretsManagement       *SecretsManagement       `json:"secrets_management,omitempty"`
+	// SecretsHygiene is present when search_credential_files is set.
+	SecretsHygiene    *SecretsHygiene    `json:"secrets_hygiene,omitempty"`
+	CommunityControls *CommunityControls `json:"community_controls,omitempty"`
+	PublicExposure    *PublicExposure    `json:"public_exposure,omitempty"`
+	SupplyChain       *SupplyChain       `json:"supply_chain,omitempty"`
+	ActionsSecurity   *ActionsSecurity   `json:"actions_security,omitempty"`
+
+	// CISBenchmark is present when Config.CISBenchmark is set.
+	CISBenchmark *CISBenchmark `json:"cis_benchmark,omitempty"`
+
+	// ComplianceSLAs is present when compliance_slas is configured.
+	ComplianceSLAs *ComplianceSLAs `json:"compliance_slas,omitempty"`
+
+	// RepositoryChanges is present when StateDir is set and a previous
+	// run's snapshot exists.
+	RepositoryChanges *RepositoryChanges `json:"repository_changes,omitempty"`
+
+	// RecentChanges is present at audit and above when the audit log could
+	// be read.
+	RecentChanges *RecentChanges `json:"recent_changes,omitempty"`
+
+	CollectionStats CollectionStats `json:"collection_stats"`
+
 	Diagnostics *Diagnostics `json:"diagnostics,omitempty"`
 }
 
+// GitHubInstance identifies the GitHub deployment collected from, and its
+// capability matrix: whether it serves each version-gated feature (see
+// github.Instance.Supports). Metrics over an unsupported feature are skipped
+// with a diagnostic rather than reported as disabled. Absent when detection
+// failed.
+type GitHubInstance struct {
+	Product      string          `json:"product"` // github.com or ghes
+	Version      string          `json:"version,omitempty"`
+	Capabilities map[string]bool `json:"capabilities"`
+}
+
+// CollectionStats reports the run's GraphQL usage. RateLimitRemaining is nil
+// when no GraphQL query completed. Aborted is set when collection stopped
+// early at the abort_below_remaining threshold, Cancelled when the run's
+// context was cancelled (e.g. the runner's timeout).
+type CollectionStats struct {
+	GraphQLQueries     int    `json:"graphql_queries"`
+	GraphQLCost        int    `json:"graphql_cost"`
+	RateLimitRemaining *int   `json:"rate_limit_remaining"`
+	RateLimitResetAt   string `json:"rate_limit_reset_at,omitempty"`
+	Aborted            bool   `json:"aborted"`
+	Cancelled          bool   `json:"cancelled,omitempty"`
+
+	// GraphQLRetries and GraphQLSplitPages count queries resent after a
+	// transient failure and repository pages fetched in two halves.
+	GraphQLRetries    int `json:"graphql_retries,omitempty"`
+	GraphQLSplitPages int `json:"graphql_split_pages,omitempty"`
+
+	// SecondaryRateLimitPauses counts the pauses GitHub's secondary rate
+	// limits imposed; the requests resumed after each.
+	SecondaryRateLimitPauses int `json:"secondary_rate_limit_pauses,omitempty"`
+
+	// Scheduling hints (see Collector.scheduleHints): when the next run
+	// should start, and when the rate limit recovers enough to repeat this
+	// run's GraphQL usage, if it hasn't already.
+	NextCollectionAt    string `json:"next_collection_at,omitempty"`
+	RateLimitRecoveryAt string `json:"rate_limit_recovery_at,omitempty"`
+
+	// Run and per-phase timing, for monitoring collector health.
+	StartedAt  string        `json:"started_at,omitempty"`
+	FinishedAt string        `json:"finished_at,omitempty"`
+	DurationMs int64         `json:"duration_ms"`
+	Phases     []PhaseTiming `json:"phases,omitempty"`
+}
+
+// PhaseTiming is when one collection phase (see the Phase constants) ran.
+type PhaseTiming struct {
+	Phase      string `json:"phase"`
+	StartedAt  string `json:"started_at"`
+	FinishedAt string `json:"finished_at"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
 // Diagnostics contains warnings and errors encountered during collection.
 // This helps identify permission issues vs features that are genuinely disabled.
 type Diagnostics struct {
@@ -67,12 +414,61 @@ type Scope struct {
 	IncludePatterns      []string `json:"include_patterns"`
 	ExcludePatterns      []string `json:"exclude_patterns"`
 	RepositoriesCoverage int      `json:"repositories_coverage"`
+
+	// ExcludedCounts is the number of repositories left out of scope by
+	// reason (an ExcludeReason): archived, mirror, template, or pattern.
+	ExcludedCounts map[string]int `json:"excluded_counts,omitempty"`
+
+	// SkippedRepositoryCount is the number of in-scope repositories whose
+	// per-repo settings couldn't be read; they are left out of the
+	// security-feature percentages and applicable_repos. The named list is
+	// audit level and above.
+	SkippedRepositoryCount int                 `json:"skipped_repository_count"`
+	SkippedRepositories    []SkippedRepository `json:"skipped_repositories,omitempty"`
+
+	// ExplicitRepositoryCount is the number of repositories in the configured
+	// repositories list (0 = the whole org was enumerated). The list itself is
+	// audit level and above.
+	ExplicitRepositoryCount int      `json:"explicit_repository_count,omitempty"`
+	ExplicitRepositories    []string `json:"explicit_repositories,omitempty"`
+
+	// Profile is the built-in collection profile the config was based on.
+	Profile string `json:"profile,omitempty"`
+
+	// Truncated is set when enumeration stopped at MaxRepositories, so the
+	// metrics cover only the first MaxRepositories in-scope repositories.
+	Truncated       bool `json:"truncated,omitempty"`
+	MaxRepositories int  `json:"max_repositories,omitempty"`
+
+	// MetricRepositoryCounts is the number of repositories each scoped metric
+	// family was evaluated over, present only when scopes are configured.
+	MetricRepositoryCounts map[string]int `json:"metric_repository_counts,omitempty"`
+}
+
+// SkippedRepository is an in-scope repository whose per-repo collection
+// failed, with a reason code (one of the SkipReason constants).
+type SkippedRepository struct {
+	Repository string `json:"repository"`
+	Reason     string `json:"reason"`
 }
 
 // Posture contains high-level posture coverage metrics.
 type Posture struct {
 	BranchProtectionCoverage int `json:"branch_protection_coverage"`
 	SecurityFeaturesCoverage int `json:"security_features_coverage"`
+	// SecurityFeaturesWeights are the per-feature weights the coverage score
+	// was computed with.
+	SecurityFeaturesWeights SecurityFeatureWeights `json:"security_features_weights"`
+}
+
+// SecurityFeatureWeights holds the weight of each feature in
+// SecurityFeaturesCoverage.
+type SecurityFeatureWeights struct {
+	VulnerabilityAlerts          float64 `json:"vulnerability_alerts"`
+	CodeScanning                 float64 `json:"code_scanning"`
+	SecretScanning               float64 `json:"secret_scanning"`
+	SecretScanningPushProtection float64 `json:"secret_scanning_push_protection"`
+	DependabotSecurityUpdates    float64 `json:"dependabot_security_updates"`
 }
 
 // AccessControl contains organization-level access control posture.
@@ -80,13 +476,67 @@ type Posture struct {
 // The audit-level fields below populate only at audit and above (omitempty).
 type AccessControl struct {
 	TwoFactorRequired *bool `json:"two_factor_required"`
+	// MembersWithout2FA is nil when unknown: only org owners can list
+	// members by 2FA status.
+	MembersWithout2FA *MembersWithout2FA `json:"members_without_2fa,omitempty"`
 
 	// Audit-level org access-control settings (from GET /orgs/{org}).
 	DefaultRepositoryPermission  string `json:"default_repository_permission,omitempty"`
 	MembersCanCreateRepositories *bool  `json:"members_can_create_repositories,omitempty"`
+
+	// SecurityManagersConfigured reports whether any team holds the security
+	// manager role, evidence of a defined security function; nil when the
+	// role assignments couldn't be read.
+	SecurityManagersConfigured *bool    `json:"security_managers_configured,omitempty"`
+	SecurityManagerTeams       []string `json:"security_manager_teams,omitempty"`
+
+	CustomRoles *CustomRoles `json:"custom_roles,omitempty"`
+	PATPolicy   *PATPolicy   `json:"pat_policy,omitempty"`
+}
+
+// MembersWithout2FA counts the org members without two-factor authentication
+// and their percentage of all members, which names the size of the gap where
+// two_factor_required only says whether one can exist.
+type MembersWithout2FA struct {
+	Count   int `json:"count"`
+	Percent int `json:"percent"`
+}
+
+// PATPolicy reports the org's fine-grained personal access token governance:
+// how many tokens have been granted access and how many requests await an
+// owner's approval. GitHub's API doesn't expose the policy settings themselves
+// (approval required, classic PATs restricted); an org that lets members
+// request tokens at all has a fine-grained token policy.
+type PATPolicy struct {
+	ApprovedTokens  int `json:"approved_tokens"`
+	PendingRequests int `json:"pending_requests"`
+
+	// Truncated is set when the grants or requests stopped at PATFetchCap,
+	// so the counts are lower bounds.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// CustomRoles inventories the org's custom organization and repository roles
+// and flags those granting a permission that can weaken security controls
+// (e.g. bypassing branch protections).
+type CustomRoles struct {
+	OrganizationRoles       int            `json:"organization_roles"`
+	RepositoryRoles         int            `json:"repository_roles"`
+	RiskyRoles              int            `json:"risky_roles"`
+	RiskyPermissionsGranted bool           `json:"risky_permissions_granted"`
+	Risky                   []RiskyRoleRow `json:"risky,omitempty"`
+}
+
+// RiskyRoleRow names one custom role and the risky permissions it grants.
+type RiskyRoleRow struct {
+	Name        string   `json:"name"`
+	Kind        string   `json:"kind"` // organization, repository
+	Permissions []string `json:"permissions"`
 }
 
 // BranchProtectionRules contains per-rule coverage percentages.
+// ForcePushesAllowed and DeletionsAllowed are the share of repos whose
+// default-branch protection rule still permits them, so lower is better.
 type BranchProtectionRules struct {
 	PullRequestRequired int `json:"pull_request_required"`
 	ApprovingReviews    int `json:"approving_reviews"`
@@ -95,6 +545,39 @@ type BranchProtectionRules struct {
 	StatusChecks        int `json:"status_checks"`
 	SignedCommits       int `json:"signed_commits"`
 	AdminEnforcement    int `json:"admin_enforcement"`
+	LinearHistory       int `json:"linear_history"`
+	ForcePushesAllowed  int `json:"force_pushes_allowed"`
+	DeletionsAllowed    int `json:"deletions_allowed"`
+
+	ConversationResolution int `json:"conversation_resolution"`
+	LastPushApproval       int `json:"last_push_approval"`
+
+	RequiredReviewCounts ReviewCountDistribution `json:"required_review_counts"`
+	MinRequiredReviews   *PolicyCheck            `json:"min_required_reviews,omitempty"`
+	ChecksVerified       *ChecksVerified         `json:"checks_verified,omitempty"`
+}
+
+// ChecksVerified reports, among repositories whose default-branch protection
+// names required status checks, the percentage where at least one required
+// check did not report on the branch's latest commit (stale or missing).
+type ChecksVerified struct {
+	ReposEvaluated int `json:"repos_evaluated"`
+	StaleOrMissing int `json:"stale_or_missing"`
+}
+
+// ReviewCountDistribution is the percentage of in-scope repositories requiring
+// one, two, or three-or-more approving reviews on the default branch.
+type ReviewCountDistribution struct {
+	One         int `json:"one"`
+	Two         int `json:"two"`
+	ThreeOrMore int `json:"three_or_more"`
+}
+
+// PolicyCheck is the result of a configured threshold policy: the threshold
+// that was evaluated and the percentage of in-scope repositories meeting it.
+type PolicyCheck struct {
+	Threshold int `json:"threshold"`
+	Compliant int `json:"compliant"`
 }
 
 // SecurityFeatures contains per-feature coverage percentages (trust) plus
@@ -106,8 +589,31 @@ type SecurityFeatures struct {
 	SecretScanningPushProtection int `json:"secret_scanning_push_protection"`
 	DependabotSecurityUpdates    int `json:"dependabot_security_updates"`
 
+	// Generic (non-provider) secret detection: repo coverage plus the org
+	// default for new repositories (nil = unknown or no default configuration).
+	SecretScanningNonProviderPatterns           int   `json:"secret_scanning_non_provider_patterns"`
+	SecretScanningNonProviderPatternsOrgDefault *bool `json:"secret_scanning_non_provider_patterns_org_default"`
+
+	// Secret validity checks, reported the same way.
+	SecretScanningValidityChecks           int   `json:"secret_scanning_validity_checks"`
+	SecretScanningValidityChecksOrgDefault *bool `json:"secret_scanning_validity_checks_org_default"`
+
+	// AdvancedSecurity is the percentage of private and internal repositories
+	// with GitHub Advanced Security enabled, which code scanning and secret
+	// scanning on them depend on. Not part of the coverage score.
+	AdvancedSecurity int `json:"advanced_security"`
+
+	// ApplicableRepos is each percentage's denominator, keyed by field name:
+	// the repositories the feature can be enabled on. Private and internal
+	// repositories without the product code or secret scanning needs are left
+	// out, so 100% stays reachable.
+	ApplicableRepos map[string]int `json:"applicable_repos,omitempty"`
+
 	// Audit-level per-repo feature flags + open-alert counts.
 	PerRepo []SecurityFeaturesRow `json:"per_repo,omitempty"`
+	// Audit-level repositories with the most open secret scanning alerts,
+	// most first.
+	SecretScanningHotspots []AlertHotspot `json:"secret_scanning_hotspots,omitempty"`
 	// Internal-level findings inventories.
 	Findings *SecurityFindings `json:"findings,omitempty"`
 }
@@ -124,6 +630,56 @@ type SecurityFeaturesRow struct {
 	OpenSecretScanningAlerts     int    `json:"open_secret_scanning_alerts"`
 	OpenCodeScanningAlerts       int    `json:"open_code_scanning_alerts"`
 	OpenDependabotAlerts         int    `json:"open_dependabot_alerts"`
+
+	SecretScanningNonProviderPatterns bool `json:"secret_scanning_non_provider_patterns"`
+	SecretScanningValidityChecks      bool `json:"secret_scanning_validity_checks"`
+	AdvancedSecurity                  bool `json:"advanced_security"`
+
+	// Unknown names the controls whose state couldn't be read, and
+	// NotApplicable those that can't be enabled without a product the
+	// repository lacks; their flags above read false either way.
+	Unknown       []string `json:"unknown,omitempty"`
+	NotApplicable []string `json:"not_applicable,omitempty"`
+}
+
+// AlertHotspot is one repository's open-alert count in a hot-spot list.
+type AlertHotspot struct {
+	Repository string `json:"repository"`
+	OpenAlerts int    `json:"open_alerts"`
+}
+
+// RepositoryHygiene contains repository-hygiene aggregates: the fork policy on
+// non-public repositories and the spread of default-branch names.
+// LegacyDefaultBranch is nil unless the legacy-branch check is configured.
+type RepositoryHygiene struct {
+	PrivateForkingAllowed int            `json:"private_forking_allowed"`
+	DefaultBranchNames    map[string]int `json:"default_branch_names"`
+	LegacyDefaultBranch   *int           `json:"legacy_default_branch,omitempty"`
+}
+
+// OrgDefaults contains the org's "automatically enable for new repositories"
+// settings. Each is a pointer: nil means unknown (GitHub only returns them to
+// org owners or Apps with organization administration).
+type OrgDefaults struct {
+	DependabotAlerts             *bool `json:"dependabot_alerts"`
+	DependabotSecurityUpdates    *bool `json:"dependabot_security_updates"`
+	DependencyGraph              *bool `json:"dependency_graph"`
+	AdvancedSecurity             *bool `json:"advanced_security"`
+	SecretScanning               *bool `json:"secret_scanning"`
+	SecretScanningPushProtection *bool `json:"secret_scanning_push_protection"`
+
+	// PushProtectionForOrg is push protection for users at the org level:
+	// members' pushes to any repository are blocked on detected secrets. It
+	// is distinct from SecretScanningPushProtection (a default for new
+	// repositories) and from repository push protection coverage. Omitted
+	// when unknown.
+	PushProtectionForOrg *bool `json:"push_protection_for_org,omitempty"`
+
+	// The org's documented vulnerability-reporting channel: a public profile
+	// email and a default SECURITY.md in its .github repository. nil when
+	// unknown.
+	ContactEmail   *bool `json:"contact_email"`
+	SecurityPolicy *bool `json:"security_policy"`
 }
 
 // --- Audit / internal surfaces ---
@@ -181,6 +737,9 @@ type RepoRow struct {
 	UpdatedAt        string                  `json:"updated_at,omitempty"`
 	PushedAt         string                  `json:"pushed_at,omitempty"`
 	PrimaryLanguage  string                  `json:"primary_language,omitempty"`
+	Class            string                  `json:"class"`
+	OwningTeam       string                  `json:"owning_team,omitempty"`
+	CISystems        []string                `json:"ci_systems,omitempty"`
 	SizeKB           int                     `json:"size_kb,omitempty"`
 	BranchProtection *BranchProtectionDetail `json:"branch_protection,omitempty"`
 
@@ -191,33 +750,51 @@ type RepoRow struct {
 	StargazerCount int      `json:"stargazer_count,omitempty"`
 }
 
-// BranchProtectionDetail is the default-branch protection rule, per repo.
+// BranchProtectionDetail is the default branch's effective protection, per
+// repo: its classic protection rule merged with the active org rulesets
+// covering the branch, which ClassicRule and Rulesets name.
 type BranchProtectionDetail struct {
-	RequiresApprovingReviews       bool `json:"requires_approving_reviews"`
-	RequiredApprovingReviewCount   int  `json:"required_approving_review_count"`
-	DismissesStaleReviews          bool `json:"dismisses_stale_reviews"`
-	RequiresCodeOwnerReviews       bool `json:"requires_code_owner_reviews"`
-	RequiresStatusChecks           bool `json:"requires_status_checks"`
-	RequiresCommitSignatures       bool `json:"requires_commit_signatures"`
-	IsAdminEnforced                bool `json:"is_admin_enforced"`
-	RequiresLinearHistory          bool `json:"requires_linear_history"`
-	AllowsForcePushes              bool `json:"allows_force_pushes"`
-	AllowsDeletions                bool `json:"allows_deletions"`
-	RequiresConversationResolution bool `json:"requires_conversation_resolution"`
-}
-
-// Codeowners reports CODEOWNERS presence (audit) and content hash (internal).
+	ClassicRule                    bool     `json:"classic_rule"`
+	Rulesets                       []string `json:"rulesets,omitempty"`
+	RequiresApprovingReviews       bool     `json:"requires_approving_reviews"`
+	RequiredApprovingReviewCount   int      `json:"required_approving_review_count"`
+	DismissesStaleReviews          bool     `json:"dismisses_stale_reviews"`
+	RequiresCodeOwnerReviews       bool     `json:"requires_code_owner_reviews"`
+	RequiresStatusChecks           bool     `json:"requires_status_checks"`
+	RequiresCommitSignatures       bool     `json:"requires_commit_signatures"`
+	IsAdminEnforced                bool     `json:"is_admin_enforced"`
+	RequiresLinearHistory          bool     `json:"requires_linear_history"`
+	AllowsForcePushes              bool     `json:"allows_force_pushes"`
+	AllowsDeletions                bool     `json:"allows_deletions"`
+	RequiresConversationResolution bool     `json:"requires_conversation_resolution"`
+	RequireLastPushApproval        bool     `json:"require_last_push_approval"`
+}
+
+// Codeowners reports CODEOWNERS presence (audit) and content hash and
+// ownership (internal). Teams rolls the repositories up by owning team.
 type Codeowners struct {
-	PerRepo []CodeownersRow `json:"per_repo,omitempty"`
+	PerRepo []CodeownersRow     `json:"per_repo,omitempty"`
+	Teams   []CodeownersTeamRow `json:"teams,omitempty"`
 }
 
 // CodeownersRow is one repo's CODEOWNERS status. The file contents are never
-// emitted; Hash is a SHA-256 computed in-process at internal level.
+// emitted; Hash is a SHA-256 and OwningTeam a team handle ("org/team"), both
+// computed in-process at internal level (see github.CodeownersInfo).
 type CodeownersRow struct {
 	Repository string `json:"repository"`
 	Present    bool   `json:"present"`
 	Path       string `json:"path,omitempty"`
 	Hash       string `json:"hash,omitempty"`
+	OwningTeam string `json:"owning_team,omitempty"`
+}
+
+// CodeownersTeamRow is one owning team's rollup: the in-scope repositories
+// CODEOWNERS assigns it, and how many of those have an unprotected default
+// branch.
+type CodeownersTeamRow struct {
+	Team             string `json:"team"`
+	OwnedRepos       int    `json:"owned_repos"`
+	UnprotectedRepos int    `json:"unprotected_repos"`
 }
 
 // Webhooks is the org + repo webhook inventory (audit counts, internal detail).
@@ -242,6 +819,72 @@ type WebhookRow struct {
 	LastResponseStatus string   `json:"last_response_status,omitempty"`
 }
 
+// VulnerabilityManagement reports use of the repository security advisory
+// (coordinated disclosure) workflow over a lookback window. Draft counts
+// include advisories still in triage. Per-repo rows list only repos with
+// advisories in the window.
+type VulnerabilityManagement struct {
+	LookbackDays         int                `json:"lookback_days"`
+	UsesAdvisoryWorkflow bool               `json:"uses_advisory_workflow"`
+	DraftAdvisories      int                `json:"draft_advisories"`
+	PublishedAdvisories  int                `json:"published_advisories"`
+	ReposWithAdvisories  int                `json:"repos_with_advisories"`
+	PerRepo              []AdvisoryCountRow `json:"per_repo,omitempty"`
+
+	// AutoTriage is nil for user accounts and when the alerts are
+	// unreadable.
+	AutoTriage *AutoTriage `json:"auto_triage,omitempty"`
+}
+
+// AutoTriage counts Dependabot alerts that auto-triage rules dismissed in
+// in-scope repositories within the lookback window. A high share of high or
+// critical alerts among them suggests an overly broad rule; the rules
+// themselves aren't exposed by the API. Truncated is set when the alert
+// fetch hit its cap.
+type AutoTriage struct {
+	AutoDismissedAlerts int             `json:"auto_dismissed_alerts"`
+	HighOrCritical      int             `json:"high_or_critical"`
+	ReposAffected       int             `json:"repos_affected"`
+	Truncated           bool            `json:"truncated,omitempty"`
+	PerRepo             []AutoTriageRow `json:"per_repo,omitempty"`
+}
+
+// AutoTriageRow is one repo's auto-dismissed alert counts.
+type AutoTriageRow struct {
+	Repository     string `json:"repository"`
+	AutoDismissed  int    `json:"auto_dismissed"`
+	HighOrCritical int    `json:"high_or_critical"`
+}
+
+// AdvisoryCountRow is one repo's advisory counts within the lookback window.
+type AdvisoryCountRow struct {
+	Repository string `json:"repository"`
+	Draft      int    `json:"draft"`
+	Published  int    `json:"published"`
+}
+
+// Triage is a lightweight proxy for whether security findings are being
+// worked: the share of repos with a security-related label and the age of
+// open issues labelled "security". Opt-in (CollectTriage); per-repo rows list
+// only repos with open security issues.
+type Triage struct {
+	StaleAfterDays        int         `json:"stale_after_days"`
+	SecurityLabelCoverage int         `json:"security_label_coverage"`
+	OpenSecurityIssues    int         `json:"open_security_issues"`
+	StaleSecurityIssues   int         `json:"stale_security_issues"`
+	ReposWithStaleIssues  int         `json:"repos_with_stale_issues"`
+	PerRepo               []TriageRow `json:"per_repo,omitempty"`
+}
+
+// TriageRow is one repo's open security-issue counts. Issue titles are never
+// emitted.
+type TriageRow struct {
+	Repository          string `json:"repository"`
+	OpenSecurityIssues  int    `json:"open_security_issues"`
+	StaleSecurityIssues int    `json:"stale_security_issues"`
+	OldestOpenDays      int    `json:"oldest_open_days"`
+}
+
 // DeployKeys is the per-repo deploy-key inventory (audit counts, internal detail).
 type DeployKeys struct {
 	TotalCount     int            `json:"total_count"`
@@ -268,6 +911,83 @@ type Actions struct {
 	OrgRunners      []RunnerRow `json:"org_runners,omitempty"`
 	RepoRunners     []RunnerRow `json:"repo_runners,omitempty"`
 	OrgSecretNames  []string    `json:"org_secret_names,omitempty"`
+
+	PublicRepoExposure *RunnerPublicExposure `json:"public_repo_exposure,omitempty"`
+}
+
+// RunnerPublicExposure flags self-hosted runners reachable from public
+// repositories, which can run code from any fork's pull request. Audit emits
+// the flags; internal adds the repositories and runner groups behind them.
+type RunnerPublicExposure struct {
+	// PublicRepoRunners is set when an in-scope public repository has its own
+	// self-hosted runners; nil when a public repository's runners couldn't be
+	// read and none of the others have any.
+	PublicRepoRunners *bool `json:"public_repo_runners,omitempty"`
+	// PublicRunnerGroups is set when an org runner group with runners allows
+	// public repositories; nil when runner groups couldn't be read.
+	PublicRunnerGroups *bool    `json:"public_runner_groups,omitempty"`
+	PublicRepos        []string `json:"public_repos,omitempty"`
+	RunnerGroups       []string `json:"runner_groups,omitempty"`
+}
+
+// SecretsManagement is the secret-hygiene surface (audit counts, internal
+// detail). Secret values are never readable through the API.
+type SecretsManagement struct {
+	RepositorySecrets *RepositorySecrets `json:"repository_secrets,omitempty"`
+}
+
+// SecretsHygiene reports signs of credentials committed to repositories
+// (audit+, opt-in).
+type SecretsHygiene struct {
+	SearchHits *SearchHits `json:"search_hits,omitempty"`
+}
+
+// SearchHits counts code search matches for common credential file names in
+// in-scope repositories. Counts only: the matching repositories and paths are
+// never emitted. Incomplete is set when a search timed out or matched more
+// results than code search returns, so the counts are lower bounds.
+type SearchHits struct {
+	TotalHits     int            `json:"total_hits"`
+	ReposWithHits int            `json:"repos_with_hits"`
+	Incomplete    bool           `json:"incomplete,omitempty"`
+	ByPattern     []SearchHitRow `json:"by_pattern"`
+}
+
+// SearchHitRow is one credential file pattern's matches and the in-scope
+// repositories they are in.
+type SearchHitRow struct {
+	Pattern    string `json:"pattern"`
+	Hits       int    `json:"hits"`
+	Repos      int    `json:"repos"`
+	Incomplete bool   `json:"incomplete,omitempty"`
+}
+
+// RepositorySecrets reports repo-level Actions secret counts and how many
+// haven't been updated within the rotation threshold. Per-repo rows list only
+// repos with secrets.
+type RepositorySecrets struct {
+	RotationThresholdDays int                   `json:"rotation_threshold_days"`
+	TotalSecrets          int                   `json:"total_secrets"`
+	StaleSecrets          int                   `json:"stale_secrets"`
+	ReposWithSecrets      int                   `json:"repos_with_secrets"`
+	ReposWithStaleSecrets int                   `json:"repos_with_stale_secrets"`
+	PerRepo               []RepositorySecretRow `json:"per_repo,omitempty"`
+}
+
+// RepositorySecretRow is one repo's Actions secret counts (audit) and
+// per-secret names and update times (internal).
+type RepositorySecretRow struct {
+	Repository      string         `json:"repository"`
+	Count           int            `json:"count"`
+	Stale           int            `json:"stale"`
+	OldestUpdatedAt string         `json:"oldest_updated_at,omitempty"`
+	Secrets         []SecretAgeRow `json:"secrets,omitempty"`
+}
+
+// SecretAgeRow is one Actions secret's name and last update.
+type SecretAgeRow struct {
+	Name      string `json:"name"`
+	UpdatedAt string `json:"updated_at,omitempty"`
 }
 
 // RunnerRow is one self-hosted runner.
@@ -289,6 +1009,38 @@ type AuditLog struct {
 	Events           []AuditLogRow  `json:"events,omitempty"`
 	Truncated        bool           `json:"truncated,omitempty"`
 	TruncatedDropped int            `json:"truncated_dropped,omitempty"`
+
+	TimeToProtection *TimeToProtection `json:"time_to_protection,omitempty"`
+}
+
+// RecentChanges reports risky changes the audit log recorded in its window
+// (WindowDays, the audit log's lookback). Truncated is set when the log was
+// capped, so the counts undercount.
+type RecentChanges struct {
+	WindowDays          int                  `json:"window_days"`
+	Truncated           bool                 `json:"truncated,omitempty"`
+	ProtectionOverrides *ProtectionOverrides `json:"protection_overrides"`
+}
+
+// ProtectionOverrides counts audit events in the window that bypassed or
+// weakened branch protection. Counts undercount when the log is truncated.
+type ProtectionOverrides struct {
+	PolicyOverrides     int `json:"policy_overrides"`     // pushes bypassing a protection rule, force pushes included
+	ForcePushes         int `json:"force_pushes"`         // the policy overrides that force-pushed
+	ProtectionsLoosened int `json:"protections_loosened"` // force pushes or deletions allowed, admin enforcement changed
+	ProtectionsRemoved  int `json:"protections_removed"`  // protection rules or rulesets deleted
+	AffectedRepos       int `json:"affected_repos"`
+}
+
+// TimeToProtection reports how quickly repositories created within the audit
+// window gained branch protection. MedianHours is nil when none did; the
+// median undercounts slow cases when the log is truncated.
+type TimeToProtection struct {
+	ReposCreated      int      `json:"repos_created"`
+	ProtectedCount    int      `json:"protected_count"`    // first protection rule seen in the window
+	UnprotectedCount  int      `json:"unprotected_count"`  // still without a protection rule
+	UnattributedCount int      `json:"unattributed_count"` // protected, but no rule creation event in the window
+	MedianHours       *float64 `json:"median_hours"`
 }
 
 // AuditLogRow is one audit event (metadata only, no payload bodies).
@@ -299,12 +1051,321 @@ type AuditLogRow struct {
 	Timestamp int64  `json:"timestamp"`
 }
 
+// CISBenchmark is the per-recommendation result of the automatable CIS
+// GitHub Benchmark checks. A recommendation is unknown when the data it needs
+// wasn't collected (level or permissions).
+type CISBenchmark struct {
+	Benchmark       string                    `json:"benchmark"`
+	Passed          int                       `json:"passed"`
+	Failed          int                       `json:"failed"`
+	Unknown         int                       `json:"unknown"`
+	Recommendations []CISRecommendationResult `json:"recommendations"`
+}
+
+// CISRecommendationResult is one recommendation's outcome. Evidence is the
+// dotted path of the metric it was evaluated from; the repository counts are
+// set for per-repository recommendations.
+type CISRecommendationResult struct {
+	ID             string `json:"id"`
+	Title          string `json:"title"`
+	Status         string `json:"status"`
+	Evidence       string `json:"evidence"`
+	ReposCompliant *int   `json:"repos_compliant,omitempty"`
+	ReposEvaluated *int   `json:"repos_evaluated,omitempty"`
+	Note           string `json:"note,omitempty"`
+}
+
+// RepositoryChanges lists repositories created, archived, renamed,
+// transferred out, or deleted since the previous run (counts at trust, the
+// named changes at audit and above). The comparison covers every repository
+// the collector can see, including archived and pattern-excluded ones.
+type RepositoryChanges struct {
+	Since          string                `json:"since"` // the previous run's collected_at
+	Created        int                   `json:"created"`
+	Archived       int                   `json:"archived"`
+	Renamed        int                   `json:"renamed"`
+	TransferredOut int                   `json:"transferred_out"`
+	Deleted        int                   `json:"deleted"`
+	Removed        int                   `json:"removed"`
+	Changes        []RepositoryChangeRow `json:"changes,omitempty"`
+}
+
+// RepositoryChangeRow is one change. NewName is the repository's current
+// owner/name after a rename or transfer.
+type RepositoryChangeRow struct {
+	Repository string `json:"repository"`
+	Change     string `json:"change"`
+	NewName    string `json:"new_name,omitempty"`
+}
+
+// CoverageBreakdown splits security feature coverage by repository context,
+// since org-wide percentages pooled over documentation and configuration
+// repositories understate coverage where code is actually built. Segments
+// cover every in-scope repository, ignoring per-metric scopes.
+type CoverageBreakdown struct {
+	// WithCI and WithoutCI split repositories on whether any CI system is
+	// configured on the default branch.
+	WithCI    CoverageSegment `json:"with_ci"`
+	WithoutCI CoverageSegment `json:"without_ci"`
+	// ByLanguage is keyed by primary language, NoLanguage when none.
+	ByLanguage map[string]CoverageSegment `json:"by_language,omitempty"`
+	// ByClass is keyed by repository class (library, service, infra, docs,
+	// fork-mirror, or unclassified; see classifyRepo), since posture
+	// expectations differ between them.
+	ByClass map[string]CoverageSegment `json:"by_class,omitempty"`
+	// ByTier is keyed by the configured repository tier (critical, high, or
+	// normal), each with its thresholds evaluated. TierThresholdsMet is
+	// whether every tier meets its thresholds, nil when none are configured
+	// or, with none missed, any is unknown.
+	ByTier            map[string]TierCoverage `json:"by_tier,omitempty"`
+	TierThresholdsMet *bool                   `json:"tier_thresholds_met,omitempty"`
+	// CISystems counts repositories per detected CI system; a repository
+	// can use several.
+	CISystems map[string]int `json:"ci_systems,omitempty"`
+}
+
+// CoverageSegment is the share of a segment's repositories with each feature
+// enabled.
+type CoverageSegment struct {
+	Repos                        int `json:"repos"`
+	BranchProtection             int `json:"branch_protection"`
+	VulnerabilityAlerts          int `json:"vulnerability_alerts"`
+	CodeScanning                 int `json:"code_scanning"`
+	SecretScanning               int `json:"secret_scanning"`
+	SecretScanningPushProtection int `json:"secret_scanning_push_protection"`
+	DependabotSecurityUpdates    int `json:"dependabot_security_updates"`
+}
+
+// TierCoverage is a repository tier's coverage segment and threshold results.
+type TierCoverage struct {
+	CoverageSegment
+	Thresholds []TierThreshold `json:"thresholds,omitempty"`
+}
+
+// TierThreshold is one tier threshold: the minimum percentage a metric must
+// reach and the tier's actual percentage. Met is nil when unknown: the tier
+// is empty or its membership couldn't be fully read.
+type TierThreshold struct {
+	Metric  string `json:"metric"`
+	Minimum int    `json:"minimum"`
+	Actual  int    `json:"actual"`
+	Met     *bool  `json:"met"`
+}
+
+// CommunityControls reports the org's moderation controls for public
+// repositories (audit+). InteractionLimitActive and BlockedUserCount are nil
+// when their endpoint couldn't be read. Blocked logins are internal level.
+type CommunityControls struct {
+	InteractionLimitActive    *bool    `json:"interaction_limit_active"`
+	InteractionLimit          string   `json:"interaction_limit,omitempty"` // existing_users, contributors_only, or collaborators_only
+	InteractionLimitExpiresAt string   `json:"interaction_limit_expires_at,omitempty"`
+	BlockedUserCount          *int     `json:"blocked_user_count"`
+	BlockedUsers              []string `json:"blocked_users,omitempty"`
+}
+
+// PublicExposure reports content readable by anyone outside the code itself
+// (audit+): wikis of public repositories and public org projects, both of
+// which can leak internal detail while the code stays locked down.
+// PublicProjectCount and ProjectCount are nil when projects couldn't be read
+// (or for user accounts). The repository and project names are internal
+// level.
+type PublicExposure struct {
+	PublicWikiCount    int      `json:"public_wiki_count"`
+	PublicWikiRepos    []string `json:"public_wiki_repos,omitempty"`
+	PrivateWikiCount   int      `json:"private_wiki_count"` // private/internal repos with a wiki (visible to org members)
+	ProjectCount       *int     `json:"project_count"`
+	PublicProjectCount *int     `json:"public_project_count"`
+	PublicProjects     []string `json:"public_projects,omitempty"`
+}
+
+// SupplyChain reports supply-chain controls (audit+). Each part is nil when
+// the data it needs couldn't be read.
+type SupplyChain struct {
+	DependencyReview *DependencyReview `json:"dependency_review,omitempty"`
+	Attestations     *Attestations     `json:"attestations,omitempty"`
+	Releases         *ReleaseIntegrity `json:"releases,omitempty"`
+}
+
+// ReleaseIntegrity reports integrity material on the recent releases (up to
+// RecentReleaseCount per repository) of in-scope repositories: releases
+// shipping a signature asset, a checksum asset, and releases GitHub marks
+// immutable. ImmutableUnknown counts releases whose immutability the API
+// didn't report. Truncated is set when only the first MaxReleaseRepos
+// repositories were checked. Per-repo rows are internal level.
+type ReleaseIntegrity struct {
+	ReposWithReleases   int                   `json:"repos_with_releases"`
+	ReleasesChecked     int                   `json:"releases_checked"`
+	SignedReleases      int                   `json:"signed_releases"`
+	ChecksummedReleases int                   `json:"checksummed_releases"`
+	ImmutableReleases   int                   `json:"immutable_releases"`
+	ImmutableUnknown    int                   `json:"immutable_unknown"`
+	Truncated           bool                  `json:"truncated,omitempty"`
+	PerRepo             []ReleaseIntegrityRow `json:"per_repo,omitempty"`
+}
+
+// ReleaseIntegrityRow is one repository's release integrity counts.
+type ReleaseIntegrityRow struct {
+	Repository       string `json:"repository"`
+	Releases         int    `json:"releases"`
+	Signed           int    `json:"signed"`
+	Checksummed      int    `json:"checksummed"`
+	Immutable        int    `json:"immutable"`
+	ImmutableUnknown int    `json:"immutable_unknown"`
+}
+
+// Attestations reports artifact attestation (provenance) adoption across
+// in-scope repositories with a published release. Adoption is the
+// percentage of those whose latest release has an attested asset. Truncated
+// is set when only the first MaxReleaseRepos repositories were checked.
+// Per-repo rows are internal level.
+type Attestations struct {
+	ReposWithReleases int              `json:"repos_with_releases"`
+	AttestedRepos     int              `json:"attested_repos"`
+	Adoption          int              `json:"adoption"`
+	Truncated         bool             `json:"truncated,omitempty"`
+	PerRepo           []AttestationRow `json:"per_repo,omitempty"`
+}
+
+// AttestationRow is one repository's latest release and how many of its
+// assets have an attestation.
+type AttestationRow struct {
+	Repository     string `json:"repository"`
+	Release        string `json:"release"`
+	Assets         int    `json:"assets"`
+	AttestedAssets int    `json:"attested_assets"`
+}
+
+// DependencyReview reports in-scope repositories whose default branch
+// requires a dependency review workflow through an org ruleset. Repositories
+// covered only by evaluate-mode rulesets, or only by rulesets targeting
+// custom properties, are counted separately and not as enforced.
+// UnreadableRulesets are those whose required workflows couldn't be read, so
+// whether they require dependency review is unknown.
+type DependencyReview struct {
+	Rulesets           []string `json:"rulesets,omitempty"`
+	UnreadableRulesets []string `json:"unreadable_rulesets,omitempty"`
+	TotalRepos         int      `json:"total_repos"`
+	EnforcedRepos      int      `json:"enforced_repos"`
+	EvaluateOnlyRepos  int      `json:"evaluate_only_repos"`
+	IndeterminateRepos int      `json:"indeterminate_repos"`
+	Coverage           int      `json:"coverage"`
+	UnenforcedRepos    []string `json:"unenforced_repos,omitempty"`
+}
+
+// Rulesets counts the org's rulesets by enforcement status. Evaluate-mode
+// rulesets only report what they would have blocked, so they add to
+// ruleset coverage on paper without protecting anything. WithBypassActors
+// counts the enabled (active or evaluate) rulesets some actor may bypass.
+// Audit names the evaluate-mode and bypassable rulesets. Truncated is set when
+// the org has more than RulesetFetchCap rulesets, so every count is partial.
+type Rulesets struct {
+	Total            int      `json:"total"`
+	Active           int      `json:"active"`
+	Evaluate         int      `json:"evaluate"`
+	Disabled         int      `json:"disabled"`
+	WithBypassActors int      `json:"with_bypass_actors"`
+	EvaluateRulesets []string `json:"evaluate_rulesets,omitempty"`
+	BypassRulesets   []string `json:"bypass_rulesets,omitempty"`
+	Truncated        bool     `json:"truncated,omitempty"`
+
+	// RepositoryRules is the audit-level coverage of the rulesets restricting
+	// repository deletion and visibility changes.
+	RepositoryRules *RepositoryRules `json:"repository_rules,omitempty"`
+}
+
+// RepositoryRules reports in-scope repositories that org repository rulesets
+// protect from deletion and from visibility changes, the protections that
+// stop a repository being made public or removed by accident. Only the
+// rulesets' bypass actors may then do either.
+type RepositoryRules struct {
+	Rulesets             []string               `json:"rulesets,omitempty"`
+	DeletionRestricted   RepositoryRuleCoverage `json:"deletion_restricted"`
+	VisibilityRestricted RepositoryRuleCoverage `json:"visibility_restricted"`
+}
+
+// RepositoryRuleCoverage counts in-scope repositories under one repository
+// rule. Repositories covered only by evaluate-mode rulesets, or only by
+// rulesets targeting custom properties, are counted separately and not as
+// enforced. UnenforcedRepos is listed at internal level.
+type RepositoryRuleCoverage struct {
+	TotalRepos         int      `json:"total_repos"`
+	EnforcedRepos      int      `json:"enforced_repos"`
+	EvaluateOnlyRepos  int      `json:"evaluate_only_repos"`
+	IndeterminateRepos int      `json:"indeterminate_repos"`
+	Coverage           int      `json:"coverage"`
+	UnenforcedRepos    []string `json:"unenforced_repos,omitempty"`
+}
+
+// ActionsSecurity reports centrally enforced Actions controls (audit+).
+// RequiredWorkflows is present when the org's rulesets could be read;
+// HardcodedCredentials when scan_workflow_credentials is set.
+type ActionsSecurity struct {
+	RequiredWorkflows    *RequiredWorkflows    `json:"required_workflows,omitempty"`
+	HardcodedCredentials *HardcodedCredentials `json:"hardcoded_credentials,omitempty"`
+}
+
+// HardcodedCredentials counts obvious credentials (provider token shapes,
+// private keys, literal secret assignments) committed in the workflow files
+// of repositories with GitHub Actions configured. Counts only; matched values
+// are never emitted. Files lists the affected workflow files (internal).
+type HardcodedCredentials struct {
+	ReposScanned      int                      `json:"repos_scanned"`
+	FilesScanned      int                      `json:"files_scanned"`
+	Findings          int                      `json:"findings"`
+	ReposWithFindings int                      `json:"repos_with_findings"`
+	ByType            map[string]int           `json:"by_type,omitempty"`
+	Files             []HardcodedCredentialRow `json:"files,omitempty"`
+}
+
+// HardcodedCredentialRow is one workflow file with credential findings.
+type HardcodedCredentialRow struct {
+	Repository string   `json:"repository"`
+	Path       string   `json:"path"`
+	Count      int      `json:"count"`
+	Types      []string `json:"types"`
+}
+
+// RequiredWorkflows lists the workflows org rulesets require to pass before
+// merging, and the in-scope repositories whose default branch is covered by
+// at least one active requirement. Coverage counts follow DependencyReview.
+type RequiredWorkflows struct {
+	Workflows          []RequiredWorkflowRow `json:"workflows,omitempty"`
+	TotalRepos         int                   `json:"total_repos"`
+	CoveredRepos       int                   `json:"covered_repos"`
+	EvaluateOnlyRepos  int                   `json:"evaluate_only_repos"`
+	IndeterminateRepos int                   `json:"indeterminate_repos"`
+	Coverage           int                   `json:"coverage"`
+	UncoveredRepos     []string              `json:"uncovered_repos,omitempty"`
+}
+
+// RequiredWorkflowRow is one workflow a ruleset requires. AppliesToRepos
+// counts the in-scope repositories the ruleset definitely applies to.
+type RequiredWorkflowRow struct {
+	Ruleset        string `json:"ruleset"`
+	Enforcement    string `json:"enforcement"` // active or evaluate
+	Path           string `json:"path"`
+	RepositoryID   int64  `json:"repository_id"`
+	Ref            string `json:"ref,omitempty"`
+	AppliesToRepos int    `json:"applies_to_repos"`
+}
+
 // Apps is the installed-GitHub-App inventory (audit+).
 type Apps struct {
 	InstallationCount int      `json:"installation_count"`
 	PerInstallation   []AppRow `json:"per_installation,omitempty"`
 }
 
+// ThirdPartyApps counts the broadly privileged App installations an access
+// review starts from (audit+); apps holds the installations themselves.
+type ThirdPartyApps struct {
+	InstallationCount int `json:"installation_count"`
+	// AdminCount is installations granted admin on any permission, or write
+	// on repository or organization administration.
+	AdminCount         int `json:"admin_count"`
+	ContentsWriteCount int `json:"contents_write_count"`
+	AllReposCount      int `json:"all_repos_count"`
+}
+
 // AppRow is one installed App.
 type AppRow struct {
 	AppSlug             string            `json:"app_slug,omitempty"`
@@ -342,3 +1403,24 @@ func NewOrgPosture(org string) *OrgPosture {
 		Organization:  org,
 	}
 }
+
+// ComplianceSLAs measures coverage metrics as SLAs: the share of days, over
+// the trailing window starting Since (YYYY-MM-DD), whose last complete run
+// met each metric's threshold.
+type ComplianceSLAs struct {
+	WindowDays int                `json:"window_days"`
+	Since      string             `json:"since"`
+	SLAs       []ComplianceSLARow `json:"slas"`
+}
+
+// ComplianceSLARow is one metric's SLA. Days without a complete run are not
+// observed; CompliancePct is DaysCompliant over DaysObserved. LowerIsBetter
+// metrics comply at or below Threshold, the rest at or above it.
+type ComplianceSLARow struct {
+	Metric        string `json:"metric"`
+	Threshold     int    `json:"threshold"`
+	LowerIsBetter bool   `json:"lower_is_better,omitempty"`
+	DaysObserved  int    `json:"days_observed"`
+	DaysCompliant int    `json:"days_compliant"`
+	CompliancePct int    `json:"compliance_pct"`
+}
@@ -0,0 +1,56 @@
+package collector
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+//go:embed profiles.json
+var profilesJSON []byte
+
+// Profile is a built-in collection profile: config defaults that select the
+// data and policy checks a compliance framework needs. Config keys are the
+// collector's own (see Config).
+type Profile struct {
+	Description string         `json:"description"`
+	Config      map[string]any `json:"config"`
+}
+
+// Profiles returns the built-in profiles keyed by name.
+func Profiles() (map[string]Profile, error) {
+	var profiles map[string]Profile
+	if err := json.Unmarshal(profilesJSON, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// ApplyProfile fills in the settings of the profile named by cfg["profile"]
+// that cfg leaves unset. Explicit config always wins, so a profile can be
+// adjusted key by key. cfg is not modified; without a profile it is returned
+// as is.
+func ApplyProfile(cfg map[string]any) (map[string]any, error) {
+	name, _ := cfg["profile"].(string)
+	if name == "" {
+		return cfg, nil
+	}
+	profiles, err := Profiles()
+	if err != nil {
+		return nil, err
+	}
+	profile, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q (want one of %s)", name, strings.Join(slices.Sorted(maps.Keys(profiles)), ", "))
+	}
+	merged := maps.Clone(cfg)
+	for key, value := range profile.Config {
+		if _, set := merged[key]; !set {
+			merged[key] = value
+		}
+	}
+	return merged, nil
+}
@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProfiles_KeysAreConfigOptions(t *testing.T) {
+	profiles, err := Profiles()
+	if err != nil {
+		t.Fatalf("Profiles() error: %v", err)
+	}
+	for _, name := range []string{"soc2", "iso27001", "nist-ssdf", "cis-github"} {
+		if _, ok := profiles[name]; !ok {
+			t.Errorf("missing built-in profile %s", name)
+		}
+	}
+
+	desc, err := DescribeConfig()
+	if err != nil {
+		t.Fatalf("DescribeConfig() error: %v", err)
+	}
+	types := map[string]string{}
+	for _, opt := range desc.Options {
+		types[opt.Key] = opt.Type
+	}
+	for name, profile := range profiles {
+		if profile.Description == "" {
+			t.Errorf("profile %s has no description", name)
+		}
+		for key, value := range profile.Config {
+			typ, ok := types[key]
+			switch {
+			case !ok || key == "profile":
+				t.Errorf("profile %s sets %q, which is not a profile-settable config key", name, key)
+			case typ == "boolean":
+				if _, ok := value.(bool); !ok {
+					t.Errorf("profile %s: %s = %v, want a boolean", name, key, value)
+				}
+			case typ == "integer":
+				if f, ok := value.(float64); !ok || f != float64(int64(f)) {
+					t.Errorf("profile %s: %s = %v, want an integer", name, key, value)
+				}
+			}
+		}
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	cfg := map[string]any{"profile": "cis-github", "min_required_reviews": float64(1)}
+	merged, err := ApplyProfile(cfg)
+	if err != nil {
+		t.Fatalf("ApplyProfile() error: %v", err)
+	}
+	if got := merged["min_required_reviews"]; got != float64(1) {
+		t.Errorf("min_required_reviews = %v, want the explicit 1", got)
+	}
+	if got := merged["verify_required_checks"]; got != true {
+		t.Errorf("verify_required_checks = %v, want the profile's true", got)
+	}
+	if _, ok := cfg["verify_required_checks"]; ok {
+		t.Error("ApplyProfile modified its input")
+	}
+
+	if _, err := ApplyProfile(map[string]any{"profile": "pci"}); err == nil || !strings.Contains(err.Error(), "soc2") {
+		t.Errorf("ApplyProfile(unknown) error = %v, want one listing the profiles", err)
+	}
+}
@@ -0,0 +1,234 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Collection phases named in progress updates.
+const (
+	PhaseRepositories     = "repositories"
+	PhaseSecuritySettings = "security_settings"
+	PhaseSurfaces         = "surfaces"
+)
+
+// Progress message formats (progress_format).
+const (
+	ProgressFormatText = "text"
+	ProgressFormatJSON = "json"
+)
+
+// validateProgressFormat rejects a progress_format other than text or json.
+func validateProgressFormat(format string) error {
+	switch format {
+	case "", ProgressFormatText, ProgressFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("invalid progress_format %q: want %q or %q", format, ProgressFormatText, ProgressFormatJSON)
+}
+
+// Progress is one progress update: the phase, repositories processed of
+// those known so far, the API request rate over the phase, and the estimated
+// time remaining. Total is 0 while unknown; ETA is 0 until it can be
+// estimated. Finished marks a phase's last update.
+type Progress struct {
+	Phase             string
+	Processed         int64
+	Total             int64
+	RequestsPerSecond float64
+	ETA               time.Duration
+	Detail            string
+	Finished          bool
+}
+
+// Progress event states.
+const (
+	ProgressStateRunning  = "running"
+	ProgressStateFinished = "finished"
+)
+
+// ProgressEvent is the machine-readable form of a Progress update, sent as
+// the progress message when progress_format is json so a runner can render
+// per-phase progress and partial stats. Percent is absent while the total is
+// unknown. Warnings and PermissionErrors count the diagnostics recorded so
+// far in the run.
+type ProgressEvent struct {
+	Phase             string  `json:"phase"`
+	State             string  `json:"state"`
+	Processed         int64   `json:"processed"`
+	Total             int64   `json:"total,omitempty"`
+	Percent           *int    `json:"percent,omitempty"`
+	RequestsPerSecond float64 `json:"requests_per_second,omitempty"`
+	ETASeconds        int64   `json:"eta_seconds,omitempty"`
+	Warnings          int     `json:"warnings"`
+	PermissionErrors  int     `json:"permission_errors"`
+	Detail            string  `json:"detail,omitempty"`
+}
+
+// event converts the update to a ProgressEvent carrying the diagnostics
+// recorded so far.
+func (p Progress) event(diag *diagnostics) ProgressEvent {
+	e := ProgressEvent{
+		Phase:             p.Phase,
+		State:             ProgressStateRunning,
+		Processed:         p.Processed,
+		Total:             p.Total,
+		RequestsPerSecond: p.RequestsPerSecond,
+		ETASeconds:        int64(p.ETA / time.Second),
+		Detail:            p.Detail,
+	}
+	if p.Finished {
+		e.State = ProgressStateFinished
+	}
+	if p.Total > 0 {
+		pct := percent(int(p.Processed), int(p.Total))
+		e.Percent = &pct
+	}
+	if diag != nil {
+		e.PermissionErrors, e.Warnings = diag.counts()
+	}
+	return e
+}
+
+// String renders the update as a single line for the runner, e.g.
+// "security_settings 120/480 repos, 4.2 req/s, ETA 1m25s: Checking security
+// settings for api".
+func (p Progress) String() string {
+	var b strings.Builder
+	b.WriteString(p.Phase)
+	if p.Total > 0 {
+		fmt.Fprintf(&b, " %d/%d repos", p.Processed, p.Total)
+	} else {
+		fmt.Fprintf(&b, " %d repos", p.Processed)
+	}
+	if p.RequestsPerSecond > 0 {
+		fmt.Fprintf(&b, ", %.1f req/s", p.RequestsPerSecond)
+	}
+	if p.ETA > 0 {
+		fmt.Fprintf(&b, ", ETA %s", p.ETA)
+	}
+	if p.Detail != "" {
+		b.WriteString(": ")
+		b.WriteString(p.Detail)
+	}
+	return b.String()
+}
+
+// progressMessage renders the update as the configured progress_format.
+func (c *Collector) progressMessage(p Progress) string {
+	if c.config.ProgressFormat != ProgressFormatJSON {
+		return p.String()
+	}
+	data, err := json.Marshal(p.event(c.progressDiag))
+	if err != nil {
+		return p.String()
+	}
+	return string(data)
+}
+
+// phaseStatus reports an update from a phase whose total isn't known: a
+// status line, or a progress event when progress_format is json.
+func (c *Collector) phaseStatus(p Progress) {
+	if c.config.ProgressFormat == ProgressFormatJSON {
+		c.progress(p)
+		return
+	}
+	c.status(p.String())
+}
+
+// phaseFinished reports a phase's final counts as a finished progress event
+// when progress_format is json. Text progress has no counterpart.
+func (c *Collector) phaseFinished(phase string, processed, total int64) {
+	if c.config.ProgressFormat == ProgressFormatJSON {
+		c.progress(Progress{Phase: phase, Processed: processed, Total: total, Finished: true})
+	}
+}
+
+// progressTracker derives rate and ETA for one phase from its start time and
+// the client's request count at that time.
+type progressTracker struct {
+	phase         string
+	start         time.Time
+	startRequests int
+	requests      func() int
+	now           func() time.Time
+}
+
+// newProgressTracker starts timing a phase. requests returns the client's
+// cumulative API request count.
+func newProgressTracker(phase string, requests func() int) *progressTracker {
+	return &progressTracker{
+		phase:         phase,
+		start:         time.Now(),
+		startRequests: requests(),
+		requests:      requests,
+		now:           time.Now,
+	}
+}
+
+// update builds the Progress for processed of total repositories. The ETA
+// assumes the phase's throughput so far holds for the rest; while the total
+// is still growing it is a lower bound.
+func (t *progressTracker) update(processed, total int64, detail string) Progress {
+	p := Progress{Phase: t.phase, Processed: processed, Total: total, Detail: detail}
+	elapsed := t.now().Sub(t.start)
+	if elapsed <= 0 {
+		return p
+	}
+	p.RequestsPerSecond = float64(t.requests()-t.startRequests) / elapsed.Seconds()
+	if processed > 0 && total > processed {
+		perRepo := elapsed / time.Duration(processed)
+		p.ETA = (perRepo * time.Duration(total-processed)).Round(time.Second)
+	}
+	return p
+}
+
+// startHeartbeat re-reports the last status whenever HeartbeatInterval
+// passes without an update, until the returned stop function is called. It
+// is a no-op when the interval or OnStatus is unset.
+func (c *Collector) startHeartbeat(ctx context.Context) (stop func()) {
+	interval := time.Duration(c.config.HeartbeatInterval) * time.Second
+	if interval <= 0 || c.config.OnStatus == nil {
+		return func() {}
+	}
+	c.reportMu.Lock()
+	c.lastReport = time.Now()
+	c.reportMu.Unlock()
+
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval / 2)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				c.heartbeat(now, interval)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// heartbeat sends a liveness update, naming the last reported status, when
+// nothing has been reported for interval.
+func (c *Collector) heartbeat(now time.Time, interval time.Duration) {
+	c.reportMu.Lock()
+	defer c.reportMu.Unlock()
+	silent := now.Sub(c.lastReport)
+	if silent < interval {
+		return
+	}
+	message := fmt.Sprintf("still collecting, no update for %s", silent.Round(time.Second))
+	if c.lastMessage != "" {
+		message += "; last: " + c.lastMessage
+	}
+	c.config.OnStatus(message)
+	c.lastReport = now
+}
@@ -0,0 +1,197 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack/componentsdk"
+)
+
+func TestProgressTracker_RateAndETA(t *testing.T) {
+	requests := 100
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := newProgressTracker(PhaseSecuritySettings, func() int { return requests })
+	tracker.start = start
+
+	// 40 requests over 10s for 25 of 100 repos: 4 req/s, 30s to go.
+	requests = 140
+	tracker.now = func() time.Time { return start.Add(10 * time.Second) }
+	p := tracker.update(25, 100, "Checking security settings for api")
+
+	if p.RequestsPerSecond != 4 {
+		t.Errorf("RequestsPerSecond = %v, want 4", p.RequestsPerSecond)
+	}
+	if p.ETA != 30*time.Second {
+		t.Errorf("ETA = %v, want 30s", p.ETA)
+	}
+	want := "security_settings 25/100 repos, 4.0 req/s, ETA 30s: Checking security settings for api"
+	if got := p.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestProgress_StringUnknownTotal(t *testing.T) {
+	p := Progress{Phase: PhaseRepositories, Processed: 300, Detail: "enumerating"}
+	if got, want := p.String(), "repositories 300 repos: enumerating"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestCollect_ProgressNamesPhase(t *testing.T) {
+	mock := &mockGitHubClient{
+		orgSecurity:  &github.OrgSecurity{},
+		repositories: []github.Repository{{Name: "api"}, {Name: "web"}},
+	}
+	var updates []string
+	var last, total int64
+	config := Config{
+		Organization: "test-org",
+		OnProgress: func(current, t int64, message string) {
+			last, total = current, t
+			updates = append(updates, message)
+		},
+	}
+	if _, err := NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelTrust); err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+
+	if len(updates) != 2 || last != 2 || total != 2 {
+		t.Fatalf("progress = %d updates ending %d/%d, want 2 ending 2/2", len(updates), last, total)
+	}
+	for _, msg := range updates {
+		if !strings.HasPrefix(msg, PhaseSecuritySettings+" ") {
+			t.Errorf("progress message %q does not name the phase", msg)
+		}
+	}
+}
+
+func TestCollect_ProgressEventsJSON(t *testing.T) {
+	mock := &mockGitHubClient{
+		orgSecurity:  &github.OrgSecurity{},
+		repositories: []github.Repository{{Name: "api"}, {Name: "web"}},
+		rulesetsErr:  fmt.Errorf("%w: rulesets", github.ErrPermissionDenied),
+	}
+	var events []ProgressEvent
+	config := Config{
+		Organization:   "test-org",
+		ProgressFormat: ProgressFormatJSON,
+		OnProgress: func(current, total int64, message string) {
+			var e ProgressEvent
+			if err := json.Unmarshal([]byte(message), &e); err != nil {
+				t.Fatalf("progress message %q is not a JSON event: %v", message, err)
+			}
+			events = append(events, e)
+		},
+	}
+	if _, err := NewWithClient(config, mock).Collect(context.Background(), componentsdk.LevelAudit); err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+
+	var phases []string
+	for _, e := range events {
+		if e.State == ProgressStateFinished {
+			phases = append(phases, e.Phase)
+		}
+	}
+	if want := []string{PhaseRepositories, PhaseSecuritySettings, PhaseSurfaces}; !slices.Equal(phases, want) {
+		t.Fatalf("finished phases = %v, want %v (events %+v)", phases, want, events)
+	}
+	var settings ProgressEvent
+	for _, e := range events {
+		if e.Phase == PhaseSecuritySettings && e.State == ProgressStateRunning {
+			settings = e
+		}
+	}
+	if settings.Processed != 2 || settings.Total != 2 || settings.Percent == nil || *settings.Percent != 100 {
+		t.Errorf("last security_settings event = %+v, want 2/2 at 100%%", settings)
+	}
+	if last := events[len(events)-1]; last.Phase != PhaseSurfaces || last.PermissionErrors == 0 {
+		t.Errorf("surfaces finished event = %+v, want the diagnostics recorded so far", last)
+	}
+
+	if _, err := New(Config{Organization: "test-org", GitHubToken: "t", ProgressFormat: "xml"}); err == nil {
+		t.Error("New() accepted progress_format xml")
+	}
+}
+
+func TestPhaseTimer_Stats(t *testing.T) {
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	timer := newPhaseTimer()
+	timer.now = func() time.Time { return clock }
+	timer.start = clock
+
+	endRepos := timer.begin(PhaseRepositories)
+	clock = clock.Add(1500 * time.Millisecond)
+	endSettings := timer.begin(PhaseSecuritySettings)
+	clock = clock.Add(time.Second)
+	endRepos()
+	clock = clock.Add(2 * time.Second)
+	endSettings()
+
+	var stats CollectionStats
+	timer.stats(&stats)
+	if stats.StartedAt != "2024-01-01T00:00:00.000Z" || stats.FinishedAt != "2024-01-01T00:00:04.500Z" || stats.DurationMs != 4500 {
+		t.Errorf("run timing = %s..%s (%dms)", stats.StartedAt, stats.FinishedAt, stats.DurationMs)
+	}
+	want := []PhaseTiming{
+		{Phase: PhaseRepositories, StartedAt: "2024-01-01T00:00:00.000Z", FinishedAt: "2024-01-01T00:00:02.500Z", DurationMs: 2500},
+		{Phase: PhaseSecuritySettings, StartedAt: "2024-01-01T00:00:01.500Z", FinishedAt: "2024-01-01T00:00:04.500Z", DurationMs: 3000},
+	}
+	if !slices.Equal(stats.Phases, want) {
+		t.Errorf("Phases = %+v, want %+v", stats.Phases, want)
+	}
+}
+
+func TestCollect_PhaseTimings(t *testing.T) {
+	phases := func(level componentsdk.Level) []string {
+		mock := &mockGitHubClient{orgSecurity: &github.OrgSecurity{}}
+		stats := collectWith(t, mock, level).CollectionStats
+		if stats.StartedAt == "" || stats.FinishedAt == "" {
+			t.Errorf("%s: run timestamps missing: %+v", level, stats)
+		}
+		var names []string
+		for _, p := range stats.Phases {
+			names = append(names, p.Phase)
+		}
+		return names
+	}
+	if got := phases(componentsdk.LevelTrust); !slices.Equal(got, []string{PhaseRepositories, PhaseSecuritySettings}) {
+		t.Errorf("trust phases = %v", got)
+	}
+	if got := phases(componentsdk.LevelAudit); !slices.Equal(got, []string{PhaseRepositories, PhaseSecuritySettings, PhaseSurfaces}) {
+		t.Errorf("audit phases = %v", got)
+	}
+}
+
+func TestHeartbeat_RepeatsLastStatusWhenSilent(t *testing.T) {
+	var got []string
+	c := NewWithClient(Config{Organization: "test-org", HeartbeatInterval: 30, OnStatus: func(m string) { got = append(got, m) }}, &mockGitHubClient{})
+	c.status("security_settings: checking repo-42")
+	start := c.lastReport
+
+	c.heartbeat(start.Add(10*time.Second), 30*time.Second)
+	if len(got) != 1 {
+		t.Fatalf("heartbeat within the interval sent %q", got[1:])
+	}
+	c.heartbeat(start.Add(45*time.Second), 30*time.Second)
+	if len(got) != 2 || !strings.Contains(got[1], "no update for 45s") || !strings.Contains(got[1], "last: security_settings: checking repo-42") {
+		t.Fatalf("heartbeat = %q, want a liveness update naming the last status", got[1:])
+	}
+	// The next beat waits a full interval again.
+	c.heartbeat(start.Add(60*time.Second), 30*time.Second)
+	if len(got) != 2 {
+		t.Errorf("heartbeat repeated too soon: %q", got[2:])
+	}
+}
+
+func TestStartHeartbeat_Disabled(t *testing.T) {
+	c := NewWithClient(Config{Organization: "test-org", OnStatus: func(string) { t.Error("unexpected status") }}, &mockGitHubClient{})
+	stop := c.startHeartbeat(context.Background())
+	stop()
+}
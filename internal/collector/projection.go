@@ -0,0 +1,129 @@
+package collector
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// identityFields are the top-level keys every projection keeps, so a
+// projected document still says what it describes.
+var identityFields = []string{"schema_version", "collected_at", "collected_at_level", "organization", "owner_type"}
+
+// validateOutputFields checks that each output_fields entry names a section
+// of the detailed artifact. Entries are dotted paths ("security_features" or
+// "security_features.per_repo"), prefixed with "-" to drop the section.
+// Only the top-level key is checked; nested keys vary with the level.
+func validateOutputFields(fields []string) error {
+	known := postureKeys()
+	for _, f := range fields {
+		path := strings.TrimPrefix(f, "-")
+		top, _, _ := strings.Cut(path, ".")
+		if !slices.Contains(known, top) {
+			return fmt.Errorf("output_fields: unknown section %q", f)
+		}
+		if strings.HasPrefix(f, "-") && slices.Contains(identityFields, path) {
+			return fmt.Errorf("output_fields: %q is always emitted", path)
+		}
+	}
+	return nil
+}
+
+// postureKeys returns the top-level JSON keys of OrgPosture.
+func postureKeys() []string {
+	t := reflect.TypeFor[OrgPosture]()
+	keys := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// ProjectFields applies output_fields to the detailed artifact. When any
+// entry selects a section, only the selected sections (and identityFields)
+// are kept; entries prefixed with "-" are then dropped. It returns posture
+// unchanged when fields is empty, and otherwise the projected JSON as a
+// json.RawMessage, with keys sorted.
+func ProjectFields(posture *OrgPosture, fields []string) (any, error) {
+	if len(fields) == 0 {
+		return posture, nil
+	}
+	raw, err := json.Marshal(posture)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var include, exclude [][]string
+	for _, f := range fields {
+		if path, ok := strings.CutPrefix(f, "-"); ok {
+			exclude = append(exclude, strings.Split(path, "."))
+		} else {
+			include = append(include, strings.Split(f, "."))
+		}
+	}
+	if len(include) > 0 {
+		selected := make(map[string]any)
+		for _, key := range identityFields {
+			if v, ok := doc[key]; ok {
+				selected[key] = v
+			}
+		}
+		for _, path := range include {
+			copyPath(doc, selected, path)
+		}
+		doc = selected
+	}
+	for _, path := range exclude {
+		deletePath(doc, path)
+	}
+	projected, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(projected), nil
+}
+
+// copyPath copies the value at path from src into dst, creating the
+// enclosing objects. Paths that don't resolve are skipped.
+func copyPath(src, dst map[string]any, path []string) {
+	v, ok := src[path[0]]
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		dst[path[0]] = v
+		return
+	}
+	child, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+	next, ok := dst[path[0]].(map[string]any)
+	if !ok {
+		next = make(map[string]any)
+		dst[path[0]] = next
+	}
+	copyPath(child, next, path[1:])
+}
+
+// deletePath removes the value at path. Paths that don't resolve are skipped.
+func deletePath(doc map[string]any, path []string) {
+	for _, key := range path[:len(path)-1] {
+		child, ok := doc[key].(map[string]any)
+		if !ok {
+			return
+		}
+		doc = child
+	}
+	delete(doc, path[len(path)-1])
+}
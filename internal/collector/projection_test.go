@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestProjectFields(t *testing.T) {
+	posture := &OrgPosture{
+		SchemaVersion: "1.0.0",
+		Organization:  "test-org",
+		Posture:       Posture{BranchProtectionCoverage: 50},
+		SecurityFeatures: SecurityFeatures{
+			PerRepo: []SecurityFeaturesRow{{Repository: "test-org/repo1"}},
+		},
+		Members: &Members{MemberCount: 2},
+	}
+	project := func(fields ...string) map[string]any {
+		t.Helper()
+		data, err := ProjectFields(posture, fields)
+		if err != nil {
+			t.Fatalf("ProjectFields(%v) error: %v", fields, err)
+		}
+		var doc map[string]any
+		if err := json.Unmarshal(data.(json.RawMessage), &doc); err != nil {
+			t.Fatalf("projection is not JSON: %v", err)
+		}
+		return doc
+	}
+	keys := func(m map[string]any) []string {
+		var out []string
+		for k := range m {
+			out = append(out, k)
+		}
+		slices.Sort(out)
+		return out
+	}
+
+	if data, _ := ProjectFields(posture, nil); data != any(posture) {
+		t.Error("no output_fields should return the posture unchanged")
+	}
+
+	doc := project("posture")
+	want := []string{"collected_at", "collected_at_level", "organization", "owner_type", "posture", "schema_version"}
+	if got := keys(doc); !slices.Equal(got, want) {
+		t.Errorf("keep posture: keys = %v, want %v", got, want)
+	}
+
+	doc = project("-security_features.per_repo", "-members")
+	if _, ok := doc["members"]; ok {
+		t.Error("members not dropped")
+	}
+	sf, _ := doc["security_features"].(map[string]any)
+	if _, ok := sf["per_repo"]; ok || sf == nil {
+		t.Errorf("security_features = %v, want it without per_repo", sf)
+	}
+	if _, ok := doc["branch_protection_rules"]; !ok {
+		t.Error("unselected sections must be kept when only dropping")
+	}
+
+	doc = project("security_features.per_repo", "members")
+	sf, _ = doc["security_features"].(map[string]any)
+	if got := keys(sf); !slices.Equal(got, []string{"per_repo"}) {
+		t.Errorf("security_features keys = %v, want only per_repo", got)
+	}
+	if _, ok := doc["members"]; !ok {
+		t.Error("members not kept")
+	}
+}
+
+func TestValidateOutputFields(t *testing.T) {
+	if err := validateOutputFields([]string{"posture", "-security_features.per_repo", "members.per_member"}); err != nil {
+		t.Errorf("valid fields rejected: %v", err)
+	}
+	for _, bad := range [][]string{{"postures"}, {"-nope.x"}, {"-organization"}} {
+		if err := validateOutputFields(bad); err == nil {
+			t.Errorf("validateOutputFields(%v) = nil, want error", bad)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package collector
+
+import (
+	"slices"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// effectiveProtection is a repository's default-branch protection with its
+// classic branch protection rule and the active org branch rulesets covering
+// the branch merged into one rule, so every metric counts a protection once
+// whichever mechanism provides it. Classic and Rulesets name the sources.
+type effectiveProtection struct {
+	github.BranchProtectionRule
+	Classic  bool
+	Rulesets []string
+}
+
+// resolveProtection merges a repository's classic default-branch rule with
+// the rulesets that apply to the branch, or returns nil when neither
+// protects it. Only active rulesets count: evaluate mode enforces nothing,
+// and rulesets targeting custom properties may not apply at all.
+//
+// A requirement holds when any source requires it, and review counts take
+// the strictest source. Force pushes and deletions stay allowed only while
+// no source blocks them. Admins are held to the branch's rules when the
+// classic rule enforces them or a covering ruleset has no bypass actors.
+func resolveProtection(repo github.Repository, rulesets []github.Ruleset) *effectiveProtection {
+	var ep *effectiveProtection
+	if bp := repo.DefaultBranchRef.BranchProtectionRule; bp != nil {
+		ep = &effectiveProtection{BranchProtectionRule: *bp, Classic: true}
+		ep.RequiredStatusCheckContexts = slices.Clone(bp.RequiredStatusCheckContexts)
+	}
+	for _, rs := range rulesets {
+		if rs.Enforcement != github.RulesetEnforcementActive || rs.Target == github.RulesetTargetRepository ||
+			matchRuleset(rs, repo) != rulesetApplies {
+			continue
+		}
+		if ep == nil {
+			// Without a classic rule nothing blocks force pushes or
+			// deletions until a ruleset does.
+			ep = &effectiveProtection{}
+			ep.AllowsForcePushes, ep.AllowsDeletions = true, true
+		}
+		ep.Rulesets = append(ep.Rulesets, rs.Name)
+		if len(rs.BypassActors) == 0 {
+			ep.IsAdminEnforced = true
+		}
+		for _, rule := range rs.Rules {
+			ep.applyRule(rule)
+		}
+	}
+	return ep
+}
+
+// applyRule folds one ruleset rule into the merged protection.
+func (ep *effectiveProtection) applyRule(rule github.RulesetRule) {
+	switch rule.Type {
+	case github.RuleTypePullRequest:
+		ep.RequiresApprovingReviews = true
+		if pr := rule.PullRequest; pr != nil {
+			ep.RequiredApprovingReviewCount = max(ep.RequiredApprovingReviewCount, pr.RequiredApprovingReviewCount)
+			ep.DismissesStaleReviews = ep.DismissesStaleReviews || pr.DismissStaleReviewsOnPush
+			ep.RequiresCodeOwnerReviews = ep.RequiresCodeOwnerReviews || pr.RequireCodeOwnerReview
+			ep.RequireLastPushApproval = ep.RequireLastPushApproval || pr.RequireLastPushApproval
+			ep.RequiresConversationResolution = ep.RequiresConversationResolution || pr.RequiredReviewThreadResolution
+		}
+	case github.RuleTypeRequiredStatusChecks:
+		ep.RequiresStatusChecks = true
+		for _, check := range rule.StatusChecks {
+			if !slices.Contains(ep.RequiredStatusCheckContexts, check) {
+				ep.RequiredStatusCheckContexts = append(ep.RequiredStatusCheckContexts, check)
+			}
+		}
+	case github.RuleTypeRequiredSignatures:
+		ep.RequiresCommitSignatures = true
+	case github.RuleTypeRequiredLinearHistory:
+		ep.RequiresLinearHistory = true
+	case github.RuleTypeNonFastForward:
+		ep.AllowsForcePushes = false
+	case github.RuleTypeDeletion:
+		ep.AllowsDeletions = false
+	}
+}
+
+// protection returns a repository's effective default-branch protection
+// under the org rulesets read for the run, or nil when it has none.
+func (m *metricsAggregator) protection(repo github.Repository) *effectiveProtection {
+	return resolveProtection(repo, m.rulesets)
+}
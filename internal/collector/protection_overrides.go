@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// Audit actions counted as overriding or weakening branch protection, by the
+// ProtectionOverrides count they feed.
+var (
+	protectionOverrideActions = []string{"protected_branch.policy_override"}
+	protectionLoosenActions   = []string{
+		"protected_branch.update_allow_force_pushes_enforcement_level",
+		"protected_branch.update_allow_deletions_enforcement_level",
+		"protected_branch.update_admin_enforced",
+	}
+	protectionRemoveActions = []string{"protected_branch.destroy", "repository_ruleset.destroy"}
+)
+
+// protectionOverrides counts the audit events in the window that bypassed or
+// loosened branch protection: pushes that overrode a protection rule (force
+// pushes included, and also counted on their own), changes allowing force
+// pushes or deletions or touching admin enforcement, and protection rules or
+// rulesets being removed.
+// Org-level ruleset events name no repository and so aren't counted in
+// AffectedRepos.
+func protectionOverrides(events []github.AuditEvent) *ProtectionOverrides {
+	po := &ProtectionOverrides{}
+	repos := map[string]bool{}
+	for _, e := range events {
+		switch {
+		case slices.Contains(protectionOverrideActions, e.Action):
+			po.PolicyOverrides++
+			if e.ForcePush {
+				po.ForcePushes++
+			}
+		case slices.Contains(protectionLoosenActions, e.Action):
+			po.ProtectionsLoosened++
+		case slices.Contains(protectionRemoveActions, e.Action):
+			po.ProtectionsRemoved++
+		default:
+			continue
+		}
+		if e.Repo != "" {
+			repos[strings.ToLower(e.Repo)] = true
+		}
+	}
+	po.AffectedRepos = len(repos)
+	return po
+}
@@ -0,0 +1,43 @@
+package collector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack/componentsdk"
+)
+
+func TestProtectionOverrides(t *testing.T) {
+	events := []github.AuditEvent{
+		{Action: "protected_branch.policy_override", Repo: "test-org/api"},
+		{Action: "protected_branch.policy_override", Repo: "Test-Org/API", ForcePush: true},
+		{Action: "protected_branch.update_allow_force_pushes_enforcement_level", Repo: "test-org/web"},
+		{Action: "protected_branch.destroy", Repo: "test-org/web"},
+		{Action: "repository_ruleset.destroy"},
+		{Action: "protected_branch.create", Repo: "test-org/docs"},
+		{Action: "member_add"},
+	}
+	want := ProtectionOverrides{PolicyOverrides: 2, ForcePushes: 1, ProtectionsLoosened: 1, ProtectionsRemoved: 2, AffectedRepos: 2}
+	if got := protectionOverrides(events); *got != want {
+		t.Errorf("protectionOverrides() = %+v, want %+v", *got, want)
+	}
+}
+
+func TestSurfaces_RecentChangesProtectionOverrides(t *testing.T) {
+	mock := richMock()
+	mock.auditEvents = append(mock.auditEvents, github.AuditEvent{
+		Action: "protected_branch.policy_override", Actor: "alice", Repo: "test-org/repo1", CreatedAt: 1700000200, ForcePush: true,
+	})
+	posture, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	rc := posture.RecentChanges
+	if rc == nil || rc.WindowDays != AuditLogWindowDays {
+		t.Fatalf("RecentChanges = %+v, want the audit log's window", rc)
+	}
+	if po := rc.ProtectionOverrides; po == nil || po.PolicyOverrides != 1 || po.ForcePushes != 1 || po.AffectedRepos != 1 {
+		t.Errorf("ProtectionOverrides = %+v, want one force-push override on one repo", po)
+	}
+}
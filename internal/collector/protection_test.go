@@ -0,0 +1,187 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack/componentsdk"
+)
+
+func TestResolveProtection(t *testing.T) {
+	var defaultBranch, otherBranch github.RulesetConditions
+	_ = json.Unmarshal([]byte(`{"ref_name":{"include":["~DEFAULT_BRANCH"]},"repository_name":{"include":["~ALL"]}}`), &defaultBranch)
+	_ = json.Unmarshal([]byte(`{"ref_name":{"include":["refs/heads/release/*"]},"repository_name":{"include":["~ALL"]}}`), &otherBranch)
+	admins := []struct {
+		ActorType string `json:"actor_type"`
+	}{{ActorType: "OrganizationAdmin"}}
+
+	reviews := github.Ruleset{Name: "reviews", Target: "branch", Enforcement: "active", Conditions: defaultBranch, BypassActors: admins, Rules: []github.RulesetRule{
+		{Type: github.RuleTypePullRequest, PullRequest: &github.PullRequestRuleOptions{RequiredApprovingReviewCount: 3, RequireCodeOwnerReview: true}},
+		{Type: github.RuleTypeRequiredStatusChecks, StatusChecks: []string{"ci/test", "lint"}},
+	}}
+	lockdown := github.Ruleset{Name: "lockdown", Target: "branch", Enforcement: "active", Conditions: defaultBranch, Rules: []github.RulesetRule{
+		{Type: github.RuleTypeNonFastForward},
+		{Type: github.RuleTypeDeletion},
+		{Type: github.RuleTypeRequiredSignatures},
+	}}
+	trial := lockdown
+	trial.Name, trial.Enforcement = "trial", "evaluate"
+	release := lockdown
+	release.Name, release.Conditions = "release", otherBranch
+
+	classic := &github.BranchProtectionRule{
+		RequiresApprovingReviews:     true,
+		RequiredApprovingReviewCount: 1,
+		RequiresStatusChecks:         true,
+		RequiredStatusCheckContexts:  []string{"ci/test"},
+		AllowsForcePushes:            true,
+	}
+	tests := []struct {
+		name     string
+		classic  *github.BranchProtectionRule
+		rulesets []github.Ruleset
+		want     *effectiveProtection
+	}{
+		{name: "unprotected"},
+		{name: "classic only", classic: classic, want: &effectiveProtection{BranchProtectionRule: *classic, Classic: true}},
+		{name: "evaluate-mode and other-branch rulesets don't protect", rulesets: []github.Ruleset{trial, release}},
+		{
+			name:     "ruleset only",
+			rulesets: []github.Ruleset{lockdown},
+			want: &effectiveProtection{
+				BranchProtectionRule: github.BranchProtectionRule{RequiresCommitSignatures: true, IsAdminEnforced: true},
+				Rulesets:             []string{"lockdown"},
+			},
+		},
+		{
+			name:     "bypassable ruleset allows force pushes and deletions it doesn't block",
+			rulesets: []github.Ruleset{reviews},
+			want: &effectiveProtection{
+				BranchProtectionRule: github.BranchProtectionRule{
+					RequiresApprovingReviews:     true,
+					RequiredApprovingReviewCount: 3,
+					RequiresCodeOwnerReviews:     true,
+					RequiresStatusChecks:         true,
+					RequiredStatusCheckContexts:  []string{"ci/test", "lint"},
+					AllowsForcePushes:            true,
+					AllowsDeletions:              true,
+				},
+				Rulesets: []string{"reviews"},
+			},
+		},
+		{
+			name:     "classic and rulesets merged",
+			classic:  classic,
+			rulesets: []github.Ruleset{reviews, lockdown, trial},
+			want: &effectiveProtection{
+				BranchProtectionRule: github.BranchProtectionRule{
+					RequiresApprovingReviews:     true,
+					RequiredApprovingReviewCount: 3,
+					RequiresCodeOwnerReviews:     true,
+					RequiresStatusChecks:         true,
+					RequiredStatusCheckContexts:  []string{"ci/test", "lint"},
+					RequiresCommitSignatures:     true,
+					IsAdminEnforced:              true,
+				},
+				Classic:  true,
+				Rulesets: []string{"reviews", "lockdown"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := github.Repository{Name: "api"}
+			repo.DefaultBranchRef.Name = "main"
+			repo.DefaultBranchRef.BranchProtectionRule = tt.classic
+			if got := resolveProtection(repo, tt.rulesets); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveProtection() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+	if len(classic.RequiredStatusCheckContexts) != 1 {
+		t.Errorf("classic rule modified: %v", classic.RequiredStatusCheckContexts)
+	}
+}
+
+func TestCollect_RulesetProtectionCounted(t *testing.T) {
+	var onlyRepo2 github.RulesetConditions
+	_ = json.Unmarshal([]byte(`{"ref_name":{"include":["~DEFAULT_BRANCH"]},"repository_name":{"include":["repo2"]}}`), &onlyRepo2)
+	mock := richMock()
+	mock.repositories[1].DefaultBranchRef.BranchProtectionRule = nil
+	mock.rulesets = []github.Ruleset{{Name: "signed", Target: "branch", Enforcement: "active", Conditions: onlyRepo2, Rules: []github.RulesetRule{
+		{Type: github.RuleTypeRequiredSignatures},
+		{Type: github.RuleTypeNonFastForward},
+	}}}
+
+	trust, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if got := trust.Posture.BranchProtectionCoverage; got != 100 {
+		t.Errorf("BranchProtectionCoverage = %d, want 100 with repo2 protected by a ruleset", got)
+	}
+	bp := trust.BranchProtectionRules
+	if bp.SignedCommits != 50 || bp.ApprovingReviews != 50 || bp.AdminEnforcement != 50 || bp.ForcePushesAllowed != 0 {
+		t.Errorf("BranchProtectionRules = %+v", bp)
+	}
+
+	audit, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if audit.Repositories.DefaultProtected != 2 {
+		t.Errorf("DefaultProtected = %d, want 2", audit.Repositories.DefaultProtected)
+	}
+	for _, row := range audit.Repositories.PerRepo {
+		if row.Name != "test-org/repo2" {
+			continue
+		}
+		want := &BranchProtectionDetail{Rulesets: []string{"signed"}, RequiresCommitSignatures: true, IsAdminEnforced: true, AllowsDeletions: true}
+		if !reflect.DeepEqual(row.BranchProtection, want) {
+			t.Errorf("repo2 BranchProtection = %+v, want %+v", row.BranchProtection, want)
+		}
+	}
+}
+
+func TestCollect_RulesetsDeniedBelowAudit(t *testing.T) {
+	denied := func() *mockGitHubClient {
+		mock := richMock()
+		mock.rulesetsErr = fmt.Errorf("%w: rulesets", github.ErrPermissionDenied)
+		return mock
+	}
+
+	trust, err := NewWithClient(Config{Organization: "test-org", StrictMode: true}, denied()).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("strict trust Collect() error: %v", err)
+	}
+	if d := trust.Diagnostics; d == nil || len(d.PermissionErrors) != 0 || !anyContains(d.Warnings, "rulesets: not readable") {
+		t.Errorf("trust Diagnostics = %+v, want a rulesets warning and no permission errors", d)
+	}
+
+	_, err = NewWithClient(Config{Organization: "test-org", StrictMode: true}, denied()).Collect(context.Background(), componentsdk.LevelAudit)
+	if !errors.Is(err, ErrDegraded) || !strings.Contains(err.Error(), "organization_administration:read") {
+		t.Errorf("strict audit Collect() error = %v, want ErrDegraded naming organization_administration:read", err)
+	}
+}
+
+func TestCollect_RulesetsTruncated(t *testing.T) {
+	mock := richMock()
+	mock.rulesetsTruncated = true
+
+	posture, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if d := posture.Diagnostics; d == nil || !anyContains(d.Warnings, "rulesets: truncated") {
+		t.Errorf("Diagnostics = %+v, want a ruleset truncation warning", d)
+	}
+	if rs := posture.Rulesets; rs == nil || !rs.Truncated {
+		t.Errorf("Rulesets = %+v, want the counts marked truncated", rs)
+	}
+}
@@ -0,0 +1,55 @@
+package collector
+
+import (
+	"slices"
+	"strings"
+)
+
+// collectPublicExposure counts wikis on public repositories, from the
+// repository data captured during the trust pass, and public org projects.
+// Audit emits the counts; internal adds the repository and project names.
+// Projects are org-only and degrade on their own: a denial leaves their
+// counts unknown while the wiki counts are still emitted.
+func (c *Collector) collectPublicExposure(p *collectionPass) {
+	exposure := &PublicExposure{}
+
+	for r := range p.metrics.repos.all() {
+		if !r.HasWikiEnabled {
+			continue
+		}
+		if strings.EqualFold(r.Visibility, "PUBLIC") {
+			exposure.PublicWikiCount++
+			if p.internal() {
+				exposure.PublicWikiRepos = append(exposure.PublicWikiRepos, r.Owner.Login+"/"+r.Name)
+			}
+		} else {
+			exposure.PrivateWikiCount++
+		}
+	}
+	slices.Sort(exposure.PublicWikiRepos)
+
+	if !p.user {
+		projects, err := c.client.ListOrgProjects(p.ctx, p.org)
+		if err != nil {
+			if isDenied(err) {
+				p.metrics.diag.surfacePermissionDenied("public_exposure.projects", "organization_projects:read")
+			}
+		} else {
+			total, public := len(projects), 0
+			for _, project := range projects {
+				if !project.Public {
+					continue
+				}
+				public++
+				if p.internal() {
+					exposure.PublicProjects = append(exposure.PublicProjects, project.Title)
+				}
+			}
+			slices.Sort(exposure.PublicProjects)
+			exposure.ProjectCount = &total
+			exposure.PublicProjectCount = &public
+		}
+	}
+
+	p.posture.PublicExposure = exposure
+}
@@ -0,0 +1,181 @@
+package collector
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// redactedRepoPrefix marks a redacted repository name.
+const redactedRepoPrefix = "repo-"
+
+// repoRedactor replaces repository names with stable pseudonyms: the same
+// repository maps to the same value in every section and on every run, so
+// rows still join and trend, but the name itself isn't shipped.
+type repoRedactor struct {
+	org string
+}
+
+// name returns the pseudonym for a repository given as "owner/name" or as a
+// bare name owned by the org. Matching is case-insensitive, like GitHub's.
+func (r repoRedactor) name(repo string) string {
+	if repo == "" {
+		return ""
+	}
+	if !strings.Contains(repo, "/") {
+		repo = r.org + "/" + repo
+	}
+	sum := sha256.Sum256([]byte(strings.ToLower(repo)))
+	return redactedRepoPrefix + hex.EncodeToString(sum[:8])
+}
+
+// Redact replaces the repository names in posture's per-repo fields with
+// pseudonyms when redact_repo_names is set. Collect leaves the names in, as
+// Notify and FileRemediation need them to name what to fix; call Redact after
+// those and before the posture is emitted.
+func (c *Collector) Redact(posture *OrgPosture) {
+	if c.config.RedactRepoNames {
+		redactRepoNames(posture)
+	}
+}
+
+// redactRepoNames rewrites every per-repo field of posture with its
+// pseudonym. Aggregates carry no names and are left untouched. Repository
+// descriptions and topics are dropped, since they often restate the name.
+func redactRepoNames(posture *OrgPosture) {
+	r := repoRedactor{org: posture.Organization}
+
+	scope := &posture.Scope
+	for i := range scope.SkippedRepositories {
+		scope.SkippedRepositories[i].Repository = r.name(scope.SkippedRepositories[i].Repository)
+	}
+	for i := range scope.ExplicitRepositories {
+		scope.ExplicitRepositories[i] = r.name(scope.ExplicitRepositories[i])
+	}
+
+	for i := range posture.SecurityFeatures.PerRepo {
+		row := &posture.SecurityFeatures.PerRepo[i]
+		row.Repository = r.name(row.Repository)
+	}
+	for i := range posture.SecurityFeatures.SecretScanningHotspots {
+		row := &posture.SecurityFeatures.SecretScanningHotspots[i]
+		row.Repository = r.name(row.Repository)
+	}
+	if f := posture.SecurityFeatures.Findings; f != nil {
+		for i := range f.SecretScanning {
+			f.SecretScanning[i].Repository = r.name(f.SecretScanning[i].Repository)
+		}
+		for i := range f.CodeScanning {
+			f.CodeScanning[i].Repository = r.name(f.CodeScanning[i].Repository)
+		}
+		for i := range f.Dependabot {
+			f.Dependabot[i].Repository = r.name(f.Dependabot[i].Repository)
+		}
+	}
+	if repos := posture.Repositories; repos != nil {
+		for i := range repos.PerRepo {
+			row := &repos.PerRepo[i]
+			row.Name = r.name(row.Name)
+			row.Description = ""
+			row.Topics = nil
+		}
+	}
+	if co := posture.Codeowners; co != nil {
+		for i := range co.PerRepo {
+			co.PerRepo[i].Repository = r.name(co.PerRepo[i].Repository)
+		}
+	}
+	if wh := posture.Webhooks; wh != nil {
+		for i := range wh.Repo {
+			wh.Repo[i].Repository = r.name(wh.Repo[i].Repository)
+		}
+	}
+	if dk := posture.DeployKeys; dk != nil {
+		for i := range dk.PerKey {
+			dk.PerKey[i].Repository = r.name(dk.PerKey[i].Repository)
+		}
+	}
+	if vm := posture.VulnerabilityManagement; vm != nil {
+		for i := range vm.PerRepo {
+			vm.PerRepo[i].Repository = r.name(vm.PerRepo[i].Repository)
+		}
+		if at := vm.AutoTriage; at != nil {
+			for i := range at.PerRepo {
+				at.PerRepo[i].Repository = r.name(at.PerRepo[i].Repository)
+			}
+		}
+	}
+	if t := posture.Triage; t != nil {
+		for i := range t.PerRepo {
+			t.PerRepo[i].Repository = r.name(t.PerRepo[i].Repository)
+		}
+	}
+	if a := posture.Actions; a != nil {
+		for i := range a.RepoRunners {
+			a.RepoRunners[i].Repository = r.name(a.RepoRunners[i].Repository)
+		}
+		if e := a.PublicRepoExposure; e != nil {
+			for i, name := range e.PublicRepos {
+				e.PublicRepos[i] = r.name(name)
+			}
+		}
+	}
+	if sm := posture.SecretsManagement; sm != nil && sm.RepositorySecrets != nil {
+		for i := range sm.RepositorySecrets.PerRepo {
+			row := &sm.RepositorySecrets.PerRepo[i]
+			row.Repository = r.name(row.Repository)
+		}
+	}
+	if rs := posture.Rulesets; rs != nil && rs.RepositoryRules != nil {
+		rr := rs.RepositoryRules
+		for _, cov := range []*RepositoryRuleCoverage{&rr.DeletionRestricted, &rr.VisibilityRestricted} {
+			for i, name := range cov.UnenforcedRepos {
+				cov.UnenforcedRepos[i] = r.name(name)
+			}
+		}
+	}
+	if pe := posture.PublicExposure; pe != nil {
+		for i := range pe.PublicWikiRepos {
+			pe.PublicWikiRepos[i] = r.name(pe.PublicWikiRepos[i])
+		}
+	}
+	if sc := posture.SupplyChain; sc != nil {
+		if dr := sc.DependencyReview; dr != nil {
+			for i, name := range dr.UnenforcedRepos {
+				dr.UnenforcedRepos[i] = r.name(name)
+			}
+		}
+		if att := sc.Attestations; att != nil {
+			for i := range att.PerRepo {
+				att.PerRepo[i].Repository = r.name(att.PerRepo[i].Repository)
+			}
+		}
+		if ri := sc.Releases; ri != nil {
+			for i := range ri.PerRepo {
+				ri.PerRepo[i].Repository = r.name(ri.PerRepo[i].Repository)
+			}
+		}
+	}
+	if as := posture.ActionsSecurity; as != nil && as.RequiredWorkflows != nil {
+		for i, name := range as.RequiredWorkflows.UncoveredRepos {
+			as.RequiredWorkflows.UncoveredRepos[i] = r.name(name)
+		}
+	}
+	if as := posture.ActionsSecurity; as != nil && as.HardcodedCredentials != nil {
+		for i := range as.HardcodedCredentials.Files {
+			row := &as.HardcodedCredentials.Files[i]
+			row.Repository = r.name(row.Repository)
+		}
+	}
+	if rc := posture.RepositoryChanges; rc != nil {
+		for i := range rc.Changes {
+			rc.Changes[i].Repository = r.name(rc.Changes[i].Repository)
+			rc.Changes[i].NewName = r.name(rc.Changes[i].NewName)
+		}
+	}
+	if al := posture.AuditLog; al != nil {
+		for i := range al.Events {
+			al.Events[i].Repo = r.name(al.Events[i].Repo)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package collector
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// Release sampling bounds for the supply-chain release checks.
+const (
+	// RecentReleaseCount is how many of a repository's newest releases the
+	// release integrity checks examine.
+	RecentReleaseCount = 5
+	// AttestationAssetCap bounds how many assets of a release are checked
+	// for attestations.
+	AttestationAssetCap = 20
+	// ReleaseRepoCap is the default MaxReleaseRepos: how many in-scope
+	// repositories the release checks examine.
+	ReleaseRepoCap = 200
+)
+
+// repoReleases is one in-scope repository's recent published releases,
+// newest first.
+type repoReleases struct {
+	repository string // owner/name
+	owner      string
+	name       string
+	releases   []github.Release
+}
+
+// loadReleases lists the recent releases of the in-scope repositories that
+// have any, in repository order, when CollectReleases is set. Only the first
+// MaxReleaseRepos repositories are checked; truncated reports that more were
+// in scope. A permission denial stops the pass with a diagnostic and ok
+// false; other per-repository failures skip that repository and are counted
+// in a diagnostic.
+func (c *Collector) loadReleases(p *collectionPass) (repos []repoReleases, truncated, ok bool) {
+	if !c.config.CollectReleases {
+		return nil, false, false
+	}
+	limit := c.config.MaxReleaseRepos
+	if limit <= 0 {
+		limit = ReleaseRepoCap
+	}
+	checked, failed := 0, 0
+	var firstErr error
+	for repo := range p.metrics.repos.all() {
+		if checked == limit {
+			truncated = true
+			break
+		}
+		checked++
+		owner, name := repo.Owner.Login, repo.Name
+		releases, err := c.client.ListRecentReleases(p.ctx, owner, name, RecentReleaseCount)
+		if err != nil {
+			if isDenied(err) {
+				p.metrics.diag.surfacePermissionDenied("supply_chain releases", "contents:read")
+				return nil, false, false
+			}
+			if failed++; firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if len(releases) > 0 {
+			repos = append(repos, repoReleases{repository: owner + "/" + name, owner: owner, name: name, releases: releases})
+		}
+	}
+	if truncated {
+		p.metrics.diag.addWarning(fmt.Sprintf("supply_chain releases: truncated at %d of %d in-scope repositories (max_release_repos)", limit, p.metrics.repos.len()))
+	}
+	if failed > 0 {
+		p.metrics.diag.addWarning(fmt.Sprintf("supply_chain releases: %d repositories skipped, releases couldn't be listed: %v", failed, firstErr))
+	}
+	slices.SortFunc(repos, func(a, b repoReleases) int {
+		return strings.Compare(a.repository, b.repository)
+	})
+	return repos, truncated, true
+}
+
+// attestations reports adoption of GitHub artifact attestations: of the
+// in-scope repositories with a published release, how many have an
+// attestation for at least one asset of their latest release, looked up by
+// the asset's digest. Audit emits the counts; internal adds per-repository
+// rows. Nil when attestations couldn't be read; lookups that fail otherwise
+// are counted in a diagnostic.
+func (c *Collector) attestations(p *collectionPass, repos []repoReleases) *Attestations {
+	att := &Attestations{ReposWithReleases: len(repos)}
+	failed := 0
+	var firstErr error
+	for _, r := range repos {
+		latest := r.releases[0]
+		row := AttestationRow{Repository: r.repository, Release: latest.TagName, Assets: len(latest.Assets)}
+		for i, asset := range latest.Assets {
+			if i == AttestationAssetCap {
+				break
+			}
+			if asset.Digest == "" {
+				continue
+			}
+			attested, err := c.client.HasAttestations(p.ctx, r.owner, r.name, asset.Digest)
+			if err != nil {
+				if isDenied(err) {
+					p.metrics.diag.surfacePermissionDenied("supply_chain.attestations", "attestations:read")
+					return nil
+				}
+				if failed++; firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			if attested {
+				row.AttestedAssets++
+			}
+		}
+		if row.AttestedAssets > 0 {
+			att.AttestedRepos++
+		}
+		if p.internal() {
+			att.PerRepo = append(att.PerRepo, row)
+		}
+	}
+	if failed > 0 {
+		p.metrics.diag.addWarning(fmt.Sprintf("supply_chain.attestations: %d asset lookups failed, counted as unattested: %v", failed, firstErr))
+	}
+	att.Adoption = percent(att.AttestedRepos, att.ReposWithReleases)
+	return att
+}
+
+// Asset name markers for release integrity material, matched case-
+// insensitively against the end of the asset name.
+var (
+	signatureSuffixes = []string{".sig", ".asc", ".gpg", ".sigstore", ".sigstore.json", ".pem", ".minisig"}
+	checksumSuffixes  = []string{".sha256", ".sha512", ".sha256sum", ".sha512sum", ".md5", "checksums.txt", "sha256sums", "sha512sums", "shasums", ".checksums"}
+)
+
+// releaseIntegrity checks the recent releases of each in-scope repository
+// for signature and checksum assets and for GitHub's immutable-release flag.
+// Audit emits release counts; internal adds per-repository rows.
+func releaseIntegrity(p *collectionPass, repos []repoReleases) *ReleaseIntegrity {
+	ri := &ReleaseIntegrity{ReposWithReleases: len(repos)}
+	for _, r := range repos {
+		row := ReleaseIntegrityRow{Repository: r.repository, Releases: len(r.releases)}
+		for _, release := range r.releases {
+			if hasAssetWithSuffix(release.Assets, signatureSuffixes) {
+				row.Signed++
+			}
+			if hasAssetWithSuffix(release.Assets, checksumSuffixes) {
+				row.Checksummed++
+			}
+			switch {
+			case release.Immutable == nil:
+				row.ImmutableUnknown++
+			case *release.Immutable:
+				row.Immutable++
+			}
+		}
+		ri.ReleasesChecked += row.Releases
+		ri.SignedReleases += row.Signed
+		ri.ChecksummedReleases += row.Checksummed
+		ri.ImmutableReleases += row.Immutable
+		ri.ImmutableUnknown += row.ImmutableUnknown
+		if p.internal() {
+			ri.PerRepo = append(ri.PerRepo, row)
+		}
+	}
+	return ri
+}
+
+// hasAssetWithSuffix reports whether any asset name ends with one of the
+// suffixes, ignoring case.
+func hasAssetWithSuffix(assets []github.ReleaseAsset, suffixes []string) bool {
+	for _, asset := range assets {
+		name := strings.ToLower(asset.Name)
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(name, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,373 @@
+package collector
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// Remediation trackers accepted by remediation_tracker.
+const (
+	RemediationTrackerGitHub = "github"
+	RemediationTrackerJira   = "jira"
+)
+
+// Remediation ticket groupings accepted by remediation_group_by.
+const (
+	RemediationGroupByRule       = "rule"
+	RemediationGroupByRepository = "repository"
+)
+
+// remediationLabel marks the tickets the collector files, so it only ever
+// matches and updates its own.
+const remediationLabel = "epack-remediation"
+
+// remediationMaxRepos bounds the repositories one rule ticket lists.
+const remediationMaxRepos = 200
+
+// remediationTicket is one ticket's content. Key, the dedup key, leads the
+// title in brackets so a later run finds the ticket by title alone.
+type remediationTicket struct {
+	Key         string
+	Title       string
+	Description string
+}
+
+// ticketTracker files and updates remediation tickets.
+type ticketTracker interface {
+	// openTickets maps the dedup keys of the open remediation tickets to
+	// their tracker IDs.
+	openTickets(ctx context.Context) (map[string]string, error)
+	create(ctx context.Context, t remediationTicket) error
+	update(ctx context.Context, id string, t remediationTicket) error
+	// resolve closes a ticket whose violation no longer holds.
+	resolve(ctx context.Context, id string) error
+}
+
+// validateRemediation checks the remediation options for the chosen
+// tracker.
+func validateRemediation(config Config) error {
+	switch config.RemediationGroupBy {
+	case "", RemediationGroupByRule, RemediationGroupByRepository:
+	default:
+		return fmt.Errorf("remediation_group_by: must be %s or %s, got %q", RemediationGroupByRule, RemediationGroupByRepository, config.RemediationGroupBy)
+	}
+	switch config.RemediationTracker {
+	case "":
+		return nil
+	case RemediationTrackerGitHub:
+		if owner, name, ok := strings.Cut(config.RemediationRepository, "/"); !ok || owner == "" || name == "" {
+			return fmt.Errorf("remediation_repository: must be owner/name, got %q", config.RemediationRepository)
+		}
+	case RemediationTrackerJira:
+		if u, err := url.Parse(config.JiraURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("jira_url: must be an https URL, got %q", config.JiraURL)
+		}
+		if config.JiraProject == "" || config.JiraEmail == "" || config.JiraAPIToken == "" {
+			return errors.New("remediation_tracker jira requires jira_project, jira_email, and JIRA_API_TOKEN")
+		}
+	default:
+		return fmt.Errorf("remediation_tracker: must be %s or %s, got %q", RemediationTrackerGitHub, RemediationTrackerJira, config.RemediationTracker)
+	}
+	return nil
+}
+
+// FileRemediation files a ticket for each current policy violation (a
+// repository lacking branch protection or a security feature), one per rule
+// or per repository as remediation_group_by says, with the configured
+// tracker. A violation that already has an open ticket updates it instead,
+// and an open ticket whose controls this run found compliant is closed.
+// Violations need per-repo detail, so nothing is filed at trust level, and
+// an aborted, cancelled, or truncated run files and closes nothing. Without
+// a tracker it does nothing.
+func (c *Collector) FileRemediation(ctx context.Context, posture *OrgPosture) error {
+	if c.config.RemediationTracker == "" {
+		return nil
+	}
+	if partialRun(posture) {
+		c.status("Remediation tickets skipped: the collection was incomplete")
+		return nil
+	}
+	tracker := c.tracker()
+	open, err := tracker.openTickets(ctx)
+	if err != nil {
+		return fmt.Errorf("listing remediation tickets: %w", err)
+	}
+	var errs []error
+	created, updated, resolved := 0, 0, 0
+	tickets, evaluated := remediationTickets(posture, c.config.RemediationGroupBy)
+	failing := make(map[string]bool, len(tickets))
+	for _, t := range tickets {
+		failing[t.Key] = true
+		if id, ok := open[t.Key]; ok {
+			if err := tracker.update(ctx, id, t); err != nil {
+				errs = append(errs, fmt.Errorf("updating remediation ticket %s: %w", id, err))
+				continue
+			}
+			updated++
+			continue
+		}
+		if err := tracker.create(ctx, t); err != nil {
+			errs = append(errs, fmt.Errorf("filing remediation ticket: %w", err))
+			continue
+		}
+		created++
+	}
+	for _, key := range slices.Sorted(maps.Keys(open)) {
+		if !evaluated[key] || failing[key] {
+			continue
+		}
+		if err := tracker.resolve(ctx, open[key]); err != nil {
+			errs = append(errs, fmt.Errorf("closing remediation ticket %s: %w", open[key], err))
+			continue
+		}
+		resolved++
+	}
+	c.status(fmt.Sprintf("Remediation tickets: %d filed, %d updated, %d closed", created, updated, resolved))
+	return errors.Join(errs...)
+}
+
+// tracker returns the configured ticket tracker.
+func (c *Collector) tracker() ticketTracker {
+	if c.config.RemediationTracker == RemediationTrackerJira {
+		return &jiraTracker{c: c}
+	}
+	owner, name, _ := strings.Cut(c.config.RemediationRepository, "/")
+	return &githubTracker{client: c.client, owner: owner, repo: name}
+}
+
+// remediationTickets builds the tickets for posture's failing controls,
+// sorted by key, and the set of ticket keys posture evaluated: those of the
+// repositories or rules with a known control. An open ticket under an
+// evaluated key that isn't failing is resolved.
+func remediationTickets(p *OrgPosture, groupBy string) ([]remediationTicket, map[string]bool) {
+	failing := map[string][]string{} // repo → controls off
+	evaluated := map[string]bool{}
+	for repo, controls := range repoControls(p) {
+		for _, control := range repoControlNames {
+			on, known := controls[control]
+			if !known {
+				continue
+			}
+			if groupBy == RemediationGroupByRepository {
+				evaluated[fmt.Sprintf("epack/%s/repo/%s", p.Organization, repo)] = true
+			} else {
+				evaluated[fmt.Sprintf("epack/%s/rule/%s", p.Organization, control)] = true
+			}
+			if !on {
+				failing[repo] = append(failing[repo], control)
+			}
+		}
+	}
+	footer := fmt.Sprintf("\n_Filed by epack-collector-github from the %s collection of %s, and updated by each run while the violation persists._\n",
+		p.CollectedAt, p.Organization)
+
+	var tickets []remediationTicket
+	if groupBy == RemediationGroupByRepository {
+		for repo, controls := range failing {
+			key := fmt.Sprintf("epack/%s/repo/%s", p.Organization, repo)
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s has these controls off:\n\n", repo)
+			for _, control := range controls {
+				fmt.Fprintf(&b, "- %s\n", control)
+			}
+			tickets = append(tickets, remediationTicket{
+				Key:         key,
+				Title:       fmt.Sprintf("[%s] %d controls off", key, len(controls)),
+				Description: b.String() + footer,
+			})
+		}
+	} else {
+		byRule := map[string][]string{}
+		for repo, controls := range failing {
+			for _, control := range controls {
+				byRule[control] = append(byRule[control], repo)
+			}
+		}
+		for control, repos := range byRule {
+			slices.Sort(repos)
+			key := fmt.Sprintf("epack/%s/rule/%s", p.Organization, control)
+			var b strings.Builder
+			fmt.Fprintf(&b, "%s is off in %d repositories:\n\n", control, len(repos))
+			for _, repo := range repos[:min(len(repos), remediationMaxRepos)] {
+				fmt.Fprintf(&b, "- %s\n", repo)
+			}
+			if n := len(repos) - remediationMaxRepos; n > 0 {
+				fmt.Fprintf(&b, "- and %d more\n", n)
+			}
+			tickets = append(tickets, remediationTicket{
+				Key:         key,
+				Title:       fmt.Sprintf("[%s] %s off in %d repositories", key, control, len(repos)),
+				Description: b.String() + footer,
+			})
+		}
+	}
+	slices.SortFunc(tickets, func(a, b remediationTicket) int { return strings.Compare(a.Key, b.Key) })
+	return tickets, evaluated
+}
+
+// ticketKey extracts the dedup key from a remediation ticket title, or ""
+// for a title the collector didn't write.
+func ticketKey(title string) string {
+	rest, ok := strings.CutPrefix(title, "[epack/")
+	if !ok {
+		return ""
+	}
+	key, _, ok := strings.Cut(rest, "]")
+	if !ok {
+		return ""
+	}
+	return "epack/" + key
+}
+
+// githubTracker files tickets as issues in one repository.
+type githubTracker struct {
+	client      github.GitHubClient
+	owner, repo string
+}
+
+func (t *githubTracker) openTickets(ctx context.Context) (map[string]string, error) {
+	issues, err := t.client.ListOpenIssuesWithLabel(ctx, t.owner, t.repo, remediationLabel)
+	if err != nil {
+		return nil, err
+	}
+	open := make(map[string]string, len(issues))
+	for _, i := range issues {
+		if key := ticketKey(i.Title); key != "" {
+			open[key] = strconv.Itoa(i.Number)
+		}
+	}
+	return open, nil
+}
+
+func (t *githubTracker) create(ctx context.Context, ticket remediationTicket) error {
+	return t.client.CreateIssue(ctx, t.owner, t.repo, github.IssueRequest{
+		Title: ticket.Title, Body: ticket.Description, Labels: []string{remediationLabel},
+	})
+}
+
+func (t *githubTracker) update(ctx context.Context, id string, ticket remediationTicket) error {
+	number, err := strconv.Atoi(id)
+	if err != nil {
+		return err
+	}
+	return t.client.UpdateIssue(ctx, t.owner, t.repo, number, github.IssueRequest{Title: ticket.Title, Body: ticket.Description})
+}
+
+func (t *githubTracker) resolve(ctx context.Context, id string) error {
+	number, err := strconv.Atoi(id)
+	if err != nil {
+		return err
+	}
+	return t.client.UpdateIssue(ctx, t.owner, t.repo, number, github.IssueRequest{State: "closed", StateReason: "completed"})
+}
+
+// jiraTracker files tickets as Jira Cloud issues (REST API v2) in one
+// project, authenticated with an account email and API token.
+type jiraTracker struct {
+	c *Collector
+}
+
+// jiraHeader returns the Basic authorization header.
+func (t *jiraTracker) jiraHeader() http.Header {
+	cred := base64.StdEncoding.EncodeToString([]byte(t.c.config.JiraEmail + ":" + t.c.config.JiraAPIToken))
+	return http.Header{"Authorization": {"Basic " + cred}}
+}
+
+// endpoint returns the API URL for path.
+func (t *jiraTracker) endpoint(path string) string {
+	return strings.TrimSuffix(t.c.config.JiraURL, "/") + "/rest/api/2" + path
+}
+
+func (t *jiraTracker) openTickets(ctx context.Context) (map[string]string, error) {
+	jql := fmt.Sprintf(`project = "%s" AND labels = "%s" AND statusCategory != Done`, t.c.config.JiraProject, remediationLabel)
+	open := map[string]string{}
+	next := ""
+	for {
+		query := url.Values{"jql": {jql}, "fields": {"summary"}, "maxResults": {"100"}}
+		if next != "" {
+			query.Set("nextPageToken", next)
+		}
+		var page struct {
+			Issues []struct {
+				Key    string `json:"key"`
+				Fields struct {
+					Summary string `json:"summary"`
+				} `json:"fields"`
+			} `json:"issues"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := t.c.getJSON(ctx, t.endpoint("/search/jql?"+query.Encode()), t.jiraHeader(), &page); err != nil {
+			return nil, err
+		}
+		for _, i := range page.Issues {
+			if key := ticketKey(i.Fields.Summary); key != "" {
+				open[key] = i.Key
+			}
+		}
+		if page.NextPageToken == "" {
+			return open, nil
+		}
+		next = page.NextPageToken
+	}
+}
+
+func (t *jiraTracker) create(ctx context.Context, ticket remediationTicket) error {
+	return t.c.sendJSON(ctx, http.MethodPost, t.endpoint("/issue"), t.jiraHeader(), map[string]any{
+		"fields": map[string]any{
+			"project":     map[string]string{"key": t.c.config.JiraProject},
+			"issuetype":   map[string]string{"name": "Task"},
+			"summary":     ticket.Title,
+			"description": ticket.Description,
+			"labels":      []string{remediationLabel},
+		},
+	})
+}
+
+func (t *jiraTracker) update(ctx context.Context, id string, ticket remediationTicket) error {
+	return t.c.sendJSON(ctx, http.MethodPut, t.endpoint("/issue/"+url.PathEscape(id)), t.jiraHeader(), map[string]any{
+		"fields": map[string]any{"summary": ticket.Title, "description": ticket.Description},
+	})
+}
+
+// resolve comments on the issue and moves it through the project's first
+// transition into the Done status category.
+func (t *jiraTracker) resolve(ctx context.Context, id string) error {
+	issue := t.endpoint("/issue/" + url.PathEscape(id))
+	var transitions struct {
+		Transitions []struct {
+			ID string `json:"id"`
+			To struct {
+				StatusCategory struct {
+					Key string `json:"key"`
+				} `json:"statusCategory"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := t.c.getJSON(ctx, issue+"/transitions", t.jiraHeader(), &transitions); err != nil {
+		return err
+	}
+	for _, tr := range transitions.Transitions {
+		if tr.To.StatusCategory.Key != "done" {
+			continue
+		}
+		if err := t.c.sendJSON(ctx, http.MethodPost, issue+"/comment", t.jiraHeader(), map[string]any{
+			"body": "_Resolved: epack-collector-github found these controls compliant._",
+		}); err != nil {
+			return err
+		}
+		return t.c.sendJSON(ctx, http.MethodPost, issue+"/transitions", t.jiraHeader(), map[string]any{
+			"transition": map[string]string{"id": tr.ID},
+		})
+	}
+	return errors.New("no transition to a done status")
+}
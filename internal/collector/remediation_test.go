@@ -0,0 +1,252 @@
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// remediationPosture has api missing branch protection and secret scanning,
+// and web missing secret scanning.
+func remediationPosture() *OrgPosture {
+	p := NewOrgPosture("test-org")
+	p.Repositories = &Repositories{PerRepo: []RepoRow{
+		{Name: "test-org/api"},
+		{Name: "test-org/web", BranchProtection: &BranchProtectionDetail{}},
+	}}
+	p.SecurityFeatures.PerRepo = []SecurityFeaturesRow{
+		{Repository: "test-org/api", VulnerabilityAlerts: true, CodeScanning: true, SecretScanningPushProtection: true, DependabotSecurityUpdates: true},
+		{Repository: "test-org/web", VulnerabilityAlerts: true, CodeScanning: true, SecretScanningPushProtection: true, DependabotSecurityUpdates: true},
+	}
+	return p
+}
+
+func TestRemediationTickets(t *testing.T) {
+	byRule, _ := remediationTickets(remediationPosture(), RemediationGroupByRule)
+	if len(byRule) != 2 {
+		t.Fatalf("rule tickets = %d, want 2", len(byRule))
+	}
+	if got, want := byRule[1].Title, "[epack/test-org/rule/secret_scanning] secret_scanning off in 2 repositories"; got != want {
+		t.Errorf("title = %q, want %q", got, want)
+	}
+	if ticketKey(byRule[1].Title) != byRule[1].Key {
+		t.Errorf("ticketKey(%q) = %q, want %q", byRule[1].Title, ticketKey(byRule[1].Title), byRule[1].Key)
+	}
+
+	byRepo, _ := remediationTickets(remediationPosture(), RemediationGroupByRepository)
+	if len(byRepo) != 2 || byRepo[0].Key != "epack/test-org/repo/test-org/api" {
+		t.Fatalf("repository tickets = %+v", byRepo)
+	}
+	if !strings.Contains(byRepo[0].Description, "- branch_protection\n- secret_scanning\n") {
+		t.Errorf("description = %q", byRepo[0].Description)
+	}
+
+	if got, evaluated := remediationTickets(NewOrgPosture("test-org"), RemediationGroupByRule); len(got) != 0 || len(evaluated) != 0 {
+		t.Errorf("tickets without per-repo detail = %+v (evaluated %v), want none", got, evaluated)
+	}
+	if ticketKey("Flaky test in api") != "" {
+		t.Error("ticketKey should ignore titles the collector didn't write")
+	}
+}
+
+func TestRemediationTickets_SkipsUnknownAndNotApplicable(t *testing.T) {
+	p := remediationPosture()
+	p.SecurityFeatures.PerRepo[0].Unknown = []string{"secret_scanning"}
+	p.SecurityFeatures.PerRepo[1].NotApplicable = []string{"secret_scanning", "secret_scanning_push_protection"}
+
+	tickets, evaluated := remediationTickets(p, RemediationGroupByRule)
+	if len(tickets) != 1 || tickets[0].Key != "epack/test-org/rule/branch_protection" {
+		t.Errorf("tickets = %+v, want branch_protection only", tickets)
+	}
+	if evaluated["epack/test-org/rule/secret_scanning"] {
+		t.Error("secret_scanning was known on no repository, so its ticket shouldn't be resolvable")
+	}
+	if !evaluated["epack/test-org/rule/code_scanning"] {
+		t.Errorf("evaluated = %v, want code_scanning", evaluated)
+	}
+}
+
+func TestFileRemediation_GitHubIssues(t *testing.T) {
+	mock := &mockGitHubClient{labelledIssues: map[string][]github.Issue{
+		"test-org/security": {{Number: 7, Title: "[epack/test-org/rule/secret_scanning] secret_scanning off in 5 repositories"}},
+	}}
+	c := NewWithClient(Config{
+		Organization:          "test-org",
+		RemediationTracker:    RemediationTrackerGitHub,
+		RemediationRepository: "test-org/security",
+	}, mock)
+
+	if err := c.FileRemediation(context.Background(), remediationPosture()); err != nil {
+		t.Fatalf("FileRemediation() error: %v", err)
+	}
+	// The open secret_scanning ticket is updated; branch_protection is new.
+	if got := mock.updatedIssues[7].Title; !strings.HasSuffix(got, "off in 2 repositories") {
+		t.Errorf("updated issue 7 title = %q", got)
+	}
+	if len(mock.createdIssues) != 1 || !strings.HasPrefix(mock.createdIssues[0].Title, "[epack/test-org/rule/branch_protection]") {
+		t.Fatalf("created issues = %+v", mock.createdIssues)
+	}
+	if labels := mock.createdIssues[0].Labels; len(labels) != 1 || labels[0] != remediationLabel {
+		t.Errorf("labels = %v, want [%s]", labels, remediationLabel)
+	}
+}
+
+func TestFileRemediation_ClosesCompliantTickets(t *testing.T) {
+	mock := &mockGitHubClient{labelledIssues: map[string][]github.Issue{
+		"test-org/security": {
+			{Number: 3, Title: "[epack/test-org/rule/code_scanning] code_scanning off in 1 repositories"},
+			{Number: 4, Title: "[epack/test-org/rule/secret_scanning] secret_scanning off in 2 repositories"},
+			{Number: 5, Title: "[epack/other-org/rule/code_scanning] code_scanning off in 1 repositories"},
+		},
+	}}
+	c := NewWithClient(Config{
+		Organization:          "test-org",
+		RemediationTracker:    RemediationTrackerGitHub,
+		RemediationRepository: "test-org/security",
+	}, mock)
+
+	if err := c.FileRemediation(context.Background(), remediationPosture()); err != nil {
+		t.Fatalf("FileRemediation() error: %v", err)
+	}
+	if got := mock.updatedIssues[3]; got.State != "closed" || got.StateReason != "completed" || got.Title != "" {
+		t.Errorf("compliant ticket update = %+v, want closed as completed", got)
+	}
+	if got := mock.updatedIssues[4]; got.State != "" {
+		t.Errorf("failing ticket update = %+v, want left open", got)
+	}
+	if _, ok := mock.updatedIssues[5]; ok {
+		t.Error("another organization's ticket was touched")
+	}
+}
+
+func TestFileRemediation_SkipsIncompleteRuns(t *testing.T) {
+	for name, mutate := range map[string]func(*OrgPosture){
+		"aborted":   func(p *OrgPosture) { p.CollectionStats.Aborted = true },
+		"cancelled": func(p *OrgPosture) { p.CollectionStats.Cancelled = true },
+		"truncated": func(p *OrgPosture) { p.Scope.Truncated = true },
+	} {
+		t.Run(name, func(t *testing.T) {
+			mock := &mockGitHubClient{labelledIssues: map[string][]github.Issue{
+				"test-org/security": {{Number: 3, Title: "[epack/test-org/rule/code_scanning] code_scanning off in 1 repositories"}},
+			}}
+			c := NewWithClient(Config{
+				Organization:          "test-org",
+				RemediationTracker:    RemediationTrackerGitHub,
+				RemediationRepository: "test-org/security",
+			}, mock)
+			p := remediationPosture()
+			mutate(p)
+			if err := c.FileRemediation(context.Background(), p); err != nil {
+				t.Fatalf("FileRemediation() error: %v", err)
+			}
+			if len(mock.createdIssues) != 0 || len(mock.updatedIssues) != 0 {
+				t.Errorf("created %+v, updated %+v; want nothing", mock.createdIssues, mock.updatedIssues)
+			}
+		})
+	}
+}
+
+func TestFileRemediation_Jira(t *testing.T) {
+	var created []string
+	updated := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "bot@example.com" || token != "jira-token" {
+			t.Errorf("auth = %q, %q", user, token)
+		}
+		var body struct {
+			Fields struct {
+				Summary string `json:"summary"`
+			} `json:"fields"`
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/search/jql":
+			if jql := r.URL.Query().Get("jql"); !strings.Contains(jql, `project = "SEC"`) {
+				t.Errorf("jql = %q", jql)
+			}
+			_, _ = w.Write([]byte(`{"issues":[{"key":"SEC-3","fields":{"summary":"[epack/test-org/repo/test-org/web] 1 controls off"}}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue":
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			created = append(created, body.Fields.Summary)
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/rest/api/2/issue/"):
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			updated[strings.TrimPrefix(r.URL.Path, "/rest/api/2/issue/")] = body.Fields.Summary
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWithClient(Config{
+		Organization:       "test-org",
+		RemediationTracker: RemediationTrackerJira,
+		RemediationGroupBy: RemediationGroupByRepository,
+		JiraURL:            srv.URL,
+		JiraProject:        "SEC",
+		JiraEmail:          "bot@example.com",
+		JiraAPIToken:       "jira-token",
+	}, &mockGitHubClient{})
+	if err := c.FileRemediation(context.Background(), remediationPosture()); err != nil {
+		t.Fatalf("FileRemediation() error: %v", err)
+	}
+	if len(created) != 1 || !strings.HasPrefix(created[0], "[epack/test-org/repo/test-org/api]") {
+		t.Errorf("created = %v", created)
+	}
+	if updated["SEC-3"] == "" {
+		t.Errorf("SEC-3 not updated: %v", updated)
+	}
+}
+
+func TestJiraTracker_Resolve(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/SEC-3/transitions":
+			_, _ = w.Write([]byte(`{"transitions":[{"id":"11","to":{"statusCategory":{"key":"indeterminate"}}},{"id":"31","to":{"statusCategory":{"key":"done"}}}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue/SEC-3/transitions":
+			var body struct {
+				Transition struct {
+					ID string `json:"id"`
+				} `json:"transition"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body.Transition.ID != "31" {
+				t.Errorf("transition = %q, want 31", body.Transition.ID)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue/SEC-3/comment":
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewWithClient(Config{Organization: "test-org", JiraURL: srv.URL, JiraEmail: "bot@example.com", JiraAPIToken: "jira-token"}, &mockGitHubClient{})
+	if err := (&jiraTracker{c: c}).resolve(context.Background(), "SEC-3"); err != nil {
+		t.Fatalf("resolve() error: %v", err)
+	}
+	if len(calls) != 3 {
+		t.Errorf("calls = %v, want transitions, comment, transition", calls)
+	}
+}
+
+func TestValidateRemediation(t *testing.T) {
+	for _, config := range []Config{
+		{RemediationTracker: "linear"},
+		{RemediationGroupBy: "team"},
+		{RemediationTracker: RemediationTrackerGitHub, RemediationRepository: "security"},
+		{RemediationTracker: RemediationTrackerJira, JiraURL: "https://example.atlassian.net", JiraProject: "SEC"},
+	} {
+		if err := validateRemediation(config); err == nil {
+			t.Errorf("validateRemediation(%+v) should fail", config)
+		}
+	}
+}
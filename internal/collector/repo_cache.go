@@ -1,23 +1,119 @@
 package collector
 
-import "github.com/locktivity/epack-collector-github/internal/github"
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"iter"
+	"os"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
 
 // repoCache holds the included repositories and their per-repo REST security
 // settings, captured during the repository scan so the audit/internal surfaces
 // can reuse them without re-fetching.
+//
+// Memory stays bounded on very large orgs: at trust nothing is retained (each
+// page is aggregated and discarded), and above trust only the first limit
+// repositories are kept in memory while the rest spill to a temporary JSON
+// Lines file that all() streams back. The spill file is plaintext in
+// os.TempDir(), readable only by the collector's user, and removed by close();
+// state_encryption_key doesn't cover it. The settings map is small per repo
+// and stays in memory.
 type repoCache struct {
+	retain bool // false at trust: repos are aggregated, then discarded
+	limit  int  // in-memory repos before spilling; 0 = RepoCacheMemoryLimit
+	count  int
+
 	included []github.Repository
+	spill    *os.File
+	spillEnc *json.Encoder
+
 	settings map[string]*github.SecuritySettings // keyed by "owner/repo"
 }
 
-// add records an included repository.
+// add records an included repository. If the spill file can't be written the
+// repository is kept in memory instead, trading the bound for completeness.
 func (rc *repoCache) add(repo github.Repository) {
-	rc.included = append(rc.included, repo)
+	if !rc.retain {
+		return
+	}
+	rc.count++
+
+	limit := rc.limit
+	if limit <= 0 {
+		limit = RepoCacheMemoryLimit
+	}
+	if len(rc.included) < limit || rc.spillRepo(repo) != nil {
+		rc.included = append(rc.included, repo)
+	}
+}
+
+// spillRepo appends a repository to the spill file, creating it on first use.
+func (rc *repoCache) spillRepo(repo github.Repository) error {
+	if rc.spill == nil {
+		f, err := os.CreateTemp("", "epack-collector-github-repos-*.jsonl")
+		if err != nil {
+			return err
+		}
+		rc.spill = f
+		rc.spillEnc = json.NewEncoder(f)
+	}
+	return rc.spillEnc.Encode(repo)
+}
+
+// len returns the number of retained repositories.
+func (rc *repoCache) len() int {
+	return rc.count
+}
+
+// all yields the retained repositories in the order they were added: the
+// in-memory ones first, then the spilled ones read back from disk. A spill
+// file that can't be read back ends the sequence early.
+func (rc *repoCache) all() iter.Seq[github.Repository] {
+	return func(yield func(github.Repository) bool) {
+		for _, repo := range rc.included {
+			if !yield(repo) {
+				return
+			}
+		}
+		if rc.spill == nil {
+			return
+		}
+		if _, err := rc.spill.Seek(0, io.SeekStart); err != nil {
+			return
+		}
+		dec := json.NewDecoder(rc.spill)
+		for {
+			var repo github.Repository
+			if err := dec.Decode(&repo); err != nil {
+				return
+			}
+			if !yield(repo) {
+				return
+			}
+		}
+	}
+}
+
+// close removes the spill file, if any.
+func (rc *repoCache) close() error {
+	if rc.spill == nil {
+		return nil
+	}
+	name := rc.spill.Name()
+	err := errors.Join(rc.spill.Close(), os.Remove(name))
+	rc.spill, rc.spillEnc = nil, nil
+	return err
 }
 
 // recordSettings caches a repo's REST security settings for the audit-level
 // SecurityFeatures surface.
 func (rc *repoCache) recordSettings(owner, name string, settings *github.SecuritySettings) {
+	if !rc.retain {
+		return
+	}
 	if rc.settings == nil {
 		rc.settings = make(map[string]*github.SecuritySettings)
 	}
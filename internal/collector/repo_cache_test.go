@@ -0,0 +1,72 @@
+package collector
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+func TestRepoCache_SpillsPastLimit(t *testing.T) {
+	rc := repoCache{retain: true, limit: 2}
+	defer func() { _ = rc.close() }()
+
+	for i := range 5 {
+		repo := github.Repository{Name: fmt.Sprintf("repo%d", i)}
+		repo.Owner.Login = "test-org"
+		repo.DefaultBranchRef.BranchProtectionRule = &github.BranchProtectionRule{RequiredApprovingReviewCount: i}
+		rc.add(repo)
+	}
+
+	if len(rc.included) != 2 {
+		t.Errorf("in-memory repos = %d, want 2", len(rc.included))
+	}
+	if rc.spill == nil {
+		t.Fatal("expected repos past the limit to spill to disk")
+	}
+	if rc.len() != 5 {
+		t.Errorf("len() = %d, want 5", rc.len())
+	}
+
+	// Iterate twice: the spill file is re-read from the start each time.
+	for pass := range 2 {
+		i := 0
+		for repo := range rc.all() {
+			if want := fmt.Sprintf("repo%d", i); repo.Name != want {
+				t.Errorf("pass %d: repo %d = %q, want %q", pass, i, repo.Name, want)
+			}
+			if repo.Owner.Login != "test-org" {
+				t.Errorf("pass %d: owner = %q, want test-org", pass, repo.Owner.Login)
+			}
+			if bp := repo.DefaultBranchRef.BranchProtectionRule; bp == nil || bp.RequiredApprovingReviewCount != i {
+				t.Errorf("pass %d: branch protection for repo%d did not round-trip", pass, i)
+			}
+			i++
+		}
+		if i != 5 {
+			t.Errorf("pass %d: iterated %d repos, want 5", pass, i)
+		}
+	}
+
+	name := rc.spill.Name()
+	if err := rc.close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := os.Stat(name); !os.IsNotExist(err) {
+		t.Errorf("spill file %s still exists after close", name)
+	}
+}
+
+func TestRepoCache_NotRetainedAtTrust(t *testing.T) {
+	rc := repoCache{}
+	rc.add(github.Repository{Name: "repo"})
+	rc.recordSettings("test-org", "repo", &github.SecuritySettings{})
+
+	if rc.len() != 0 || len(rc.included) != 0 || rc.settings != nil {
+		t.Error("trust-level cache should not retain repositories or settings")
+	}
+	for range rc.all() {
+		t.Error("all() should yield nothing when nothing is retained")
+	}
+}
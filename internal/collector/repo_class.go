@@ -0,0 +1,97 @@
+package collector
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// Repository classes assigned by classifyRepo, keying
+// coverage_breakdown.by_class and reported per repository.
+const (
+	RepoClassLibrary      = "library"
+	RepoClassService      = "service"
+	RepoClassInfra        = "infra"
+	RepoClassDocs         = "docs"
+	RepoClassForkMirror   = "fork-mirror"
+	RepoClassUnclassified = "unclassified"
+)
+
+// repoClassWords maps topics and repository name words to the class they
+// indicate. Topics are checked before name words, and both in classOrder.
+var repoClassWords = map[string][]string{
+	RepoClassDocs:    {"docs", "doc", "documentation", "handbook", "wiki", "website", "site", "blog", "runbook", "runbooks"},
+	RepoClassInfra:   {"infra", "infrastructure", "terraform", "ansible", "helm", "k8s", "kubernetes", "iac", "gitops", "devops", "deploy", "deployment", "ops"},
+	RepoClassLibrary: {"lib", "library", "sdk", "client", "package", "pkg", "module", "plugin", "common", "shared", "utils"},
+	RepoClassService: {"service", "microservice", "svc", "api", "server", "backend", "app", "worker", "daemon"},
+}
+
+// classOrder is the precedence among classes a repository's words suggest:
+// the narrower purposes first, since e.g. "api-docs" is documentation.
+var classOrder = []string{RepoClassDocs, RepoClassInfra, RepoClassLibrary, RepoClassService}
+
+// repoClassLanguages maps primary languages that settle the class on their
+// own.
+var repoClassLanguages = map[string]string{
+	"Markdown":         RepoClassDocs,
+	"MDX":              RepoClassDocs,
+	"TeX":              RepoClassDocs,
+	"reStructuredText": RepoClassDocs,
+	"AsciiDoc":         RepoClassDocs,
+	"HCL":              RepoClassInfra,
+	"Nix":              RepoClassInfra,
+	"Jsonnet":          RepoClassInfra,
+	"Puppet":           RepoClassInfra,
+	"Dockerfile":       RepoClassInfra,
+}
+
+// classifyRepo assigns a repository its class from, in order: being a fork
+// or mirror; its topics; the words of its name; its primary language; and its
+// marker files (an MkDocs config for docs, a Dockerfile for a service). A
+// repository without a primary language is docs or configuration, so docs.
+// Anything else is unclassified rather than guessed.
+func classifyRepo(repo github.Repository) string {
+	if repo.IsFork || repo.IsMirror {
+		return RepoClassForkMirror
+	}
+	topics := make([]string, 0, len(repo.RepositoryTopics.Nodes))
+	for _, t := range repo.RepositoryTopics.Nodes {
+		topics = append(topics, strings.ToLower(t.Topic.Name))
+	}
+	if class := classFromWords(topics); class != "" {
+		return class
+	}
+	words := strings.FieldsFunc(strings.ToLower(repo.Name), func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+	if class := classFromWords(words); class != "" {
+		return class
+	}
+	if repo.PrimaryLanguage == nil || repo.PrimaryLanguage.Name == "" {
+		return RepoClassDocs
+	}
+	if class, ok := repoClassLanguages[repo.PrimaryLanguage.Name]; ok {
+		return class
+	}
+	switch {
+	case repo.HasFile("mkdocs.yml"):
+		return RepoClassDocs
+	case repo.HasFile("Dockerfile"):
+		return RepoClassService
+	}
+	return RepoClassUnclassified
+}
+
+// classFromWords returns the first class in classOrder any of words
+// indicates, or "".
+func classFromWords(words []string) string {
+	for _, class := range classOrder {
+		for _, w := range words {
+			if slices.Contains(repoClassWords[class], w) {
+				return class
+			}
+		}
+	}
+	return ""
+}
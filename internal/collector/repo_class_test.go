@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+func TestClassifyRepo(t *testing.T) {
+	repo := func(name, language string, topics ...string) github.Repository {
+		r := github.Repository{Name: name}
+		if language != "" {
+			r.PrimaryLanguage = &struct{ Name string }{Name: language}
+		}
+		for _, topic := range topics {
+			var node struct{ Topic struct{ Name string } }
+			node.Topic.Name = topic
+			r.RepositoryTopics.Nodes = append(r.RepositoryTopics.Nodes, node)
+		}
+		return r
+	}
+	fork := repo("billing-api", "Go")
+	fork.IsFork = true
+	dockerized := repo("billing", "Go")
+	dockerized.RootTree = &github.GitTree{TreeEntries: github.TreeEntries{Entries: []github.TreeEntry{{Name: "Dockerfile"}}}}
+	mkdocs := repo("guides", "Python")
+	mkdocs.RootTree = &github.GitTree{TreeEntries: github.TreeEntries{Entries: []github.TreeEntry{{Name: "mkdocs.yml"}}}}
+
+	tests := []struct {
+		name string
+		repo github.Repository
+		want string
+	}{
+		{"fork", fork, RepoClassForkMirror},
+		{"topic beats name", repo("billing-api", "Go", "Terraform"), RepoClassInfra},
+		{"name words", repo("payments_sdk", "Java"), RepoClassLibrary},
+		{"docs before service", repo("api-docs", "JavaScript"), RepoClassDocs},
+		{"language", repo("network", "HCL"), RepoClassInfra},
+		{"no language", repo("notes", ""), RepoClassDocs},
+		{"dockerfile", dockerized, RepoClassService},
+		{"mkdocs", mkdocs, RepoClassDocs},
+		{"no signal", repo("billing", "Go"), RepoClassUnclassified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyRepo(tt.repo); got != tt.want {
+				t.Errorf("classifyRepo() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack-collector-github/internal/state"
+	"github.com/locktivity/epack/componentsdk"
+)
+
+// Repository change kinds reported in repository_changes.
+const (
+	RepoChangeCreated        = "created"
+	RepoChangeArchived       = "archived"
+	RepoChangeRenamed        = "renamed"
+	RepoChangeTransferredOut = "transferred_out"
+	RepoChangeDeleted        = "deleted"
+	// RepoChangeRemoved is a repository that still exists under its name but
+	// is no longer visible to the collector (e.g. removed from the App
+	// installation), or whose fate couldn't be determined.
+	RepoChangeRemoved = "removed"
+)
+
+// trackRepositoryChanges compares this run's repository inventory with the
+// previous run's snapshot, reports the differences, and saves the new
+// snapshot. The first run only saves. Store failures are warnings: they never
+// fail the run. A snapshot that can't be read (under the wrong key, or
+// corrupt) is kept, since replacing it would lose the baseline.
+func (c *Collector) trackRepositoryChanges(ctx context.Context, store *state.Store, posture *OrgPosture, metrics *metricsAggregator, level componentsdk.Level) {
+	account := c.config.Organization
+	prev, err := store.Load(account)
+	if err != nil {
+		metrics.diag.snapshotUnavailable(err)
+		return
+	}
+
+	current := metrics.inventory
+	if prev != nil {
+		changes := c.diffRepositories(ctx, prev.Repositories, current)
+		changes.Since = prev.CollectedAt.UTC().Format(time.RFC3339)
+		if !level.AtLeast(componentsdk.LevelAudit) {
+			changes.Changes = nil
+		}
+		posture.RepositoryChanges = changes
+	}
+
+	snap := &state.Snapshot{CollectedAt: time.Now().UTC(), Repositories: current}
+	if err := store.Save(account, snap); err != nil {
+		metrics.diag.snapshotUnavailable(err)
+	}
+}
+
+// diffRepositories classifies the differences between two inventories.
+// Repositories that disappeared are looked up by their old name: GitHub
+// redirects renamed and transferred repositories and 404s deleted ones.
+func (c *Collector) diffRepositories(ctx context.Context, prev, current []state.Repository) *RepositoryChanges {
+	key := func(name string) string { return strings.ToLower(name) }
+	prevByName := make(map[string]state.Repository, len(prev))
+	for _, r := range prev {
+		prevByName[key(r.Name)] = r
+	}
+	currentByName := make(map[string]state.Repository, len(current))
+	for _, r := range current {
+		currentByName[key(r.Name)] = r
+	}
+
+	changes := &RepositoryChanges{}
+	add := func(repo, change, newName string) {
+		changes.Changes = append(changes.Changes, RepositoryChangeRow{Repository: repo, Change: change, NewName: newName})
+	}
+
+	renamedTo := map[string]bool{}
+	for _, r := range prev {
+		if _, ok := currentByName[key(r.Name)]; ok {
+			continue
+		}
+		owner, name, _ := strings.Cut(r.Name, "/")
+		fullName, err := c.client.LookupRepository(ctx, owner, name)
+		newOwner, _, _ := strings.Cut(fullName, "/")
+		_, nowListed := currentByName[key(fullName)]
+		switch {
+		case errors.Is(err, github.ErrNotFound):
+			add(r.Name, RepoChangeDeleted, "")
+		case err != nil:
+			add(r.Name, RepoChangeRemoved, "")
+		case !strings.EqualFold(newOwner, owner):
+			add(r.Name, RepoChangeTransferredOut, fullName)
+		case nowListed && !strings.EqualFold(fullName, r.Name):
+			renamedTo[key(fullName)] = true
+			add(r.Name, RepoChangeRenamed, fullName)
+		default:
+			add(r.Name, RepoChangeRemoved, "")
+		}
+	}
+	for _, r := range current {
+		old, existed := prevByName[key(r.Name)]
+		switch {
+		case !existed && !renamedTo[key(r.Name)]:
+			add(r.Name, RepoChangeCreated, "")
+		case existed && r.Archived && !old.Archived:
+			add(r.Name, RepoChangeArchived, "")
+		}
+	}
+
+	for _, row := range changes.Changes {
+		switch row.Change {
+		case RepoChangeCreated:
+			changes.Created++
+		case RepoChangeArchived:
+			changes.Archived++
+		case RepoChangeRenamed:
+			changes.Renamed++
+		case RepoChangeTransferredOut:
+			changes.TransferredOut++
+		case RepoChangeDeleted:
+			changes.Deleted++
+		case RepoChangeRemoved:
+			changes.Removed++
+		}
+	}
+	slices.SortFunc(changes.Changes, func(a, b RepositoryChangeRow) int {
+		if n := strings.Compare(a.Change, b.Change); n != 0 {
+			return n
+		}
+		return strings.Compare(a.Repository, b.Repository)
+	})
+	return changes
+}
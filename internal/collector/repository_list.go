@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// repositoryRef is one entry of the explicit repositories list.
+type repositoryRef struct {
+	owner, name string
+}
+
+func (r repositoryRef) String() string {
+	return r.owner + "/" + r.name
+}
+
+// parseRepositoryList parses the repositories config option. Entries are
+// "owner/name", or a bare "name" owned by defaultOwner. Duplicates (GitHub
+// names are case-insensitive) are dropped, keeping the first occurrence.
+func parseRepositoryList(entries []string, defaultOwner string) ([]repositoryRef, error) {
+	refs := make([]repositoryRef, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		owner, name, found := strings.Cut(strings.TrimSpace(entry), "/")
+		if !found {
+			owner, name = defaultOwner, owner
+		}
+		if owner == "" || name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("invalid repository %q: want owner/name or name", entry)
+		}
+		ref := repositoryRef{owner: owner, name: name}
+		key := strings.ToLower(ref.String())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// repositoryList returns the parsed repositories config option, or nil when
+// collection covers the whole org.
+func (c *Collector) repositoryList() ([]repositoryRef, error) {
+	if len(c.config.Repositories) == 0 {
+		return nil, nil
+	}
+	return parseRepositoryList(c.config.Repositories, c.config.Organization)
+}
+
+// fetchListedRepositories is the enumeration phase when collection is narrowed
+// to an explicit repository list: each listed repository is fetched directly
+// instead of paging through the org. A listed repository that can't be fetched
+// is recorded as skipped and the rest proceed.
+func (c *Collector) fetchListedRepositories(ctx context.Context, metrics *metricsAggregator, refs []repositoryRef, matcher *RepoMatcher, included chan<- github.Repository, discovered *atomic.Int64) error {
+	c.status(fmt.Sprintf("Fetching %d listed repositories...", len(refs)))
+
+	for _, ref := range refs {
+		repo, err := c.client.FetchRepository(ctx, ref.owner, ref.name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			metrics.addSkipped(SkippedRepository{
+				Repository: ref.String(),
+				Reason:     skipReason(err),
+			})
+		} else if metrics.processRepository(*repo, matcher) {
+			discovered.Add(1)
+			included <- *repo
+		}
+		if c.budgetExhausted() {
+			return errBudgetExhausted
+		}
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack/componentsdk"
+)
+
+// Ruleset condition tokens GitHub expands itself.
+const (
+	rulesetAll           = "~ALL"
+	rulesetDefaultBranch = "~DEFAULT_BRANCH"
+)
+
+// rulesetMatch is how a ruleset relates to one repository's default branch.
+type rulesetMatch int
+
+const (
+	rulesetNoMatch rulesetMatch = iota
+	// rulesetIndeterminate: the ruleset targets repositories by custom
+	// property, which isn't collected, so whether it applies is unknown.
+	rulesetIndeterminate
+	rulesetApplies
+)
+
+// loadRulesets reads the org's rulesets once per run, before enumeration,
+// for the effective branch protection metrics and the surfaces that evaluate
+// them. On a failure metrics.rulesetsRead stays false, protection falls back
+// to classic rules alone, and a diagnostic is recorded. Below audit, where
+// rulesets only refine branch protection, a denial is a warning rather than
+// a permission error, so strict mode doesn't require the permission there.
+func (c *Collector) loadRulesets(ctx context.Context, metrics *metricsAggregator, level componentsdk.Level) {
+	rulesets, truncated, err := c.client.ListOrgRulesets(ctx, c.config.Organization)
+	if err != nil {
+		switch {
+		case isDenied(err) && level.AtLeast(componentsdk.LevelAudit):
+			metrics.diag.surfacePermissionDenied("rulesets", "organization_administration:read")
+		case isDenied(err):
+			metrics.diag.addWarning("rulesets: not readable without organization_administration:read; branch protection counts classic rules only")
+		case isUnsupported(err):
+			metrics.diag.unsupportedOnInstance("rulesets", metrics.instance)
+		default:
+			metrics.diag.addWarning("rulesets: unavailable, branch protection counts classic rules only: " + err.Error())
+		}
+		return
+	}
+	if truncated {
+		metrics.diag.addWarning(fmt.Sprintf("rulesets: truncated at %d; protection from the rest is not counted", github.RulesetFetchCap))
+	}
+	metrics.rulesets, metrics.rulesetsRead, metrics.rulesetsTruncated = rulesets, true, truncated
+}
+
+// rulesetSummary counts rulesets by enforcement status, and the enabled ones
+// with bypass actors. names adds the evaluate-mode and bypassable rulesets'
+// names, sorted.
+func rulesetSummary(rulesets []github.Ruleset, names bool) *Rulesets {
+	summary := &Rulesets{Total: len(rulesets)}
+	for _, rs := range rulesets {
+		switch rs.Enforcement {
+		case github.RulesetEnforcementActive:
+			summary.Active++
+		case github.RulesetEnforcementEvaluate:
+			summary.Evaluate++
+			if names {
+				summary.EvaluateRulesets = append(summary.EvaluateRulesets, rs.Name)
+			}
+		case github.RulesetEnforcementDisabled:
+			summary.Disabled++
+			continue
+		}
+		if len(rs.BypassActors) > 0 {
+			summary.WithBypassActors++
+			if names {
+				summary.BypassRulesets = append(summary.BypassRulesets, rs.Name)
+			}
+		}
+	}
+	slices.Sort(summary.EvaluateRulesets)
+	slices.Sort(summary.BypassRulesets)
+	return summary
+}
+
+// matchRuleset reports whether a branch ruleset covers a repository's default
+// branch, or a repository ruleset the repository itself. Enforcement isn't
+// considered; callers branch on it.
+func matchRuleset(rs github.Ruleset, repo github.Repository) rulesetMatch {
+	if rs.Target != "" && rs.Target != "branch" && rs.Target != github.RulesetTargetRepository {
+		return rulesetNoMatch
+	}
+	cond := rs.Conditions
+	if ref := cond.RefName; ref != nil && !refConditionMatches(ref.Include, ref.Exclude, repo.DefaultBranchRef.Name) {
+		return rulesetNoMatch
+	}
+	if name := cond.RepositoryName; name != nil {
+		if !rulesetPatternsMatch(name.Include, repo.Name) || rulesetPatternsMatch(name.Exclude, repo.Name) {
+			return rulesetNoMatch
+		}
+	}
+	if ids := cond.RepositoryID; ids != nil && !slices.Contains(ids.RepositoryIDs, repo.DatabaseID) {
+		return rulesetNoMatch
+	}
+	if len(cond.RepositoryProperty) > 0 && string(cond.RepositoryProperty) != "null" {
+		return rulesetIndeterminate
+	}
+	return rulesetApplies
+}
+
+// refConditionMatches evaluates a ref_name condition against a default
+// branch name.
+func refConditionMatches(include, exclude []string, defaultBranch string) bool {
+	matches := func(patterns []string) bool {
+		for _, pattern := range patterns {
+			switch pattern {
+			case rulesetAll, rulesetDefaultBranch:
+				return true
+			}
+			if MatchesPattern(defaultBranch, strings.TrimPrefix(pattern, "refs/heads/"), false) {
+				return true
+			}
+		}
+		return false
+	}
+	return matches(include) && !matches(exclude)
+}
+
+// rulesetPatternsMatch reports whether a repository name matches any of a
+// repository_name condition's patterns, which GitHub matches ignoring case.
+func rulesetPatternsMatch(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if pattern == rulesetAll || MatchesPattern(name, pattern, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// rulesetCoverage counts the in-scope repositories whose default branch a set
+// of rulesets covers. A repository counts as enforced when an active
+// ruleset applies, evaluate-only when only evaluate-mode ones do, and
+// indeterminate when only property-targeted ones might. Disabled rulesets are
+// ignored. uncovered lists the repositories not enforced, for internal level.
+type rulesetCoverage struct {
+	TotalRepos         int
+	EnforcedRepos      int
+	EvaluateOnlyRepos  int
+	IndeterminateRepos int
+	Coverage           int
+	uncovered          []string
+}
+
+// coverageOf evaluates rulesets against every in-scope repository.
+func coverageOf(p *collectionPass, rulesets []github.Ruleset) rulesetCoverage {
+	return coverageWith(p, rulesets, nil)
+}
+
+// coverageWith is coverageOf where the rulesets named in unknown may or may
+// not carry the rule, so the repositories they apply to are indeterminate
+// unless another ruleset enforces it.
+func coverageWith(p *collectionPass, rulesets []github.Ruleset, unknown map[string]bool) rulesetCoverage {
+	var cov rulesetCoverage
+	for repo := range p.metrics.repos.all() {
+		cov.TotalRepos++
+		enforced, evaluated, indeterminate := false, false, false
+		for _, rs := range rulesets {
+			if rs.Enforcement == github.RulesetEnforcementDisabled {
+				continue
+			}
+			switch matchRuleset(rs, repo) {
+			case rulesetApplies:
+				if unknown[rs.Name] {
+					indeterminate = true
+				} else if rs.Enforcement == github.RulesetEnforcementActive {
+					enforced = true
+				} else {
+					evaluated = true
+				}
+			case rulesetIndeterminate:
+				indeterminate = true
+			}
+		}
+		switch {
+		case enforced:
+			cov.EnforcedRepos++
+		case evaluated:
+			cov.EvaluateOnlyRepos++
+		case indeterminate:
+			cov.IndeterminateRepos++
+		}
+		if !enforced && p.internal() {
+			cov.uncovered = append(cov.uncovered, repo.Owner.Login+"/"+repo.Name)
+		}
+	}
+	slices.Sort(cov.uncovered)
+	cov.Coverage = percent(cov.EnforcedRepos, cov.TotalRepos)
+	return cov
+}
+
+// rulesetHasRule reports whether a ruleset carries a rule of the given type.
+func rulesetHasRule(rs github.Ruleset, ruleType string) bool {
+	return slices.ContainsFunc(rs.Rules, func(r github.RulesetRule) bool { return r.Type == ruleType })
+}
+
+// repositoryRules reports which in-scope repositories org repository
+// rulesets protect from deletion and from visibility changes by anyone but
+// the rulesets' bypass actors. Audit emits the rulesets and counts; internal
+// adds the repositories without enforcement.
+func repositoryRules(p *collectionPass) *RepositoryRules {
+	rr := &RepositoryRules{}
+	coverage := func(ruleType string) RepositoryRuleCoverage {
+		var restricting []github.Ruleset
+		for _, rs := range p.rulesets {
+			if rs.Target == github.RulesetTargetRepository && rs.Enforcement != github.RulesetEnforcementDisabled && rulesetHasRule(rs, ruleType) {
+				restricting = append(restricting, rs)
+				if !slices.Contains(rr.Rulesets, rs.Name) {
+					rr.Rulesets = append(rr.Rulesets, rs.Name)
+				}
+			}
+		}
+		cov := coverageOf(p, restricting)
+		return RepositoryRuleCoverage{
+			TotalRepos:         cov.TotalRepos,
+			EnforcedRepos:      cov.EnforcedRepos,
+			EvaluateOnlyRepos:  cov.EvaluateOnlyRepos,
+			IndeterminateRepos: cov.IndeterminateRepos,
+			Coverage:           cov.Coverage,
+			UnenforcedRepos:    cov.uncovered,
+		}
+	}
+	rr.DeletionRestricted = coverage(github.RuleTypeRepositoryDelete)
+	rr.VisibilityRestricted = coverage(github.RuleTypeRepositoryVisibility)
+	slices.Sort(rr.Rulesets)
+	return rr
+}
@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+func TestMatchRuleset(t *testing.T) {
+	repo := github.Repository{DatabaseID: 42, Name: "api"}
+	repo.DefaultBranchRef.Name = "main"
+
+	ruleset := func(conditions string) github.Ruleset {
+		rs := github.Ruleset{Target: "branch"}
+		if err := json.Unmarshal([]byte(conditions), &rs.Conditions); err != nil {
+			t.Fatalf("conditions %s: %v", conditions, err)
+		}
+		return rs
+	}
+	tests := []struct {
+		name       string
+		conditions string
+		want       rulesetMatch
+	}{
+		{"all repos, default branch", `{"ref_name":{"include":["~DEFAULT_BRANCH"]},"repository_name":{"include":["~ALL"]}}`, rulesetApplies},
+		{"branch pattern", `{"ref_name":{"include":["refs/heads/ma*"]},"repository_name":{"include":["~ALL"]}}`, rulesetApplies},
+		{"other branch", `{"ref_name":{"include":["refs/heads/release/*"]},"repository_name":{"include":["~ALL"]}}`, rulesetNoMatch},
+		{"branch excluded", `{"ref_name":{"include":["~ALL"],"exclude":["refs/heads/main"]},"repository_name":{"include":["~ALL"]}}`, rulesetNoMatch},
+		{"name pattern ignores case", `{"ref_name":{"include":["~ALL"]},"repository_name":{"include":["AP*"]}}`, rulesetApplies},
+		{"name excluded", `{"ref_name":{"include":["~ALL"]},"repository_name":{"include":["~ALL"],"exclude":["api"]}}`, rulesetNoMatch},
+		{"listed by ID", `{"ref_name":{"include":["~ALL"]},"repository_id":{"repository_ids":[7,42]}}`, rulesetApplies},
+		{"not listed by ID", `{"ref_name":{"include":["~ALL"]},"repository_id":{"repository_ids":[7]}}`, rulesetNoMatch},
+		{"custom property", `{"ref_name":{"include":["~ALL"]},"repository_property":{"include":[{"name":"tier","property_values":["prod"]}]}}`, rulesetIndeterminate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchRuleset(ruleset(tt.conditions), repo); got != tt.want {
+				t.Errorf("matchRuleset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	tag := ruleset(`{"ref_name":{"include":["~ALL"]},"repository_name":{"include":["~ALL"]}}`)
+	tag.Target = "tag"
+	if got := matchRuleset(tag, repo); got != rulesetNoMatch {
+		t.Errorf("tag ruleset = %v, want no match", got)
+	}
+}
+
+func TestRulesetSummary(t *testing.T) {
+	bypass := func(rs github.Ruleset) github.Ruleset {
+		rs.BypassActors = append(rs.BypassActors, struct {
+			ActorType string `json:"actor_type"`
+		}{ActorType: "OrganizationAdmin"})
+		return rs
+	}
+	rulesets := []github.Ruleset{
+		{Name: "protect main", Enforcement: github.RulesetEnforcementActive},
+		bypass(github.Ruleset{Name: "release branches", Enforcement: github.RulesetEnforcementActive}),
+		bypass(github.Ruleset{Name: "trial signing", Enforcement: github.RulesetEnforcementEvaluate}),
+		bypass(github.Ruleset{Name: "old", Enforcement: github.RulesetEnforcementDisabled}),
+	}
+
+	got := rulesetSummary(rulesets, false)
+	want := Rulesets{Total: 4, Active: 2, Evaluate: 1, Disabled: 1, WithBypassActors: 2}
+	if got.Total != want.Total || got.Active != want.Active || got.Evaluate != want.Evaluate || got.Disabled != want.Disabled || got.WithBypassActors != want.WithBypassActors {
+		t.Errorf("rulesetSummary() = %+v, want %+v", *got, want)
+	}
+	if got.EvaluateRulesets != nil || got.BypassRulesets != nil {
+		t.Errorf("names without names set: %v, %v", got.EvaluateRulesets, got.BypassRulesets)
+	}
+
+	named := rulesetSummary(rulesets, true)
+	if len(named.EvaluateRulesets) != 1 || named.EvaluateRulesets[0] != "trial signing" {
+		t.Errorf("EvaluateRulesets = %v", named.EvaluateRulesets)
+	}
+	if len(named.BypassRulesets) != 2 || named.BypassRulesets[0] != "release branches" || named.BypassRulesets[1] != "trial signing" {
+		t.Errorf("BypassRulesets = %v, want the enabled rulesets with bypass actors", named.BypassRulesets)
+	}
+}
@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack-collector-github/internal/state"
+)
+
+// ErrTooSoon is returned by Collect when the previous run started less than
+// min_interval_minutes ago. Nothing is sent to GitHub.
+var ErrTooSoon = errors.New("previous collection too recent")
+
+// ErrRateLimited is returned by Collect when GitHub's primary rate limit was
+// exhausted for longer than max_rate_limit_wait_seconds. The run is worth
+// retrying once the limit resets, which the error names.
+var ErrRateLimited = errors.New("GitHub rate limit exhausted")
+
+// rateLimited returns an ErrRateLimited error when a request failed on an
+// exhausted primary rate limit, or nil. Secondary limits never fail a run:
+// the client pauses and resumes.
+func (c *Collector) rateLimited() error {
+	until := c.client.Stats().RateLimitExhaustedUntil
+	if until.IsZero() {
+		return nil
+	}
+	return fmt.Errorf("%w until %s; retry then, or raise max_rate_limit_wait_seconds to wait it out", ErrRateLimited, formatTime(until))
+}
+
+// validateMinInterval checks min_interval_minutes, which needs state_dir to
+// remember the previous run.
+func validateMinInterval(config Config) error {
+	if config.MinIntervalMinutes < 0 {
+		return fmt.Errorf("min_interval_minutes: must be at least 0, got %d", config.MinIntervalMinutes)
+	}
+	if config.MinIntervalMinutes > 0 && config.StateDir == "" {
+		return errors.New("min_interval_minutes requires state_dir")
+	}
+	return nil
+}
+
+// minInterval returns MinIntervalMinutes as a duration.
+func (c *Collector) minInterval() time.Duration {
+	return time.Duration(c.config.MinIntervalMinutes) * time.Minute
+}
+
+// claimRun refuses a run starting within MinIntervalMinutes of the previous
+// run's start, and otherwise records now as this run's start. Refused runs
+// leave the recorded start alone, so a runner retrying in a loop can't push
+// the next allowed run back.
+func (c *Collector) claimRun(store *state.Store, now time.Time) error {
+	if c.config.MinIntervalMinutes <= 0 || store == nil {
+		return nil
+	}
+	account := c.config.Organization
+	last, err := store.LastRun(account)
+	if err != nil {
+		return err
+	}
+	if allowed := last.Add(c.minInterval()); !last.IsZero() && now.Before(allowed) {
+		return fmt.Errorf("%w: the previous run started at %s and min_interval_minutes is %d; run again from %s",
+			ErrTooSoon, formatTime(last), c.config.MinIntervalMinutes, formatTime(allowed))
+	}
+	return store.SaveLastRun(account, now)
+}
+
+// scheduleHints fills the recommended start of the next run. A run that used
+// more GraphQL points than the token has left (or stopped at
+// abort_below_remaining) couldn't repeat before the rate limit resets, so the
+// reset is its rate_limit_recovery_at and the next run waits for it; the next
+// run also waits out MinIntervalMinutes. Without an observed rate limit or an
+// interval there is nothing to recommend.
+func (c *Collector) scheduleHints(out *CollectionStats, stats github.QueryStats, now time.Time) {
+	if stats.RateLimitRemaining == nil && c.config.MinIntervalMinutes <= 0 {
+		return
+	}
+	next := now.Add(c.minInterval())
+	if remaining := stats.RateLimitRemaining; remaining != nil && !stats.RateLimitResetAt.IsZero() &&
+		(out.Aborted || *remaining < stats.GraphQLCost) {
+		out.RateLimitRecoveryAt = formatTime(stats.RateLimitResetAt)
+		if stats.RateLimitResetAt.After(next) {
+			next = stats.RateLimitResetAt
+		}
+	}
+	out.NextCollectionAt = formatTime(next)
+}
@@ -0,0 +1,163 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack-collector-github/internal/state"
+	"github.com/locktivity/epack/componentsdk"
+)
+
+func TestCollect_StateLocked(t *testing.T) {
+	mock := &mockGitHubClient{orgSecurity: &github.OrgSecurity{}}
+	config := Config{Organization: "test-org", StateDir: t.TempDir()}
+	store, err := state.Open(config.StateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock, err := store.Lock("test-org")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A run in progress for the account holds off another.
+	if _, err := collectAndClose(context.Background(), config, mock, componentsdk.LevelTrust); !errors.Is(err, state.ErrLocked) {
+		t.Fatalf("Collect() while locked error = %v, want state.ErrLocked", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	// And a closed run releases the lock.
+	for range 2 {
+		if _, err := collectAndClose(context.Background(), config, mock, componentsdk.LevelTrust); err != nil {
+			t.Fatalf("Collect() error: %v", err)
+		}
+	}
+
+	// The lock outlives Collect, so Notify saves its baseline under it.
+	c := NewWithClient(config, mock)
+	if _, err := c.Collect(context.Background(), componentsdk.LevelTrust); err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if _, err := store.Lock("test-org"); !errors.Is(err, state.ErrLocked) {
+		t.Fatalf("Lock() before Close error = %v, want state.ErrLocked", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+	lock, err = store.Lock("test-org")
+	if err != nil {
+		t.Fatalf("Lock() after Close error: %v", err)
+	}
+	_ = lock.Unlock()
+}
+
+func TestCollect_MinInterval(t *testing.T) {
+	mock := &mockGitHubClient{orgSecurity: &github.OrgSecurity{}}
+	config := Config{Organization: "test-org", StateDir: t.TempDir(), MinIntervalMinutes: 60}
+	if err := validateMinInterval(config); err != nil {
+		t.Fatalf("validateMinInterval() error: %v", err)
+	}
+
+	if _, err := collectAndClose(context.Background(), config, mock, componentsdk.LevelTrust); err != nil {
+		t.Fatalf("first Collect() error: %v", err)
+	}
+	_, err := collectAndClose(context.Background(), config, mock, componentsdk.LevelTrust)
+	if !errors.Is(err, ErrTooSoon) {
+		t.Fatalf("second Collect() error = %v, want ErrTooSoon", err)
+	}
+
+	// A refused run doesn't move the recorded start.
+	c := NewWithClient(config, mock)
+	store, err := state.Open(config.StateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last, err := store.LastRun("test-org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.claimRun(store, last.Add(59*time.Minute)); !errors.Is(err, ErrTooSoon) {
+		t.Errorf("claimRun() 59 minutes later = %v, want ErrTooSoon", err)
+	}
+	if err := c.claimRun(store, last.Add(time.Hour)); err != nil {
+		t.Errorf("claimRun() an hour later = %v, want nil", err)
+	}
+}
+
+func TestScheduleHints(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	reset := now.Add(40 * time.Minute)
+	remaining := func(n int) *int { return &n }
+
+	tests := []struct {
+		name         string
+		interval     int
+		stats        github.QueryStats
+		aborted      bool
+		wantNext     string
+		wantRecovery string
+	}{
+		{"nothing observed", 0, github.QueryStats{}, false, "", ""},
+		{"interval only", 30, github.QueryStats{}, false, "2026-03-01T12:30:00Z", ""},
+		{"budget left", 0, github.QueryStats{GraphQLCost: 100, RateLimitRemaining: remaining(4000), RateLimitResetAt: reset}, false, "2026-03-01T12:00:00Z", ""},
+		{"budget short", 30, github.QueryStats{GraphQLCost: 3000, RateLimitRemaining: remaining(2000), RateLimitResetAt: reset}, false, "2026-03-01T12:40:00Z", "2026-03-01T12:40:00Z"},
+		{"aborted, interval longer", 60, github.QueryStats{GraphQLCost: 100, RateLimitRemaining: remaining(4000), RateLimitResetAt: reset}, true, "2026-03-01T13:00:00Z", "2026-03-01T12:40:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewWithClient(Config{MinIntervalMinutes: tt.interval}, &mockGitHubClient{})
+			out := CollectionStats{Aborted: tt.aborted}
+			c.scheduleHints(&out, tt.stats, now)
+			if out.NextCollectionAt != tt.wantNext || out.RateLimitRecoveryAt != tt.wantRecovery {
+				t.Errorf("hints = %q, %q; want %q, %q", out.NextCollectionAt, out.RateLimitRecoveryAt, tt.wantNext, tt.wantRecovery)
+			}
+		})
+	}
+}
+
+func TestValidateMinInterval(t *testing.T) {
+	for _, config := range []Config{
+		{MinIntervalMinutes: -1, StateDir: "/tmp/state"},
+		{MinIntervalMinutes: 60},
+	} {
+		if err := validateMinInterval(config); err == nil {
+			t.Errorf("validateMinInterval(%+v) should fail", config)
+		}
+	}
+}
+
+func TestCollect_RateLimited(t *testing.T) {
+	reset := time.Date(2026, 5, 1, 13, 0, 0, 0, time.UTC)
+	mock := richMock()
+	mock.stats = github.QueryStats{RateLimitExhaustedUntil: reset}
+	_, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("Collect() error = %v, want ErrRateLimited", err)
+	}
+	if !strings.Contains(err.Error(), formatTime(reset)) {
+		t.Errorf("Collect() error = %q, want the reset time as the retry hint", err)
+	}
+
+	// Secondary limits pause and resume, so the run succeeds and counts them.
+	mock.stats = github.QueryStats{SecondaryRateLimitPauses: 2}
+	posture, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelTrust)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if got := posture.CollectionStats.SecondaryRateLimitPauses; got != 2 {
+		t.Errorf("SecondaryRateLimitPauses = %d, want 2", got)
+	}
+}
+
+// collectAndClose runs one collection and releases its state lock, as main
+// does once the run's notifications are sent.
+func collectAndClose(ctx context.Context, config Config, client github.GitHubClient, level componentsdk.Level) (*OrgPosture, error) {
+	c := NewWithClient(config, client)
+	defer func() { _ = c.Close() }()
+	return c.Collect(ctx, level)
+}
@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Metric families that can be scoped separately with Config.Scopes. Each
+// family's percentages use the repositories in its own scope as the
+// denominator.
+const (
+	MetricBranchProtection          = "branch_protection"
+	MetricVulnerabilityAlerts       = "vulnerability_alerts"
+	MetricCodeScanning              = "code_scanning"
+	MetricSecretScanning            = "secret_scanning"
+	MetricDependabotSecurityUpdates = "dependabot_security_updates"
+	MetricRepositoryHygiene         = "repository_hygiene"
+)
+
+// metricFamilies lists every scopable metric family.
+var metricFamilies = []string{
+	MetricBranchProtection,
+	MetricVulnerabilityAlerts,
+	MetricCodeScanning,
+	MetricSecretScanning,
+	MetricDependabotSecurityUpdates,
+	MetricRepositoryHygiene,
+}
+
+// MetricScope narrows one metric family to a subset of the in-scope
+// repositories. Include defaults to every repository; exclude takes
+// precedence.
+type MetricScope struct {
+	Include []string `json:"include,omitempty"`
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// metricScopes holds the compiled per-family matchers. A family without an
+// entry covers every in-scope repository.
+type metricScopes map[string]*RepoMatcher
+
+// newMetricScopes compiles the configured scopes, rejecting unknown families
+// and invalid patterns.
+func newMetricScopes(scopes map[string]MetricScope, caseInsensitive bool) (metricScopes, error) {
+	if len(scopes) == 0 {
+		return nil, nil
+	}
+	compiled := make(metricScopes, len(scopes))
+	for family, scope := range scopes {
+		if !slices.Contains(metricFamilies, family) {
+			return nil, fmt.Errorf("scopes: unknown metric family %q (want one of %s)", family, strings.Join(metricFamilies, ", "))
+		}
+		matcher, err := NewRepoMatcher(scope.Include, scope.Exclude, caseInsensitive)
+		if err != nil {
+			return nil, fmt.Errorf("scopes.%s: %w", family, err)
+		}
+		compiled[family] = matcher
+	}
+	return compiled, nil
+}
+
+// includes reports whether an in-scope repository counts toward family.
+func (s metricScopes) includes(family, name string) bool {
+	matcher, ok := s[family]
+	return !ok || matcher.Match(name)
+}
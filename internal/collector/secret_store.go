@@ -0,0 +1,94 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack-collector-github/internal/secretstore"
+)
+
+// secretStoreTimeout bounds the secret manager requests of one run.
+const secretStoreTimeout = 30 * time.Second
+
+// validateSecretStore checks the secret_store options: a known provider,
+// with at least one reference to read, and no reference without a provider.
+func validateSecretStore(config Config) error {
+	refs := config.GitHubTokenRef != "" || config.PrivateKeyRef != ""
+	if config.SecretStore == "" {
+		if refs {
+			return errors.New("github_token_ref and private_key_ref require secret_store")
+		}
+		return nil
+	}
+	if !slices.Contains(secretstore.Providers, config.SecretStore) {
+		return fmt.Errorf("secret_store: must be one of %s, got %q", strings.Join(secretstore.Providers, ", "), config.SecretStore)
+	}
+	if !refs {
+		return errors.New("secret_store requires github_token_ref or private_key_ref")
+	}
+	if config.VaultAuthMethod != "" && !slices.Contains(secretstore.VaultAuthMethods, config.VaultAuthMethod) {
+		return fmt.Errorf("vault_auth_method: must be one of %s, got %q", strings.Join(secretstore.VaultAuthMethods, ", "), config.VaultAuthMethod)
+	}
+	return nil
+}
+
+// LoadStoredSecrets reads the GitHub token and App private key referenced by
+// github_token_ref and private_key_ref from the configured secret_store into
+// config, replacing any GITHUB_TOKEN or GITHUB_APP_PRIVATE_KEY secret. The
+// requests share the proxy and CA settings. Without a secret_store it does
+// nothing.
+func LoadStoredSecrets(ctx context.Context, config *Config) error {
+	if err := validateSecretStore(*config); err != nil || config.SecretStore == "" {
+		return err
+	}
+	transport, err := github.NewTransport(github.TransportConfig{
+		HTTPProxy:    config.HTTPProxy,
+		HTTPSProxy:   config.HTTPSProxy,
+		NoProxy:      config.NoProxy,
+		CABundlePath: config.CABundlePath,
+	})
+	if err != nil {
+		return fmt.Errorf("configuring secret_store transport: %w", err)
+	}
+	store, err := secretstore.New(secretstore.Options{
+		Provider:        config.SecretStore,
+		VaultAddress:    config.VaultAddress,
+		VaultToken:      config.VaultToken,
+		VaultAuthMethod: config.VaultAuthMethod,
+		VaultAuthMount:  config.VaultAuthMount,
+		VaultRole:       config.VaultRole,
+		VaultSecretID:   config.VaultSecretID,
+		VaultJWTPath:    config.VaultJWTPath,
+		Client:          &http.Client{Transport: transport, Timeout: secretStoreTimeout},
+	})
+	if err != nil {
+		return err
+	}
+	for _, s := range []struct {
+		key string
+		ref string
+		dst *string
+	}{
+		{"github_token_ref", config.GitHubTokenRef, &config.GitHubToken},
+		{"private_key_ref", config.PrivateKeyRef, &config.PrivateKey},
+	} {
+		if s.ref == "" {
+			continue
+		}
+		value, err := store.Get(ctx, s.ref)
+		if err != nil {
+			return fmt.Errorf("%s: %w", s.key, err)
+		}
+		if value == "" {
+			return fmt.Errorf("%s: the secret is empty", s.key)
+		}
+		*s.dst = value
+	}
+	return nil
+}
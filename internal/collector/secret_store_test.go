@@ -0,0 +1,81 @@
+package collector
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestValidateSecretStore(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"unset", Config{}, false},
+		{"vault token", Config{SecretStore: "vault", GitHubTokenRef: "secret/data/github#token"}, false},
+		{"aws key", Config{SecretStore: "aws", PrivateKeyRef: "github-app-key"}, false},
+		{"unknown provider", Config{SecretStore: "keychain", GitHubTokenRef: "github"}, true},
+		{"no reference", Config{SecretStore: "gcp"}, true},
+		{"reference without store", Config{GitHubTokenRef: "secret/data/github"}, true},
+		{"vault kubernetes", Config{SecretStore: "vault", GitHubTokenRef: "secret/data/github#token", VaultAuthMethod: "kubernetes"}, false},
+		{"unknown vault auth method", Config{SecretStore: "vault", GitHubTokenRef: "secret/data/github#token", VaultAuthMethod: "ldap"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateSecretStore(tt.config); (err != nil) != tt.wantErr {
+				t.Errorf("validateSecretStore() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadStoredSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-tok" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/github":
+			_, _ = io.WriteString(w, `{"data": {"data": {"token": "ghs_stored", "private_key": "PEM"}, "metadata": {}}}`)
+		case "/v1/secret/data/empty":
+			_, _ = io.WriteString(w, `{"data": {"data": {"token": ""}, "metadata": {}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	config := Config{
+		GitHubToken:    "from-env",
+		SecretStore:    "vault",
+		GitHubTokenRef: "secret/data/github#token",
+		PrivateKeyRef:  "secret/data/github#private_key",
+		VaultAddress:   server.URL,
+		VaultToken:     "vault-tok",
+	}
+	if err := LoadStoredSecrets(context.Background(), &config); err != nil {
+		t.Fatalf("LoadStoredSecrets() error: %v", err)
+	}
+	if config.GitHubToken != "ghs_stored" || config.PrivateKey != "PEM" {
+		t.Errorf("token %q, private key %q", config.GitHubToken, config.PrivateKey)
+	}
+
+	config.GitHubTokenRef = "secret/data/empty#token"
+	if err := LoadStoredSecrets(context.Background(), &config); err == nil {
+		t.Error("LoadStoredSecrets() of an empty secret should fail")
+	}
+	config.GitHubTokenRef = "secret/data/missing#token"
+	if err := LoadStoredSecrets(context.Background(), &config); err == nil {
+		t.Error("LoadStoredSecrets() of a missing secret should fail")
+	}
+
+	// Without a secret store, the secrets are left as they are.
+	plain := Config{GitHubToken: "from-env"}
+	if err := LoadStoredSecrets(context.Background(), &plain); err != nil || plain.GitHubToken != "from-env" {
+		t.Errorf("LoadStoredSecrets() without a store = %q, %v", plain.GitHubToken, err)
+	}
+}
@@ -0,0 +1,92 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// credentialFileSearches are the common credential file names counted by
+// secrets_hygiene.search_hits, each with its code search qualifier.
+var credentialFileSearches = []struct {
+	pattern   string
+	qualifier string
+}{
+	{"id_rsa", "filename:id_rsa"},
+	{".env", "filename:.env"},
+	{"*.pem", "extension:pem"},
+}
+
+// searchBudgetShare is the share of a run's remaining time the paced
+// credential searches may take, leaving the rest for the surfaces collected
+// after them.
+const searchBudgetShare = 0.5
+
+// collectSecretsHygiene counts committed files named like credentials in
+// in-scope repositories with code search (opt-in, audit+): a cheap signal for
+// orgs without secret scanning. One query per pattern covers the whole
+// account, paced by the client at code search's rate limit; the pacing is
+// bounded by searchBudgetShare of the run's remaining time, and searches
+// that don't fit leave the counts incomplete. Only counts are emitted, never
+// repositories, paths, or contents.
+func (c *Collector) collectSecretsHygiene(p *collectionPass) {
+	ctx := p.ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(float64(time.Until(deadline))*searchBudgetShare))
+		defer cancel()
+	}
+	scope := "org:" + p.org
+	if p.user {
+		scope = "user:" + p.org
+	}
+	inScope := map[string]bool{}
+	for r := range p.metrics.repos.all() {
+		inScope[strings.ToLower(r.Owner.Login+"/"+r.Name)] = true
+	}
+
+	hits := &SearchHits{}
+	withHits := map[string]bool{}
+	permissionDenied := false
+	var skipped []string
+	for _, s := range credentialFileSearches {
+		result, err := c.client.SearchCode(ctx, scope+" "+s.qualifier)
+		if err != nil {
+			permissionDenied = permissionDenied || isDenied(err)
+			if p.ctx.Err() == nil && (errors.Is(err, github.ErrSearchDeadline) || errors.Is(err, context.DeadlineExceeded)) {
+				skipped = append(skipped, s.pattern)
+			}
+			continue
+		}
+		row := SearchHitRow{Pattern: s.pattern, Incomplete: result.Incomplete}
+		for repo, n := range result.PerRepo {
+			repo = strings.ToLower(repo)
+			if !inScope[repo] {
+				continue
+			}
+			row.Hits += n
+			row.Repos++
+			withHits[repo] = true
+		}
+		hits.TotalHits += row.Hits
+		hits.Incomplete = hits.Incomplete || row.Incomplete
+		hits.ByPattern = append(hits.ByPattern, row)
+	}
+	if permissionDenied {
+		p.metrics.diag.surfacePermissionDenied("secrets_hygiene.search_hits", "contents:read")
+	}
+	if len(skipped) > 0 {
+		hits.Incomplete = true
+		p.metrics.diag.addWarning(fmt.Sprintf("secrets_hygiene.search_hits: %s not searched within the run's time budget", strings.Join(skipped, ", ")))
+	}
+	if len(hits.ByPattern) == 0 {
+		// No search succeeded, so zero hits would be unfounded.
+		return
+	}
+	hits.ReposWithHits = len(withHits)
+	p.posture.SecretsHygiene = &SecretsHygiene{SearchHits: hits}
+}
@@ -0,0 +1,71 @@
+package collector
+
+import "time"
+
+// SecretRotationDays is the default age after which an Actions secret that
+// hasn't been updated counts as overdue for rotation.
+const SecretRotationDays = 90
+
+// collectSecretsManagement gathers per-repo Actions secret counts and
+// last-updated times, flagging secrets not updated within the rotation
+// threshold. Audit emits counts and per-repo rows for repos with secrets;
+// internal adds each secret's name and last-updated time (never values).
+func (c *Collector) collectSecretsManagement(p *collectionPass) {
+	rotationDays := c.config.SecretRotationDays
+	if rotationDays <= 0 {
+		rotationDays = SecretRotationDays
+	}
+	staleBefore := time.Now().UTC().AddDate(0, 0, -rotationDays)
+
+	rs := &RepositorySecrets{RotationThresholdDays: rotationDays}
+	permissionDenied := false
+	read := 0
+
+	for r := range p.metrics.repos.all() {
+		secrets, err := c.client.ListRepoActionsSecrets(p.ctx, r.Owner.Login, r.Name)
+		if err != nil {
+			permissionDenied = permissionDenied || isDenied(err)
+			continue
+		}
+		read++
+		if len(secrets) == 0 {
+			continue
+		}
+
+		row := RepositorySecretRow{Repository: r.Owner.Login + "/" + r.Name, Count: len(secrets)}
+		var oldest time.Time
+		for _, s := range secrets {
+			updated, err := time.Parse(time.RFC3339, s.UpdatedAt)
+			if err == nil {
+				if updated.Before(staleBefore) {
+					row.Stale++
+				}
+				if oldest.IsZero() || updated.Before(oldest) {
+					oldest = updated
+				}
+			}
+			if p.internal() {
+				row.Secrets = append(row.Secrets, SecretAgeRow{Name: s.Name, UpdatedAt: s.UpdatedAt})
+			}
+		}
+		if !oldest.IsZero() {
+			row.OldestUpdatedAt = formatTime(oldest)
+		}
+
+		rs.TotalSecrets += row.Count
+		rs.StaleSecrets += row.Stale
+		rs.ReposWithSecrets++
+		if row.Stale > 0 {
+			rs.ReposWithStaleSecrets++
+		}
+		rs.PerRepo = append(rs.PerRepo, row)
+	}
+	if permissionDenied {
+		p.metrics.diag.surfacePermissionDenied("secrets_management.repository_secrets", "secrets:read")
+		if read == 0 {
+			// No repo was readable, so "no secrets" would be unfounded.
+			return
+		}
+	}
+	p.posture.SecretsManagement = &SecretsManagement{RepositorySecrets: rs}
+}
@@ -1,14 +1,25 @@
 package collector
 
+import (
+	"cmp"
+	"slices"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// SecretHotspotCount is the default number of repositories in the secret
+// scanning hot-spot list.
+const SecretHotspotCount = 10
+
 // augmentSecurityFeatures adds the audit-level per-repo feature rows (and, at
 // internal, the findings inventory). The trust-level percentages on
 // SecurityFeatures are left untouched.
 func (c *Collector) augmentSecurityFeatures(p *collectionPass) {
-	rows := make([]SecurityFeaturesRow, 0, len(p.metrics.repos.included))
+	rows := make([]SecurityFeaturesRow, 0, p.metrics.repos.len())
 	permissionDenied := false
 	featureOff := false
 
-	for _, repo := range p.metrics.repos.included {
+	for repo := range p.metrics.repos.all() {
 		owner := repo.Owner.Login
 		key := owner + "/" + repo.Name
 		settings := p.metrics.repos.settingsFor(owner, repo.Name)
@@ -17,11 +28,18 @@ func (c *Collector) augmentSecurityFeatures(p *collectionPass) {
 			Repository:          key,
 			VulnerabilityAlerts: repo.HasVulnerabilityAlertsEnabled,
 		}
+		row.Unknown, row.NotApplicable = controlStates(repo, settings, p.metrics.vulnerabilityAlertsUnknown[key])
 		if settings != nil {
 			row.CodeScanning = settings.CodeScanningEnabled
 			row.SecretScanning = settings.SecretScanning
 			row.SecretScanningPushProtection = settings.SecretScanningPushProtection
+			row.SecretScanningNonProviderPatterns = settings.SecretScanningNonProviderPatterns
+			row.SecretScanningValidityChecks = settings.SecretScanningValidityChecks
 			row.DependabotSecurityUpdates = settings.DependabotSecurityUpdates
+			row.AdvancedSecurity = settings.AdvancedSecurity
+			if settings.VulnerabilityAlerts != nil {
+				row.VulnerabilityAlerts = row.VulnerabilityAlerts || *settings.VulnerabilityAlerts
+			}
 		}
 
 		counts, err := c.client.GetOpenAlertCounts(p.ctx, owner, repo.Name)
@@ -43,12 +61,47 @@ func (c *Collector) augmentSecurityFeatures(p *collectionPass) {
 	recordAlertDiagnostic(p, "security_features.alert_counts", permissionDenied, featureOff)
 
 	p.posture.SecurityFeatures.PerRepo = rows
+	p.posture.SecurityFeatures.SecretScanningHotspots = c.secretHotspots(rows)
 
 	if p.internal() {
 		c.collectFindings(p)
 	}
 }
 
+// controlStates returns a repository's controls (see repoControlNames) whose
+// state is unknown and those that don't apply. Without settings every control
+// read from them is unknown, and vulnerability alerts GraphQL reported off
+// went unconfirmed. The security_and_analysis flags are unknown when GitHub
+// withheld the field.
+func controlStates(repo github.Repository, settings *github.SecuritySettings, alertsUnconfirmed bool) (unknown, notApplicable []string) {
+	if settings == nil {
+		if !repo.HasVulnerabilityAlertsEnabled {
+			unknown = append(unknown, "vulnerability_alerts")
+		}
+		return append(unknown, "code_scanning", "secret_scanning", "secret_scanning_push_protection", "dependabot_security_updates"), nil
+	}
+	if alertsUnconfirmed {
+		unknown = append(unknown, "vulnerability_alerts")
+	}
+	codeScanning, secretScanning := scanningUnavailable(isNonPublic(repo), settings)
+	switch {
+	case codeScanning:
+		notApplicable = append(notApplicable, "code_scanning")
+	case settings.CodeScanningPermissionDenied:
+		unknown = append(unknown, "code_scanning")
+	}
+	switch {
+	case secretScanning:
+		notApplicable = append(notApplicable, "secret_scanning", "secret_scanning_push_protection")
+	case !settings.AnalysisReported:
+		unknown = append(unknown, "secret_scanning", "secret_scanning_push_protection")
+	}
+	if !settings.AnalysisReported {
+		unknown = append(unknown, "dependabot_security_updates")
+	}
+	return unknown, notApplicable
+}
+
 // recordAlertDiagnostic records the right diagnostic for a security-alert
 // surface. A genuine permission denial is actionable (grant the scope); a
 // feature-not-enabled 403 is informational (the repo just doesn't have code /
@@ -63,3 +116,26 @@ func recordAlertDiagnostic(p *collectionPass, surface string, permissionDenied,
 			"code/secret scanning or Dependabot alerts not enabled on some repositories")
 	}
 }
+
+// secretHotspots returns the repositories with the most open secret scanning
+// alerts, most first (ties by name), so remediation can start where the
+// alerts concentrate. Repositories without open alerts are left out.
+func (c *Collector) secretHotspots(rows []SecurityFeaturesRow) []AlertHotspot {
+	limit := c.config.SecretHotspotCount
+	if limit <= 0 {
+		limit = SecretHotspotCount
+	}
+	var hotspots []AlertHotspot
+	for _, row := range rows {
+		if row.OpenSecretScanningAlerts > 0 {
+			hotspots = append(hotspots, AlertHotspot{Repository: row.Repository, OpenAlerts: row.OpenSecretScanningAlerts})
+		}
+	}
+	slices.SortFunc(hotspots, func(a, b AlertHotspot) int {
+		return cmp.Or(cmp.Compare(b.OpenAlerts, a.OpenAlerts), cmp.Compare(a.Repository, b.Repository))
+	})
+	if len(hotspots) > limit {
+		hotspots = hotspots[:limit]
+	}
+	return hotspots
+}
@@ -0,0 +1,31 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrDegraded is returned by Collect in strict mode when missing permissions
+// left metrics unknown or incomplete. The error message lists each one.
+var ErrDegraded = errors.New("collection degraded by missing permissions")
+
+// strictModeViolations lists the permission-related degradations in a
+// finished posture: an unknown 2FA requirement and every permission error
+// diagnostic. Feature-unavailable warnings and budget aborts aren't
+// permission problems and don't count.
+func strictModeViolations(posture *OrgPosture) []string {
+	var violations []string
+	if posture.OwnerType == OwnerTypeOrganization && posture.AccessControl.TwoFactorRequired == nil {
+		violations = append(violations, "access_control.two_factor_required unknown: grant organization administration: read")
+	}
+	if posture.Diagnostics != nil {
+		violations = append(violations, posture.Diagnostics.PermissionErrors...)
+	}
+	return violations
+}
+
+// strictModeError wraps ErrDegraded with the violations, one per line.
+func strictModeError(violations []string) error {
+	return fmt.Errorf("%w (strict_mode):\n  - %s", ErrDegraded, strings.Join(violations, "\n  - "))
+}
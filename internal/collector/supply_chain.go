@@ -0,0 +1,142 @@
+package collector
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"gopkg.in/yaml.v3"
+)
+
+// dependencyReviewAction is the action a required workflow must use to count
+// as dependency review enforcement.
+const dependencyReviewAction = "actions/dependency-review-action"
+
+// collectSupplyChain gathers the supply-chain controls (audit+).
+func (c *Collector) collectSupplyChain(p *collectionPass) {
+	sc := &SupplyChain{}
+	if p.rulesetsRead {
+		sc.DependencyReview = c.dependencyReview(p)
+	}
+	if releases, truncated, ok := c.loadReleases(p); ok {
+		if sc.Attestations = c.attestations(p, releases); sc.Attestations != nil {
+			sc.Attestations.Truncated = truncated
+		}
+		sc.Releases = releaseIntegrity(p, releases)
+		sc.Releases.Truncated = truncated
+	}
+	if *sc != (SupplyChain{}) {
+		p.posture.SupplyChain = sc
+	}
+}
+
+// dependencyReview reports which in-scope repositories must pass a dependency
+// review workflow before merging to their default branch, as required by a
+// ruleset workflows rule. Audit emits the rulesets and counts; internal adds
+// the repositories without enforcement. A ruleset whose required workflows
+// can't all be read is reported in UnreadableRulesets, and the repositories
+// only it might enforce count as indeterminate.
+func (c *Collector) dependencyReview(p *collectionPass) *DependencyReview {
+	var requiring []github.Ruleset
+	unreadable := make(map[string]bool)
+	for _, rs := range p.rulesets {
+		if rs.Enforcement == github.RulesetEnforcementDisabled {
+			continue
+		}
+		switch required, err := c.requiresDependencyReview(p, rs); {
+		case required:
+			requiring = append(requiring, rs)
+		case err != nil:
+			unreadable[rs.Name] = true
+			requiring = append(requiring, rs)
+			if isDenied(err) {
+				p.metrics.diag.surfacePermissionDenied("supply_chain.dependency_review", "contents:read")
+			} else {
+				p.metrics.diag.addWarning(fmt.Sprintf("supply_chain.dependency_review: ruleset %q required workflow unreadable, its repositories are indeterminate: %v", rs.Name, err))
+			}
+		}
+	}
+
+	dr := &DependencyReview{}
+	for _, rs := range requiring {
+		if unreadable[rs.Name] {
+			dr.UnreadableRulesets = append(dr.UnreadableRulesets, rs.Name)
+		} else {
+			dr.Rulesets = append(dr.Rulesets, rs.Name)
+		}
+	}
+	slices.Sort(dr.Rulesets)
+	slices.Sort(dr.UnreadableRulesets)
+
+	cov := coverageWith(p, requiring, unreadable)
+	dr.TotalRepos = cov.TotalRepos
+	dr.EnforcedRepos = cov.EnforcedRepos
+	dr.EvaluateOnlyRepos = cov.EvaluateOnlyRepos
+	dr.IndeterminateRepos = cov.IndeterminateRepos
+	dr.Coverage = cov.Coverage
+	dr.UnenforcedRepos = cov.uncovered
+	return dr
+}
+
+// requiresDependencyReview reports whether a ruleset requires a workflow that
+// runs the dependency review action. Each required workflow file is read
+// and its uses: fields checked; err is the first read or parse failure when
+// no readable workflow runs the action, leaving the answer unknown.
+func (c *Collector) requiresDependencyReview(p *collectionPass, rs github.Ruleset) (bool, error) {
+	var firstErr error
+	for _, rule := range rs.Rules {
+		for _, wf := range rule.Workflows {
+			content, err := c.client.GetWorkflowFile(p.ctx, wf.RepositoryID, wf.Path, wf.Ref)
+			if err == nil {
+				var uses []string
+				if uses, err = workflowUses(content); err == nil && slices.ContainsFunc(uses, isDependencyReviewAction) {
+					return true, nil
+				}
+			}
+			if err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", wf.Path, err)
+			}
+		}
+	}
+	return false, firstErr
+}
+
+// workflow is the part of a workflow file that names the actions and
+// reusable workflows it runs.
+type workflow struct {
+	Jobs map[string]struct {
+		Uses  string `yaml:"uses"`
+		Steps []struct {
+			Uses string `yaml:"uses"`
+		} `yaml:"steps"`
+	} `yaml:"jobs"`
+}
+
+// workflowUses returns the uses: values of a workflow file's jobs and steps.
+// Comments and other keys are ignored.
+func workflowUses(content []byte) ([]string, error) {
+	var wf workflow
+	if err := yaml.Unmarshal(content, &wf); err != nil {
+		return nil, err
+	}
+	var uses []string
+	for _, job := range wf.Jobs {
+		if job.Uses != "" {
+			uses = append(uses, job.Uses)
+		}
+		for _, step := range job.Steps {
+			if step.Uses != "" {
+				uses = append(uses, step.Uses)
+			}
+		}
+	}
+	return uses, nil
+}
+
+// isDependencyReviewAction reports whether a uses: value is the dependency
+// review action at any ref.
+func isDependencyReviewAction(uses string) bool {
+	name, _, _ := strings.Cut(uses, "@")
+	return strings.EqualFold(name, dependencyReviewAction)
+}
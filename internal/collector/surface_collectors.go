@@ -1,6 +1,9 @@
 package collector
 
 import (
+	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,7 +17,7 @@ const (
 	AuditLogCap = 5000
 )
 
-// AuditLogWindowDays bounds the audit-log lookback.
+// AuditLogWindowDays is the default audit-log lookback.
 const AuditLogWindowDays = 7
 
 // collectRepositories builds the repo inventory from the GraphQL data already
@@ -22,9 +25,9 @@ const AuditLogWindowDays = 7
 // branch-protection detail; internal adds low-sensitivity metadata.
 func (c *Collector) collectRepositories(p *collectionPass) {
 	repos := &Repositories{}
-	rows := make([]RepoRow, 0, len(p.metrics.repos.included))
+	rows := make([]RepoRow, 0, p.metrics.repos.len())
 
-	for _, r := range p.metrics.repos.included {
+	for r := range p.metrics.repos.all() {
 		repos.TotalCount++
 		switch strings.ToUpper(r.Visibility) {
 		case "PUBLIC":
@@ -37,7 +40,8 @@ func (c *Collector) collectRepositories(p *collectionPass) {
 		if r.IsArchived {
 			repos.ArchivedCount++
 		}
-		if r.DefaultBranchRef.BranchProtectionRule != nil {
+		bp := p.metrics.protection(r)
+		if bp != nil {
 			repos.DefaultProtected++
 		}
 
@@ -51,12 +55,16 @@ func (c *Collector) collectRepositories(p *collectionPass) {
 			UpdatedAt:     formatTime(r.UpdatedAt.Time),
 			PushedAt:      formatTime(r.PushedAt.Time),
 			SizeKB:        r.DiskUsage,
+			Class:         classifyRepo(r),
 		}
 		if r.PrimaryLanguage != nil {
 			row.PrimaryLanguage = r.PrimaryLanguage.Name
 		}
-		if bp := r.DefaultBranchRef.BranchProtectionRule; bp != nil {
+		row.CISystems = r.CISystems()
+		if bp != nil {
 			row.BranchProtection = &BranchProtectionDetail{
+				ClassicRule:                    bp.Classic,
+				Rulesets:                       bp.Rulesets,
 				RequiresApprovingReviews:       bp.RequiresApprovingReviews,
 				RequiredApprovingReviewCount:   bp.RequiredApprovingReviewCount,
 				DismissesStaleReviews:          bp.DismissesStaleReviews,
@@ -68,6 +76,7 @@ func (c *Collector) collectRepositories(p *collectionPass) {
 				AllowsForcePushes:              bp.AllowsForcePushes,
 				AllowsDeletions:                bp.AllowsDeletions,
 				RequiresConversationResolution: bp.RequiresConversationResolution,
+				RequireLastPushApproval:        bp.RequireLastPushApproval,
 			}
 		}
 		if p.internal() {
@@ -83,7 +92,7 @@ func (c *Collector) collectRepositories(p *collectionPass) {
 		rows = append(rows, row)
 	}
 
-	kept, dropped, truncated := Truncate(rows, ReposCap, func(a, b RepoRow) bool {
+	kept, dropped, truncated := Truncate(rows, c.listCap(ListRepositories), func(a, b RepoRow) bool {
 		// Private first, then most-recently-pushed first.
 		ap, bp := a.Visibility == "PRIVATE", b.Visibility == "PRIVATE"
 		if ap != bp {
@@ -100,27 +109,63 @@ func (c *Collector) collectRepositories(p *collectionPass) {
 // collectCodeowners checks each repo for a CODEOWNERS file. Audit emits
 // presence + path; internal adds a content hash (bytes never emitted).
 func (c *Collector) collectCodeowners(p *collectionPass) {
-	wantHash := p.internal()
-	rows := make([]CodeownersRow, 0, len(p.metrics.repos.included))
+	detail := p.internal()
+	rows := make([]CodeownersRow, 0, p.metrics.repos.len())
+	teams := map[string]*CodeownersTeamRow{}
 	permissionDenied := false
 
-	for _, r := range p.metrics.repos.included {
-		present, path, hash, err := c.client.GetCodeownersInfo(p.ctx, r.Owner.Login, r.Name, wantHash)
+	for r := range p.metrics.repos.all() {
+		info, err := c.client.GetCodeownersInfo(p.ctx, r.Owner.Login, r.Name, detail)
 		if err != nil {
 			permissionDenied = permissionDenied || isDenied(err)
 			continue
 		}
 		rows = append(rows, CodeownersRow{
 			Repository: r.Owner.Login + "/" + r.Name,
-			Present:    present,
-			Path:       path,
-			Hash:       hash,
+			Present:    info.Present,
+			Path:       info.Path,
+			Hash:       info.Hash,
+			OwningTeam: info.OwningTeam,
 		})
+		if info.OwningTeam == "" {
+			continue
+		}
+		team := teams[info.OwningTeam]
+		if team == nil {
+			team = &CodeownersTeamRow{Team: info.OwningTeam}
+			teams[info.OwningTeam] = team
+		}
+		team.OwnedRepos++
+		if p.metrics.protection(r) == nil {
+			team.UnprotectedRepos++
+		}
 	}
 	if permissionDenied {
 		p.metrics.diag.surfacePermissionDenied("codeowners", "contents:read")
 	}
-	p.posture.Codeowners = &Codeowners{PerRepo: rows}
+	co := &Codeowners{PerRepo: rows}
+	for _, name := range slices.Sorted(maps.Keys(teams)) {
+		co.Teams = append(co.Teams, *teams[name])
+	}
+	p.posture.Codeowners = co
+	attachOwningTeams(p.posture)
+}
+
+// attachOwningTeams copies each repository's CODEOWNERS owning team onto
+// its repositories.per_repo row, so findings can be routed from either.
+func attachOwningTeams(p *OrgPosture) {
+	if p.Repositories == nil || p.Codeowners == nil {
+		return
+	}
+	owning := make(map[string]string, len(p.Codeowners.PerRepo))
+	for _, row := range p.Codeowners.PerRepo {
+		if row.OwningTeam != "" {
+			owning[row.Repository] = row.OwningTeam
+		}
+	}
+	for i := range p.Repositories.PerRepo {
+		p.Repositories.PerRepo[i].OwningTeam = owning[p.Repositories.PerRepo[i].Name]
+	}
 }
 
 // collectWebhooks gathers org + repo webhooks. Audit emits counts + by-event
@@ -129,20 +174,22 @@ func (c *Collector) collectWebhooks(p *collectionPass) {
 	w := &Webhooks{CountByEvent: map[string]int{}}
 	permissionDenied := false
 
-	orgHooks, err := c.client.ListOrgHooks(p.ctx, p.org)
-	if err != nil {
-		permissionDenied = permissionDenied || isDenied(err)
-	} else {
-		w.OrgCount = len(orgHooks)
-		for _, h := range orgHooks {
-			tallyHookEvents(w.CountByEvent, h.Events)
-			if p.internal() {
-				w.Org = append(w.Org, toWebhookRow("", h))
+	if !p.user {
+		orgHooks, err := c.client.ListOrgHooks(p.ctx, p.org)
+		if err != nil {
+			permissionDenied = permissionDenied || isDenied(err)
+		} else {
+			w.OrgCount = len(orgHooks)
+			for _, h := range orgHooks {
+				tallyHookEvents(w.CountByEvent, h.Events)
+				if p.internal() {
+					w.Org = append(w.Org, toWebhookRow("", h))
+				}
 			}
 		}
 	}
 
-	for _, r := range p.metrics.repos.included {
+	for r := range p.metrics.repos.all() {
 		hooks, herr := c.client.ListRepoHooks(p.ctx, r.Owner.Login, r.Name)
 		if herr != nil {
 			permissionDenied = permissionDenied || isDenied(herr)
@@ -189,7 +236,7 @@ func (c *Collector) collectDeployKeys(p *collectionPass) {
 	dk := &DeployKeys{}
 	permissionDenied := false
 
-	for _, r := range p.metrics.repos.included {
+	for r := range p.metrics.repos.all() {
 		keys, err := c.client.ListRepoDeployKeys(p.ctx, r.Owner.Login, r.Name)
 		if err != nil {
 			permissionDenied = permissionDenied || isDenied(err)
@@ -220,46 +267,44 @@ func (c *Collector) collectDeployKeys(p *collectionPass) {
 	p.posture.DeployKeys = dk
 }
 
-// collectActions gathers self-hosted runners + org Actions secret names. Audit
-// emits counts; internal adds per-runner rows + secret names.
+// collectActions gathers self-hosted runners + org Actions secret names, and
+// flags runners exposed to public repositories. Audit emits counts and flags;
+// internal adds per-runner rows + secret names.
 func (c *Collector) collectActions(p *collectionPass) {
-	a := &Actions{}
+	a := &Actions{PublicRepoExposure: &RunnerPublicExposure{}}
 	permissionDenied := false
+	publicRunners, publicUnread := false, false
 
-	if orgRunners, err := c.client.ListOrgRunners(p.ctx, p.org); err != nil {
-		permissionDenied = permissionDenied || isDenied(err)
-	} else {
-		a.OrgRunnerCount = len(orgRunners)
-		if p.internal() {
-			for _, r := range orgRunners {
-				a.OrgRunners = append(a.OrgRunners, toRunnerRow("", r))
-			}
-		}
-	}
-
-	if names, err := c.client.ListOrgActionsSecretNames(p.ctx, p.org); err != nil {
-		permissionDenied = permissionDenied || isDenied(err)
-	} else {
-		a.OrgSecretCount = len(names)
-		if p.internal() {
-			a.OrgSecretNames = names
-		}
+	if !p.user {
+		permissionDenied = c.collectOrgActions(p, a)
 	}
 
-	for _, r := range p.metrics.repos.included {
+	for r := range p.metrics.repos.all() {
 		runners, err := c.client.ListRepoRunners(p.ctx, r.Owner.Login, r.Name)
 		if err != nil {
 			permissionDenied = permissionDenied || isDenied(err)
+			publicUnread = publicUnread || r.Visibility == "PUBLIC"
 			continue
 		}
 		a.RepoRunnerCount += len(runners)
+		repoKey := r.Owner.Login + "/" + r.Name
+		if len(runners) > 0 && r.Visibility == "PUBLIC" {
+			publicRunners = true
+			if p.internal() {
+				a.PublicRepoExposure.PublicRepos = append(a.PublicRepoExposure.PublicRepos, repoKey)
+			}
+		}
 		if p.internal() {
-			repoKey := r.Owner.Login + "/" + r.Name
 			for _, rn := range runners {
 				a.RepoRunners = append(a.RepoRunners, toRunnerRow(repoKey, rn))
 			}
 		}
 	}
+	// A public repository whose runners couldn't be listed leaves the flag
+	// unknown unless another one already shows exposure.
+	if publicRunners || !publicUnread {
+		a.PublicRepoExposure.PublicRepoRunners = &publicRunners
+	}
 
 	if permissionDenied {
 		p.metrics.diag.surfacePermissionDenied("actions",
@@ -268,6 +313,48 @@ func (c *Collector) collectActions(p *collectionPass) {
 	p.posture.Actions = a
 }
 
+// collectOrgActions adds the org-level runners, runner groups open to public
+// repositories and Actions secret names, reporting whether any call was
+// denied.
+func (c *Collector) collectOrgActions(p *collectionPass, a *Actions) (permissionDenied bool) {
+	if orgRunners, err := c.client.ListOrgRunners(p.ctx, p.org); err != nil {
+		permissionDenied = isDenied(err)
+	} else {
+		a.OrgRunnerCount = len(orgRunners)
+		if p.internal() {
+			for _, r := range orgRunners {
+				a.OrgRunners = append(a.OrgRunners, toRunnerRow("", r))
+			}
+		}
+	}
+
+	if groups, err := c.client.ListRunnerGroups(p.ctx, p.org); err != nil {
+		permissionDenied = permissionDenied || isDenied(err)
+	} else {
+		exposed := false
+		for _, g := range groups {
+			if !g.AllowsPublicRepositories || g.Runners == 0 {
+				continue
+			}
+			exposed = true
+			if p.internal() {
+				a.PublicRepoExposure.RunnerGroups = append(a.PublicRepoExposure.RunnerGroups, g.Name)
+			}
+		}
+		a.PublicRepoExposure.PublicRunnerGroups = &exposed
+	}
+
+	if names, err := c.client.ListOrgActionsSecretNames(p.ctx, p.org); err != nil {
+		permissionDenied = permissionDenied || isDenied(err)
+	} else {
+		a.OrgSecretCount = len(names)
+		if p.internal() {
+			a.OrgSecretNames = names
+		}
+	}
+	return permissionDenied
+}
+
 func toRunnerRow(repo string, r github.Runner) RunnerRow {
 	return RunnerRow{
 		Repository: repo,
@@ -286,8 +373,8 @@ func toRunnerRow(repo string, r github.Runner) RunnerRow {
 // which collectMembers consumes for per-member last-activity. Returns nil when
 // the surface is skipped (feature unavailable or permission denied).
 func (c *Collector) collectAuditLog(p *collectionPass) map[string]int64 {
-	since := time.Now().UTC().AddDate(0, 0, -AuditLogWindowDays).Format("2006-01-02")
-	events, more, err := c.client.GetOrgAuditLog(p.ctx, p.org, since, AuditLogCap)
+	window := c.window(LookbackAuditLog)
+	events, more, err := c.client.GetOrgAuditLog(p.ctx, p.org, window.startDate(), c.listCap(ListAuditLog))
 	if err != nil {
 		if isFeatureUnavailable(err) {
 			p.metrics.diag.surfaceUnavailable("audit_log", "requires GitHub Enterprise Cloud")
@@ -297,7 +384,7 @@ func (c *Collector) collectAuditLog(p *collectionPass) map[string]int64 {
 		return nil
 	}
 
-	al := &AuditLog{WindowDays: AuditLogWindowDays, CountByCategory: map[string]int{}}
+	al := &AuditLog{WindowDays: window.days, CountByCategory: map[string]int{}}
 	activity := map[string]int64{}
 
 	for _, e := range events {
@@ -317,7 +404,13 @@ func (c *Collector) collectAuditLog(p *collectionPass) map[string]int64 {
 	if more {
 		al.Truncated = true
 	}
+	al.TimeToProtection = timeToProtection(p.metrics.repos.all(), p.rulesets, events, window)
 	p.posture.AuditLog = al
+	p.posture.RecentChanges = &RecentChanges{
+		WindowDays:          window.days,
+		Truncated:           al.Truncated,
+		ProtectionOverrides: protectionOverrides(events),
+	}
 	return activity
 }
 
@@ -330,7 +423,8 @@ func categoryOf(action string) string {
 }
 
 // collectApps gathers GitHub Apps installed in the org. Audit emits count +
-// per-installation summary; internal adds timestamps + repo selection + events.
+// per-installation summary, and third_party_apps the privileged installation
+// counts; internal adds timestamps + repo selection + events.
 func (c *Collector) collectApps(p *collectionPass) {
 	installs, err := c.client.ListOrgInstallations(p.ctx, p.org)
 	if err != nil {
@@ -340,7 +434,17 @@ func (c *Collector) collectApps(p *collectionPass) {
 		return
 	}
 	apps := &Apps{InstallationCount: len(installs)}
+	thirdParty := &ThirdPartyApps{InstallationCount: len(installs)}
 	for _, i := range installs {
+		if appHasAdmin(i.Permissions) {
+			thirdParty.AdminCount++
+		}
+		if i.Permissions["contents"] == "write" {
+			thirdParty.ContentsWriteCount++
+		}
+		if i.RepositorySelection == "all" {
+			thirdParty.AllReposCount++
+		}
 		row := AppRow{
 			AppSlug:     i.AppSlug,
 			AppID:       i.AppID,
@@ -356,14 +460,28 @@ func (c *Collector) collectApps(p *collectionPass) {
 		apps.PerInstallation = append(apps.PerInstallation, row)
 	}
 	p.posture.Apps = apps
+	p.posture.ThirdPartyApps = thirdParty
+}
+
+// appHasAdmin reports whether an installation's permissions amount to admin:
+// an admin grant on anything, or write on an administration permission.
+func appHasAdmin(perms map[string]string) bool {
+	for name, level := range perms {
+		if level == "admin" || level == "write" && (name == "administration" || name == "organization_administration") {
+			return true
+		}
+	}
+	return false
 }
 
 // collectTokens gathers fine-grained PAT grants (Enterprise / FGT-policy orgs
 // only). Audit emits the count; internal adds per-token metadata (no values).
 func (c *Collector) collectTokens(p *collectionPass) {
-	grants, _, err := c.client.ListOrgPATs(p.ctx, p.org)
+	grants, truncated, err := c.client.ListOrgPATs(p.ctx, p.org)
 	if err != nil {
-		if isFeatureUnavailable(err) {
+		if isUnsupported(err) {
+			p.metrics.diag.unsupportedOnInstance("surface tokens", p.metrics.instance)
+		} else if isFeatureUnavailable(err) {
 			p.metrics.diag.surfaceUnavailable("tokens", "requires a fine-grained personal-access-token policy")
 		} else if isDenied(err) {
 			p.metrics.diag.surfacePermissionDenied("tokens", "organization_personal_access_tokens:read")
@@ -384,6 +502,26 @@ func (c *Collector) collectTokens(p *collectionPass) {
 		}
 	}
 	p.posture.Tokens = tokens
+	c.augmentPATPolicy(p, len(grants), truncated)
+}
+
+// augmentPATPolicy adds access_control.pat_policy once the token grants have
+// been read: the approved count and the requests awaiting approval. Either
+// list stopping at PATFetchCap marks the counts as lower bounds.
+func (c *Collector) augmentPATPolicy(p *collectionPass, approved int, grantsTruncated bool) {
+	pending, requestsTruncated, err := c.client.CountOrgPATRequests(p.ctx, p.org)
+	if err != nil {
+		if isDenied(err) {
+			p.metrics.diag.surfacePermissionDenied("access_control.pat_policy", "organization_personal_access_token_requests:read")
+		}
+		return
+	}
+	policy := &PATPolicy{ApprovedTokens: approved, PendingRequests: pending}
+	if grantsTruncated || requestsTruncated {
+		policy.Truncated = true
+		p.metrics.diag.addWarning(fmt.Sprintf("access_control.pat_policy: token grants or requests truncated at %d; counts are lower bounds", github.PATFetchCap))
+	}
+	p.posture.AccessControl.PATPolicy = policy
 }
 
 // collectMembers builds the member inventory. Audit emits counts + per-member
@@ -436,7 +574,7 @@ func (c *Collector) collectMembers(p *collectionPass, activity map[string]int64)
 		rows = append(rows, row)
 	}
 
-	kept, dropped, truncated := Truncate(rows, MembersCap, func(a, b MemberRow) bool {
+	kept, dropped, truncated := Truncate(rows, c.listCap(ListMembers), func(a, b MemberRow) bool {
 		ra, rb := roleRank(a.Role), roleRank(b.Role)
 		if ra != rb {
 			return ra < rb
@@ -0,0 +1,207 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// Repository tiers, from most to least critical. A repository belongs to the
+// first tier whose rule it matches; TierNormal holds the rest.
+const (
+	TierCritical = "critical"
+	TierHigh     = "high"
+	TierNormal   = "normal"
+)
+
+// tierNames lists the tiers in assignment order.
+var tierNames = []string{TierCritical, TierHigh, TierNormal}
+
+// tierThresholdMetrics are the coverage_breakdown segment metrics a tier
+// threshold can name.
+var tierThresholdMetrics = []string{
+	MetricBranchProtection,
+	MetricVulnerabilityAlerts,
+	MetricCodeScanning,
+	MetricSecretScanning,
+	FeatureSecretScanningPushProtection,
+	MetricDependabotSecurityUpdates,
+}
+
+// TierRule assigns repositories to one tier: those matching an include
+// pattern or any of the custom property values, and no exclude pattern.
+// Thresholds is the minimum percentage per segment metric (e.g.
+// {"branch_protection": 100}). The normal tier takes thresholds only.
+type TierRule struct {
+	Include    []string            `json:"include,omitempty"`
+	Exclude    []string            `json:"exclude,omitempty"`
+	Properties map[string][]string `json:"properties,omitempty"`
+	Thresholds map[string]int      `json:"thresholds,omitempty"`
+}
+
+// repoTiers is the compiled tiers config. values holds the org's custom
+// property values (repository name -> property -> values) when a rule
+// matches on properties; incomplete is set when they couldn't all be read,
+// which leaves every tier's membership, and so its thresholds, unknown.
+type repoTiers struct {
+	org        string
+	rules      map[string]compiledTierRule
+	thresholds map[string]map[string]int
+	values     map[string]map[string][]string
+	incomplete bool
+}
+
+type compiledTierRule struct {
+	include    []*regexp.Regexp
+	exclude    []*regexp.Regexp
+	properties map[string][]string
+}
+
+// newRepoTiers compiles the tiers config, rejecting unknown tiers and
+// metrics, out-of-range thresholds, invalid patterns, and critical or high
+// rules matching nothing. It returns nil when no tiers are configured.
+func newRepoTiers(config Config) (*repoTiers, error) {
+	if len(config.Tiers) == 0 {
+		return nil, nil
+	}
+	tiers := &repoTiers{
+		org:        config.Organization,
+		rules:      make(map[string]compiledTierRule),
+		thresholds: make(map[string]map[string]int),
+	}
+	for name, rule := range config.Tiers {
+		if !slices.Contains(tierNames, name) {
+			return nil, fmt.Errorf("tiers: unknown tier %q (want one of %s)", name, strings.Join(tierNames, ", "))
+		}
+		for metric, minimum := range rule.Thresholds {
+			if !slices.Contains(tierThresholdMetrics, metric) {
+				return nil, fmt.Errorf("tiers.%s.thresholds: unknown metric %q (want one of %s)", name, metric, strings.Join(tierThresholdMetrics, ", "))
+			}
+			if minimum < 0 || minimum > MaxPercentage {
+				return nil, fmt.Errorf("tiers.%s.thresholds.%s: must be between 0 and %d, got %d", name, metric, MaxPercentage, minimum)
+			}
+		}
+		if len(rule.Thresholds) > 0 {
+			tiers.thresholds[name] = rule.Thresholds
+		}
+		if name == TierNormal {
+			if len(rule.Include) > 0 || len(rule.Exclude) > 0 || len(rule.Properties) > 0 {
+				return nil, fmt.Errorf("tiers.%s: takes thresholds only; it holds the repositories no other tier matches", name)
+			}
+			continue
+		}
+		if len(rule.Include) == 0 && len(rule.Properties) == 0 {
+			return nil, fmt.Errorf("tiers.%s: requires include patterns or properties", name)
+		}
+		include, err := compilePatterns("tiers."+name+".include", rule.Include, config.CaseInsensitivePatterns)
+		if err != nil {
+			return nil, err
+		}
+		exclude, err := compilePatterns("tiers."+name+".exclude", rule.Exclude, config.CaseInsensitivePatterns)
+		if err != nil {
+			return nil, err
+		}
+		tiers.rules[name] = compiledTierRule{include: include, exclude: exclude, properties: rule.Properties}
+	}
+	return tiers, nil
+}
+
+// usesProperties reports whether any rule matches on custom properties.
+func (t *repoTiers) usesProperties() bool {
+	for _, rule := range t.rules {
+		if len(rule.properties) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// assign returns a repository's tier. Custom properties are only known for
+// the org's own repositories.
+func (t *repoTiers) assign(repo github.Repository) string {
+	var props map[string][]string
+	if strings.EqualFold(repo.Owner.Login, t.org) {
+		props = t.values[repo.Name]
+	}
+	for _, name := range tierNames {
+		rule, ok := t.rules[name]
+		if ok && rule.matches(repo.Name, props) {
+			return name
+		}
+	}
+	return TierNormal
+}
+
+func (r compiledTierRule) matches(name string, props map[string][]string) bool {
+	for _, re := range r.exclude {
+		if re.MatchString(name) {
+			return false
+		}
+	}
+	for _, re := range r.include {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	for property, accepted := range r.properties {
+		for _, value := range props[property] {
+			if slices.Contains(accepted, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// loadTierProperties reads the org's custom property values before
+// enumeration, for the tier rules matching on them. On a failure the rules
+// match on patterns alone, the tiers are incomplete, and a diagnostic is
+// recorded; past PropertyValuesFetchCap repositories likewise.
+func (c *Collector) loadTierProperties(ctx context.Context, tiers *repoTiers, metrics *metricsAggregator) {
+	values, truncated, err := c.client.ListRepositoryPropertyValues(ctx, c.config.Organization)
+	if err != nil {
+		tiers.incomplete = true
+		if isDenied(err) {
+			metrics.diag.surfacePermissionDenied("tiers", "organization_custom_properties:read")
+		} else {
+			metrics.diag.addWarning("tiers: custom property values unavailable, matching on patterns only; thresholds unknown: " + err.Error())
+		}
+		return
+	}
+	if truncated {
+		tiers.incomplete = true
+		metrics.diag.addWarning(fmt.Sprintf("tiers: custom property values truncated at %d repositories; thresholds unknown", github.PropertyValuesFetchCap))
+	}
+	tiers.values = values
+}
+
+// tierCoverage converts a tier's tally to its coverage, evaluating the
+// tier's thresholds sorted by metric. Whether a threshold is met is unknown
+// (nil) for an empty tier, which has nothing to measure, and when the tier's
+// membership is incomplete.
+func tierCoverage(counts segmentCounts, thresholds map[string]int, incomplete bool) TierCoverage {
+	coverage := TierCoverage{CoverageSegment: counts.toSegment()}
+	enabled := map[string]int{
+		MetricBranchProtection:              counts.branchProtection,
+		MetricVulnerabilityAlerts:           counts.vulnerabilityAlerts,
+		MetricCodeScanning:                  counts.codeScanning,
+		MetricSecretScanning:                counts.secretScanning,
+		FeatureSecretScanningPushProtection: counts.secretScanningPushProtection,
+		MetricDependabotSecurityUpdates:     counts.dependabotSecurityUpdates,
+	}
+	for _, metric := range slices.Sorted(maps.Keys(thresholds)) {
+		actual := percent(enabled[metric], counts.repos)
+		threshold := TierThreshold{Metric: metric, Minimum: thresholds[metric], Actual: actual}
+		if counts.repos > 0 && !incomplete {
+			met := actual >= thresholds[metric]
+			threshold.Met = &met
+		}
+		coverage.Thresholds = append(coverage.Thresholds, threshold)
+	}
+	return coverage
+}
@@ -0,0 +1,88 @@
+package collector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+func TestNewRepoTiers(t *testing.T) {
+	tests := []struct {
+		name    string
+		tiers   map[string]TierRule
+		wantErr bool
+	}{
+		{"unset", nil, false},
+		{"patterns and thresholds", map[string]TierRule{
+			TierCritical: {Include: []string{"payments-*"}, Thresholds: map[string]int{"branch_protection": 100}},
+			TierNormal:   {Thresholds: map[string]int{"secret_scanning": 80}},
+		}, false},
+		{"properties", map[string]TierRule{TierHigh: {Properties: map[string][]string{"tier": {"high"}}}}, false},
+		{"unknown tier", map[string]TierRule{"gold": {Include: []string{"*"}}}, true},
+		{"matches nothing", map[string]TierRule{TierCritical: {Exclude: []string{"sandbox-*"}}}, true},
+		{"normal with patterns", map[string]TierRule{TierNormal: {Include: []string{"*"}}}, true},
+		{"unknown metric", map[string]TierRule{TierCritical: {Include: []string{"*"}, Thresholds: map[string]int{"coverage": 90}}}, true},
+		{"threshold above 100", map[string]TierRule{TierCritical: {Include: []string{"*"}, Thresholds: map[string]int{"code_scanning": 101}}}, true},
+		{"invalid pattern", map[string]TierRule{TierCritical: {Include: []string{"re:("}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newRepoTiers(Config{Organization: "test-org", Tiers: tt.tiers}); (err != nil) != tt.wantErr {
+				t.Errorf("newRepoTiers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRepoTiers_Assign(t *testing.T) {
+	tiers, err := newRepoTiers(Config{Organization: "test-org", Tiers: map[string]TierRule{
+		TierCritical: {Include: []string{"payments-*"}, Exclude: []string{"*-sandbox"}, Properties: map[string][]string{"criticality": {"critical"}}},
+		TierHigh:     {Include: []string{"*-api", "payments-*"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tiers.values = map[string]map[string][]string{"ledger": {"criticality": {"critical"}}}
+
+	for name, want := range map[string]string{
+		"payments-core":    TierCritical,
+		"ledger":           TierCritical,
+		"payments-sandbox": TierHigh,
+		"search-api":       TierHigh,
+		"handbook":         TierNormal,
+	} {
+		repo := github.Repository{Name: name}
+		repo.Owner.Login = "test-org"
+		if got := tiers.assign(repo); got != want {
+			t.Errorf("assign(%s) = %q, want %q", name, got, want)
+		}
+	}
+
+	// Another owner's same-named repository has no known properties.
+	other := github.Repository{Name: "ledger"}
+	other.Owner.Login = "partner"
+	if got := tiers.assign(other); got != TierNormal {
+		t.Errorf("assign(partner/ledger) = %q, want %q", got, TierNormal)
+	}
+}
+
+func TestTierCoverage(t *testing.T) {
+	counts := segmentCounts{repos: 3, branchProtection: 3, secretScanning: 2}
+	got := tierCoverage(counts, map[string]int{"secret_scanning": 100, "branch_protection": 100}, false)
+	yes, no := true, false
+	want := []TierThreshold{
+		{Metric: "branch_protection", Minimum: 100, Actual: 100, Met: &yes},
+		{Metric: "secret_scanning", Minimum: 100, Actual: 66, Met: &no},
+	}
+	if !reflect.DeepEqual(got.Thresholds, want) {
+		t.Errorf("Thresholds = %+v, want %+v", got.Thresholds, want)
+	}
+
+	if empty := tierCoverage(segmentCounts{}, map[string]int{"code_scanning": 100}, false); empty.Thresholds[0].Met != nil {
+		t.Errorf("an empty tier's thresholds should be unknown: %+v", empty.Thresholds)
+	}
+	if partial := tierCoverage(counts, map[string]int{"branch_protection": 100}, true); partial.Thresholds[0].Met != nil {
+		t.Errorf("an incomplete tier's thresholds should be unknown: %+v", partial.Thresholds)
+	}
+}
@@ -0,0 +1,85 @@
+package collector
+
+import (
+	"iter"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+)
+
+// protectionCreateAction is the audit action recorded when a branch
+// protection rule is added.
+const protectionCreateAction = "protected_branch.create"
+
+// timeToProtection measures how long repositories created within the audit
+// window went without branch protection: from the repository's creation to
+// its first protected_branch.create event. Repositories protected by other
+// means (rulesets, or a rule whose event fell outside a truncated log) are
+// counted as unattributed rather than guessed at; rulesets decide, with
+// classic rules, whether a repository is protected at all.
+func timeToProtection(repos iter.Seq[github.Repository], rulesets []github.Ruleset, events []github.AuditEvent, window timeWindow) *TimeToProtection {
+	firstProtected := map[string]time.Time{}
+	for _, e := range events {
+		if e.Action != protectionCreateAction || e.Repo == "" {
+			continue
+		}
+		key := strings.ToLower(e.Repo)
+		at := auditEventTime(e.CreatedAt)
+		if first, ok := firstProtected[key]; !ok || at.Before(first) {
+			firstProtected[key] = at
+		}
+	}
+
+	ttp := &TimeToProtection{}
+	var latencies []float64
+	for r := range repos {
+		created := r.CreatedAt.Time
+		if created.IsZero() || !window.contains(created) {
+			continue
+		}
+		ttp.ReposCreated++
+		protectedAt, ok := firstProtected[strings.ToLower(r.Owner.Login+"/"+r.Name)]
+		switch {
+		case ok && !protectedAt.Before(created):
+			ttp.ProtectedCount++
+			latencies = append(latencies, protectedAt.Sub(created).Hours())
+		case resolveProtection(r, rulesets) == nil:
+			ttp.UnprotectedCount++
+		default:
+			ttp.UnattributedCount++
+		}
+	}
+	if len(latencies) > 0 {
+		median := roundHours(medianOf(latencies))
+		ttp.MedianHours = &median
+	}
+	return ttp
+}
+
+// auditEventTime converts an audit event timestamp to a time. GitHub reports
+// milliseconds since the epoch; values small enough to be seconds are
+// accepted as such.
+func auditEventTime(ts int64) time.Time {
+	if ts > 1e12 {
+		return time.UnixMilli(ts).UTC()
+	}
+	return time.Unix(ts, 0).UTC()
+}
+
+// medianOf returns the median of values, which must be non-empty.
+func medianOf(values []float64) float64 {
+	sorted := slices.Sorted(slices.Values(values))
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// roundHours rounds to two decimal places.
+func roundHours(h float64) float64 {
+	return math.Round(h*100) / 100
+}
@@ -0,0 +1,82 @@
+package collector
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack/componentsdk"
+	"github.com/shurcooL/githubv4"
+)
+
+func TestTimeToProtection(t *testing.T) {
+	now := time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)
+	window := newTimeWindow(30, now)
+	repo := func(name string, created time.Time, protected bool) github.Repository {
+		r := github.Repository{Name: name, CreatedAt: githubv4.DateTime{Time: created}}
+		r.Owner.Login = "test-org"
+		if protected {
+			r.DefaultBranchRef.BranchProtectionRule = &github.BranchProtectionRule{}
+		}
+		return r
+	}
+	day := func(d int) time.Time { return time.Date(2026, 6, d, 0, 0, 0, 0, time.UTC) }
+	repos := []github.Repository{
+		repo("fast", day(10), true),
+		repo("slow", day(11), true),
+		repo("never", day(12), false),
+		repo("ruleset", day(13), true),
+		repo("Mixed", day(14), true),
+		repo("old", day(1).AddDate(0, -3, 0), true),
+	}
+	events := []github.AuditEvent{
+		// GitHub reports milliseconds; the earliest rule creation counts.
+		{Action: "protected_branch.create", Repo: "test-org/fast", CreatedAt: day(10).Add(4 * time.Hour).UnixMilli()},
+		{Action: "protected_branch.create", Repo: "test-org/fast", CreatedAt: day(10).Add(2 * time.Hour).UnixMilli()},
+		{Action: "protected_branch.create", Repo: "test-org/slow", CreatedAt: day(13).Unix()},
+		{Action: "protected_branch.update", Repo: "test-org/ruleset", CreatedAt: day(13).Unix()},
+		{Action: "protected_branch.create", Repo: "test-org/mixed", CreatedAt: day(14).Add(10 * time.Hour).Unix()},
+		{Action: "protected_branch.create", Repo: "test-org/old", CreatedAt: day(2).Unix()},
+	}
+
+	got := timeToProtection(slices.Values(repos), nil, events, window)
+	if got.ReposCreated != 5 || got.ProtectedCount != 3 || got.UnprotectedCount != 1 || got.UnattributedCount != 1 {
+		t.Errorf("counts = %+v, want 5 created: 3 protected, 1 unprotected, 1 unattributed", got)
+	}
+	// Latencies 2h, 48h and 10h.
+	if got.MedianHours == nil || *got.MedianHours != 10 {
+		t.Errorf("MedianHours = %v, want 10", got.MedianHours)
+	}
+
+	if got := timeToProtection(slices.Values(repos[2:3]), nil, nil, window); got.MedianHours != nil || got.UnprotectedCount != 1 {
+		t.Errorf("no protection events = %+v, want unprotected with no median", got)
+	}
+}
+
+func TestMedianOf(t *testing.T) {
+	if got := medianOf([]float64{5, 1, 3}); got != 3 {
+		t.Errorf("odd median = %v, want 3", got)
+	}
+	if got := medianOf([]float64{4, 1, 2, 3}); got != 2.5 {
+		t.Errorf("even median = %v, want 2.5", got)
+	}
+}
+
+func TestSurfaces_AuditLogTimeToProtection(t *testing.T) {
+	created := time.Now().UTC().Add(-72 * time.Hour)
+	mock := richMock()
+	mock.repositories[0].CreatedAt = githubv4.DateTime{Time: created}
+	mock.auditEvents = append(mock.auditEvents, github.AuditEvent{
+		Action: "protected_branch.create", Repo: "test-org/repo1", CreatedAt: created.Add(6 * time.Hour).UnixMilli(),
+	})
+	posture, err := NewWithClient(Config{Organization: "test-org"}, mock).Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	ttp := posture.AuditLog.TimeToProtection
+	if ttp == nil || ttp.ReposCreated != 1 || ttp.MedianHours == nil || *ttp.MedianHours != 6 {
+		t.Errorf("TimeToProtection = %+v, want one repo protected after 6h", ttp)
+	}
+}
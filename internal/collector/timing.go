@@ -0,0 +1,69 @@
+package collector
+
+import (
+	"sync"
+	"time"
+)
+
+// timingLayout formats phase timestamps with millisecond precision, since
+// phases of small runs finish within the same second.
+const timingLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// phaseOrder is the order phases are reported in. Repository enumeration and
+// security settings run concurrently, so they overlap.
+var phaseOrder = []string{PhaseRepositories, PhaseSecuritySettings, PhaseSurfaces}
+
+// phaseTimer records when a run and each of its phases started and finished.
+// Phases may be timed from concurrent goroutines.
+type phaseTimer struct {
+	now   func() time.Time
+	start time.Time
+
+	mu     sync.Mutex
+	phases map[string][2]time.Time
+}
+
+func newPhaseTimer() *phaseTimer {
+	t := &phaseTimer{now: time.Now, phases: make(map[string][2]time.Time)}
+	t.start = t.now()
+	return t
+}
+
+// begin starts timing a phase and returns the func that ends it.
+func (t *phaseTimer) begin(phase string) func() {
+	started := t.now()
+	return func() {
+		finished := t.now()
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.phases[phase] = [2]time.Time{started, finished}
+	}
+}
+
+// stats fills the timing fields of the collection stats, ending the run now.
+// Phases that never ran (e.g. surfaces on an aborted run) are left out.
+func (t *phaseTimer) stats(out *CollectionStats) {
+	finished := t.now()
+	out.StartedAt = formatTiming(t.start)
+	out.FinishedAt = formatTiming(finished)
+	out.DurationMs = finished.Sub(t.start).Milliseconds()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, phase := range phaseOrder {
+		span, ok := t.phases[phase]
+		if !ok {
+			continue
+		}
+		out.Phases = append(out.Phases, PhaseTiming{
+			Phase:      phase,
+			StartedAt:  formatTiming(span[0]),
+			FinishedAt: formatTiming(span[1]),
+			DurationMs: span[1].Sub(span[0]).Milliseconds(),
+		})
+	}
+}
+
+func formatTiming(t time.Time) string {
+	return t.UTC().Format(timingLayout)
+}
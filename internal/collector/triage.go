@@ -0,0 +1,111 @@
+package collector
+
+import (
+	"strings"
+	"time"
+)
+
+// TriageStaleDays is the default age after which an open security issue
+// counts as stale.
+const TriageStaleDays = 30
+
+// SecurityIssueLabel is the label whose open issues the triage surface ages.
+const SecurityIssueLabel = "security"
+
+// securityLabelKeywords mark a label as security-related (case-insensitive
+// substring match), e.g. "security", "type: vulnerability", "CVE".
+var securityLabelKeywords = []string{"security", "vulnerab", "cve"}
+
+// isSecurityLabel reports whether a label name is security-related.
+func isSecurityLabel(name string) bool {
+	lower := strings.ToLower(name)
+	for _, kw := range securityLabelKeywords {
+		if strings.Contains(lower, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectTriage reports, when CollectTriage is set, the share of in-scope
+// repos with a security-related label and the open issues labelled
+// SecurityIssueLabel older than the stale threshold. Issues are only listed
+// for repos that have the label, so repos without it cost one request.
+func (c *Collector) collectTriage(p *collectionPass) {
+	if !c.config.CollectTriage {
+		return
+	}
+	staleDays := c.config.TriageStaleDays
+	if staleDays <= 0 {
+		staleDays = TriageStaleDays
+	}
+	now := time.Now().UTC()
+	staleBefore := now.AddDate(0, 0, -staleDays)
+
+	t := &Triage{StaleAfterDays: staleDays}
+	permissionDenied := false
+	read, labelled := 0, 0
+
+	for r := range p.metrics.repos.all() {
+		labels, err := c.client.ListRepoLabels(p.ctx, r.Owner.Login, r.Name)
+		if err != nil {
+			permissionDenied = permissionDenied || isDenied(err)
+			continue
+		}
+		read++
+
+		issueLabel := ""
+		hasSecurityLabel := false
+		for _, name := range labels {
+			if isSecurityLabel(name) {
+				hasSecurityLabel = true
+			}
+			if strings.EqualFold(name, SecurityIssueLabel) {
+				issueLabel = name
+			}
+		}
+		if hasSecurityLabel {
+			labelled++
+		}
+		if issueLabel == "" {
+			continue
+		}
+
+		issues, err := c.client.ListOpenIssuesWithLabel(p.ctx, r.Owner.Login, r.Name, issueLabel)
+		if err != nil {
+			permissionDenied = permissionDenied || isDenied(err)
+			continue
+		}
+		row := TriageRow{Repository: r.Owner.Login + "/" + r.Name, OpenSecurityIssues: len(issues)}
+		for _, issue := range issues {
+			created, err := time.Parse(time.RFC3339, issue.CreatedAt)
+			if err != nil {
+				continue
+			}
+			if created.Before(staleBefore) {
+				row.StaleSecurityIssues++
+			}
+			if age := int(now.Sub(created).Hours() / 24); age > row.OldestOpenDays {
+				row.OldestOpenDays = age
+			}
+		}
+		if row.OpenSecurityIssues == 0 {
+			continue
+		}
+		t.OpenSecurityIssues += row.OpenSecurityIssues
+		t.StaleSecurityIssues += row.StaleSecurityIssues
+		if row.StaleSecurityIssues > 0 {
+			t.ReposWithStaleIssues++
+		}
+		t.PerRepo = append(t.PerRepo, row)
+	}
+	if permissionDenied {
+		p.metrics.diag.surfacePermissionDenied("triage", "issues:read")
+		if read == 0 {
+			// No repo was readable, so "no labels" would be unfounded.
+			return
+		}
+	}
+	t.SecurityLabelCoverage = percent(labelled, read)
+	p.posture.Triage = t
+}
@@ -1,6 +1,13 @@
 package collector
 
-import "sort"
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
 
 // Truncate applies a per-surface cap to a slice. If len(items) is within the
 // cap, the slice is returned unchanged with droppedCount=0 and truncated=false.
@@ -19,3 +26,48 @@ func Truncate[T any](items []T, maxItems int, less func(a, b T) bool) (kept []T,
 	sort.SliceStable(items, func(i, j int) bool { return less(items[i], items[j]) })
 	return items[:maxItems], len(items) - maxItems, true
 }
+
+// Lists whose caps list_caps overrides, with their default caps.
+const (
+	ListRepositories = "repositories"
+	ListMembers      = "members"
+	ListFindings     = "findings"
+	ListAuditLog     = "audit_log"
+)
+
+// defaultListCaps are the caps of the lists list_caps can lower or raise.
+var defaultListCaps = map[string]int{
+	ListRepositories: ReposCap,
+	ListMembers:      MembersCap,
+	ListFindings:     FindingsCap,
+	ListAuditLog:     AuditLogCap,
+}
+
+// errRepositoryCap stops repository enumeration once max_repositories
+// in-scope repositories were found.
+var errRepositoryCap = errors.New("max_repositories reached")
+
+// validateCaps checks max_repositories and that each list_caps key names a
+// capped list with a positive cap.
+func validateCaps(config Config) error {
+	if config.MaxRepositories < 0 {
+		return fmt.Errorf("max_repositories: must be at least 0, got %d", config.MaxRepositories)
+	}
+	for list, limit := range config.ListCaps {
+		if _, ok := defaultListCaps[list]; !ok {
+			return fmt.Errorf("list_caps: unknown list %q (want one of %s)", list, strings.Join(slices.Sorted(maps.Keys(defaultListCaps)), ", "))
+		}
+		if limit <= 0 {
+			return fmt.Errorf("list_caps.%s: must be positive, got %d", list, limit)
+		}
+	}
+	return nil
+}
+
+// listCap returns a list's cap: its list_caps entry, or its default.
+func (c *Collector) listCap(list string) int {
+	if limit, ok := c.config.ListCaps[list]; ok && limit > 0 {
+		return limit
+	}
+	return defaultListCaps[list]
+}
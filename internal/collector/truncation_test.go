@@ -1,6 +1,11 @@
 package collector
 
-import "testing"
+import (
+	"context"
+	"testing"
+
+	"github.com/locktivity/epack/componentsdk"
+)
 
 func TestTruncate_WithinCap(t *testing.T) {
 	items := []int{3, 1, 2}
@@ -67,3 +72,35 @@ func TestTruncate_EmptyAndExactCap(t *testing.T) {
 		t.Errorf("exact cap: got kept=%v dropped=%d truncated=%v", kept, dropped, truncated)
 	}
 }
+
+func TestValidateCaps(t *testing.T) {
+	valid := Config{MaxRepositories: 100, ListCaps: map[string]int{ListMembers: 50, ListAuditLog: 10}}
+	if err := validateCaps(valid); err != nil {
+		t.Errorf("validateCaps(%+v) error: %v", valid, err)
+	}
+	for _, config := range []Config{
+		{MaxRepositories: -1},
+		{ListCaps: map[string]int{"webhooks": 10}},
+		{ListCaps: map[string]int{ListFindings: 0}},
+	} {
+		if err := validateCaps(config); err == nil {
+			t.Errorf("validateCaps(%+v) = nil, want error", config)
+		}
+	}
+}
+
+func TestCollect_ListCaps(t *testing.T) {
+	config := Config{Organization: "test-org", ListCaps: map[string]int{ListRepositories: 1}}
+	posture, err := NewWithClient(config, richMock()).Collect(context.Background(), componentsdk.LevelAudit)
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	repos := posture.Repositories
+	if repos == nil || len(repos.PerRepo) != 1 || !repos.Truncated || repos.TruncatedDropped != 1 {
+		t.Fatalf("Repositories = %+v, want one row, truncated, one dropped", repos)
+	}
+	// Private repositories are kept first.
+	if repos.PerRepo[0].Name != "test-org/repo1" {
+		t.Errorf("kept %s, want test-org/repo1", repos.PerRepo[0].Name)
+	}
+}
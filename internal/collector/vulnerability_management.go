@@ -0,0 +1,104 @@
+package collector
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// AdvisoryLookbackDays is the default window for repository security
+// advisory counts.
+const AdvisoryLookbackDays = 365
+
+// collectVulnerabilityManagement counts draft and published repository
+// security advisories created within the lookback window, per in-scope repo,
+// and reports whether the org uses the advisory workflow at all.
+func (c *Collector) collectVulnerabilityManagement(p *collectionPass) {
+	window := c.window(LookbackVulnerabilityManagement)
+	vm := &VulnerabilityManagement{LookbackDays: window.days}
+	permissionDenied := false
+	read := 0
+
+	for r := range p.metrics.repos.all() {
+		advisories, err := c.client.ListRepoSecurityAdvisories(p.ctx, r.Owner.Login, r.Name)
+		if err != nil {
+			permissionDenied = permissionDenied || isDenied(err)
+			continue
+		}
+		read++
+		row := AdvisoryCountRow{Repository: r.Owner.Login + "/" + r.Name}
+		for _, a := range advisories {
+			if !window.containsTimestamp(a.CreatedAt) {
+				continue
+			}
+			switch a.State {
+			case "triage", "draft":
+				row.Draft++
+			case "published":
+				row.Published++
+			}
+		}
+		if row.Draft+row.Published == 0 {
+			continue
+		}
+		vm.DraftAdvisories += row.Draft
+		vm.PublishedAdvisories += row.Published
+		vm.ReposWithAdvisories++
+		vm.PerRepo = append(vm.PerRepo, row)
+	}
+	if permissionDenied {
+		p.metrics.diag.surfacePermissionDenied("vulnerability_management", "repository_advisories:read")
+		if read == 0 {
+			// No repo was readable, so "no advisories" would be unfounded.
+			return
+		}
+	}
+	vm.UsesAdvisoryWorkflow = vm.ReposWithAdvisories > 0
+	if !p.user {
+		vm.AutoTriage = c.autoTriage(p, window)
+	}
+	p.posture.VulnerabilityManagement = vm
+}
+
+// autoTriage counts the org's auto-dismissed Dependabot alerts in in-scope
+// repositories, per repo and in total. It returns nil when the alerts can't
+// be read.
+func (c *Collector) autoTriage(p *collectionPass, window timeWindow) *AutoTriage {
+	alerts, truncated, err := c.client.ListOrgAutoDismissedAlerts(p.ctx, p.org)
+	switch {
+	case isDenied(err):
+		p.metrics.diag.surfacePermissionDenied("vulnerability_management.auto_triage", "dependabot_alerts:read")
+		return nil
+	case err != nil:
+		// Dependabot alerts off org-wide: nothing to auto-dismiss.
+		return nil
+	}
+
+	inScope := map[string]bool{}
+	for r := range p.metrics.repos.all() {
+		inScope[strings.ToLower(r.Owner.Login+"/"+r.Name)] = true
+	}
+	at := &AutoTriage{Truncated: truncated}
+	rows := map[string]*AutoTriageRow{}
+	for _, a := range alerts {
+		if !inScope[strings.ToLower(a.Repository)] || !window.containsTimestamp(a.AutoDismissedAt) {
+			continue
+		}
+		row := rows[a.Repository]
+		if row == nil {
+			row = &AutoTriageRow{Repository: a.Repository}
+			rows[a.Repository] = row
+		}
+		row.AutoDismissed++
+		at.AutoDismissedAlerts++
+		if a.Severity == "high" || a.Severity == "critical" {
+			row.HighOrCritical++
+			at.HighOrCritical++
+		}
+	}
+	for _, repo := range slices.Sorted(maps.Keys(rows)) {
+		at.PerRepo = append(at.PerRepo, *rows[repo])
+	}
+	at.ReposAffected = len(at.PerRepo)
+	return at
+}
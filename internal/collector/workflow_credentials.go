@@ -0,0 +1,104 @@
+package collector
+
+import (
+	"regexp"
+	"slices"
+)
+
+// Credential types hardcodedCredentials detects in workflow files.
+const (
+	CredentialGitHubToken      = "github_token"
+	CredentialAWSAccessKey     = "aws_access_key"
+	CredentialSlackToken       = "slack_token"
+	CredentialGoogleAPIKey     = "google_api_key"
+	CredentialPrivateKey       = "private_key"
+	CredentialSecretAssignment = "secret_assignment"
+)
+
+// credentialPatterns are the obvious credential shapes looked for. The
+// assignment pattern skips ${{ }} expressions and other $-prefixed values,
+// which reference secrets rather than embed them.
+var credentialPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{CredentialGitHubToken, regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{CredentialAWSAccessKey, regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{CredentialSlackToken, regexp.MustCompile(`\bxox[baprs]-[0-9A-Za-z-]{10,}`)},
+	{CredentialGoogleAPIKey, regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{CredentialPrivateKey, regexp.MustCompile(`-----BEGIN ([A-Z]+ )?PRIVATE KEY-----`)},
+	{CredentialSecretAssignment, regexp.MustCompile(`(?im)^\s*[\w-]*(password|passwd|secret|token|api_?key)[\w-]*\s*[:=]\s*["']?[^\s"'$]{8,}`)},
+}
+
+// scanWorkflowCredentials counts the credential matches in one workflow file
+// by type. Matched text is never kept.
+func scanWorkflowCredentials(content []byte) map[string]int {
+	var found map[string]int
+	for _, cp := range credentialPatterns {
+		if n := len(cp.pattern.FindAllIndex(content, -1)); n > 0 {
+			if found == nil {
+				found = make(map[string]int)
+			}
+			found[cp.kind] += n
+		}
+	}
+	return found
+}
+
+// hardcodedCredentials scans the workflow files of in-scope repositories with
+// GitHub Actions configured for hardcoded credentials (opt-in). Audit emits
+// counts; internal adds the affected workflow files. Secret values are never
+// emitted.
+func (c *Collector) hardcodedCredentials(p *collectionPass) *HardcodedCredentials {
+	hc := &HardcodedCredentials{}
+	permissionDenied := false
+	for r := range p.metrics.repos.all() {
+		if r.GitHubActionsConfig == nil {
+			continue
+		}
+		paths, err := c.client.ListWorkflowFiles(p.ctx, r.Owner.Login, r.Name)
+		if err != nil {
+			permissionDenied = permissionDenied || isDenied(err)
+			continue
+		}
+		hc.ReposScanned++
+		repoFindings := 0
+		for _, path := range paths {
+			content, err := c.client.GetWorkflowFile(p.ctx, r.DatabaseID, path, "")
+			if err != nil {
+				permissionDenied = permissionDenied || isDenied(err)
+				continue
+			}
+			hc.FilesScanned++
+			found := scanWorkflowCredentials(content)
+			if len(found) == 0 {
+				continue
+			}
+			row := HardcodedCredentialRow{Repository: r.Owner.Login + "/" + r.Name, Path: path}
+			for kind, n := range found {
+				if hc.ByType == nil {
+					hc.ByType = make(map[string]int)
+				}
+				hc.ByType[kind] += n
+				row.Count += n
+				row.Types = append(row.Types, kind)
+			}
+			slices.Sort(row.Types)
+			repoFindings += row.Count
+			if p.internal() {
+				hc.Files = append(hc.Files, row)
+			}
+		}
+		if repoFindings > 0 {
+			hc.Findings += repoFindings
+			hc.ReposWithFindings++
+		}
+	}
+	if permissionDenied {
+		p.metrics.diag.surfacePermissionDenied("actions_security.hardcoded_credentials", "contents:read")
+		if hc.ReposScanned == 0 {
+			return nil
+		}
+	}
+	return hc
+}
@@ -0,0 +1,179 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"golang.org/x/oauth2"
+)
+
+// AuthProvider authenticates a client's requests to GitHub. Transport wraps
+// the base transport (see NewTransport) with the provider's credentials;
+// requests through it carry them, refreshed as the provider needs.
+type AuthProvider interface {
+	Transport(base http.RoundTripper) (http.RoundTripper, error)
+}
+
+// TokenAuth authenticates with a fixed token: an installation token injected
+// by the runtime, or a classic personal access token.
+type TokenAuth struct {
+	Token string
+}
+
+// Transport implements AuthProvider.
+func (a TokenAuth) Transport(base http.RoundTripper) (http.RoundTripper, error) {
+	if a.Token == "" {
+		return nil, errors.New("token auth: token is empty")
+	}
+	return oauthTransport(base, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: a.Token})), nil
+}
+
+// AppAuth authenticates as a GitHub App installation, minting installation
+// tokens from the App's private key as they expire. BaseURL is the REST API
+// root tokens are minted at (DefaultBaseURL when empty). With a Cache, the
+// installation's tokens are shared with the other clients using it.
+type AppAuth struct {
+	AppID          int64
+	InstallationID int64
+	PrivateKey     []byte
+	BaseURL        string
+	Cache          *Cache
+}
+
+// Transport implements AuthProvider.
+func (a AppAuth) Transport(base http.RoundTripper) (http.RoundTripper, error) {
+	if a.Cache != nil {
+		itr, err := a.Cache.installation(a, base)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GitHub App transport: %w", err)
+		}
+		return &installationTransport{itr: itr, base: baseTransport(base)}, nil
+	}
+	itr, err := a.installationTransport(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub App transport: %w", err)
+	}
+	return itr, nil
+}
+
+// installationTransport builds the installation's token minter on base.
+func (a AppAuth) installationTransport(base http.RoundTripper) (*ghinstallation.Transport, error) {
+	itr, err := ghinstallation.New(baseTransport(base), a.AppID, a.InstallationID, a.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if a.BaseURL != "" {
+		itr.BaseURL = strings.TrimSuffix(a.BaseURL, "/")
+	}
+	return itr, nil
+}
+
+// CommandTokenLifetime is how long a token printed by a token command
+// without an expiry is used before the command is run again: under the hour
+// GitHub installation tokens last.
+const CommandTokenLifetime = 50 * time.Minute
+
+// commandTimeout bounds one run of a token command.
+const commandTimeout = 30 * time.Second
+
+// CommandAuth authenticates with a token printed by an external command, for
+// deployments that mint short-lived tokens outside the collector (Vault, a
+// cloud secret manager, an internal broker). The command is run without a
+// shell, as Command[0] with the rest as arguments, when the first request
+// needs a token and again before it expires. It prints either the bare token
+// or a JSON object {"token": "...", "expires_at": "RFC 3339 time"}; a bare
+// token is used for CommandTokenLifetime. With a Cache, the clients running
+// the same command share its tokens.
+type CommandAuth struct {
+	Command []string
+	Cache   *Cache
+}
+
+// Transport implements AuthProvider.
+func (a CommandAuth) Transport(base http.RoundTripper) (http.RoundTripper, error) {
+	if len(a.Command) == 0 || a.Command[0] == "" {
+		return nil, errors.New("token_command: command is empty")
+	}
+	if a.Cache != nil {
+		return oauthTransport(base, a.Cache.command(a.Command)), nil
+	}
+	return oauthTransport(base, oauth2.ReuseTokenSource(nil, commandTokenSource{command: a.Command})), nil
+}
+
+// commandTokenSource runs the token command for each token it returns;
+// oauth2.ReuseTokenSource caches them until they expire.
+type commandTokenSource struct {
+	command []string
+}
+
+// commandToken is the JSON form of a token command's output.
+type commandToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Token implements oauth2.TokenSource.
+func (s commandTokenSource) Token() (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, s.command[0], s.command[1:]...)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		// stderr helps diagnose a failed command; stdout may hold a token.
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("token_command %s: %w: %s", s.command[0], err, truncate(msg, 200))
+		}
+		return nil, fmt.Errorf("token_command %s: %w", s.command[0], err)
+	}
+	return parseCommandToken(stdout.Bytes(), time.Now())
+}
+
+// parseCommandToken reads a token command's output: a JSON commandToken, or
+// the bare token, which expires CommandTokenLifetime after now.
+func parseCommandToken(out []byte, now time.Time) (*oauth2.Token, error) {
+	out = bytes.TrimSpace(out)
+	if bytes.HasPrefix(out, []byte("{")) {
+		var parsed commandToken
+		if err := json.Unmarshal(out, &parsed); err != nil {
+			return nil, fmt.Errorf("token_command: parsing JSON output: %w", err)
+		}
+		if parsed.Token == "" {
+			return nil, errors.New(`token_command: JSON output has no "token"`)
+		}
+		expiry := parsed.ExpiresAt
+		if expiry.IsZero() {
+			expiry = now.Add(CommandTokenLifetime)
+		}
+		return &oauth2.Token{AccessToken: parsed.Token, Expiry: expiry}, nil
+	}
+	token := string(out)
+	if token == "" {
+		return nil, errors.New("token_command: printed no token")
+	}
+	if strings.ContainsAny(token, " \t\r\n") {
+		return nil, errors.New("token_command: output is not a single token")
+	}
+	return &oauth2.Token{AccessToken: token, Expiry: now.Add(CommandTokenLifetime)}, nil
+}
+
+// oauthTransport authenticates requests on base with tokens from src.
+func oauthTransport(base http.RoundTripper, src oauth2.TokenSource) http.RoundTripper {
+	return &oauth2.Transport{Source: src, Base: baseTransport(base)}
+}
+
+// truncate shortens s to at most n bytes.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,72 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestHelperTokenCommand is the token command run by TestCommandAuth: the
+// test binary re-run, printing EPACK_TEST_TOKEN_OUTPUT.
+func TestHelperTokenCommand(t *testing.T) {
+	out, ok := os.LookupEnv("EPACK_TEST_TOKEN_OUTPUT")
+	if !ok {
+		t.Skip("only run as a token command")
+	}
+	fmt.Print(out)
+	os.Exit(0)
+}
+
+func TestCommandAuth(t *testing.T) {
+	t.Setenv("EPACK_TEST_TOKEN_OUTPUT", "tok-from-command\n")
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	rt, err := CommandAuth{Command: []string{os.Args[0], "-test.run=^TestHelperTokenCommand$"}}.Transport(nil)
+	if err != nil {
+		t.Fatalf("Transport() error: %v", err)
+	}
+	resp, err := (&http.Client{Transport: rt}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("request error: %v", err)
+	}
+	resp.Body.Close()
+	if got != "Bearer tok-from-command" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer tok-from-command")
+	}
+
+	if _, err := (CommandAuth{}).Transport(nil); err == nil {
+		t.Error("Transport() with no command should fail")
+	}
+}
+
+func TestParseCommandToken(t *testing.T) {
+	now := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	token, err := parseCommandToken([]byte("ghs_abc\n"), now)
+	if err != nil || token.AccessToken != "ghs_abc" || !token.Expiry.Equal(now.Add(CommandTokenLifetime)) {
+		t.Errorf("bare token = %+v, %v", token, err)
+	}
+
+	token, err = parseCommandToken([]byte(`{"token": "ghs_def", "expires_at": "2026-03-02T00:10:00Z"}`), now)
+	if err != nil || token.AccessToken != "ghs_def" || !token.Expiry.Equal(now.Add(10*time.Minute)) {
+		t.Errorf("JSON token = %+v, %v", token, err)
+	}
+
+	token, err = parseCommandToken([]byte(`{"token": "ghs_ghi"}`), now)
+	if err != nil || !token.Expiry.Equal(now.Add(CommandTokenLifetime)) {
+		t.Errorf("JSON token without expiry = %+v, %v", token, err)
+	}
+
+	for _, bad := range []string{"", "  \n", `{"expires_at": "2026-03-02T00:10:00Z"}`, `{"token": `, "Error: not logged in"} {
+		if _, err := parseCommandToken([]byte(bad), now); err == nil {
+			t.Errorf("parseCommandToken(%q) should fail", bad)
+		}
+	}
+}
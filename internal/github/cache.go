@@ -0,0 +1,115 @@
+package github
+
+import (
+	"crypto/sha256"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"golang.org/x/oauth2"
+)
+
+// Cache shares the lookups that don't depend on the organization between
+// the clients of one process, so a multi-org run (see pkg/batch) collecting
+// several organizations under one enterprise or App makes them once: App
+// installation tokens, token command output, and instance detection. Each
+// entry lives as long as the Cache. Safe for concurrent use.
+//
+// There is no enterprise settings entry: the collector reads no
+// /enterprises endpoints, and every setting it does read (security
+// defaults, rulesets, policies) is the organization's own, so sharing one
+// between organizations would report the wrong org's values.
+type Cache struct {
+	mu            sync.Mutex
+	installations map[installationKey]*ghinstallation.Transport
+	commands      map[string]oauth2.TokenSource
+	instances     map[string]Instance // REST base URL -> detected instance
+}
+
+// installationKey identifies an App installation's tokens on one instance.
+// The key's hash keeps two configs with the same IDs but different keys
+// apart.
+type installationKey struct {
+	baseURL        string
+	appID          int64
+	installationID int64
+	keyHash        [sha256.Size]byte
+}
+
+// NewCache returns an empty Cache.
+func NewCache() *Cache {
+	return &Cache{
+		installations: make(map[installationKey]*ghinstallation.Transport),
+		commands:      make(map[string]oauth2.TokenSource),
+		instances:     make(map[string]Instance),
+	}
+}
+
+// installation returns the shared token minter of an App installation,
+// creating it on base when it's the first use. Tokens are minted through the
+// first client's transport and reused by every client until they expire.
+func (c *Cache) installation(a AppAuth, base http.RoundTripper) (*ghinstallation.Transport, error) {
+	key := installationKey{baseURL: a.BaseURL, appID: a.AppID, installationID: a.InstallationID, keyHash: sha256.Sum256(a.PrivateKey)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if itr, ok := c.installations[key]; ok {
+		return itr, nil
+	}
+	itr, err := a.installationTransport(base)
+	if err != nil {
+		return nil, err
+	}
+	c.installations[key] = itr
+	return itr, nil
+}
+
+// command returns the shared token source of a token command, so the
+// command runs once per token however many clients use it.
+func (c *Cache) command(command []string) oauth2.TokenSource {
+	key := strings.Join(command, "\x00")
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	src, ok := c.commands[key]
+	if !ok {
+		src = oauth2.ReuseTokenSource(nil, commandTokenSource{command: command})
+		c.commands[key] = src
+	}
+	return src
+}
+
+// instance returns the instance detected at a base URL, if any.
+func (c *Cache) instance(baseURL string) (Instance, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	instance, ok := c.instances[baseURL]
+	return instance, ok
+}
+
+// setInstance records the instance detected at a base URL.
+func (c *Cache) setInstance(baseURL string, instance Instance) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.instances[baseURL] = instance
+}
+
+// installationTransport authenticates requests on base with a shared App
+// installation's tokens, as ghinstallation.Transport does on its own.
+type installationTransport struct {
+	itr  *ghinstallation.Transport
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *installationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.itr.Token(req.Context())
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+	out := req.Clone(req.Context())
+	out.Header.Set("Authorization", "token "+token)
+	return t.base.RoundTrip(out)
+}
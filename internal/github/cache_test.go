@@ -0,0 +1,107 @@
+package github
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// redirectTransport sends every request to target, standing in for
+// api.github.com.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.URL.Scheme, out.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(out)
+}
+
+func TestCache_InstallationTokens(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	var mints atomic.Int32
+	auths := make(chan string, 3)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			n := mints.Add(1)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": %q}`, n, time.Now().Add(time.Hour).Format(time.RFC3339))
+			return
+		}
+		auths <- r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	base := redirectTransport{target: target}
+
+	cache := NewCache()
+	get := func(auth AppAuth) string {
+		rt, err := auth.Transport(base)
+		if err != nil {
+			t.Fatalf("Transport() error: %v", err)
+		}
+		resp, err := (&http.Client{Transport: rt}).Get("https://api.github.com/orgs/org")
+		if err != nil {
+			t.Fatalf("request error: %v", err)
+		}
+		resp.Body.Close()
+		return <-auths
+	}
+	first := get(AppAuth{AppID: 1, InstallationID: 42, PrivateKey: privateKey, Cache: cache})
+	second := get(AppAuth{AppID: 1, InstallationID: 42, PrivateKey: privateKey, Cache: cache})
+	if first != "token ghs_1" || second != first || mints.Load() != 1 {
+		t.Errorf("Authorization = %q then %q after %d mints, want one shared token", first, second, mints.Load())
+	}
+
+	// Another installation mints its own tokens.
+	if other := get(AppAuth{AppID: 1, InstallationID: 43, PrivateKey: privateKey, Cache: cache}); other != "token ghs_2" {
+		t.Errorf("Authorization = %q, want the second installation's token", other)
+	}
+}
+
+func TestCache_Instance(t *testing.T) {
+	var probes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+		w.Header().Set(enterpriseVersionHeader, "enterprise-server@3.9.2")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	cache := NewCache()
+	for range 2 {
+		client := NewClientWithHTTP(server.Client(), server.URL)
+		client.SetCache(cache)
+		instance, err := client.DetectInstance(t.Context())
+		if err != nil || instance.Version != "3.9.2" || client.instance != instance {
+			t.Fatalf("DetectInstance() = %+v, %v", instance, err)
+		}
+	}
+	if probes.Load() != 1 {
+		t.Errorf("probes = %d, want 1", probes.Load())
+	}
+}
+
+func TestCache_Command(t *testing.T) {
+	cache := NewCache()
+	if cache.command([]string{"print-token", "org"}) != cache.command([]string{"print-token", "org"}) {
+		t.Error("the same command should share one token source")
+	}
+	if cache.command([]string{"print-token", "org"}) == cache.command([]string{"print-token org"}) {
+		t.Error("different commands should not share a token source")
+	}
+}
@@ -0,0 +1,132 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// captureQueryParams are the query parameters whose values a debug capture
+// records; every other value is replaced by redactedValue, since redirects
+// (e.g. to artifact storage) can carry signed credentials in the query.
+var captureQueryParams = []string{
+	"after", "affiliation", "before", "direction", "filter", "page", "per_page",
+	"ref", "role", "since", "sort", "state", "tool_name", "type", "visibility",
+}
+
+// captureHeaders are the response headers a debug capture records: the
+// request ID GitHub support asks for, the rate limit, and the instance.
+var captureHeaders = map[string]string{
+	"X-Github-Request-Id":         "request_id",
+	"X-Ratelimit-Limit":           "ratelimit_limit",
+	"X-Ratelimit-Remaining":       "ratelimit_remaining",
+	"X-Ratelimit-Used":            "ratelimit_used",
+	"X-Ratelimit-Reset":           "ratelimit_reset",
+	"X-Ratelimit-Resource":        "ratelimit_resource",
+	"Retry-After":                 "retry_after",
+	"X-Github-Enterprise-Version": "enterprise_version",
+}
+
+// redactedValue replaces the query values a capture doesn't record.
+const redactedValue = "REDACTED"
+
+// captureRecord is one line of a debug capture: the metadata of one HTTP
+// request sent to GitHub, timed until its response headers arrived. Request
+// and response bodies, and every request header, are never recorded.
+type captureRecord struct {
+	Time       time.Time         `json:"time"`
+	Method     string            `json:"method"`
+	URL        string            `json:"url"`
+	Status     int               `json:"status,omitempty"`
+	DurationMS int64             `json:"duration_ms"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// captureTransport appends a captureRecord per request to an NDJSON file.
+// The file is opened for each record, so several clients (e.g. a batch run's
+// targets) can append to the same one.
+type captureTransport struct {
+	base http.RoundTripper
+	path string
+	mu   sync.Mutex
+}
+
+// newCaptureTransport checks that the capture file can be created and
+// appended to, then returns the transport.
+func newCaptureTransport(base http.RoundTripper, path string) (*captureTransport, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening debug capture file: %w", err)
+	}
+	_ = f.Close()
+	return &captureTransport{base: base, path: path}, nil
+}
+
+// RoundTrip sends the request and records it. A failure to write the
+// capture never fails the request.
+func (t *captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	record := captureRecord{
+		Time:       start.UTC(),
+		Method:     req.Method,
+		URL:        sanitizeCaptureURL(req.URL),
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	} else {
+		record.Status = resp.StatusCode
+		for header, key := range captureHeaders {
+			if v := resp.Header.Get(header); v != "" {
+				if record.Headers == nil {
+					record.Headers = make(map[string]string)
+				}
+				record.Headers[key] = v
+			}
+		}
+	}
+	t.write(record)
+	return resp, err
+}
+
+func (t *captureTransport) write(record captureRecord) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	f, err := os.OpenFile(t.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+	_, _ = f.Write(append(line, '\n'))
+}
+
+// sanitizeCaptureURL drops the URL's user info and fragment and redacts the
+// query values outside captureQueryParams.
+func sanitizeCaptureURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	clean.Fragment, clean.RawFragment = "", ""
+	if clean.RawQuery != "" {
+		query := clean.Query()
+		for key, values := range query {
+			if !slices.Contains(captureQueryParams, key) {
+				for i := range values {
+					values[i] = redactedValue
+				}
+			}
+		}
+		clean.RawQuery = query.Encode()
+	}
+	return clean.String()
+}
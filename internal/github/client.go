@@ -6,10 +6,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/shurcooL/githubv4"
-	"golang.org/x/oauth2"
+	"golang.org/x/time/rate"
 )
 
 // ErrPermissionDenied is returned when the API returns 403 Forbidden.
@@ -21,25 +23,59 @@ var ErrPermissionDenied = errors.New("permission denied")
 type GitHubClient interface {
 	FetchOrgSecurity(ctx context.Context, org string) (*OrgSecurity, error)
 	FetchRepositories(ctx context.Context, org string, callback func([]Repository) error) error
+	FetchUserRepositories(ctx context.Context, login string, callback func([]Repository) error) error
+	FetchRepository(ctx context.Context, owner, name string) (*Repository, error)
+	LookupRepository(ctx context.Context, owner, name string) (string, error)
 	FetchSecuritySettings(ctx context.Context, owner, repo string) (*SecuritySettings, error)
+	GetVulnerabilityAlertsEnabled(ctx context.Context, owner, repo string) (bool, error)
+	ListCommitCheckContexts(ctx context.Context, owner, repo, ref string) ([]string, error)
 
 	// Audit / internal surfaces.
 	GetOrgSettings(ctx context.Context, org string) (*OrgSettings, error)
+	ListSecurityManagerTeams(ctx context.Context, org string) ([]string, error)
+	ListCustomOrgRoles(ctx context.Context, org string) ([]CustomRole, error)
+	ListCustomRepoRoles(ctx context.Context, org string) ([]CustomRole, error)
 	GetOpenAlertCounts(ctx context.Context, owner, repo string) (*AlertCounts, error)
 	ListSecretScanningAlerts(ctx context.Context, owner, repo string) ([]SecretScanningAlert, bool, error)
 	ListCodeScanningAlerts(ctx context.Context, owner, repo string) ([]CodeScanningAlert, bool, error)
 	ListDependabotAlerts(ctx context.Context, owner, repo string) ([]DependabotAlert, bool, error)
+	ListOrgAutoDismissedAlerts(ctx context.Context, org string) ([]AutoDismissedAlert, bool, error)
 	GetOrgMembership(ctx context.Context, org string) (*OrgMembership, error)
-	GetCodeownersInfo(ctx context.Context, owner, repo string, wantHash bool) (present bool, path string, hash string, err error)
+	GetCodeownersInfo(ctx context.Context, owner, repo string, detail bool) (*CodeownersInfo, error)
 	ListOrgHooks(ctx context.Context, org string) ([]Hook, error)
 	ListRepoHooks(ctx context.Context, owner, repo string) ([]Hook, error)
 	ListRepoDeployKeys(ctx context.Context, owner, repo string) ([]DeployKey, error)
+	ListRepoSecurityAdvisories(ctx context.Context, owner, repo string) ([]RepositoryAdvisory, error)
+	ListRepoLabels(ctx context.Context, owner, repo string) ([]string, error)
+	ListOpenIssuesWithLabel(ctx context.Context, owner, repo, label string) ([]Issue, error)
+	CreateIssue(ctx context.Context, owner, repo string, issue IssueRequest) error
+	UpdateIssue(ctx context.Context, owner, repo string, number int, issue IssueRequest) error
 	ListOrgRunners(ctx context.Context, org string) ([]Runner, error)
 	ListRepoRunners(ctx context.Context, owner, repo string) ([]Runner, error)
+	ListRunnerGroups(ctx context.Context, org string) ([]RunnerGroup, error)
 	ListOrgActionsSecretNames(ctx context.Context, org string) ([]string, error)
+	ListRepoActionsSecrets(ctx context.Context, owner, repo string) ([]ActionsSecret, error)
 	GetOrgAuditLog(ctx context.Context, org, sinceISO string, maxEvents int) ([]AuditEvent, bool, error)
 	ListOrgInstallations(ctx context.Context, org string) ([]Installation, error)
 	ListOrgPATs(ctx context.Context, org string) ([]PATGrant, bool, error)
+	CountOrgPATRequests(ctx context.Context, org string) (int, bool, error)
+	GetOrgInteractionLimit(ctx context.Context, org string) (*InteractionLimit, error)
+	ListOrgBlockedUsers(ctx context.Context, org string) ([]string, error)
+	ListOrgProjects(ctx context.Context, org string) ([]Project, error)
+	ListOrgRulesets(ctx context.Context, org string) ([]Ruleset, bool, error)
+	ListRepositoryPropertyValues(ctx context.Context, org string) (map[string]map[string][]string, bool, error)
+	GetWorkflowFile(ctx context.Context, repositoryID int64, path, ref string) ([]byte, error)
+	ListWorkflowFiles(ctx context.Context, owner, repo string) ([]string, error)
+	ListRecentReleases(ctx context.Context, owner, repo string, limit int) ([]Release, error)
+	HasAttestations(ctx context.Context, owner, repo, digest string) (bool, error)
+	SearchCode(ctx context.Context, query string) (*CodeSearchResult, error)
+
+	// DetectInstance identifies the GitHub deployment, so endpoints it
+	// doesn't serve are skipped (see Instance.Supports).
+	DetectInstance(ctx context.Context) (Instance, error)
+
+	// Stats reports cumulative GraphQL cost and the last-seen rate limit.
+	Stats() QueryStats
 }
 
 // Client wraps the GitHub GraphQL and REST clients.
@@ -47,88 +83,287 @@ type Client struct {
 	graphql    *githubv4.Client
 	httpClient *http.Client
 	token      string
-	baseURL    string // REST API base URL (for testing with httptest)
+	baseURL    string   // REST API base URL (for testing with httptest)
+	instance   Instance // set by DetectInstance
+	cache      *Cache   // shared with other clients; nil when unset
+
+	// limits handles rate limit refusals under every request (see
+	// rateLimitTransport).
+	limits *rateLimitTransport
+
+	statsMu sync.Mutex
+	stats   QueryStats
+
+	// searchLimiter paces SearchCode (see searchLimit).
+	searchOnce    sync.Once
+	searchLimiter *rate.Limiter
+}
+
+// QueryStats is the cumulative API usage of a client. RateLimitRemaining is
+// nil until the first GraphQL query returns.
+type QueryStats struct {
+	GraphQLQueries     int
+	GraphQLCost        int
+	RateLimitRemaining *int
+	RateLimitResetAt   time.Time
+
+	// GraphQLRetries counts GraphQL queries resent after a transient
+	// failure; GraphQLSplitPages counts repository pages fetched in two
+	// halves after their full query kept failing.
+	GraphQLRetries    int
+	GraphQLSplitPages int
+
+	// RESTRequests counts REST requests sent, whatever their outcome.
+	RESTRequests int
+
+	// SecondaryRateLimitPauses counts the pauses secondary rate limits
+	// imposed. RateLimitExhaustedUntil is set when a request failed because
+	// the core or GraphQL primary rate limit was exhausted for longer than
+	// the client waits; it is when the limit resets.
+	SecondaryRateLimitPauses int
+	RateLimitExhaustedUntil  time.Time
+
+	// DecodeErrors counts REST responses that couldn't be decoded (see
+	// DecodeError); LastDecodeError describes the most recent.
+	DecodeErrors    int
+	LastDecodeError string
+}
+
+// Stats returns a snapshot of the client's GraphQL usage.
+func (c *Client) Stats() QueryStats {
+	c.statsMu.Lock()
+	stats := c.stats
+	c.statsMu.Unlock()
+	if c.limits != nil {
+		c.limits.stats(&stats)
+	}
+	return stats
+}
+
+// SetMaxRateLimitWait sets how long the client waits for an exhausted
+// primary rate limit to reset before failing requests with a
+// RateLimitError (default 0: fail at once).
+func (c *Client) SetMaxRateLimitWait(wait time.Duration) {
+	c.limits.maxWait = wait
+}
+
+// SetBaseURL points the client at another REST API root, e.g.
+// https://ghe.example.com/api/v3 for GitHub Enterprise Server. GraphQL is
+// sent to the matching endpoint (see GraphQLURL).
+func (c *Client) SetBaseURL(apiURL string) {
+	c.baseURL = strings.TrimSuffix(apiURL, "/")
+	c.graphql = githubv4.NewEnterpriseClient(GraphQLURL(c.baseURL), c.httpClient)
+}
+
+// GraphQLURL returns the GraphQL endpoint of a REST API root: /api/graphql
+// beside an Enterprise Server's /api/v3, else /graphql under it.
+func GraphQLURL(apiURL string) string {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	if root, ok := strings.CutSuffix(apiURL, "/api/v3"); ok {
+		return root + "/api/graphql"
+	}
+	return apiURL + "/graphql"
+}
+
+// SetCache shares the client's instance detection with the other clients
+// using cache. Pass the same cache to their AppAuth or CommandAuth to share
+// tokens too.
+func (c *Client) SetCache(cache *Cache) {
+	c.cache = cache
+}
+
+// recordRateLimit folds one query's rateLimit object into the stats.
+func (c *Client) recordRateLimit(rl RateLimit) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	remaining := rl.Remaining
+	c.stats.GraphQLQueries++
+	c.stats.GraphQLCost += rl.Cost
+	c.stats.RateLimitRemaining = &remaining
+	c.stats.RateLimitResetAt = rl.ResetAt.Time
+}
+
+// do sends a REST request, counting it in the stats. The response body is
+// capped at MaxResponseBytes.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	c.statsMu.Lock()
+	c.stats.RESTRequests++
+	c.statsMu.Unlock()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &cappedBody{ReadCloser: resp.Body, remaining: MaxResponseBytes}
+	return resp, nil
 }
 
 // Ensure Client implements GitHubClient.
 var _ GitHubClient = (*Client)(nil)
 
-// NewClient creates a new GitHub client with the given token.
-func NewClient(token string) *Client {
-	src := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	httpClient := oauth2.NewClient(context.Background(), src)
+// NewClient creates a new GitHub client with the given token. base is the
+// underlying transport (see NewTransport); nil uses http.DefaultTransport.
+func NewClient(token string, base http.RoundTripper) *Client {
+	// A token transport never fails to build.
+	client, _ := NewClientWithAuth(TokenAuth{Token: token}, base)
+	client.token = token
+	return client
+}
 
+// NewClientWithAuth creates a client whose requests are authenticated by
+// auth. base is the underlying transport (see NewTransport); nil uses
+// http.DefaultTransport.
+func NewClientWithAuth(auth AuthProvider, base http.RoundTripper) (*Client, error) {
+	authed, err := auth.Transport(base)
+	if err != nil {
+		return nil, err
+	}
+	limits := newRateLimitTransport(authed)
+	httpClient := &http.Client{Transport: limits}
 	return &Client{
 		graphql:    githubv4.NewClient(httpClient),
 		httpClient: httpClient,
-		token:      token,
 		baseURL:    DefaultBaseURL,
-	}
+		limits:     limits,
+	}, nil
 }
 
 // NewClientWithHTTP creates a client with a custom HTTP client and base URL (for testing).
 func NewClientWithHTTP(httpClient *http.Client, baseURL string) *Client {
+	httpClient, limits := withRateLimits(httpClient)
 	return &Client{
 		httpClient: httpClient,
 		baseURL:    baseURL,
+		limits:     limits,
 	}
 }
 
 // NewClientWithGraphQL creates a client with custom HTTP client, base URL, and GraphQL endpoint (for testing).
 func NewClientWithGraphQL(httpClient *http.Client, baseURL, graphqlURL string) *Client {
+	httpClient, limits := withRateLimits(httpClient)
 	return &Client{
 		graphql:    githubv4.NewEnterpriseClient(graphqlURL, httpClient),
 		httpClient: httpClient,
 		baseURL:    baseURL,
+		limits:     limits,
 	}
 }
 
+// withRateLimits returns a copy of httpClient whose requests go through a
+// rateLimitTransport, and the transport.
+func withRateLimits(httpClient *http.Client) (*http.Client, *rateLimitTransport) {
+	limited := *httpClient
+	limits := newRateLimitTransport(baseTransport(httpClient.Transport))
+	limited.Transport = limits
+	return &limited, limits
+}
+
 // NewClientFromApp creates a client using GitHub App authentication.
 // This is the recommended authentication method for organization-level access.
-func NewClientFromApp(appID, installationID int64, privateKey []byte) (*Client, error) {
-	itr, err := ghinstallation.New(http.DefaultTransport, appID, installationID, privateKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create GitHub App transport: %w", err)
-	}
+// base is the underlying transport (see NewTransport); nil uses
+// http.DefaultTransport.
+func NewClientFromApp(appID, installationID int64, privateKey []byte, base http.RoundTripper) (*Client, error) {
+	return NewClientWithAuth(AppAuth{AppID: appID, InstallationID: installationID, PrivateKey: privateKey}, base)
+}
 
-	httpClient := &http.Client{Transport: itr}
-	return &Client{
-		graphql:    githubv4.NewClient(httpClient),
-		httpClient: httpClient,
-		baseURL:    DefaultBaseURL,
-	}, nil
+// baseTransport returns base, or http.DefaultTransport when base is nil.
+func baseTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		return http.DefaultTransport
+	}
+	return base
 }
 
 // FetchRepositories fetches all repositories for an organization with pagination.
 // It returns repositories one page at a time via the callback function.
 func (c *Client) FetchRepositories(ctx context.Context, org string, callback func([]Repository) error) error {
-	var cursor *githubv4.String
-
-	for {
-		var query RepositoriesQuery
-		variables := map[string]interface{}{
-			"org":    githubv4.String(org),
-			"cursor": cursor,
-		}
+	return c.eachRepositoryPage(repositoryPager{
+		page: func(cursor *githubv4.String, details bool) (RepositoryConnection, error) {
+			var query RepositoriesQuery
+			variables := map[string]interface{}{
+				"org":     githubv4.String(org),
+				"cursor":  cursor,
+				"details": githubv4.Boolean(details),
+			}
+			if err := c.query(ctx, &query, variables); err != nil {
+				return RepositoryConnection{}, err
+			}
+			c.recordRateLimit(query.RateLimit)
+			return query.Organization.Repositories, nil
+		},
+		details: func(cursor *githubv4.String) ([]RepositoryDetailsNode, error) {
+			var query RepositoryDetailsQuery
+			variables := map[string]interface{}{
+				"org":    githubv4.String(org),
+				"cursor": cursor,
+			}
+			if err := c.query(ctx, &query, variables); err != nil {
+				return nil, err
+			}
+			c.recordRateLimit(query.RateLimit)
+			return query.Organization.Repositories.Nodes, nil
+		},
+	}, callback)
+}
 
-		if err := c.graphql.Query(ctx, &query, variables); err != nil {
-			return err
-		}
+// FetchUserRepositories fetches the repositories owned by a user account with
+// pagination, one page at a time via the callback function.
+func (c *Client) FetchUserRepositories(ctx context.Context, login string, callback func([]Repository) error) error {
+	return c.eachRepositoryPage(repositoryPager{
+		page: func(cursor *githubv4.String, details bool) (RepositoryConnection, error) {
+			var query UserRepositoriesQuery
+			variables := map[string]interface{}{
+				"login":   githubv4.String(login),
+				"cursor":  cursor,
+				"details": githubv4.Boolean(details),
+			}
+			if err := c.query(ctx, &query, variables); err != nil {
+				return RepositoryConnection{}, err
+			}
+			c.recordRateLimit(query.RateLimit)
+			return query.User.Repositories, nil
+		},
+		details: func(cursor *githubv4.String) ([]RepositoryDetailsNode, error) {
+			var query UserRepositoryDetailsQuery
+			variables := map[string]interface{}{
+				"login":  githubv4.String(login),
+				"cursor": cursor,
+			}
+			if err := c.query(ctx, &query, variables); err != nil {
+				return nil, err
+			}
+			c.recordRateLimit(query.RateLimit)
+			return query.User.Repositories.Nodes, nil
+		},
+	}, callback)
+}
 
-		if err := callback(query.Organization.Repositories.Nodes); err != nil {
-			return err
-		}
+// FetchRepository fetches a single repository by owner and name. A repository
+// that doesn't exist or isn't visible to the token returns ErrNotFound.
+func (c *Client) FetchRepository(ctx context.Context, owner, name string) (*Repository, error) {
+	var query RepositoryQuery
+	variables := map[string]interface{}{
+		"owner":   githubv4.String(owner),
+		"name":    githubv4.String(name),
+		"details": githubv4.Boolean(true),
+	}
 
-		if !query.Organization.Repositories.PageInfo.HasNextPage {
-			break
+	err := c.query(ctx, &query, variables)
+	// A not-found response still carries the rateLimit object.
+	if err == nil || !query.RateLimit.ResetAt.IsZero() {
+		c.recordRateLimit(query.RateLimit)
+	}
+	if err != nil {
+		// GitHub reports an unresolvable repository as a GraphQL error
+		// alongside a null repository rather than as an HTTP 404.
+		if query.Repository == nil && strings.Contains(err.Error(), "Could not resolve to a Repository") {
+			return nil, fmt.Errorf("%w: repository %s/%s", ErrNotFound, owner, name)
 		}
-
-		cursor = &query.Organization.Repositories.PageInfo.EndCursor
+		return nil, err
 	}
-
-	return nil
+	if query.Repository == nil {
+		return nil, fmt.Errorf("%w: repository %s/%s", ErrNotFound, owner, name)
+	}
+	return query.Repository, nil
 }
 
 // OrgSecurity represents organization-level security settings.
@@ -136,6 +371,62 @@ func (c *Client) FetchRepositories(ctx context.Context, org string, callback fun
 // determine the value (nil = insufficient permissions).
 type OrgSecurity struct {
 	TwoFactorRequired *bool
+	// MembersWithout2FA counts the members without two-factor
+	// authentication. nil = unknown: GitHub filters members by 2FA status
+	// only for org owners.
+	MembersWithout2FA *MemberTwoFactorCounts
+
+	// MembersWithout2FAErr is why MembersWithout2FA is unknown, for the
+	// diagnostics.
+	MembersWithout2FAErr error
+
+	// SecretScanningNonProviderPatternsDefault reports whether the org's
+	// default code security configuration enables non-provider (generic)
+	// secret detection for new repositories. nil = no default configuration
+	// or insufficient permissions.
+	SecretScanningNonProviderPatternsDefault *bool
+	// SecretScanningValidityChecksDefault is the same for validity checks
+	// (GitHub verifying whether leaked tokens are still active).
+	SecretScanningValidityChecksDefault *bool
+
+	// NewRepoDefaults are the org's "automatically enable for new
+	// repositories" settings from GET /orgs/{org}.
+	NewRepoDefaults NewRepoDefaults
+
+	// PushProtectionForOrg is the org-level push protection for users: org
+	// members' pushes are blocked on detected secrets in any repository,
+	// whatever the repository's own push protection. nil = unknown: owners
+	// only, like NewRepoDefaults, and absent from the documented GET
+	// /orgs/{org} response (see orgREST).
+	PushProtectionForOrg *bool
+
+	// ContactEmail is whether the org profile lists a public email. nil =
+	// the org couldn't be read.
+	ContactEmail *bool
+	// SecurityPolicy is whether the org's .github repository holds a default
+	// SECURITY.md. nil = the repository couldn't be read.
+	SecurityPolicy *bool
+}
+
+// MemberTwoFactorCounts counts the org's members without two-factor
+// authentication (Without2FA) out of all members (Members). Truncated is set
+// when either list stopped at MemberFetchCap, so the counts are partial.
+type MemberTwoFactorCounts struct {
+	Without2FA int
+	Members    int
+	Truncated  bool
+}
+
+// NewRepoDefaults holds the org's automatic-enablement settings for new
+// repositories. GitHub returns them only to org owners (or Apps with
+// organization administration), so each is nil when unknown.
+type NewRepoDefaults struct {
+	DependabotAlerts             *bool `json:"dependabot_alerts_enabled_for_new_repositories"`
+	DependabotSecurityUpdates    *bool `json:"dependabot_security_updates_enabled_for_new_repositories"`
+	DependencyGraph              *bool `json:"dependency_graph_enabled_for_new_repositories"`
+	AdvancedSecurity             *bool `json:"advanced_security_enabled_for_new_repositories"`
+	SecretScanning               *bool `json:"secret_scanning_enabled_for_new_repositories"`
+	SecretScanningPushProtection *bool `json:"secret_scanning_push_protection_enabled_for_new_repositories"`
 }
 
 // FetchOrgSecurity fetches organization-level security settings.
@@ -155,12 +446,34 @@ type OrgSecurity struct {
 func (c *Client) FetchOrgSecurity(ctx context.Context, org string) (*OrgSecurity, error) {
 	result := &OrgSecurity{}
 
-	// Fetch 2FA via REST API (works with GitHub Apps, unlike GraphQL)
-	twoFA, err := c.fetchOrgTwoFactorREST(ctx, org)
+	// Fetch 2FA and new-repo defaults via REST API (works with GitHub Apps,
+	// unlike GraphQL)
+	orgREST, err := c.fetchOrgREST(ctx, org)
 	if err == nil {
-		result.TwoFactorRequired = twoFA
+		result.TwoFactorRequired = orgREST.TwoFactorRequirementEnabled
+		result.NewRepoDefaults = orgREST.NewRepoDefaults
+		result.PushProtectionForOrg = orgREST.PushProtectionForUsers
+		hasEmail := orgREST.Email != nil && *orgREST.Email != ""
+		result.ContactEmail = &hasEmail
+	}
+	// If REST fails, 2FA and the defaults stay nil (unknown)
+
+	result.MembersWithout2FA, result.MembersWithout2FAErr = c.countMembersWithout2FA(ctx, org)
+
+	if present, err := c.hasOrgSecurityPolicy(ctx, org); err == nil {
+		result.SecurityPolicy = &present
+	}
+
+	// Secret scanning defaults come from the org's default code security
+	// configurations; on any error they stay nil (unknown).
+	if defaults, err := c.fetchCodeSecurityDefaults(ctx, org); err == nil {
+		result.SecretScanningNonProviderPatternsDefault = defaults.settingDefault(func(cfg codeSecurityConfiguration) string {
+			return cfg.SecretScanningNonProviderPatterns
+		})
+		result.SecretScanningValidityChecksDefault = defaults.settingDefault(func(cfg codeSecurityConfiguration) string {
+			return cfg.SecretScanningValidityChecks
+		})
 	}
-	// If REST fails, 2FA stays nil (unknown)
 
 	// SSO detection is not supported - always returns nil
 	// See comment above for details on the API limitations.
@@ -168,9 +481,66 @@ func (c *Client) FetchOrgSecurity(ctx context.Context, org string) (*OrgSecurity
 	return result, nil
 }
 
-// fetchOrgTwoFactorREST fetches 2FA requirement via REST API.
+// countMembersWithout2FA counts the members GitHub lists with
+// filter=2fa_disabled, which answers only org owners (members:read plus
+// organization administration for an App). All members are listed only when
+// some lack 2FA, since the share is zero otherwise.
+func (c *Client) countMembersWithout2FA(ctx context.Context, org string) (*MemberTwoFactorCounts, error) {
+	disabled, truncated, err := c.getPagedRaw(ctx, fmt.Sprintf("/orgs/%s/members?filter=2fa_disabled&per_page=100", org), MemberFetchCap)
+	if err != nil {
+		return nil, err
+	}
+	counts := &MemberTwoFactorCounts{Without2FA: len(disabled), Truncated: truncated}
+	if counts.Without2FA == 0 {
+		return counts, nil
+	}
+	members, truncated, err := c.getPagedRaw(ctx, fmt.Sprintf("/orgs/%s/members?per_page=100", org), MemberFetchCap)
+	if err != nil {
+		return nil, err
+	}
+	counts.Members = len(members)
+	counts.Truncated = counts.Truncated || truncated
+	return counts, nil
+}
+
+// orgREST is the subset of GET /orgs/{org} read for org security.
+// TwoFactorRequirementEnabled and the new-repo defaults are only present for
+// org owners/admins; the public email is present for everyone.
+// PushProtectionForUsers isn't part of the documented response, which only
+// has the new-repository push protection default, so it is read when GitHub
+// returns it and left unknown otherwise.
+type orgREST struct {
+	TwoFactorRequirementEnabled *bool   `json:"two_factor_requirement_enabled"`
+	Email                       *string `json:"email"`
+	PushProtectionForUsers      *bool   `json:"secret_scanning_push_protection_for_users_enabled"`
+	NewRepoDefaults
+}
+
+// securityPolicyPaths are where GitHub looks for a SECURITY.md, in order.
+var securityPolicyPaths = []string{"SECURITY.md", ".github/SECURITY.md", "docs/SECURITY.md"}
+
+// hasOrgSecurityPolicy reports whether the org's .github repository holds a
+// default SECURITY.md, which GitHub shows for every repository without its
+// own. A missing .github repository (or one the caller can't see, which
+// GitHub also answers with 404) counts as no policy. Requires contents:read
+// on that repository.
+func (c *Client) hasOrgSecurityPolicy(ctx context.Context, org string) (bool, error) {
+	for _, p := range securityPolicyPaths {
+		err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/.github/contents/%s", org, p), nil)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// fetchOrgREST fetches the 2FA requirement and new-repo defaults via REST API.
 // This works with GitHub Apps (unlike the GraphQL requiresTwoFactorAuthentication field).
-func (c *Client) fetchOrgTwoFactorREST(ctx context.Context, org string) (*bool, error) {
+func (c *Client) fetchOrgREST(ctx context.Context, org string) (*orgREST, error) {
 	url := fmt.Sprintf("%s/orgs/%s", c.baseURL, org)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -180,7 +550,7 @@ func (c *Client) fetchOrgTwoFactorREST(ctx context.Context, org string) (*bool,
 
 	setAPIHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -190,29 +560,92 @@ func (c *Client) fetchOrgTwoFactorREST(ctx context.Context, org string) (*bool,
 		return nil, fmt.Errorf("org API returned status %d", resp.StatusCode)
 	}
 
-	var result struct {
-		TwoFactorRequirementEnabled *bool `json:"two_factor_requirement_enabled"`
-		// Note: This field is only present for org owners/admins
+	var result orgREST
+	if err := c.decodeJSON(resp, &result); err != nil {
+		return nil, err
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	return &result, nil
+}
+
+// codeSecurityConfiguration is the subset of a code security configuration
+// the collector reads. Settings are "enabled", "disabled", or "not_set".
+type codeSecurityConfiguration struct {
+	SecretScanningNonProviderPatterns string `json:"secret_scanning_non_provider_patterns"`
+	SecretScanningValidityChecks      string `json:"secret_scanning_validity_checks"`
+}
+
+// codeSecurityDefaults is the org's list of default code security
+// configurations, one per repository visibility they apply to.
+type codeSecurityDefaults []struct {
+	DefaultForNewRepos string                    `json:"default_for_new_repos"`
+	Configuration      codeSecurityConfiguration `json:"configuration"`
+}
+
+// fetchCodeSecurityDefaults fetches the org's default code security
+// configurations via GET /orgs/{org}/code-security/configurations/defaults.
+func (c *Client) fetchCodeSecurityDefaults(ctx context.Context, org string) (codeSecurityDefaults, error) {
+	var defaults codeSecurityDefaults
+	if err := c.getJSON(ctx, fmt.Sprintf("/orgs/%s/code-security/configurations/defaults", org), &defaults); err != nil {
 		return nil, err
 	}
+	return defaults, nil
+}
 
-	return result.TwoFactorRequirementEnabled, nil
+// settingDefault reports whether any default configuration applied to new
+// repositories enables the setting picked by get. It returns nil when no
+// default configuration applies to new repositories.
+func (d codeSecurityDefaults) settingDefault(get func(codeSecurityConfiguration) string) *bool {
+	var result *bool
+	for _, def := range d {
+		if def.DefaultForNewRepos == "" || def.DefaultForNewRepos == "none" {
+			continue
+		}
+		enabled := get(def.Configuration) == StatusEnabled
+		if result == nil || enabled {
+			result = &enabled
+		}
+	}
+	return result
 }
 
 // SecuritySettings represents the security settings for a repository.
 type SecuritySettings struct {
 	SecretScanning               bool
 	SecretScanningPushProtection bool
+	// SecretScanningNonProviderPatterns is generic (non-provider) secret
+	// detection, which GitHub reports separately from secret scanning.
+	SecretScanningNonProviderPatterns bool
+	// SecretScanningValidityChecks is whether GitHub verifies detected
+	// secrets against their provider to see if they are still active.
+	SecretScanningValidityChecks bool
 	DependabotSecurityUpdates    bool
+	// AdvancedSecurity is whether GitHub Advanced Security is enabled; GitHub
+	// reports it only for private and internal repositories.
+	AdvancedSecurity             bool
 	CodeScanningEnabled          bool
 	CodeScanningPermissionDenied bool
 	CodeScanningErrorMessage     string // Actual error message from GitHub API
+
+	// VulnerabilityAlerts is the REST vulnerability-alerts state (see
+	// GetVulnerabilityAlertsEnabled) when the caller checked it; nil when it
+	// wasn't checked or couldn't be read.
+	VulnerabilityAlerts *bool
+
+	// SecretProtection and CodeSecurity are the standalone products GitHub
+	// split Advanced Security into; either one makes the matching scanning
+	// feature available on a private or internal repository.
+	SecretProtection bool
+	CodeSecurity     bool
+	// AnalysisReported is whether GitHub returned security_and_analysis at
+	// all. It is withheld from callers without admin access, which leaves
+	// the flags above unknown rather than disabled.
+	AnalysisReported bool
 }
 
 // FetchSecuritySettings fetches security settings for a repository via REST API.
+// A repository that can't be read returns a sentinel-wrapped error (see
+// classifyStatus) so callers can report why it was skipped.
 func (c *Client) FetchSecuritySettings(ctx context.Context, owner, repo string) (*SecuritySettings, error) {
 	url := fmt.Sprintf("%s/repos/%s/%s", c.baseURL, owner, repo)
 
@@ -223,7 +656,7 @@ func (c *Client) FetchSecuritySettings(ctx context.Context, owner, repo string)
 
 	setAPIHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -233,8 +666,7 @@ func (c *Client) FetchSecuritySettings(ctx context.Context, owner, repo string)
 		return nil, fmt.Errorf("%w: security settings for %s/%s (status 403)", ErrPermissionDenied, owner, repo)
 	}
 	if resp.StatusCode != http.StatusOK {
-		// Return empty settings on other errors (repo might not support these features)
-		return &SecuritySettings{}, nil
+		return nil, classifyStatus(resp, fmt.Sprintf("/repos/%s/%s", owner, repo))
 	}
 
 	var result struct {
@@ -245,27 +677,58 @@ func (c *Client) FetchSecuritySettings(ctx context.Context, owner, repo string)
 			SecretScanningPushProtection *struct {
 				Status string `json:"status"`
 			} `json:"secret_scanning_push_protection"`
+			SecretScanningNonProviderPatterns *struct {
+				Status string `json:"status"`
+			} `json:"secret_scanning_non_provider_patterns"`
+			SecretScanningValidityChecks *struct {
+				Status string `json:"status"`
+			} `json:"secret_scanning_validity_checks"`
 			DependabotSecurityUpdates *struct {
 				Status string `json:"status"`
 			} `json:"dependabot_security_updates"`
+			AdvancedSecurity *struct {
+				Status string `json:"status"`
+			} `json:"advanced_security"`
+			SecretProtection *struct {
+				Status string `json:"status"`
+			} `json:"secret_protection"`
+			CodeSecurity *struct {
+				Status string `json:"status"`
+			} `json:"code_security"`
 		} `json:"security_and_analysis"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return &SecuritySettings{}, nil
+	if err := c.decodeJSON(resp, &result); err != nil {
+		return nil, err
 	}
 
 	settings := &SecuritySettings{}
 	if result.SecurityAndAnalysis != nil {
+		settings.AnalysisReported = true
 		if result.SecurityAndAnalysis.SecretScanning != nil {
 			settings.SecretScanning = result.SecurityAndAnalysis.SecretScanning.Status == StatusEnabled
 		}
 		if result.SecurityAndAnalysis.SecretScanningPushProtection != nil {
 			settings.SecretScanningPushProtection = result.SecurityAndAnalysis.SecretScanningPushProtection.Status == StatusEnabled
 		}
+		if result.SecurityAndAnalysis.SecretScanningNonProviderPatterns != nil {
+			settings.SecretScanningNonProviderPatterns = result.SecurityAndAnalysis.SecretScanningNonProviderPatterns.Status == StatusEnabled
+		}
+		if result.SecurityAndAnalysis.SecretScanningValidityChecks != nil {
+			settings.SecretScanningValidityChecks = result.SecurityAndAnalysis.SecretScanningValidityChecks.Status == StatusEnabled
+		}
 		if result.SecurityAndAnalysis.DependabotSecurityUpdates != nil {
 			settings.DependabotSecurityUpdates = result.SecurityAndAnalysis.DependabotSecurityUpdates.Status == StatusEnabled
 		}
+		if result.SecurityAndAnalysis.AdvancedSecurity != nil {
+			settings.AdvancedSecurity = result.SecurityAndAnalysis.AdvancedSecurity.Status == StatusEnabled
+		}
+		if result.SecurityAndAnalysis.SecretProtection != nil {
+			settings.SecretProtection = result.SecurityAndAnalysis.SecretProtection.Status == StatusEnabled
+		}
+		if result.SecurityAndAnalysis.CodeSecurity != nil {
+			settings.CodeSecurity = result.SecurityAndAnalysis.CodeSecurity.Status == StatusEnabled
+		}
 	}
 
 	// Check code scanning status
@@ -277,6 +740,35 @@ func (c *Client) FetchSecuritySettings(ctx context.Context, owner, repo string)
 	return settings, nil
 }
 
+// GetVulnerabilityAlertsEnabled reports whether Dependabot (vulnerability)
+// alerts are enabled for a repository: 204 means enabled, 404 disabled. The
+// GraphQL hasVulnerabilityAlertsEnabled flag reads false for App
+// installations lacking permission, so this is the per-repo fallback.
+// Requires administration:read.
+func (c *Client) GetVulnerabilityAlertsEnabled(ctx context.Context, owner, repo string) (bool, error) {
+	path := fmt.Sprintf("/repos/%s/%s/vulnerability-alerts", owner, repo)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return false, err
+	}
+	setAPIHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, classifyStatus(resp, path)
+	}
+}
+
 // codeScanningResult holds the result of checking code scanning status.
 type codeScanningResult struct {
 	enabled          bool
@@ -290,6 +782,10 @@ type codeScanningResult struct {
 // not-configured there. When default setup is off and we weren't denied, fall
 // back to the analyses surface, which sees both setups.
 func (c *Client) checkCodeScanning(ctx context.Context, owner, repo string) codeScanningResult {
+	if !c.instance.Supports(FeatureCodeScanningDefaultSetup) {
+		// Instances without default setup only scan via workflows.
+		return codeScanningResult{enabled: c.hasCodeScanningAnalyses(ctx, owner, repo)}
+	}
 	result := c.checkDefaultSetup(ctx, owner, repo)
 	if !result.enabled && !result.permissionDenied && c.hasCodeScanningAnalyses(ctx, owner, repo) {
 		result.enabled = true
@@ -309,7 +805,7 @@ func (c *Client) checkDefaultSetup(ctx context.Context, owner, repo string) code
 
 	setAPIHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return codeScanningResult{}
 	}
@@ -333,7 +829,7 @@ func (c *Client) checkDefaultSetup(ctx context.Context, owner, repo string) code
 		State string `json:"state"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := c.decodeJSON(resp, &result); err != nil {
 		return codeScanningResult{}
 	}
 
@@ -354,7 +850,7 @@ func (c *Client) hasCodeScanningAnalyses(ctx context.Context, owner, repo string
 
 	setAPIHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return false
 	}
@@ -365,7 +861,7 @@ func (c *Client) hasCodeScanningAnalyses(ctx context.Context, owner, repo string
 	}
 
 	var analyses []json.RawMessage
-	if err := json.NewDecoder(resp.Body).Decode(&analyses); err != nil {
+	if err := c.decodeJSON(resp, &analyses); err != nil {
 		return false
 	}
 	return len(analyses) > 0
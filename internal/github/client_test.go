@@ -8,9 +8,12 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 func TestFetchSecuritySettings(t *testing.T) {
@@ -30,17 +33,24 @@ func TestFetchSecuritySettings(t *testing.T) {
 				"security_and_analysis": {
 					"secret_scanning": {"status": "enabled"},
 					"secret_scanning_push_protection": {"status": "enabled"},
-					"dependabot_security_updates": {"status": "enabled"}
+					"secret_scanning_non_provider_patterns": {"status": "enabled"},
+					"secret_scanning_validity_checks": {"status": "enabled"},
+					"dependabot_security_updates": {"status": "enabled"},
+					"advanced_security": {"status": "enabled"}
 				}
 			}`,
 			repoStatus:   http.StatusOK,
 			codeResponse: `{"state": "configured"}`,
 			codeStatus:   http.StatusOK,
 			wantSettings: SecuritySettings{
-				SecretScanning:               true,
-				SecretScanningPushProtection: true,
-				DependabotSecurityUpdates:    true,
-				CodeScanningEnabled:          true,
+				AnalysisReported:                  true,
+				SecretScanning:                    true,
+				SecretScanningPushProtection:      true,
+				SecretScanningNonProviderPatterns: true,
+				SecretScanningValidityChecks:      true,
+				DependabotSecurityUpdates:         true,
+				AdvancedSecurity:                  true,
+				CodeScanningEnabled:               true,
 			},
 		},
 		{
@@ -56,6 +66,7 @@ func TestFetchSecuritySettings(t *testing.T) {
 			codeResponse: `{"state": "not-configured"}`,
 			codeStatus:   http.StatusOK,
 			wantSettings: SecuritySettings{
+				AnalysisReported:             true,
 				SecretScanning:               false,
 				SecretScanningPushProtection: false,
 				DependabotSecurityUpdates:    false,
@@ -74,6 +85,7 @@ func TestFetchSecuritySettings(t *testing.T) {
 			codeResponse: `{"state": "configured"}`,
 			codeStatus:   http.StatusOK,
 			wantSettings: SecuritySettings{
+				AnalysisReported:             true,
 				SecretScanning:               true,
 				SecretScanningPushProtection: false,
 				DependabotSecurityUpdates:    false,
@@ -93,16 +105,27 @@ func TestFetchSecuritySettings(t *testing.T) {
 			analysesResponse: `[{"ref": "refs/heads/main"}]`,
 			analysesStatus:   http.StatusOK,
 			wantSettings: SecuritySettings{
+				AnalysisReported:    true,
 				CodeScanningEnabled: true,
 			},
 		},
 		{
-			name:         "repo not found",
-			repoResponse: `{"message": "Not Found"}`,
-			repoStatus:   http.StatusNotFound,
-			codeResponse: ``,
-			codeStatus:   http.StatusNotFound,
-			wantSettings: SecuritySettings{},
+			name: "standalone secret protection",
+			repoResponse: `{
+				"security_and_analysis": {
+					"secret_protection": {"status": "enabled"},
+					"code_security": {"status": "disabled"},
+					"secret_scanning": {"status": "enabled"}
+				}
+			}`,
+			repoStatus:   http.StatusOK,
+			codeResponse: `{"state": "not-configured"}`,
+			codeStatus:   http.StatusOK,
+			wantSettings: SecuritySettings{
+				AnalysisReported: true,
+				SecretProtection: true,
+				SecretScanning:   true,
+			},
 		},
 		{
 			name:         "no security_and_analysis field",
@@ -123,6 +146,7 @@ func TestFetchSecuritySettings(t *testing.T) {
 			codeResponse: `{"message": "Advanced Security must be enabled"}`,
 			codeStatus:   http.StatusNotFound,
 			wantSettings: SecuritySettings{
+				AnalysisReported:    true,
 				SecretScanning:      true,
 				CodeScanningEnabled: false,
 			},
@@ -166,9 +190,24 @@ func TestFetchSecuritySettings(t *testing.T) {
 			if settings.SecretScanningPushProtection != tt.wantSettings.SecretScanningPushProtection {
 				t.Errorf("SecretScanningPushProtection = %v, want %v", settings.SecretScanningPushProtection, tt.wantSettings.SecretScanningPushProtection)
 			}
+			if settings.SecretScanningNonProviderPatterns != tt.wantSettings.SecretScanningNonProviderPatterns {
+				t.Errorf("SecretScanningNonProviderPatterns = %v, want %v", settings.SecretScanningNonProviderPatterns, tt.wantSettings.SecretScanningNonProviderPatterns)
+			}
+			if settings.SecretScanningValidityChecks != tt.wantSettings.SecretScanningValidityChecks {
+				t.Errorf("SecretScanningValidityChecks = %v, want %v", settings.SecretScanningValidityChecks, tt.wantSettings.SecretScanningValidityChecks)
+			}
 			if settings.DependabotSecurityUpdates != tt.wantSettings.DependabotSecurityUpdates {
 				t.Errorf("DependabotSecurityUpdates = %v, want %v", settings.DependabotSecurityUpdates, tt.wantSettings.DependabotSecurityUpdates)
 			}
+			if settings.AdvancedSecurity != tt.wantSettings.AdvancedSecurity {
+				t.Errorf("AdvancedSecurity = %v, want %v", settings.AdvancedSecurity, tt.wantSettings.AdvancedSecurity)
+			}
+			if settings.SecretProtection != tt.wantSettings.SecretProtection || settings.CodeSecurity != tt.wantSettings.CodeSecurity {
+				t.Errorf("SecretProtection/CodeSecurity = %v/%v, want %v/%v", settings.SecretProtection, settings.CodeSecurity, tt.wantSettings.SecretProtection, tt.wantSettings.CodeSecurity)
+			}
+			if settings.AnalysisReported != tt.wantSettings.AnalysisReported {
+				t.Errorf("AnalysisReported = %v, want %v", settings.AnalysisReported, tt.wantSettings.AnalysisReported)
+			}
 			if settings.CodeScanningEnabled != tt.wantSettings.CodeScanningEnabled {
 				t.Errorf("CodeScanningEnabled = %v, want %v", settings.CodeScanningEnabled, tt.wantSettings.CodeScanningEnabled)
 			}
@@ -194,6 +233,39 @@ func TestFetchSecuritySettings_PermissionDenied(t *testing.T) {
 	}
 }
 
+func TestFetchSecuritySettings_RepoErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error // nil = any error
+	}{
+		{name: "not found", status: http.StatusNotFound, body: `{"message": "Not Found"}`, wantErr: ErrNotFound},
+		{name: "DMCA blocked", status: http.StatusUnavailableForLegalReasons, body: `{"message": "Repository access blocked"}`, wantErr: ErrRepositoryBlocked},
+		{name: "server error", status: http.StatusBadGateway, body: `{}`},
+		{name: "malformed body", status: http.StatusOK, body: `{"security_and_analysis":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClientWithHTTP(server.Client(), server.URL)
+			settings, err := client.FetchSecuritySettings(context.Background(), "owner", "repo")
+			if err == nil {
+				t.Fatalf("expected error, got settings %+v", settings)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestCheckCodeScanning(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -322,8 +394,29 @@ func TestFetchOrgSecurity_Success(t *testing.T) {
 		if r.URL.Path == "/orgs/test-org" {
 			w.Header().Set("Content-Type", "application/json")
 			_ = json.NewEncoder(w).Encode(map[string]interface{}{
-				"two_factor_requirement_enabled": true,
+				"two_factor_requirement_enabled":                           true,
+				"dependabot_alerts_enabled_for_new_repositories":           true,
+				"secret_scanning_enabled_for_new_repositories":             false,
+				"advanced_security_enabled_for_new_repositories":           true,
+				"dependency_graph_enabled_for_new_repositories":            true,
+				"dependabot_security_updates_enabled_for_new_repositories": false,
+				"secret_scanning_push_protection_for_users_enabled":        true,
+				"email": "security@test-org.example",
 			})
+		} else if r.URL.Path == "/orgs/test-org/members" && r.URL.Query().Get("filter") == "2fa_disabled" {
+			_, _ = w.Write([]byte(`[{"login": "bob"}]`))
+		} else if r.URL.Path == "/orgs/test-org/members" {
+			_, _ = w.Write([]byte(`[{"login": "alice"}, {"login": "bob"}, {"login": "carol"}, {"login": "dave"}]`))
+		} else if r.URL.Path == "/repos/test-org/.github/contents/.github/SECURITY.md" {
+			_, _ = w.Write([]byte(`{"type": "file"}`))
+		} else if strings.HasPrefix(r.URL.Path, "/repos/test-org/.github/contents/") {
+			w.WriteHeader(http.StatusNotFound)
+		} else if r.URL.Path == "/orgs/test-org/code-security/configurations/defaults" {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[
+				{"default_for_new_repos": "public", "configuration": {"secret_scanning_non_provider_patterns": "disabled", "secret_scanning_validity_checks": "disabled"}},
+				{"default_for_new_repos": "private_and_internal", "configuration": {"secret_scanning_non_provider_patterns": "enabled", "secret_scanning_validity_checks": "not_set"}}
+			]`))
 		} else {
 			t.Errorf("unexpected path: %s", r.URL.Path)
 			w.WriteHeader(http.StatusNotFound)
@@ -341,6 +434,78 @@ func TestFetchOrgSecurity_Success(t *testing.T) {
 	if security.TwoFactorRequired == nil || *security.TwoFactorRequired != true {
 		t.Errorf("TwoFactorRequired = %v, want true", security.TwoFactorRequired)
 	}
+	defaults := security.NewRepoDefaults
+	if defaults.DependabotAlerts == nil || !*defaults.DependabotAlerts {
+		t.Errorf("NewRepoDefaults.DependabotAlerts = %v, want true", defaults.DependabotAlerts)
+	}
+	if defaults.SecretScanning == nil || *defaults.SecretScanning {
+		t.Errorf("NewRepoDefaults.SecretScanning = %v, want false", defaults.SecretScanning)
+	}
+	if defaults.SecretScanningPushProtection != nil {
+		t.Errorf("NewRepoDefaults.SecretScanningPushProtection = %v, want nil when absent", defaults.SecretScanningPushProtection)
+	}
+	if security.PushProtectionForOrg == nil || !*security.PushProtectionForOrg {
+		t.Errorf("PushProtectionForOrg = %v, want true", security.PushProtectionForOrg)
+	}
+	if security.SecretScanningNonProviderPatternsDefault == nil || !*security.SecretScanningNonProviderPatternsDefault {
+		t.Errorf("SecretScanningNonProviderPatternsDefault = %v, want true", security.SecretScanningNonProviderPatternsDefault)
+	}
+	if security.SecretScanningValidityChecksDefault == nil || *security.SecretScanningValidityChecksDefault {
+		t.Errorf("SecretScanningValidityChecksDefault = %v, want false", security.SecretScanningValidityChecksDefault)
+	}
+	if security.ContactEmail == nil || !*security.ContactEmail {
+		t.Errorf("ContactEmail = %v, want true", security.ContactEmail)
+	}
+	if security.SecurityPolicy == nil || !*security.SecurityPolicy {
+		t.Errorf("SecurityPolicy = %v, want true (.github/SECURITY.md)", security.SecurityPolicy)
+	}
+	if got := security.MembersWithout2FA; got == nil || *got != (MemberTwoFactorCounts{Without2FA: 1, Members: 4}) {
+		t.Errorf("MembersWithout2FA = %+v, want 1 of 4", got)
+	}
+}
+
+// documentedOrgResponse is the security-relevant part of the example
+// response GitHub documents for GET /orgs/{org}.
+const documentedOrgResponse = `{
+	"login": "github",
+	"id": 1,
+	"email": "octocat@github.com",
+	"two_factor_requirement_enabled": true,
+	"advanced_security_enabled_for_new_repositories": false,
+	"dependabot_alerts_enabled_for_new_repositories": false,
+	"dependabot_security_updates_enabled_for_new_repositories": false,
+	"dependency_graph_enabled_for_new_repositories": false,
+	"secret_scanning_enabled_for_new_repositories": false,
+	"secret_scanning_push_protection_enabled_for_new_repositories": false,
+	"secret_scanning_push_protection_custom_link_enabled": false,
+	"secret_scanning_push_protection_custom_link": "https://github.com/octo-org/octo-repo/blob/main/im-blocked.md"
+}`
+
+func TestOrgREST_DocumentedResponse(t *testing.T) {
+	var org orgREST
+	if err := json.Unmarshal([]byte(documentedOrgResponse), &org); err != nil {
+		t.Fatal(err)
+	}
+	// Every documented key the collector reads decodes.
+	d := org.NewRepoDefaults
+	for name, got := range map[string]*bool{
+		"two_factor_requirement_enabled":                               org.TwoFactorRequirementEnabled,
+		"advanced_security_enabled_for_new_repositories":               d.AdvancedSecurity,
+		"dependabot_alerts_enabled_for_new_repositories":               d.DependabotAlerts,
+		"dependabot_security_updates_enabled_for_new_repositories":     d.DependabotSecurityUpdates,
+		"dependency_graph_enabled_for_new_repositories":                d.DependencyGraph,
+		"secret_scanning_enabled_for_new_repositories":                 d.SecretScanning,
+		"secret_scanning_push_protection_enabled_for_new_repositories": d.SecretScanningPushProtection,
+	} {
+		if got == nil {
+			t.Errorf("%s not decoded", name)
+		}
+	}
+	// Push protection for users isn't documented, so it stays unknown
+	// rather than being inferred from the new-repository default.
+	if org.PushProtectionForUsers != nil {
+		t.Errorf("PushProtectionForUsers = %v, want nil from the documented response", *org.PushProtectionForUsers)
+	}
 }
 
 func TestFetchOrgSecurity_TwoFactorDisabled(t *testing.T) {
@@ -350,6 +515,12 @@ func TestFetchOrgSecurity_TwoFactorDisabled(t *testing.T) {
 			_ = json.NewEncoder(w).Encode(map[string]interface{}{
 				"two_factor_requirement_enabled": false,
 			})
+		} else if r.URL.Path == "/orgs/test-org/code-security/configurations/defaults" ||
+			strings.HasPrefix(r.URL.Path, "/repos/test-org/.github/") {
+			w.WriteHeader(http.StatusForbidden)
+		} else if r.URL.Path == "/orgs/test-org/members" {
+			// Only owners may filter by 2FA status.
+			w.WriteHeader(http.StatusUnprocessableEntity)
 		} else {
 			t.Errorf("unexpected path: %s", r.URL.Path)
 			w.WriteHeader(http.StatusNotFound)
@@ -367,6 +538,18 @@ func TestFetchOrgSecurity_TwoFactorDisabled(t *testing.T) {
 	if security.TwoFactorRequired == nil || *security.TwoFactorRequired != false {
 		t.Errorf("TwoFactorRequired = %v, want false", security.TwoFactorRequired)
 	}
+	if security.SecretScanningNonProviderPatternsDefault != nil {
+		t.Errorf("SecretScanningNonProviderPatternsDefault = %v, want nil when defaults are not readable", security.SecretScanningNonProviderPatternsDefault)
+	}
+	if security.ContactEmail == nil || *security.ContactEmail {
+		t.Errorf("ContactEmail = %v, want false without an email", security.ContactEmail)
+	}
+	if security.SecurityPolicy != nil {
+		t.Errorf("SecurityPolicy = %v, want nil when .github is not readable", security.SecurityPolicy)
+	}
+	if security.MembersWithout2FA != nil || security.MembersWithout2FAErr == nil {
+		t.Errorf("MembersWithout2FA = %+v (error %v), want nil with the error when the 2FA filter is refused", security.MembersWithout2FA, security.MembersWithout2FAErr)
+	}
 }
 
 func TestFetchOrgSecurity_PermissionError(t *testing.T) {
@@ -479,6 +662,155 @@ func TestFetchRepositories_Pagination(t *testing.T) {
 	}
 }
 
+func TestFetchRepositories_RecordsRateLimit(t *testing.T) {
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "rateLimit") {
+			t.Error("query should request the rateLimit object")
+		}
+
+		response := map[string]interface{}{
+			"data": map[string]interface{}{
+				"rateLimit": map[string]interface{}{
+					"cost":      callCount,
+					"remaining": 5000 - callCount,
+					"resetAt":   "2026-01-15T11:00:00Z",
+				},
+				"organization": map[string]interface{}{
+					"repositories": map[string]interface{}{
+						"nodes": []map[string]interface{}{
+							{"name": fmt.Sprintf("repo%d", callCount), "owner": map[string]interface{}{"login": "org"}},
+						},
+						"pageInfo": map[string]interface{}{
+							"hasNextPage": callCount < 2,
+							"endCursor":   "cursor",
+						},
+					},
+				},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClientWithGraphQL(server.Client(), server.URL, server.URL+"/graphql")
+	if stats := client.Stats(); stats.RateLimitRemaining != nil {
+		t.Errorf("RateLimitRemaining before any query = %d, want nil", *stats.RateLimitRemaining)
+	}
+
+	err := client.FetchRepositories(context.Background(), "org", func([]Repository) error { return nil })
+	if err != nil {
+		t.Fatalf("FetchRepositories() error: %v", err)
+	}
+
+	stats := client.Stats()
+	if stats.GraphQLQueries != 2 {
+		t.Errorf("GraphQLQueries = %d, want 2", stats.GraphQLQueries)
+	}
+	if stats.GraphQLCost != 3 {
+		t.Errorf("GraphQLCost = %d, want 3 (1 + 2)", stats.GraphQLCost)
+	}
+	if stats.RateLimitRemaining == nil || *stats.RateLimitRemaining != 4998 {
+		t.Errorf("RateLimitRemaining = %v, want 4998 (last query)", stats.RateLimitRemaining)
+	}
+	if want := time.Date(2026, 1, 15, 11, 0, 0, 0, time.UTC); !stats.RateLimitResetAt.Equal(want) {
+		t.Errorf("RateLimitResetAt = %v, want %v", stats.RateLimitResetAt, want)
+	}
+}
+
+func TestFetchUserRepositories(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodyStr := string(body)
+		if !strings.Contains(bodyStr, "user(login: $login)") || !strings.Contains(bodyStr, "ownerAffiliations: [OWNER]") {
+			t.Errorf("unexpected query: %s", bodyStr)
+		}
+
+		response := map[string]interface{}{
+			"data": map[string]interface{}{
+				"user": map[string]interface{}{
+					"repositories": map[string]interface{}{
+						"nodes": []map[string]interface{}{
+							{"name": "dotfiles", "owner": map[string]interface{}{"login": "octocat"}},
+						},
+						"pageInfo": map[string]interface{}{"hasNextPage": false, "endCursor": ""},
+					},
+				},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClientWithGraphQL(server.Client(), server.URL, server.URL+"/graphql")
+
+	var repos []Repository
+	err := client.FetchUserRepositories(context.Background(), "octocat", func(page []Repository) error {
+		repos = append(repos, page...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("FetchUserRepositories() error: %v", err)
+	}
+	if len(repos) != 1 || repos[0].Name != "dotfiles" || repos[0].Owner.Login != "octocat" {
+		t.Errorf("FetchUserRepositories() = %+v, want octocat/dotfiles", repos)
+	}
+}
+
+func TestFetchRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rateLimit := map[string]interface{}{"cost": 1, "remaining": 4990, "resetAt": "2026-01-15T11:00:00Z"}
+
+		var response map[string]interface{}
+		if strings.Contains(string(body), `"name":"api"`) {
+			response = map[string]interface{}{
+				"data": map[string]interface{}{
+					"rateLimit": rateLimit,
+					"repository": map[string]interface{}{
+						"name":                          "api",
+						"owner":                         map[string]interface{}{"login": "other-org"},
+						"hasVulnerabilityAlertsEnabled": true,
+					},
+				},
+			}
+		} else {
+			response = map[string]interface{}{
+				"data": map[string]interface{}{"rateLimit": rateLimit, "repository": nil},
+				"errors": []map[string]interface{}{
+					{"type": "NOT_FOUND", "message": "Could not resolve to a Repository with the name 'other-org/missing'."},
+				},
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClientWithGraphQL(server.Client(), server.URL, server.URL+"/graphql")
+
+	repo, err := client.FetchRepository(context.Background(), "other-org", "api")
+	if err != nil {
+		t.Fatalf("FetchRepository() error: %v", err)
+	}
+	if repo.Name != "api" || repo.Owner.Login != "other-org" || !repo.HasVulnerabilityAlertsEnabled {
+		t.Errorf("FetchRepository() = %+v, want other-org/api with vulnerability alerts", repo)
+	}
+
+	_, err = client.FetchRepository(context.Background(), "other-org", "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("FetchRepository(missing) error = %v, want ErrNotFound", err)
+	}
+
+	if stats := client.Stats(); stats.GraphQLQueries != 2 {
+		t.Errorf("GraphQLQueries = %d, want 2 (not-found responses still report rateLimit)", stats.GraphQLQueries)
+	}
+}
+
 func TestFetchOrgSecurity_RateLimitError(t *testing.T) {
 	// Rate limit errors should result in graceful degradation (nil values)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -549,6 +881,8 @@ func TestFetchRepositories_BranchProtection(t *testing.T) {
 			"requiresStatusChecks",
 			"requiresCommitSignatures",
 			"isAdminEnforced",
+			"requiresConversationResolution",
+			"requireLastPushApproval",
 		}
 		for _, field := range expectedFields {
 			if !strings.Contains(bodyStr, field) {
@@ -575,6 +909,7 @@ func TestFetchRepositories_BranchProtection(t *testing.T) {
 										"requiresStatusChecks":         true,
 										"requiresCommitSignatures":     false,
 										"isAdminEnforced":              true,
+										"requireLastPushApproval":      true,
 									},
 								},
 								"hasVulnerabilityAlertsEnabled": true,
@@ -633,6 +968,9 @@ func TestFetchRepositories_BranchProtection(t *testing.T) {
 	if !rule.IsAdminEnforced {
 		t.Error("IsAdminEnforced should be true")
 	}
+	if !rule.RequireLastPushApproval {
+		t.Error("RequireLastPushApproval should be true")
+	}
 }
 
 func TestGetOrgMembership_IncludesDisplayNames(t *testing.T) {
@@ -791,3 +1129,615 @@ func TestCollaboratorNames_CapAndAbort(t *testing.T) {
 		t.Errorf("incomplete = %v, want one cap reason", incomplete)
 	}
 }
+
+func TestListOpenIssuesWithLabel_DropsPullRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/org/repo/issues" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("labels"); got != "Security Review" {
+			t.Errorf("labels = %q, want %q", got, "Security Review")
+		}
+		if got := r.URL.Query().Get("state"); got != "open" {
+			t.Errorf("state = %q, want open", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"number": 1, "created_at": "2026-01-01T00:00:00Z", "title": "leaked key"},
+			{"number": 2, "created_at": "2026-01-02T00:00:00Z", "pull_request": {"url": "x"}}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	issues, err := client.ListOpenIssuesWithLabel(context.Background(), "org", "repo", "Security Review")
+	if err != nil {
+		t.Fatalf("ListOpenIssuesWithLabel() error: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Number != 1 || issues[0].CreatedAt != "2026-01-01T00:00:00Z" {
+		t.Errorf("ListOpenIssuesWithLabel() = %+v, want only issue #1", issues)
+	}
+}
+
+func TestListCommitCheckContexts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.EscapedPath() {
+		case "/repos/org/repo/commits/release%2F1.0/check-runs":
+			_, _ = w.Write([]byte(`{"total_count": 1, "check_runs": [{"name": "build"}]}`))
+		case "/repos/org/repo/commits/release%2F1.0/status":
+			_, _ = w.Write([]byte(`{"state": "success", "statuses": [{"context": "ci/lint"}]}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.EscapedPath())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	names, err := client.ListCommitCheckContexts(context.Background(), "org", "repo", "release/1.0")
+	if err != nil {
+		t.Fatalf("ListCommitCheckContexts() error: %v", err)
+	}
+	if len(names) != 2 || names[0] != "build" || names[1] != "ci/lint" {
+		t.Errorf("ListCommitCheckContexts() = %v, want [build ci/lint]", names)
+	}
+}
+
+func TestListSecurityManagerTeams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/org/security-managers" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id": 1, "slug": "appsec", "name": "AppSec"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	teams, err := client.ListSecurityManagerTeams(context.Background(), "org")
+	if err != nil {
+		t.Fatalf("ListSecurityManagerTeams() error: %v", err)
+	}
+	if len(teams) != 1 || teams[0] != "appsec" {
+		t.Errorf("ListSecurityManagerTeams() = %v, want [appsec]", teams)
+	}
+}
+
+func TestLookupRepository_FollowsRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/org/old":
+			http.Redirect(w, r, "/repositories/42", http.StatusMovedPermanently)
+		case "/repositories/42":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": 42, "full_name": "other/new"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	name, err := client.LookupRepository(context.Background(), "org", "old")
+	if err != nil {
+		t.Fatalf("LookupRepository() error: %v", err)
+	}
+	if name != "other/new" {
+		t.Errorf("LookupRepository() = %q, want other/new", name)
+	}
+
+	if _, err := client.LookupRepository(context.Background(), "org", "gone"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("LookupRepository(gone) error = %v, want ErrNotFound", err)
+	}
+	// A followed redirect is one REST request; a failed one still counts.
+	if got := client.Stats().RESTRequests; got != 2 {
+		t.Errorf("RESTRequests = %d, want 2", got)
+	}
+}
+
+func TestGetVulnerabilityAlertsEnabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/org/on/vulnerability-alerts":
+			w.WriteHeader(http.StatusNoContent)
+		case "/repos/org/off/vulnerability-alerts":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	for repo, want := range map[string]bool{"on": true, "off": false} {
+		got, err := client.GetVulnerabilityAlertsEnabled(context.Background(), "org", repo)
+		if err != nil || got != want {
+			t.Errorf("GetVulnerabilityAlertsEnabled(%s) = %v, %v; want %v", repo, got, err, want)
+		}
+	}
+	if _, err := client.GetVulnerabilityAlertsEnabled(context.Background(), "org", "denied"); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("GetVulnerabilityAlertsEnabled(denied) error = %v, want ErrPermissionDenied", err)
+	}
+}
+
+func TestGetOrgInteractionLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/limited/interaction-limits":
+			w.Write([]byte(`{"limit":"existing_users","origin":"organization","expires_at":"2026-02-01T00:00:00Z"}`))
+		case "/orgs/open/interaction-limits":
+			w.WriteHeader(http.StatusNoContent)
+		case "/orgs/empty/interaction-limits":
+			w.Write([]byte(`{}`))
+		case "/orgs/blank/interaction-limits":
+		case "/orgs/garbled/interaction-limits":
+			w.Write([]byte(`{"limit":`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	limit, err := client.GetOrgInteractionLimit(context.Background(), "limited")
+	if err != nil || limit == nil || limit.Limit != "existing_users" || limit.ExpiresAt != "2026-02-01T00:00:00Z" {
+		t.Errorf("GetOrgInteractionLimit(limited) = %+v, %v", limit, err)
+	}
+	for _, org := range []string{"open", "empty", "blank"} {
+		if limit, err := client.GetOrgInteractionLimit(context.Background(), org); err != nil || limit != nil {
+			t.Errorf("GetOrgInteractionLimit(%s) = %+v, %v; want nil, nil", org, limit, err)
+		}
+	}
+	if _, err := client.GetOrgInteractionLimit(context.Background(), "denied"); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("GetOrgInteractionLimit(denied) error = %v, want ErrPermissionDenied", err)
+	}
+	var decErr *DecodeError
+	if _, err := client.GetOrgInteractionLimit(context.Background(), "garbled"); !errors.As(err, &decErr) {
+		t.Errorf("GetOrgInteractionLimit(garbled) error = %v, want a DecodeError", err)
+	}
+}
+
+func TestListOrgBlockedUsers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/org/blocks" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[{"login":"spammer-1"},{"login":"spammer-2"}]`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	got, err := client.ListOrgBlockedUsers(context.Background(), "org")
+	if err != nil || !slices.Equal(got, []string{"spammer-1", "spammer-2"}) {
+		t.Errorf("ListOrgBlockedUsers() = %v, %v", got, err)
+	}
+}
+
+func TestListOrgProjects(t *testing.T) {
+	graphqlCalls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		graphqlCalls++
+		body, _ := io.ReadAll(r.Body)
+		switch {
+		case strings.Contains(string(body), `"denied"`):
+			w.Write([]byte(`{"data":{"organization":{"projectsV2":null}},"errors":[{"type":"FORBIDDEN","message":"Resource not accessible by integration"}]}`))
+		case graphqlCalls == 1:
+			w.Write([]byte(`{"data":{"organization":{"projectsV2":{"nodes":[{"number":1,"title":"Roadmap","public":true,"closed":false}],"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}}`))
+		default:
+			if !strings.Contains(string(body), "c1") {
+				t.Error("second page should carry the first page's cursor")
+			}
+			w.Write([]byte(`{"data":{"organization":{"projectsV2":{"nodes":[{"number":2,"title":"Ops","public":false,"closed":true}],"pageInfo":{"hasNextPage":false,"endCursor":""}}}}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithGraphQL(server.Client(), server.URL, server.URL+"/graphql")
+	projects, err := client.ListOrgProjects(context.Background(), "org")
+	if err != nil {
+		t.Fatalf("ListOrgProjects() error: %v", err)
+	}
+	want := []Project{{Number: 1, Title: "Roadmap", Public: true}, {Number: 2, Title: "Ops", Closed: true}}
+	if !slices.Equal(projects, want) {
+		t.Errorf("ListOrgProjects() = %+v, want %+v", projects, want)
+	}
+	if _, err := client.ListOrgProjects(context.Background(), "denied"); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("ListOrgProjects(denied) error = %v, want ErrPermissionDenied", err)
+	}
+}
+
+func TestListOrgRulesets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/orgs/org/rulesets":
+			w.Write([]byte(`[{"id":7,"name":"supply-chain"}]`))
+		case "/orgs/org/rulesets/7":
+			w.Write([]byte(`{"id":7,"name":"supply-chain","target":"branch","enforcement":"active",
+				"conditions":{"ref_name":{"include":["~DEFAULT_BRANCH"],"exclude":[]},"repository_name":{"include":["~ALL"],"exclude":["sandbox-*"]}},
+				"bypass_actors":[{"actor_id":1,"actor_type":"OrganizationAdmin","bypass_mode":"always"}],
+				"rules":[{"type":"deletion"},{"type":"workflows","parameters":{"workflows":[{"path":".github/workflows/dr.yml","repository_id":42,"ref":"refs/heads/main"}]}},
+					{"type":"pull_request","parameters":{"required_approving_review_count":2,"require_code_owner_review":true}},
+					{"type":"required_status_checks","parameters":{"required_status_checks":[{"context":"ci/test"},{"context":"lint","integration_id":3}]}}]}`))
+		case "/repositories/42/contents/.github/workflows/dr.yml":
+			if r.URL.Query().Get("ref") != "refs/heads/main" {
+				t.Errorf("workflow ref = %q", r.URL.Query().Get("ref"))
+			}
+			w.Write([]byte(`{"encoding":"base64","size":42,"content":"dXNlczogYWN0aW9ucy9kZXBlbmRlbmN5LXJldmlldy1hY3Rpb25AdjQ=\n"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	rulesets, truncated, err := client.ListOrgRulesets(context.Background(), "org")
+	if err != nil {
+		t.Fatalf("ListOrgRulesets() error: %v", err)
+	}
+	if len(rulesets) != 1 || truncated {
+		t.Fatalf("rulesets = %+v (truncated %v), want one", rulesets, truncated)
+	}
+	rs := rulesets[0]
+	if rs.Enforcement != RulesetEnforcementActive || rs.Conditions.RepositoryName == nil || !slices.Equal(rs.Conditions.RepositoryName.Exclude, []string{"sandbox-*"}) {
+		t.Errorf("ruleset = %+v", rs)
+	}
+	if len(rs.Rules) != 4 || rs.Rules[0].Workflows != nil || !slices.Equal(rs.Rules[1].Workflows, []RulesetWorkflow{{Path: ".github/workflows/dr.yml", RepositoryID: 42, Ref: "refs/heads/main"}}) {
+		t.Fatalf("rules = %+v", rs.Rules)
+	}
+	if pr := rs.Rules[2].PullRequest; pr == nil || *pr != (PullRequestRuleOptions{RequiredApprovingReviewCount: 2, RequireCodeOwnerReview: true}) {
+		t.Errorf("pull_request parameters = %+v", pr)
+	}
+	if got := rs.Rules[3].StatusChecks; !slices.Equal(got, []string{"ci/test", "lint"}) {
+		t.Errorf("required status checks = %v", got)
+	}
+	if len(rs.BypassActors) != 1 {
+		t.Errorf("bypass actors = %+v, want one", rs.BypassActors)
+	}
+
+	content, err := client.GetWorkflowFile(context.Background(), 42, ".github/workflows/dr.yml", "refs/heads/main")
+	if err != nil || string(content) != "uses: actions/dependency-review-action@v4" {
+		t.Errorf("GetWorkflowFile() = %q, %v", content, err)
+	}
+	if _, err := client.GetWorkflowFile(context.Background(), 42, "missing.yml", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetWorkflowFile(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListWorkflowFiles(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/ci/contents/.github/workflows":
+			w.Write([]byte(`[
+				{"type":"file","path":".github/workflows/build.yml"},
+				{"type":"file","path":".github/workflows/README.md"},
+				{"type":"dir","path":".github/workflows/shared.yaml"},
+				{"type":"file","path":".github/workflows/release.yaml"}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	paths, err := client.ListWorkflowFiles(context.Background(), "o", "ci")
+	if err != nil || !slices.Equal(paths, []string{".github/workflows/build.yml", ".github/workflows/release.yaml"}) {
+		t.Errorf("ListWorkflowFiles() = %v, %v", paths, err)
+	}
+	if paths, err := client.ListWorkflowFiles(context.Background(), "o", "none"); err != nil || paths != nil {
+		t.Errorf("ListWorkflowFiles(no workflows) = %v, %v; want none", paths, err)
+	}
+}
+
+func TestListRecentReleasesAndAttestations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/releases":
+			if r.URL.Query().Get("per_page") != "2" {
+				t.Errorf("per_page = %q, want 2", r.URL.Query().Get("per_page"))
+			}
+			w.Write([]byte(`[{"tag_name":"v3","draft":true},{"tag_name":"v2","immutable":true,"assets":[{"name":"a.tgz","digest":"sha256:aa"}]}]`))
+		case "/repos/o/r/attestations/sha256:aa":
+			w.Write([]byte(`{"attestations":[{"bundle":{}}]}`))
+		case "/repos/o/r/attestations/sha256:bb":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	releases, err := client.ListRecentReleases(context.Background(), "o", "r", 2)
+	if err != nil {
+		t.Fatalf("ListRecentReleases() error: %v", err)
+	}
+	if len(releases) != 1 || releases[0].TagName != "v2" || releases[0].Immutable == nil || !*releases[0].Immutable || releases[0].Assets[0].Digest != "sha256:aa" {
+		t.Errorf("ListRecentReleases() = %+v, want v2 only (drafts skipped)", releases)
+	}
+
+	if ok, err := client.HasAttestations(context.Background(), "o", "r", "sha256:aa"); !ok || err != nil {
+		t.Errorf("HasAttestations(aa) = %v, %v; want true", ok, err)
+	}
+	if ok, err := client.HasAttestations(context.Background(), "o", "r", "sha256:bb"); ok || err != nil {
+		t.Errorf("HasAttestations(bb) = %v, %v; want false, nil", ok, err)
+	}
+	if _, err := client.HasAttestations(context.Background(), "o", "denied", "sha256:aa"); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("HasAttestations(denied) error = %v, want ErrPermissionDenied", err)
+	}
+}
+
+func TestDetectInstance(t *testing.T) {
+	tests := []struct {
+		header string
+		body   string
+		want   Instance
+	}{
+		{"", `{"verifiable_password_authentication": false}`, Instance{}},
+		{"enterprise-server@3.8.4", `{}`, Instance{Enterprise: true, Version: "3.8.4"}},
+		{"3.12.1", `{}`, Instance{Enterprise: true, Version: "3.12.1"}},
+		// Without the header, e.g. behind a proxy stripping it.
+		{"", `{"installed_version": "3.10.2"}`, Instance{Enterprise: true, Version: "3.10.2"}},
+	}
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tt.header != "" {
+				w.Header().Set(enterpriseVersionHeader, tt.header)
+			}
+			w.Write([]byte(tt.body))
+		}))
+		client := NewClientWithHTTP(server.Client(), server.URL)
+		got, err := client.DetectInstance(context.Background())
+		server.Close()
+		if err != nil || got != tt.want {
+			t.Errorf("DetectInstance(%q) = %+v, %v; want %+v", tt.header, got, err, tt.want)
+		}
+	}
+}
+
+func TestInstanceSupports(t *testing.T) {
+	tests := []struct {
+		instance Instance
+		feature  string
+		want     bool
+	}{
+		{Instance{}, FeatureFineGrainedPATs, true},
+		{Instance{Enterprise: true}, FeatureFineGrainedPATs, true},
+		{Instance{Enterprise: true, Version: "3.9.5"}, FeatureFineGrainedPATs, false},
+		{Instance{Enterprise: true, Version: "3.10.0"}, FeatureFineGrainedPATs, true},
+		{Instance{Enterprise: true, Version: "3.8.4"}, FeatureCodeScanningDefaultSetup, false},
+		{Instance{Enterprise: true, Version: "3.9"}, FeatureCodeScanningDefaultSetup, true},
+		{Instance{Enterprise: true, Version: "3.10.8"}, FeatureRulesets, false},
+		{Instance{Enterprise: true, Version: "3.11.0"}, FeatureRulesets, true},
+		{Instance{Enterprise: true, Version: "3.0.0"}, "unknown_feature", true},
+	}
+	for _, tt := range tests {
+		if got := tt.instance.Supports(tt.feature); got != tt.want {
+			t.Errorf("%s.Supports(%s) = %v, want %v", tt.instance, tt.feature, got, tt.want)
+		}
+	}
+}
+
+func TestInstanceRelease(t *testing.T) {
+	for instance, want := range map[Instance]string{
+		{}:                                      "",
+		{Enterprise: true}:                      "",
+		{Enterprise: true, Version: "3.9.2"}:    "3.9",
+		{Enterprise: true, Version: "3.12"}:     "3.12",
+		{Enterprise: true, Version: "3.14.0.1"}: "3.14",
+	} {
+		if got := instance.Release(); got != want {
+			t.Errorf("%s.Release() = %q, want %q", instance, got, want)
+		}
+	}
+	capabilities := Instance{Enterprise: true, Version: "3.10.1"}.Capabilities()
+	if len(capabilities) != len(featureMinVersion) || !capabilities[FeatureFineGrainedPATs] || capabilities[FeatureRulesets] {
+		t.Errorf("Capabilities() = %v", capabilities)
+	}
+}
+
+func TestGraphQLURL(t *testing.T) {
+	for apiURL, want := range map[string]string{
+		DefaultBaseURL:                    "https://api.github.com/graphql",
+		"https://ghe.example.com/api/v3":  "https://ghe.example.com/api/graphql",
+		"https://ghe.example.com/api/v3/": "https://ghe.example.com/api/graphql",
+	} {
+		if got := GraphQLURL(apiURL); got != want {
+			t.Errorf("GraphQLURL(%q) = %q, want %q", apiURL, got, want)
+		}
+	}
+}
+
+func TestUnsupportedEndpointsSkipped(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/meta":
+			w.Header().Set(enterpriseVersionHeader, "enterprise-server@3.8.0")
+			w.Write([]byte(`{}`))
+		case "/repos/org/repo/code-scanning/analyses":
+			w.Write([]byte(`[{"id":1}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	if _, err := client.DetectInstance(context.Background()); err != nil {
+		t.Fatalf("DetectInstance() error: %v", err)
+	}
+	if got := client.checkCodeScanning(context.Background(), "org", "repo"); !got.enabled {
+		t.Error("code scanning should be detected from analyses when default setup is unsupported")
+	}
+	if _, _, err := client.ListOrgPATs(context.Background(), "org"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("ListOrgPATs() error = %v, want ErrUnsupported", err)
+	}
+	if _, _, err := client.ListOrgRulesets(context.Background(), "org"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("ListOrgRulesets() error = %v, want ErrUnsupported", err)
+	}
+	if slices.Contains(paths, "/repos/org/repo/code-scanning/default-setup") || slices.Contains(paths, "/orgs/org/personal-access-tokens") ||
+		slices.Contains(paths, "/orgs/org/rulesets") {
+		t.Errorf("unsupported endpoints were requested: %v", paths)
+	}
+}
+
+func TestDecodeJSON_RejectsMalformedBodies(t *testing.T) {
+	bodies := map[string]string{
+		"/truncated": `{"security_and_analysis": {`,
+		"/trailing":  `{"limit":"existing_users"}<html>proxy error</html>`,
+		"/html":      `<html>502 Bad Gateway</html>`,
+		"/oversized": `[` + strings.Repeat(`"x",`, MaxResponseBytes/4) + `"x"]`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(bodies[r.URL.Path]))
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	for path := range bodies {
+		var out any
+		err := client.getJSON(context.Background(), path, &out)
+		var decErr *DecodeError
+		if !errors.As(err, &decErr) || decErr.Path != path {
+			t.Errorf("getJSON(%s) error = %v, want a DecodeError for the path", path, err)
+		}
+	}
+	var out any
+	if err := client.getJSON(context.Background(), "/oversized", &out); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("oversized body error = %v, want ErrResponseTooLarge", err)
+	}
+	if stats := client.Stats(); stats.DecodeErrors != len(bodies)+1 || stats.LastDecodeError == "" {
+		t.Errorf("DecodeErrors = %d (%q), want %d", stats.DecodeErrors, stats.LastDecodeError, len(bodies)+1)
+	}
+}
+
+func TestFetchSecuritySettings_MalformedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"security_and_analysis": {"secret_scanning": {"status": "enabled"`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	settings, err := client.FetchSecuritySettings(context.Background(), "org", "repo")
+	var decErr *DecodeError
+	if settings != nil || !errors.As(err, &decErr) {
+		t.Errorf("FetchSecuritySettings() = %+v, %v; want a DecodeError, not zero-valued settings", settings, err)
+	}
+}
+
+func TestFetchRepositories_CISystems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `rootTree: object(expression: \"HEAD:\"){... on Tree{entries{name}}}`) {
+			t.Errorf("query lacks the root tree lookup: %s", body)
+		}
+		if n := strings.Count(string(body), "object(expression:"); n != 2 {
+			t.Errorf("query has %d git object lookups per repository, want 2: %s", n, body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"organization":{"repositories":{
+			"nodes":[
+				{"name":"api","owner":{"login":"org"},"githubActionsConfig":{"oid":"abc"},"rootTree":{"entries":[{"name":"Jenkinsfile"},{"name":"go.mod"}]}},
+				{"name":"docs","owner":{"login":"org"},"githubActionsConfig":null}
+			],
+			"pageInfo":{"hasNextPage":false,"endCursor":""}}}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithGraphQL(server.Client(), server.URL, server.URL+"/graphql")
+	var repos []Repository
+	err := client.FetchRepositories(context.Background(), "org", func(page []Repository) error {
+		repos = append(repos, page...)
+		return nil
+	})
+	if err != nil || len(repos) != 2 {
+		t.Fatalf("FetchRepositories() = %d repos, %v", len(repos), err)
+	}
+	if got := repos[0].CISystems(); !slices.Equal(got, []string{CISystemGitHubActions, CISystemJenkins}) {
+		t.Errorf("api CISystems() = %v", got)
+	}
+	if got := repos[1].CISystems(); got != nil {
+		t.Errorf("docs CISystems() = %v, want none", got)
+	}
+}
+
+func TestSearchCode(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/search/code" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		queries = append(queries, r.URL.Query().Get("q"))
+		items := make([]string, 0, 100)
+		n := 100
+		if r.URL.Query().Get("page") == "2" {
+			n = 20
+		}
+		for i := range n {
+			repo := "o/a"
+			if i%4 == 0 {
+				repo = "o/b"
+			}
+			items = append(items, fmt.Sprintf(`{"path":"x/id_rsa","repository":{"full_name":%q}}`, repo))
+		}
+		fmt.Fprintf(w, `{"total_count":120,"incomplete_results":false,"items":[%s]}`, strings.Join(items, ","))
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	client.searchLimiter = rate.NewLimiter(rate.Inf, 1)
+	got, err := client.SearchCode(context.Background(), "org:o filename:id_rsa")
+	if err != nil {
+		t.Fatalf("SearchCode() error: %v", err)
+	}
+	if got.TotalCount != 120 || got.Incomplete || got.PerRepo["o/a"] != 90 || got.PerRepo["o/b"] != 30 {
+		t.Errorf("SearchCode() = %+v", got)
+	}
+	if len(queries) != 2 || queries[0] != "org:o filename:id_rsa" {
+		t.Errorf("queries = %q, want the query on 2 pages", queries)
+	}
+
+	// A page whose paced wait would pass the deadline isn't waited for: a
+	// later page leaves the result incomplete, a first page fails the query.
+	queries = nil
+	client.searchLimiter = rate.NewLimiter(rate.Every(time.Hour), 1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	got, err = client.SearchCode(ctx, "org:o filename:id_rsa")
+	if err != nil || !got.Incomplete || len(queries) != 1 {
+		t.Errorf("SearchCode() = %+v, %v after %d pages; want 1 page, incomplete", got, err, len(queries))
+	}
+	if _, err := client.SearchCode(ctx, "org:o filename:id_rsa"); !errors.Is(err, ErrSearchDeadline) {
+		t.Errorf("SearchCode() error = %v, want ErrSearchDeadline", err)
+	}
+}
+
+func TestListRepositoryPropertyValues(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orgs/org/properties/values" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`[
+			{"repository_id":1,"repository_name":"ledger","repository_full_name":"org/ledger","properties":[
+				{"property_name":"tier","value":"critical"},{"property_name":"teams","value":["payments","risk"]},{"property_name":"owner","value":null}]},
+			{"repository_id":2,"repository_name":"docs","repository_full_name":"org/docs","properties":[]}]`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	values, truncated, err := client.ListRepositoryPropertyValues(context.Background(), "org")
+	if err != nil || truncated {
+		t.Fatalf("ListRepositoryPropertyValues() error: %v", err)
+	}
+	ledger := values["ledger"]
+	if len(values) != 2 || len(ledger) != 2 || !slices.Equal(ledger["tier"], []string{"critical"}) || !slices.Equal(ledger["teams"], []string{"payments", "risk"}) {
+		t.Errorf("values = %v, want ledger's tier and teams, unset owner omitted", values)
+	}
+}
@@ -0,0 +1,58 @@
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+	"strings"
+)
+
+// codeownersCatchAll are the CODEOWNERS patterns matching every file.
+var codeownersCatchAll = []string{"*", "/*", "**", "/**", "/"}
+
+// parseCodeownersOwner returns the repository's owning team from its
+// CODEOWNERS file: the first team owning the last catch-all rule (the rule
+// GitHub applies to files no later rule matches), or else the team owning the
+// most rules, the earliest to appear on a tie. User and email owners are
+// skipped: only teams route findings.
+func parseCodeownersOwner(data []byte) (owning string) {
+	var teams []string
+	rules := map[string]int{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		var lineTeams []string
+		for _, owner := range fields[1:] {
+			team, ok := strings.CutPrefix(owner, "@")
+			if !ok || !strings.Contains(team, "/") {
+				continue
+			}
+			team = strings.ToLower(team)
+			if !slices.Contains(lineTeams, team) {
+				lineTeams = append(lineTeams, team)
+			}
+		}
+		for _, team := range lineTeams {
+			if _, seen := rules[team]; !seen {
+				teams = append(teams, team)
+			}
+			rules[team]++
+		}
+		if len(lineTeams) > 0 && slices.Contains(codeownersCatchAll, fields[0]) {
+			owning = lineTeams[0]
+		}
+	}
+	if owning != "" {
+		return owning
+	}
+	for _, team := range teams {
+		if owning == "" || rules[team] > rules[owning] {
+			owning = team
+		}
+	}
+	return owning
+}
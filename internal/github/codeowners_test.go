@@ -0,0 +1,39 @@
+package github
+
+import "testing"
+
+func TestParseCodeownersOwner(t *testing.T) {
+	cases := []struct {
+		name      string
+		file      string
+		wantOwner string
+	}{
+		{
+			name:      "last catch-all wins",
+			file:      "# Default owners\n* @acme/Platform\n/docs/ @acme/docs alice@example.com\n* @bob @acme/payments @acme/platform\n",
+			wantOwner: "acme/payments",
+		},
+		{
+			name:      "most rules without a catch-all",
+			file:      "/api/ @acme/backend\n/web/ @acme/frontend\n/lib/ @acme/frontend # shared\n",
+			wantOwner: "acme/frontend",
+		},
+		{
+			name:      "users only",
+			file:      "* @alice @bob\n",
+			wantOwner: "",
+		},
+		{
+			name:      "catch-all without teams falls back",
+			file:      "/api/ @acme/backend\n* @alice\n",
+			wantOwner: "acme/backend",
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if owner := parseCodeownersOwner([]byte(tc.file)); owner != tc.wantOwner {
+				t.Errorf("parseCodeownersOwner() = %q, want %q", owner, tc.wantOwner)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// MaxResponseBytes caps how much of a REST response body is read, protecting
+// the runner from pathological or proxy-mangled responses. The largest
+// legitimate bodies (100-item pages) are well under a megabyte.
+const MaxResponseBytes = 32 << 20
+
+// ErrResponseTooLarge is returned when a REST response body exceeds
+// MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// DecodeError reports a REST response body that isn't the single JSON value
+// expected: malformed, truncated, followed by trailing data, or too large.
+type DecodeError struct {
+	Path string
+	Err  error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("decoding %s: %v", e.Path, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// decodeJSON decodes a response body into out, requiring exactly one JSON
+// value. A failure is returned as a *DecodeError and counted in the stats, so
+// it surfaces as a warning instead of reading as zero-valued settings.
+func (c *Client) decodeJSON(resp *http.Response, out any) error {
+	dec := json.NewDecoder(resp.Body)
+	err := dec.Decode(out)
+	if err == nil {
+		if _, tokErr := dec.Token(); !errors.Is(tokErr, io.EOF) {
+			err = errors.New("unexpected data after JSON value")
+		}
+	}
+	if err == nil {
+		return nil
+	}
+	decErr := &DecodeError{Path: resp.Request.URL.Path, Err: err}
+	c.recordDecodeError(decErr)
+	return decErr
+}
+
+// recordDecodeError counts a decode failure in the stats.
+func (c *Client) recordDecodeError(err *DecodeError) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	c.stats.DecodeErrors++
+	c.stats.LastDecodeError = err.Error()
+}
+
+// cappedBody fails reads with ErrResponseTooLarge once more than remaining
+// bytes have been read.
+type cappedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *cappedBody) Read(p []byte) (int, error) {
+	if b.remaining == 0 {
+		// At the cap: any further byte means the body is over it.
+		var probe [1]byte
+		n, err := b.ReadCloser.Read(probe[:])
+		if n > 0 {
+			return 0, ErrResponseTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
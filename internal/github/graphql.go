@@ -0,0 +1,122 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// GraphQLAttempts is how many times a GraphQL query is sent before a
+// transient failure is returned.
+const GraphQLAttempts = 3
+
+// graphQLRetryDelay is the wait before the first retry; it doubles after
+// each. Replaced in tests.
+var graphQLRetryDelay = time.Second
+
+// transientGraphQLMessages mark failures worth retrying: gateway errors on
+// heavy queries and GitHub's query resource limits and timeouts.
+var transientGraphQLMessages = []string{
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+	"resource limits for this query exceeded",
+	"resource_limited",
+	"something went wrong while executing your query",
+}
+
+// isTransientGraphQLError reports whether a GraphQL failure may succeed on
+// retry.
+func isTransientGraphQLError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, m := range transientGraphQLMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// query runs a GraphQL query, retrying transient failures with exponential
+// backoff. q is reset before each retry so a partial response doesn't leak
+// into the next one.
+func (c *Client) query(ctx context.Context, q any, variables map[string]any) error {
+	delay := graphQLRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := c.graphql.Query(ctx, q, variables)
+		if err == nil || attempt == GraphQLAttempts || !isTransientGraphQLError(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+		reflect.ValueOf(q).Elem().SetZero()
+		c.statsMu.Lock()
+		c.stats.GraphQLRetries++
+		c.statsMu.Unlock()
+	}
+}
+
+// repositoryPager runs the queries of one repositories connection: page
+// fetches a page with or without RepositoryDetails, details fetches just the
+// details of the same page.
+type repositoryPager struct {
+	page    func(cursor *githubv4.String, details bool) (RepositoryConnection, error)
+	details func(cursor *githubv4.String) ([]RepositoryDetailsNode, error)
+}
+
+// eachRepositoryPage hands every page of the connection to callback. A page
+// whose full query still fails transiently after retries is fetched in two
+// halves, the repository fields and then their details, merged by name.
+func (c *Client) eachRepositoryPage(p repositoryPager, callback func([]Repository) error) error {
+	var cursor *githubv4.String
+	for {
+		conn, err := p.page(cursor, true)
+		if isTransientGraphQLError(err) {
+			conn, err = c.splitRepositoryPage(p, cursor)
+		}
+		if err != nil {
+			return err
+		}
+		if err := callback(conn.Nodes); err != nil {
+			return err
+		}
+		if !conn.PageInfo.HasNextPage {
+			return nil
+		}
+		cursor = &conn.PageInfo.EndCursor
+	}
+}
+
+// splitRepositoryPage fetches one page as two lighter queries.
+func (c *Client) splitRepositoryPage(p repositoryPager, cursor *githubv4.String) (RepositoryConnection, error) {
+	conn, err := p.page(cursor, false)
+	if err != nil {
+		return conn, err
+	}
+	nodes, err := p.details(cursor)
+	if err != nil {
+		return conn, err
+	}
+	details := make(map[string]RepositoryDetails, len(nodes))
+	for _, n := range nodes {
+		details[n.Name] = n.RepositoryDetails
+	}
+	for i := range conn.Nodes {
+		conn.Nodes[i].RepositoryDetails = details[conn.Nodes[i].Name]
+	}
+	c.statsMu.Lock()
+	c.stats.GraphQLSplitPages++
+	c.statsMu.Unlock()
+	return conn, nil
+}
@@ -0,0 +1,139 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fastRetries shortens the GraphQL retry backoff for a test.
+func fastRetries(t *testing.T) {
+	t.Helper()
+	orig := graphQLRetryDelay
+	graphQLRetryDelay = time.Millisecond
+	t.Cleanup(func() { graphQLRetryDelay = orig })
+}
+
+func TestIsTransientGraphQLError(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want bool
+	}{
+		{`non-200 OK status code: 502 Bad Gateway body: ""`, true},
+		{`non-200 OK status code: 504 Gateway Timeout body: ""`, true},
+		{"Resource limits for this query exceeded.", true},
+		{"Something went wrong while executing your query. This may be the result of a timeout.", true},
+		{"Could not resolve to a Repository with the name 'org/gone'.", false},
+		{`non-200 OK status code: 401 Unauthorized body: ""`, false},
+	}
+	for _, tt := range tests {
+		if got := isTransientGraphQLError(&testError{tt.msg}); got != tt.want {
+			t.Errorf("isTransientGraphQLError(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+	if isTransientGraphQLError(context.DeadlineExceeded) {
+		t.Error("context deadline treated as transient")
+	}
+}
+
+type testError struct{ msg string }
+
+func (e *testError) Error() string { return e.msg }
+
+func TestFetchRepositories_RetriesTransientFailure(t *testing.T) {
+	fastRetries(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"organization":{"repositories":{
+			"nodes":[{"name":"api","owner":{"login":"org"}}],
+			"pageInfo":{"hasNextPage":false,"endCursor":""}}}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClientWithGraphQL(server.Client(), server.URL, server.URL+"/graphql")
+	var repos []Repository
+	err := client.FetchRepositories(context.Background(), "org", func(page []Repository) error {
+		repos = append(repos, page...)
+		return nil
+	})
+	if err != nil || len(repos) != 1 {
+		t.Fatalf("FetchRepositories() = %d repos, %v; want 1 repo after retries", len(repos), err)
+	}
+	if got := client.Stats().GraphQLRetries; got != 2 {
+		t.Errorf("GraphQLRetries = %d, want 2", got)
+	}
+	if got := client.Stats().GraphQLSplitPages; got != 0 {
+		t.Errorf("GraphQLSplitPages = %d, want 0", got)
+	}
+}
+
+func TestFetchRepositories_SplitsFailingPage(t *testing.T) {
+	fastRetries(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.Unmarshal(body, &req)
+		details, hasDetails := req.Variables["details"]
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case details == true:
+			w.WriteHeader(http.StatusBadGateway)
+		case hasDetails:
+			_, _ = w.Write([]byte(`{"data":{"organization":{"repositories":{
+				"nodes":[{"name":"api","owner":{"login":"org"},"visibility":"PRIVATE"},{"name":"docs","owner":{"login":"org"}}],
+				"pageInfo":{"hasNextPage":false,"endCursor":""}}}}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"organization":{"repositories":{
+				"nodes":[{"name":"docs"},{"name":"api","githubActionsConfig":{"oid":"abc"}}]}}}}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithGraphQL(server.Client(), server.URL, server.URL+"/graphql")
+	var repos []Repository
+	err := client.FetchRepositories(context.Background(), "org", func(page []Repository) error {
+		repos = append(repos, page...)
+		return nil
+	})
+	if err != nil || len(repos) != 2 {
+		t.Fatalf("FetchRepositories() = %d repos, %v; want the split page", len(repos), err)
+	}
+	if repos[0].Visibility != "PRIVATE" || !slices.Equal(repos[0].CISystems(), []string{CISystemGitHubActions}) {
+		t.Errorf("api = %+v, want core fields merged with its details", repos[0])
+	}
+	if repos[1].CISystems() != nil {
+		t.Errorf("docs CISystems() = %v, want none", repos[1].CISystems())
+	}
+	if got := client.Stats().GraphQLSplitPages; got != 1 {
+		t.Errorf("GraphQLSplitPages = %d, want 1", got)
+	}
+}
+
+func TestFetchRepositories_PermanentFailureNotRetried(t *testing.T) {
+	fastRetries(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewClientWithGraphQL(server.Client(), server.URL, server.URL+"/graphql")
+	err := client.FetchRepositories(context.Background(), "org", func([]Repository) error { return nil })
+	if err == nil || requests != 1 {
+		t.Errorf("FetchRepositories() = %v after %d requests, want one failed request", err, requests)
+	}
+}
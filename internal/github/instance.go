@@ -0,0 +1,166 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Endpoint features that not every GitHub instance serves. GitHub.com serves
+// all of them; GitHub Enterprise Server gained each in the release listed in
+// featureMinVersion.
+const (
+	FeatureCodeScanningDefaultSetup = "code_scanning_default_setup"
+	FeatureFineGrainedPATs          = "fine_grained_pats"
+	FeatureRulesets                 = "rulesets"
+)
+
+// featureMinVersion is the first GitHub Enterprise Server release serving
+// each feature's endpoints: the capability matrix deciding which metrics are
+// attempted. Endpoints not listed here are called on every release, and how
+// their surfaces read a 404 from an older one is documented in
+// docs/configuration.md (Instance Feature Detection).
+var featureMinVersion = map[string]string{
+	FeatureCodeScanningDefaultSetup: "3.9",
+	FeatureFineGrainedPATs:          "3.10",
+	FeatureRulesets:                 "3.11",
+}
+
+// enterpriseVersionHeader carries the release on every GitHub Enterprise
+// Server response; GitHub.com never sends it.
+const enterpriseVersionHeader = "X-GitHub-Enterprise-Version"
+
+// maxMetaBytes bounds the /meta body read for the installed version;
+// GitHub.com's, listing its IP ranges, is larger and never has it.
+const maxMetaBytes = 1 << 20
+
+// Instance describes the GitHub deployment a client talks to.
+type Instance struct {
+	// Enterprise is set for GitHub Enterprise Server.
+	Enterprise bool
+	// Version is the Enterprise Server release, e.g. "3.9.2"; empty on
+	// GitHub.com.
+	Version string
+}
+
+// String names the instance for diagnostics.
+func (i Instance) String() string {
+	if !i.Enterprise {
+		return "GitHub.com"
+	}
+	if i.Version == "" {
+		return "GitHub Enterprise Server"
+	}
+	return "GitHub Enterprise Server " + i.Version
+}
+
+// Release is the Enterprise Server feature release, e.g. "3.9" for 3.9.2;
+// empty on GitHub.com or when the version is unknown.
+func (i Instance) Release() string {
+	major, rest, _ := strings.Cut(i.Version, ".")
+	minor, _, _ := strings.Cut(rest, ".")
+	if !i.Enterprise || minor == "" {
+		return i.Version
+	}
+	return major + "." + minor
+}
+
+// Capabilities reports Supports for every version-gated feature.
+func (i Instance) Capabilities() map[string]bool {
+	capabilities := make(map[string]bool, len(featureMinVersion))
+	for feature := range featureMinVersion {
+		capabilities[feature] = i.Supports(feature)
+	}
+	return capabilities
+}
+
+// Supports reports whether the instance serves a feature's endpoints. An
+// Enterprise Server of unknown release, and any feature without a minimum
+// release, are assumed to be supported.
+func (i Instance) Supports(feature string) bool {
+	minVersion, ok := featureMinVersion[feature]
+	if !i.Enterprise || i.Version == "" || !ok {
+		return true
+	}
+	return versionAtLeast(i.Version, minVersion)
+}
+
+// DetectInstance reads /meta to identify the instance, and
+// remembers the result so that endpoints the instance doesn't serve are
+// skipped rather than read as disabled. With a Cache (see SetCache), an
+// instance already detected at the same base URL is reused. It must be called
+// before concurrent use of the client.
+func (c *Client) DetectInstance(ctx context.Context) (Instance, error) {
+	if c.cache != nil {
+		if instance, ok := c.cache.instance(c.baseURL); ok {
+			c.instance = instance
+			return instance, nil
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/meta", nil)
+	if err != nil {
+		return Instance{}, err
+	}
+	setAPIHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return Instance{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return Instance{}, classifyStatus(resp, "/meta")
+	}
+	instance := Instance{}
+	if v := resp.Header.Get(enterpriseVersionHeader); v != "" {
+		// The header reads "enterprise-server@3.9.2" on current releases and
+		// "3.9.2" on older ones.
+		_, version, _ := strings.Cut(v, "@")
+		if version == "" {
+			version = v
+		}
+		instance = Instance{Enterprise: true, Version: version}
+	} else {
+		// A proxy can strip the header; Enterprise Server also reports its
+		// release in the body, which GitHub.com doesn't.
+		var meta struct {
+			InstalledVersion string `json:"installed_version"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, maxMetaBytes)).Decode(&meta) == nil && meta.InstalledVersion != "" {
+			instance = Instance{Enterprise: true, Version: meta.InstalledVersion}
+		}
+	}
+	c.instance = instance
+	if c.cache != nil {
+		c.cache.setInstance(c.baseURL, instance)
+	}
+	return instance, nil
+}
+
+// versionAtLeast compares dotted release numbers numerically. Unparseable
+// components compare as zero.
+func versionAtLeast(version, minVersion string) bool {
+	have, want := strings.Split(version, "."), strings.Split(minVersion, ".")
+	for i, w := range want {
+		wn, _ := strconv.Atoi(w)
+		hn := 0
+		if i < len(have) {
+			hn, _ = strconv.Atoi(have[i])
+		}
+		if hn != wn {
+			return hn > wn
+		}
+	}
+	return true
+}
+
+// errUnsupported reports a feature whose endpoints the instance doesn't
+// serve.
+func (c *Client) errUnsupported(feature string) error {
+	return fmt.Errorf("%w: %s on %s", ErrUnsupported, feature, c.instance)
+}
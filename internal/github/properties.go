@@ -0,0 +1,51 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// PropertyValuesFetchCap bounds how many repositories'
+// ListRepositoryPropertyValues reads.
+const PropertyValuesFetchCap = 50000
+
+// ListRepositoryPropertyValues returns the custom property values of the
+// org's repositories, keyed by repository name (without the owner) and then
+// property name. A multi-select property holds each selected value; unset
+// properties are absent. truncated reports that the org has more
+// repositories than PropertyValuesFetchCap. Requires
+// organization_custom_properties:read.
+func (c *Client) ListRepositoryPropertyValues(ctx context.Context, org string) (map[string]map[string][]string, bool, error) {
+	raw, truncated, err := c.getPagedRaw(ctx, fmt.Sprintf("/orgs/%s/properties/values?per_page=100", org), PropertyValuesFetchCap)
+	if err != nil {
+		return nil, false, err
+	}
+	values := make(map[string]map[string][]string, len(raw))
+	for _, r := range raw {
+		var row struct {
+			RepositoryName string `json:"repository_name"`
+			Properties     []struct {
+				PropertyName string          `json:"property_name"`
+				Value        json.RawMessage `json:"value"`
+			} `json:"properties"`
+		}
+		if json.Unmarshal(r, &row) != nil || row.RepositoryName == "" {
+			continue
+		}
+		props := make(map[string][]string, len(row.Properties))
+		for _, p := range row.Properties {
+			// A value is a string, a list of strings (multi-select), or null.
+			var one string
+			var many []string
+			switch {
+			case json.Unmarshal(p.Value, &one) == nil && one != "":
+				props[p.PropertyName] = []string{one}
+			case json.Unmarshal(p.Value, &many) == nil && len(many) > 0:
+				props[p.PropertyName] = many
+			}
+		}
+		values[row.RepositoryName] = props
+	}
+	return values, truncated, nil
+}
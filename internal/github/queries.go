@@ -6,33 +6,87 @@ import "github.com/shurcooL/githubv4"
 // RepositoriesQuery is the GraphQL query for fetching organization repositories
 // with branch protection and security feature information.
 type RepositoriesQuery struct {
+	Organization struct {
+		Repositories RepositoryConnection `graphql:"repositories(first: 100, after: $cursor)"`
+	} `graphql:"organization(login: $org)"`
+	RateLimit RateLimit
+}
+
+// RepositoryConnection is one page of repositories.
+type RepositoryConnection struct {
+	Nodes    []Repository
+	PageInfo struct {
+		HasNextPage bool
+		EndCursor   githubv4.String
+	}
+}
+
+// RepositoryDetailsQuery fetches just the details (see RepositoryDetails) of
+// a page of organization repositories. It is the second half of a page split
+// after the full RepositoriesQuery kept failing.
+type RepositoryDetailsQuery struct {
 	Organization struct {
 		Repositories struct {
-			Nodes    []Repository
-			PageInfo struct {
-				HasNextPage bool
-				EndCursor   githubv4.String
-			}
+			Nodes []RepositoryDetailsNode
 		} `graphql:"repositories(first: 100, after: $cursor)"`
 	} `graphql:"organization(login: $org)"`
+	RateLimit RateLimit
+}
+
+// RepositoryDetailsNode is one repository of a RepositoryDetailsQuery page.
+type RepositoryDetailsNode struct {
+	Name string
+	RepositoryDetails
+}
+
+// UserRepositoriesQuery is the GraphQL query for fetching the repositories a
+// user account owns (not those it merely collaborates on or has forked into
+// an org), for personal-account collection.
+type UserRepositoriesQuery struct {
+	User struct {
+		Repositories RepositoryConnection `graphql:"repositories(first: 100, after: $cursor, ownerAffiliations: [OWNER])"`
+	} `graphql:"user(login: $login)"`
+	RateLimit RateLimit
+}
+
+// UserRepositoryDetailsQuery is RepositoryDetailsQuery for a user account.
+type UserRepositoryDetailsQuery struct {
+	User struct {
+		Repositories struct {
+			Nodes []RepositoryDetailsNode
+		} `graphql:"repositories(first: 100, after: $cursor, ownerAffiliations: [OWNER])"`
+	} `graphql:"user(login: $login)"`
+	RateLimit RateLimit
+}
+
+// RepositoryQuery is the GraphQL query for fetching a single repository by
+// owner and name, used when collection is narrowed to an explicit list.
+type RepositoryQuery struct {
+	Repository *Repository `graphql:"repository(owner: $owner, name: $name)"`
+	RateLimit  RateLimit
 }
 
 // Repository represents a GitHub repository with security-relevant fields.
 // The inventory fields (timestamps, language, size, etc.) are used only by the
 // audit/internal Repositories surface; trust collection ignores them.
 type Repository struct {
-	Name  string
-	Owner struct {
+	DatabaseID int64 `graphql:"databaseId"`
+	Name       string
+	Owner      struct {
 		Login string
 	}
 	IsArchived       bool
 	IsTemplate       bool
+	IsFork           bool
+	IsMirror         bool
+	ForkingAllowed   bool
 	Visibility       string // PUBLIC, PRIVATE, INTERNAL
 	DefaultBranchRef struct {
 		Name                 string
 		BranchProtectionRule *BranchProtectionRule
 	}
 	HasVulnerabilityAlertsEnabled bool
+	HasWikiEnabled                bool
 
 	// Inventory metadata (audit / internal).
 	CreatedAt       githubv4.DateTime
@@ -47,6 +101,16 @@ type Repository struct {
 	LicenseInfo *struct {
 		SpdxID string `graphql:"spdxId"`
 	}
+
+	// Details are only queried when $details is true, so a page whose full
+	// query keeps failing can be fetched in two halves.
+	RepositoryDetails `graphql:"... @include(if: $details)"`
+}
+
+// RepositoryDetails are the costliest repository fields to resolve: topics
+// and the two git object lookups the CI systems and classification marker
+// files are detected from.
+type RepositoryDetails struct {
 	RepositoryTopics struct {
 		Nodes []struct {
 			Topic struct {
@@ -54,6 +118,109 @@ type Repository struct {
 			}
 		}
 	} `graphql:"repositoryTopics(first: 20)"`
+
+	// RootTree is the default branch's top-level tree, nil when the
+	// repository is empty. CI configuration and classification marker files
+	// are looked up by name among its entries (see CISystems and HasFile),
+	// so one lookup covers them all.
+	RootTree *GitTree `graphql:"rootTree: object(expression: \"HEAD:\")"`
+	// GitHubActionsConfig is the workflows directory, nil when absent. It
+	// sits below the root, so RootTree can't show it.
+	GitHubActionsConfig *GitObjectRef `graphql:"githubActionsConfig: object(expression: \"HEAD:.github/workflows\")"`
+}
+
+// GitTree is a git tree object's entries.
+type GitTree struct {
+	TreeEntries `graphql:"... on Tree"`
+}
+
+// TreeEntries lists a tree's entries.
+type TreeEntries struct {
+	Entries []TreeEntry
+}
+
+// TreeEntry is one file or directory in a tree.
+type TreeEntry struct {
+	Name string
+}
+
+// HasFile reports whether the default branch has a top-level file or
+// directory named name.
+func (r Repository) HasFile(name string) bool {
+	if r.RootTree == nil {
+		return false
+	}
+	for _, entry := range r.RootTree.Entries {
+		if entry.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GitObjectRef is a git object (blob or tree) that exists at a path.
+type GitObjectRef struct {
+	Oid githubv4.GitObjectID
+}
+
+// CI systems detected from their configuration files.
+const (
+	CISystemGitHubActions  = "github_actions"
+	CISystemCircleCI       = "circleci"
+	CISystemGitLabCI       = "gitlab_ci"
+	CISystemJenkins        = "jenkins"
+	CISystemTravisCI       = "travis_ci"
+	CISystemAzurePipelines = "azure_pipelines"
+	CISystemBuildkite      = "buildkite"
+)
+
+// CISystems returns the CI systems configured on the repository's default
+// branch, in a fixed order. GitHub Actions counts when .github/workflows
+// exists, whatever it contains.
+func (r Repository) CISystems() []string {
+	var systems []string
+	if r.GitHubActionsConfig != nil {
+		systems = append(systems, CISystemGitHubActions)
+	}
+	for _, ci := range []struct {
+		name, config string
+	}{
+		{CISystemCircleCI, ".circleci"},
+		{CISystemGitLabCI, ".gitlab-ci.yml"},
+		{CISystemJenkins, "Jenkinsfile"},
+		{CISystemTravisCI, ".travis.yml"},
+		{CISystemAzurePipelines, "azure-pipelines.yml"},
+		{CISystemBuildkite, ".buildkite"},
+	} {
+		if r.HasFile(ci.config) {
+			systems = append(systems, ci.name)
+		}
+	}
+	return systems
+}
+
+// OrgProjectsQuery is the GraphQL query for listing an organization's
+// projects (Projects V2; classic projects have no bulk visibility field).
+type OrgProjectsQuery struct {
+	Organization struct {
+		ProjectsV2 struct {
+			Nodes    []Project
+			PageInfo struct {
+				HasNextPage bool
+				EndCursor   githubv4.String
+			}
+		} `graphql:"projectsV2(first: 100, after: $cursor)"`
+	} `graphql:"organization(login: $org)"`
+	RateLimit RateLimit
+}
+
+// Project is an organization project. Public projects are readable by
+// anyone, whatever the visibility of the repositories they track.
+type Project struct {
+	Number int
+	Title  string
+	Public bool
+	Closed bool
 }
 
 // MembersWithRoleQuery is the GraphQL query for fetching member display
@@ -71,6 +238,15 @@ type MembersWithRoleQuery struct {
 			}
 		} `graphql:"membersWithRole(first: 100, after: $cursor)"`
 	} `graphql:"organization(login: $org)"`
+	RateLimit RateLimit
+}
+
+// RateLimit is the GraphQL rateLimit object requested alongside every query:
+// the query's point cost and the token's remaining budget.
+type RateLimit struct {
+	Cost      int
+	Remaining int
+	ResetAt   githubv4.DateTime
 }
 
 // BranchProtectionRule represents branch protection settings.
@@ -80,10 +256,12 @@ type BranchProtectionRule struct {
 	DismissesStaleReviews          bool
 	RequiresCodeOwnerReviews       bool
 	RequiresStatusChecks           bool
+	RequiredStatusCheckContexts    []string
 	RequiresCommitSignatures       bool
 	IsAdminEnforced                bool
 	RequiresLinearHistory          bool
 	AllowsForcePushes              bool
 	AllowsDeletions                bool
 	RequiresConversationResolution bool
+	RequireLastPushApproval        bool
 }
@@ -0,0 +1,279 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when GitHub refused a request for a rate limit
+// the client couldn't wait out (see RateLimitError).
+var ErrRateLimited = errors.New("rate limited")
+
+// SecondaryRateLimitAttempts is how many times a request refused by a
+// secondary rate limit is sent before the refusal is returned.
+const SecondaryRateLimitAttempts = 3
+
+// defaultSecondaryRetryAfter is the pause after a secondary rate limit
+// refusal without a Retry-After header, as GitHub's docs advise. Replaced in
+// tests.
+var defaultSecondaryRetryAfter = time.Minute
+
+// RateLimitError is a request refused for a rate limit. A secondary limit
+// (too many concurrent or too rapid requests) lifts after RetryAfter; the
+// primary limit (the hourly request or point budget) at ResetAt.
+type RateLimitError struct {
+	Secondary  bool
+	RetryAfter time.Duration
+	ResetAt    time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Secondary {
+		return fmt.Sprintf("secondary rate limit exceeded; retry after %s", e.RetryAfter)
+	}
+	if e.ResetAt.IsZero() {
+		return "primary rate limit exhausted"
+	}
+	return "primary rate limit exhausted until " + e.ResetAt.UTC().Format(time.RFC3339)
+}
+
+func (e *RateLimitError) Unwrap() error { return ErrRateLimited }
+
+// Primary rate limit resources (X-RateLimit-Resource) the collector's
+// requests draw on. Each has its own budget; the collection depends on core
+// and graphql, while a search budget running out only fails the searches.
+const (
+	resourceCore       = "core"
+	resourceGraphQL    = "graphql"
+	resourceSearch     = "search"
+	resourceCodeSearch = "code_search"
+)
+
+// rateLimitTransport handles GitHub's rate limit refusals for every request
+// of a client, REST and GraphQL. A secondary limit pauses every request of
+// the client for Retry-After, then resumes, up to SecondaryRateLimitAttempts
+// per request. The primary limit is tracked per resource: it is waited out
+// when it resets within maxWait; otherwise the request fails with a
+// RateLimitError, and so does every later one drawing on the same resource
+// until the reset, without being sent.
+type rateLimitTransport struct {
+	base    http.RoundTripper
+	maxWait time.Duration
+
+	mu              sync.Mutex
+	pausedUntil     time.Time
+	resetWait       map[string]time.Time // resource -> primary reset waited for
+	exhaustedUntil  map[string]time.Time // resource -> primary reset not waited for
+	secondaryPauses int
+}
+
+func newRateLimitTransport(base http.RoundTripper) *rateLimitTransport {
+	return &rateLimitTransport{
+		base:           base,
+		resetWait:      make(map[string]time.Time),
+		exhaustedUntil: make(map[string]time.Time),
+	}
+}
+
+// requestResource returns the primary rate limit resource a request draws
+// on, from its path; GitHub Enterprise Server's /api/v3 prefix is ignored.
+func requestResource(req *http.Request) string {
+	path := strings.TrimPrefix(req.URL.Path, "/api/v3")
+	switch {
+	case strings.HasSuffix(path, "/graphql"):
+		return resourceGraphQL
+	case strings.HasPrefix(path, "/search/code"):
+		return resourceCodeSearch
+	case strings.HasPrefix(path, "/search/"):
+		return resourceSearch
+	}
+	return resourceCore
+}
+
+// RoundTrip sends the request once any pause has passed, retrying it after
+// the rate limit refusals the transport waits out.
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource := requestResource(req)
+	for attempt := 1; ; attempt++ {
+		t.mu.Lock()
+		pausedUntil := later(t.pausedUntil, t.resetWait[resource])
+		exhaustedUntil := t.exhaustedUntil[resource]
+		t.mu.Unlock()
+		if time.Now().Before(exhaustedUntil) {
+			return nil, &RateLimitError{ResetAt: exhaustedUntil}
+		}
+		if err := sleepUntil(req, pausedUntil); err != nil {
+			return nil, err
+		}
+
+		send := req
+		if attempt > 1 {
+			if send = rewound(req); send == nil {
+				return nil, &RateLimitError{Secondary: true}
+			}
+		}
+		resp, err := t.base.RoundTrip(send)
+		if err != nil {
+			return nil, err
+		}
+		limited := classifyRateLimit(resp, resource)
+		if limited == nil {
+			return resp, nil
+		}
+		_ = resp.Body.Close()
+		// The response names the budget it ran out of; the path is a guess.
+		limitedResource := resource
+		if r := resp.Header.Get("X-RateLimit-Resource"); r != "" {
+			limitedResource = r
+		}
+
+		now := time.Now()
+		t.mu.Lock()
+		switch {
+		case limited.Secondary:
+			t.secondaryPauses++
+			t.pausedUntil = later(t.pausedUntil, now.Add(limited.RetryAfter))
+		case !limited.ResetAt.IsZero() && limited.ResetAt.Sub(now) <= t.maxWait:
+			t.resetWait[limitedResource] = later(t.resetWait[limitedResource], limited.ResetAt)
+		default:
+			t.exhaustedUntil[limitedResource] = later(t.exhaustedUntil[limitedResource], limited.ResetAt)
+			t.mu.Unlock()
+			return nil, limited
+		}
+		t.mu.Unlock()
+		if attempt == SecondaryRateLimitAttempts {
+			return nil, limited
+		}
+	}
+}
+
+// stats folds the transport's rate limit handling into a client's stats.
+// Only the core and GraphQL budgets count as exhausted for the client.
+func (t *rateLimitTransport) stats(out *QueryStats) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out.SecondaryRateLimitPauses = t.secondaryPauses
+	out.RateLimitExhaustedUntil = later(t.exhaustedUntil[resourceCore], t.exhaustedUntil[resourceGraphQL])
+}
+
+// classifyRateLimit returns the rate limit a 403 or 429 response refused the
+// request for, or nil when it's a refusal of another kind (e.g. a missing
+// permission). A refusal is secondary when it carries Retry-After or says so,
+// and primary when no requests remain in the budget. GraphQL reports an
+// exhausted budget as a 200 whose errors are RATE_LIMITED instead, which is
+// classified as primary too. The body is restored for the caller.
+func classifyRateLimit(resp *http.Response, resource string) *RateLimitError {
+	if resp.StatusCode == http.StatusOK && resource == resourceGraphQL {
+		return classifyGraphQLRateLimit(resp)
+	}
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+	if after := resp.Header.Get("Retry-After"); after != "" {
+		seconds, err := strconv.Atoi(after)
+		if err != nil || seconds < 0 {
+			return &RateLimitError{Secondary: true, RetryAfter: defaultSecondaryRetryAfter}
+		}
+		return &RateLimitError{Secondary: true, RetryAfter: time.Duration(seconds) * time.Second}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return primaryRateLimit(resp)
+	}
+	if strings.Contains(strings.ToLower(string(peekBody(resp))), "secondary rate limit") {
+		return &RateLimitError{Secondary: true, RetryAfter: defaultSecondaryRetryAfter}
+	}
+	return nil
+}
+
+// classifyGraphQLRateLimit returns the primary rate limit a GraphQL 200
+// response refused the query for, or nil when it answered it. A 200 with
+// X-RateLimit-Remaining 0 and data is the query that spent the last points,
+// so only a RATE_LIMITED error counts as a refusal.
+func classifyGraphQLRateLimit(resp *http.Response) *RateLimitError {
+	var body struct {
+		Errors []struct {
+			Type string `json:"type"`
+		} `json:"errors"`
+	}
+	// A refusal is a short errors-only body; anything longer carries data.
+	if json.Unmarshal(peekBody(resp), &body) != nil {
+		return nil
+	}
+	for _, e := range body.Errors {
+		if e.Type == "RATE_LIMITED" {
+			return primaryRateLimit(resp)
+		}
+	}
+	return nil
+}
+
+// primaryRateLimit returns the primary rate limit refusal of resp, resetting
+// at its X-RateLimit-Reset when it has one.
+func primaryRateLimit(resp *http.Response) *RateLimitError {
+	out := &RateLimitError{}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		out.ResetAt = time.Unix(reset, 0)
+	}
+	return out
+}
+
+// peekBody returns up to the first 4 KiB of resp's body, restoring it for
+// the caller.
+func peekBody(resp *http.Response) []byte {
+	peek, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(peek), resp.Body), resp.Body}
+	return peek
+}
+
+// rewound returns a copy of req to send again, or nil when its body can't
+// be replayed.
+func rewound(req *http.Request) *http.Request {
+	out := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil
+		}
+		out.Body = body
+	}
+	return out
+}
+
+// sleepUntil waits until t, or returns the request context's error if it
+// ends first.
+func sleepUntil(req *http.Request, t time.Time) error {
+	wait := time.Until(t)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// later returns the later of a and b.
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
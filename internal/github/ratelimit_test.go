@@ -0,0 +1,218 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimit_SecondaryPausesAndResumes(t *testing.T) {
+	defer func(d time.Duration) { defaultSecondaryRetryAfter = d }(defaultSecondaryRetryAfter)
+	defaultSecondaryRetryAfter = 10 * time.Millisecond
+
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, `{"message":"You have exceeded a secondary rate limit."}`)
+		default:
+			_, _ = io.WriteString(w, `{"login":"test-org"}`)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	var org struct{ Login string }
+	if err := client.getJSON(context.Background(), "/orgs/test-org", &org); err != nil {
+		t.Fatalf("getJSON() error: %v", err)
+	}
+	if org.Login != "test-org" || calls.Load() != 3 {
+		t.Errorf("got %+v after %d calls, want test-org after 3", org, calls.Load())
+	}
+	if got := client.Stats().SecondaryRateLimitPauses; got != 2 {
+		t.Errorf("SecondaryRateLimitPauses = %d, want 2", got)
+	}
+}
+
+func TestRateLimit_SecondaryGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	err := client.getJSON(context.Background(), "/orgs/test-org", &struct{}{})
+	var limited *RateLimitError
+	if !errors.As(err, &limited) || !limited.Secondary {
+		t.Fatalf("getJSON() error = %v, want a secondary RateLimitError", err)
+	}
+	if errors.Is(err, ErrPermissionDenied) {
+		t.Error("a rate limit refusal read as a permission denial")
+	}
+	if calls.Load() != SecondaryRateLimitAttempts {
+		t.Errorf("sent %d times, want %d", calls.Load(), SecondaryRateLimitAttempts)
+	}
+}
+
+func TestRateLimit_PrimaryExhausted(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	client.SetMaxRateLimitWait(time.Minute)
+	for range 2 {
+		err := client.getJSON(context.Background(), "/orgs/test-org", &struct{}{})
+		var limited *RateLimitError
+		if !errors.As(err, &limited) || limited.Secondary || !limited.ResetAt.Equal(reset) {
+			t.Fatalf("getJSON() error = %v, want a primary RateLimitError resetting at %s", err, reset)
+		}
+	}
+	// The reset is beyond the wait, so the second request isn't sent.
+	if calls.Load() != 1 {
+		t.Errorf("sent %d requests, want 1", calls.Load())
+	}
+	if got := client.Stats().RateLimitExhaustedUntil; !got.Equal(reset) {
+		t.Errorf("RateLimitExhaustedUntil = %s, want %s", got, reset)
+	}
+}
+
+func TestRateLimit_PrimaryWaitedOut(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	client.SetMaxRateLimitWait(time.Minute)
+	if err := client.getJSON(context.Background(), "/orgs/test-org", &struct{}{}); err != nil {
+		t.Fatalf("getJSON() error: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("sent %d requests, want 2", calls.Load())
+	}
+	if got := client.Stats().RateLimitExhaustedUntil; !got.IsZero() {
+		t.Errorf("RateLimitExhaustedUntil = %s, want zero", got)
+	}
+}
+
+func TestRateLimit_GraphQLRateLimited(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		// GraphQL refuses an exhausted budget with a 200, not a 403.
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		_, _ = io.WriteString(w, `{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`)
+	}))
+	defer server.Close()
+
+	client := NewClientWithGraphQL(server.Client(), server.URL, server.URL+"/graphql")
+	client.SetMaxRateLimitWait(time.Minute)
+	for range 2 {
+		err := client.FetchRepositories(context.Background(), "test-org", func([]Repository) error { return nil })
+		var limited *RateLimitError
+		if !errors.As(err, &limited) || limited.Secondary || !limited.ResetAt.Equal(reset) {
+			t.Fatalf("FetchRepositories() error = %v, want a primary RateLimitError resetting at %s", err, reset)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("sent %d queries, want 1", calls.Load())
+	}
+	if got := client.Stats().RateLimitExhaustedUntil; !got.Equal(reset) {
+		t.Errorf("RateLimitExhaustedUntil = %s, want %s", got, reset)
+	}
+}
+
+func TestRateLimit_ResourcesTrackedApart(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	var searches, cores atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search/issues" {
+			searches.Add(1)
+			w.Header().Set("X-RateLimit-Resource", "search")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		cores.Add(1)
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	client.SetMaxRateLimitWait(time.Minute)
+	for range 2 {
+		var limited *RateLimitError
+		if err := client.getJSON(context.Background(), "/search/issues?q=x", &struct{}{}); !errors.As(err, &limited) {
+			t.Fatalf("search error = %v, want a RateLimitError", err)
+		}
+	}
+	// An exhausted search budget stops searches, not the core requests.
+	if err := client.getJSON(context.Background(), "/orgs/test-org", &struct{}{}); err != nil {
+		t.Fatalf("core request error: %v", err)
+	}
+	if searches.Load() != 1 || cores.Load() != 1 {
+		t.Errorf("sent %d searches and %d core requests, want 1 each", searches.Load(), cores.Load())
+	}
+	if got := client.Stats().RateLimitExhaustedUntil; !got.IsZero() {
+		t.Errorf("RateLimitExhaustedUntil = %s, want zero for a search budget", got)
+	}
+}
+
+func TestRequestResource(t *testing.T) {
+	for path, want := range map[string]string{
+		"/orgs/acme":                   resourceCore,
+		"/graphql":                     resourceGraphQL,
+		"/api/graphql":                 resourceGraphQL,
+		"/search/issues":               resourceSearch,
+		"/api/v3/search/code":          resourceCodeSearch,
+		"/api/v3/repos/acme/api/pulls": resourceCore,
+	} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if got := requestResource(req); got != want {
+			t.Errorf("requestResource(%s) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestRateLimit_PermissionDeniedUntouched(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, `{"message":"Resource not accessible by integration"}`)
+	}))
+	defer server.Close()
+
+	client := NewClientWithHTTP(server.Client(), server.URL)
+	if err := client.getJSON(context.Background(), "/orgs/test-org/hooks", &struct{}{}); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("getJSON() error = %v, want ErrPermissionDenied", err)
+	}
+}
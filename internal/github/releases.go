@@ -0,0 +1,61 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// Release is a published repository release. Drafts are never returned.
+// Immutable is nil where the API doesn't report immutability (older GitHub
+// Enterprise Server versions).
+type Release struct {
+	TagName     string         `json:"tag_name"`
+	Prerelease  bool           `json:"prerelease"`
+	Immutable   *bool          `json:"immutable"`
+	PublishedAt string         `json:"published_at"`
+	Assets      []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is a file attached to a release. Digest ("sha256:...") is
+// empty for assets uploaded before GitHub began recording digests.
+type ReleaseAsset struct {
+	Name   string `json:"name"`
+	Digest string `json:"digest"`
+}
+
+// ListRecentReleases returns up to limit of a repository's most recent
+// published releases, newest first. Requires contents:read.
+func (c *Client) ListRecentReleases(ctx context.Context, owner, repo string, limit int) ([]Release, error) {
+	var releases []struct {
+		Release
+		Draft bool `json:"draft"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/releases?per_page=%d", owner, repo, limit), &releases); err != nil {
+		return nil, err
+	}
+	out := make([]Release, 0, len(releases))
+	for _, r := range releases {
+		if !r.Draft {
+			out = append(out, r.Release)
+		}
+	}
+	return out, nil
+}
+
+// HasAttestations reports whether any artifact attestation exists for the
+// subject digest ("sha256:...") in the repository. Requires attestations:read.
+func (c *Client) HasAttestations(ctx context.Context, owner, repo, digest string) (bool, error) {
+	var body struct {
+		Attestations []struct{} `json:"attestations"`
+	}
+	err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/attestations/%s?per_page=1", owner, repo, url.PathEscape(digest)), &body)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(body.Attestations) > 0, nil
+}
@@ -0,0 +1,197 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Ruleset enforcement modes.
+const (
+	RulesetEnforcementActive   = "active"
+	RulesetEnforcementEvaluate = "evaluate"
+	RulesetEnforcementDisabled = "disabled"
+)
+
+// Ruleset rule types the collector interprets.
+const (
+	RuleTypeWorkflows = "workflows"
+	// Branch rules equivalent to classic branch protection settings.
+	RuleTypePullRequest           = "pull_request"
+	RuleTypeRequiredStatusChecks  = "required_status_checks"
+	RuleTypeRequiredSignatures    = "required_signatures"
+	RuleTypeRequiredLinearHistory = "required_linear_history"
+	RuleTypeNonFastForward        = "non_fast_forward"
+	RuleTypeDeletion              = "deletion"
+	// Repository-target rules restricting who may delete a repository or
+	// change its visibility (bypass actors excepted).
+	RuleTypeRepositoryDelete     = "repository_delete"
+	RuleTypeRepositoryVisibility = "repository_visibility"
+)
+
+// RulesetTargetRepository is the target of rulesets governing repositories
+// themselves (deletion, visibility, names) rather than their refs.
+const RulesetTargetRepository = "repository"
+
+// Ruleset is an organization repository ruleset with its conditions and
+// rules. Only the parts the collector evaluates are decoded.
+type Ruleset struct {
+	ID          int64             `json:"id"`
+	Name        string            `json:"name"`
+	Target      string            `json:"target"` // branch, tag, push, or repository
+	Enforcement string            `json:"enforcement"`
+	Conditions  RulesetConditions `json:"conditions"`
+	Rules       []RulesetRule     `json:"rules"`
+	// BypassActors may skip the ruleset; only their number is kept.
+	BypassActors []struct {
+		ActorType string `json:"actor_type"`
+	} `json:"bypass_actors"`
+}
+
+// RulesetConditions selects the repositories and refs a ruleset applies to.
+// Absent conditions are nil.
+type RulesetConditions struct {
+	RefName *struct {
+		Include []string `json:"include"`
+		Exclude []string `json:"exclude"`
+	} `json:"ref_name"`
+	RepositoryName *struct {
+		Include []string `json:"include"`
+		Exclude []string `json:"exclude"`
+	} `json:"repository_name"`
+	RepositoryID *struct {
+		RepositoryIDs []int64 `json:"repository_ids"`
+	} `json:"repository_id"`
+	// RepositoryProperty targets repositories by custom property, which the
+	// collector doesn't read; its presence makes coverage indeterminate.
+	RepositoryProperty json.RawMessage `json:"repository_property"`
+}
+
+// RulesetRule is one rule of a ruleset. Workflows is set for workflows
+// rules, PullRequest for pull_request rules, and StatusChecks (the required
+// check contexts) for required_status_checks rules.
+type RulesetRule struct {
+	Type         string                  `json:"type"`
+	Workflows    []RulesetWorkflow       `json:"-"`
+	PullRequest  *PullRequestRuleOptions `json:"-"`
+	StatusChecks []string                `json:"-"`
+}
+
+// PullRequestRuleOptions are the parameters of a pull_request rule.
+type PullRequestRuleOptions struct {
+	RequiredApprovingReviewCount   int  `json:"required_approving_review_count"`
+	DismissStaleReviewsOnPush      bool `json:"dismiss_stale_reviews_on_push"`
+	RequireCodeOwnerReview         bool `json:"require_code_owner_review"`
+	RequireLastPushApproval        bool `json:"require_last_push_approval"`
+	RequiredReviewThreadResolution bool `json:"required_review_thread_resolution"`
+}
+
+// RulesetWorkflow is a workflow a workflows rule requires to pass.
+type RulesetWorkflow struct {
+	Path         string `json:"path"`
+	RepositoryID int64  `json:"repository_id"`
+	Ref          string `json:"ref"`
+}
+
+// UnmarshalJSON decodes a rule, picking the parameters the collector
+// interprets out of workflows, pull_request, and required_status_checks
+// rules.
+func (r *RulesetRule) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Type       string          `json:"type"`
+		Parameters json.RawMessage `json:"parameters"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*r = RulesetRule{Type: raw.Type}
+	if len(raw.Parameters) == 0 {
+		return nil
+	}
+	switch raw.Type {
+	case RuleTypeWorkflows:
+		var params struct {
+			Workflows []RulesetWorkflow `json:"workflows"`
+		}
+		if err := json.Unmarshal(raw.Parameters, &params); err != nil {
+			return err
+		}
+		r.Workflows = params.Workflows
+	case RuleTypePullRequest:
+		r.PullRequest = &PullRequestRuleOptions{}
+		return json.Unmarshal(raw.Parameters, r.PullRequest)
+	case RuleTypeRequiredStatusChecks:
+		var params struct {
+			RequiredStatusChecks []struct {
+				Context string `json:"context"`
+			} `json:"required_status_checks"`
+		}
+		if err := json.Unmarshal(raw.Parameters, &params); err != nil {
+			return err
+		}
+		for _, check := range params.RequiredStatusChecks {
+			r.StatusChecks = append(r.StatusChecks, check.Context)
+		}
+	}
+	return nil
+}
+
+// RulesetFetchCap bounds how many org rulesets ListOrgRulesets reads.
+const RulesetFetchCap = 500
+
+// ListOrgRulesets returns the org's rulesets with their conditions and rules
+// (the list endpoint omits both, so each is fetched individually). truncated
+// reports that the org has more than RulesetFetchCap. Requires
+// organization_administration:read.
+func (c *Client) ListOrgRulesets(ctx context.Context, org string) ([]Ruleset, bool, error) {
+	if !c.instance.Supports(FeatureRulesets) {
+		return nil, false, c.errUnsupported(FeatureRulesets)
+	}
+	raw, truncated, err := c.getPagedRaw(ctx, fmt.Sprintf("/orgs/%s/rulesets?per_page=100", org), RulesetFetchCap)
+	if err != nil {
+		return nil, false, err
+	}
+	rulesets := make([]Ruleset, 0, len(raw))
+	for _, r := range raw {
+		var summary struct {
+			ID int64 `json:"id"`
+		}
+		if json.Unmarshal(r, &summary) != nil || summary.ID == 0 {
+			continue
+		}
+		var rs Ruleset
+		if err := c.getJSON(ctx, fmt.Sprintf("/orgs/%s/rulesets/%d", org, summary.ID), &rs); err != nil {
+			return nil, false, err
+		}
+		rulesets = append(rulesets, rs)
+	}
+	return rulesets, truncated, nil
+}
+
+// workflowFileMaxBytes caps the workflow file size GetWorkflowFile reads.
+const workflowFileMaxBytes = 1 << 20
+
+// GetWorkflowFile fetches a workflow file a ruleset references, by the ID of
+// the repository holding it. ref is optional. Requires contents:read on that
+// repository; an absent file is ErrNotFound.
+func (c *Client) GetWorkflowFile(ctx context.Context, repositoryID int64, path, ref string) ([]byte, error) {
+	p := fmt.Sprintf("/repositories/%d/contents/%s", repositoryID, path)
+	if ref != "" {
+		p += "?ref=" + url.QueryEscape(ref)
+	}
+	var body struct {
+		Encoding string `json:"encoding"`
+		Content  string `json:"content"`
+		Size     int    `json:"size"`
+	}
+	if err := c.getJSON(ctx, p, &body); err != nil {
+		return nil, err
+	}
+	if body.Size > workflowFileMaxBytes || body.Encoding != "base64" {
+		return nil, fmt.Errorf("%w: %s (unreadable content)", ErrNotFound, p)
+	}
+	return base64.StdEncoding.DecodeString(strings.ReplaceAll(body.Content, "\n", ""))
+}
@@ -0,0 +1,114 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// CodeSearchInterval spaces a client's code search requests: GitHub allows
+// an authenticated caller 10 a minute, shared with the caller's other
+// integrations, so the collector never bursts.
+const CodeSearchInterval = 6 * time.Second
+
+// CodeSearchMaxResults is the most results code search returns for one
+// query, whatever its total count.
+const CodeSearchMaxResults = 1000
+
+// ErrSearchDeadline is returned by SearchCode when its paced wait for the
+// first page would run past the context's deadline.
+var ErrSearchDeadline = errors.New("code search pacing would pass the deadline")
+
+// CodeSearchResult counts one code search query's matches. PerRepo counts
+// the returned results by repository ("owner/name"); when Incomplete, GitHub
+// timed out or the query matched more than CodeSearchMaxResults, so PerRepo
+// undercounts TotalCount.
+type CodeSearchResult struct {
+	TotalCount int
+	Incomplete bool
+	PerRepo    map[string]int
+}
+
+// SearchCode runs a code search query and counts its results per
+// repository, paging up to CodeSearchMaxResults at most one request per
+// CodeSearchInterval. Paging stops early, leaving the result Incomplete, when
+// the next page's wait would pass ctx's deadline. Only repository names are
+// read from the results, never paths or file contents. Private repositories
+// need contents:read.
+func (c *Client) SearchCode(ctx context.Context, query string) (*CodeSearchResult, error) {
+	out := &CodeSearchResult{PerRepo: map[string]int{}}
+	fetched := 0
+	for page := 1; fetched < CodeSearchMaxResults; page++ {
+		if err := c.searchWait(ctx); err != nil {
+			if errors.Is(err, ErrSearchDeadline) && page > 1 {
+				break
+			}
+			return nil, err
+		}
+		var body struct {
+			TotalCount        int  `json:"total_count"`
+			IncompleteResults bool `json:"incomplete_results"`
+			Items             []struct {
+				Repository struct {
+					FullName string `json:"full_name"`
+				} `json:"repository"`
+			} `json:"items"`
+		}
+		path := fmt.Sprintf("/search/code?q=%s&per_page=100&page=%d", url.QueryEscape(query), page)
+		if err := c.getJSON(ctx, path, &body); err != nil {
+			return nil, err
+		}
+		out.TotalCount = body.TotalCount
+		out.Incomplete = out.Incomplete || body.IncompleteResults
+		for _, item := range body.Items {
+			out.PerRepo[item.Repository.FullName]++
+		}
+		fetched += len(body.Items)
+		if len(body.Items) < 100 || fetched >= body.TotalCount {
+			break
+		}
+	}
+	if fetched < out.TotalCount {
+		out.Incomplete = true
+	}
+	return out, nil
+}
+
+// searchWait waits for the client's next code search slot, failing with
+// ErrSearchDeadline without waiting when that slot falls after ctx's
+// deadline.
+func (c *Client) searchWait(ctx context.Context) error {
+	r := c.searchLimit().Reserve()
+	delay := r.Delay()
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		r.Cancel()
+		return ErrSearchDeadline
+	}
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return ctx.Err()
+	}
+}
+
+// searchLimit returns the client's code search limiter, created on first
+// use.
+func (c *Client) searchLimit() *rate.Limiter {
+	c.searchOnce.Do(func() {
+		if c.searchLimiter == nil {
+			c.searchLimiter = rate.NewLimiter(rate.Every(CodeSearchInterval), 1)
+		}
+	})
+	return c.searchLimiter
+}
@@ -1,6 +1,7 @@
 package github
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/base64"
@@ -27,6 +28,15 @@ var ErrNotFound = errors.New("not found")
 // degrade to a diagnostic rather than failing the run.
 var ErrFeatureUnavailable = errors.New("feature unavailable")
 
+// ErrUnsupported is returned, without a request, for an endpoint the
+// instance doesn't serve (see Instance.Supports), so callers can tell it
+// apart from a disabled feature.
+var ErrUnsupported = errors.New("unsupported on this instance")
+
+// ErrRepositoryBlocked is returned when GitHub blocks access to a repository
+// (451 Unavailable For Legal Reasons, e.g. a DMCA takedown).
+var ErrRepositoryBlocked = errors.New("repository blocked")
+
 // featureDisabledMarkers are substrings GitHub uses in a 403 body when a repo
 // feature isn't enabled (e.g. "Advanced Security must be enabled...",
 // "Dependabot alerts are disabled..."), as opposed to the App lacking the
@@ -51,8 +61,9 @@ func is403FeatureDisabled(body string) bool {
 }
 
 // classifyStatus maps a non-200 REST response to a sentinel-wrapped error:
-// 404→ErrNotFound; 403→ErrFeatureUnavailable when the body says the feature is
-// off, otherwise ErrPermissionDenied; anything else→a generic error. Callers
+// 404→ErrNotFound; 451→ErrRepositoryBlocked; 403→ErrFeatureUnavailable when
+// the body says the feature is off, otherwise ErrPermissionDenied; anything
+// else→a generic error. Callers
 // that treat 404 as "empty/feature off" branch on errors.Is(ErrNotFound).
 func classifyStatus(resp *http.Response, path string) error {
 	switch resp.StatusCode {
@@ -64,6 +75,8 @@ func classifyStatus(resp *http.Response, path string) error {
 		return fmt.Errorf("%w: %s (status 403)", ErrPermissionDenied, path)
 	case http.StatusNotFound:
 		return fmt.Errorf("%w: %s (status 404)", ErrNotFound, path)
+	case http.StatusUnavailableForLegalReasons:
+		return fmt.Errorf("%w: %s (status 451)", ErrRepositoryBlocked, path)
 	default:
 		return fmt.Errorf("%s returned status %d", path, resp.StatusCode)
 	}
@@ -81,7 +94,7 @@ func (c *Client) getJSON(ctx context.Context, path string, out any) error {
 	}
 	setAPIHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return err
 	}
@@ -93,7 +106,7 @@ func (c *Client) getJSON(ctx context.Context, path string, out any) error {
 	if out == nil {
 		return nil
 	}
-	return json.NewDecoder(resp.Body).Decode(out)
+	return c.decodeJSON(resp, out)
 }
 
 // OrgSettings holds org-level access-control settings surfaced at audit level.
@@ -126,6 +139,78 @@ func (c *Client) GetOrgSettings(ctx context.Context, org string) (*OrgSettings,
 	}, nil
 }
 
+// LookupRepository returns the current "owner/name" of a repository by its
+// former full name. GitHub redirects renamed and transferred repositories, so
+// the result differs from owner/name when the repository moved; a deleted (or
+// no longer visible) repository returns ErrNotFound.
+func (c *Client) LookupRepository(ctx context.Context, owner, name string) (string, error) {
+	var body struct {
+		FullName string `json:"full_name"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s", owner, name), &body); err != nil {
+		return "", err
+	}
+	return body.FullName, nil
+}
+
+// ListSecurityManagerTeams returns the slugs of the teams assigned the org's
+// security manager role. Requires organization_administration:read.
+func (c *Client) ListSecurityManagerTeams(ctx context.Context, org string) ([]string, error) {
+	var teams []struct {
+		Slug string `json:"slug"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/orgs/%s/security-managers", org), &teams); err != nil {
+		return nil, err
+	}
+	slugs := make([]string, 0, len(teams))
+	for _, t := range teams {
+		slugs = append(slugs, t.Slug)
+	}
+	return slugs, nil
+}
+
+// CustomRole is an org-defined organization or repository role. Only the
+// name, base role, and permission names are retained.
+type CustomRole struct {
+	Name        string   `json:"name"`
+	BaseRole    string   `json:"base_role,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// ListCustomOrgRoles returns the org's custom organization roles; GitHub's
+// predefined roles are dropped. Requires organization_custom_org_roles:read.
+func (c *Client) ListCustomOrgRoles(ctx context.Context, org string) ([]CustomRole, error) {
+	var body struct {
+		Roles []struct {
+			CustomRole
+			Source string `json:"source"`
+		} `json:"roles"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/orgs/%s/organization-roles", org), &body); err != nil {
+		return nil, err
+	}
+	out := make([]CustomRole, 0, len(body.Roles))
+	for _, r := range body.Roles {
+		if r.Source == "Predefined" {
+			continue
+		}
+		out = append(out, r.CustomRole)
+	}
+	return out, nil
+}
+
+// ListCustomRepoRoles returns the org's custom repository roles. Requires
+// organization_custom_roles:read.
+func (c *Client) ListCustomRepoRoles(ctx context.Context, org string) ([]CustomRole, error) {
+	var body struct {
+		CustomRoles []CustomRole `json:"custom_roles"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/orgs/%s/custom-repository-roles", org), &body); err != nil {
+		return nil, err
+	}
+	return body.CustomRoles, nil
+}
+
 // AlertType identifies a GitHub Advanced Security alert endpoint.
 type AlertType string
 
@@ -150,7 +235,7 @@ func (c *Client) getOpenAlertTotal(ctx context.Context, owner, repo string, aler
 	}
 	setAPIHeaders(req)
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return 0, err
 	}
@@ -172,8 +257,8 @@ func (c *Client) getOpenAlertTotal(ctx context.Context, owner, repo string, aler
 	}
 	// No Link header: the result fits on one page. Count the items.
 	var items []json.RawMessage
-	if decErr := json.NewDecoder(resp.Body).Decode(&items); decErr != nil {
-		return 0, nil
+	if err := c.decodeJSON(resp, &items); err != nil {
+		return 0, err
 	}
 	return len(items), nil
 }
@@ -241,7 +326,7 @@ func (c *Client) getPagedRaw(ctx context.Context, firstPath string, maxItems int
 		}
 		setAPIHeaders(req)
 
-		resp, err := c.httpClient.Do(req)
+		resp, err := c.do(req)
 		if err != nil {
 			return nil, false, err
 		}
@@ -257,11 +342,11 @@ func (c *Client) getPagedRaw(ctx context.Context, firstPath string, maxItems int
 		}
 
 		var page []json.RawMessage
-		decErr := json.NewDecoder(resp.Body).Decode(&page)
+		decErr := c.decodeJSON(resp, &page)
 		link := resp.Header.Get("Link")
 		_ = resp.Body.Close()
 		if decErr != nil {
-			return all, false, nil
+			return nil, false, decErr
 		}
 		all = append(all, page...)
 		if len(all) >= maxItems {
@@ -445,6 +530,47 @@ func (c *Client) ListDependabotAlerts(ctx context.Context, owner, repo string) (
 	return out, more, nil
 }
 
+// AutoDismissedAlert is one Dependabot alert closed by an auto-triage rule.
+type AutoDismissedAlert struct {
+	Repository      string `json:"repository"`
+	Severity        string `json:"severity"`
+	AutoDismissedAt string `json:"auto_dismissed_at"`
+}
+
+// ListOrgAutoDismissedAlerts returns the org's Dependabot alerts in state
+// auto_dismissed, i.e. closed by an auto-triage rule (GitHub presets or
+// custom rules). The rules themselves have no API. Requires
+// dependabot_alerts:read; ErrFeatureUnavailable when Dependabot alerts are
+// off org-wide.
+func (c *Client) ListOrgAutoDismissedAlerts(ctx context.Context, org string) ([]AutoDismissedAlert, bool, error) {
+	path := fmt.Sprintf("/orgs/%s/dependabot/alerts?state=auto_dismissed&per_page=100", org)
+	raw, more, err := c.getPagedRaw(ctx, path, AlertFetchCap)
+	if err != nil {
+		return nil, false, err
+	}
+	out := make([]AutoDismissedAlert, 0, len(raw))
+	for _, r := range raw {
+		var a struct {
+			AutoDismissedAt       string `json:"auto_dismissed_at"`
+			SecurityVulnerability struct {
+				Severity string `json:"severity"`
+			} `json:"security_vulnerability"`
+			Repository struct {
+				FullName string `json:"full_name"`
+			} `json:"repository"`
+		}
+		if json.Unmarshal(r, &a) != nil {
+			continue
+		}
+		out = append(out, AutoDismissedAlert{
+			Repository:      a.Repository.FullName,
+			Severity:        a.SecurityVulnerability.Severity,
+			AutoDismissedAt: a.AutoDismissedAt,
+		})
+	}
+	return out, more, nil
+}
+
 // MemberFetchCap bounds login pagination defensively.
 const MemberFetchCap = 50000
 
@@ -545,9 +671,10 @@ func (c *Client) getMemberNames(ctx context.Context, org string) (map[string]str
 			"org":    githubv4.String(org),
 			"cursor": cursor,
 		}
-		if err := c.graphql.Query(ctx, &query, variables); err != nil {
+		if err := c.query(ctx, &query, variables); err != nil {
 			return nil, err
 		}
+		c.recordRateLimit(query.RateLimit)
 		for _, n := range query.Organization.MembersWithRole.Nodes {
 			if n.Login != "" && n.Name != "" {
 				names[n.Login] = n.Name
@@ -606,25 +733,39 @@ func (c *Client) getUserName(ctx context.Context, login string) (string, error)
 	return user.Name, nil
 }
 
-// GetCodeownersInfo reports whether a CODEOWNERS file exists (and its path) and,
-// when wantHash is true (internal), a SHA-256 of its contents. File bytes are
-// hashed in-process and never emitted.
-func (c *Client) GetCodeownersInfo(ctx context.Context, owner, repo string, wantHash bool) (present bool, path string, hash string, err error) {
+// CodeownersInfo is what the collector keeps of a repository's CODEOWNERS
+// file. Hash and OwningTeam are filled only on request (internal).
+type CodeownersInfo struct {
+	Present bool
+	Path    string
+	Hash    string // SHA-256 of the contents
+	// OwningTeam is a team handle ("org/team", no "@"); see
+	// parseCodeownersOwner.
+	OwningTeam string
+}
+
+// GetCodeownersInfo reports whether a CODEOWNERS file exists (and its path)
+// and, when detail is true (internal), a SHA-256 of its contents and its
+// owning team. File bytes are hashed and parsed in-process and never
+// emitted.
+func (c *Client) GetCodeownersInfo(ctx context.Context, owner, repo string, detail bool) (*CodeownersInfo, error) {
 	for _, p := range []string{".github/CODEOWNERS", "docs/CODEOWNERS", "CODEOWNERS"} {
 		bytes, ferr := c.getFileContents(ctx, owner, repo, p)
 		if errors.Is(ferr, ErrNotFound) {
 			continue
 		}
 		if ferr != nil {
-			return false, "", "", ferr
+			return nil, ferr
 		}
-		if !wantHash {
-			return true, p, "", nil
+		info := &CodeownersInfo{Present: true, Path: p}
+		if detail {
+			sum := sha256.Sum256(bytes)
+			info.Hash = hex.EncodeToString(sum[:])
+			info.OwningTeam = parseCodeownersOwner(bytes)
 		}
-		sum := sha256.Sum256(bytes)
-		return true, p, hex.EncodeToString(sum[:]), nil
+		return info, nil
 	}
-	return false, "", "", nil
+	return &CodeownersInfo{}, nil
 }
 
 // codeownersMaxBytes caps the hashable CODEOWNERS size; larger files are
@@ -632,7 +773,7 @@ func (c *Client) GetCodeownersInfo(ctx context.Context, owner, repo string, want
 const codeownersMaxBytes = 1 << 20
 
 // getFileContents fetches and base64-decodes a repo file. Bytes are used only
-// for hashing by the single CODEOWNERS caller.
+// for hashing and owner parsing by the single CODEOWNERS caller.
 func (c *Client) getFileContents(ctx context.Context, owner, repo, path string) ([]byte, error) {
 	var body struct {
 		Encoding string `json:"encoding"`
@@ -645,7 +786,7 @@ func (c *Client) getFileContents(ctx context.Context, owner, repo, path string)
 	if body.Size > codeownersMaxBytes || body.Encoding != "base64" {
 		return nil, ErrNotFound
 	}
-	// LINT-ALLOW: bytes are SHA-256 hashed by the caller and immediately discarded; never emitted.
+	// LINT-ALLOW: bytes are SHA-256 hashed and parsed for team handles by the caller, then discarded; never emitted.
 	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body.Content, "\n", ""))
 	if err != nil {
 		return nil, err
@@ -767,6 +908,186 @@ func (c *Client) ListRepoDeployKeys(ctx context.Context, owner, repo string) ([]
 	return out, nil
 }
 
+// RepositoryAdvisory is the metadata for one repository security advisory.
+// The advisory description and vulnerability details are not fetched.
+type RepositoryAdvisory struct {
+	Repository  string `json:"repository"`
+	GHSAID      string `json:"ghsa_id"`
+	State       string `json:"state"` // triage, draft, published, closed
+	Severity    string `json:"severity,omitempty"`
+	CreatedAt   string `json:"created_at"`
+	PublishedAt string `json:"published_at,omitempty"`
+}
+
+// ListRepoSecurityAdvisories returns a repo's security advisories in every
+// state. Requires repository_advisories:read.
+func (c *Client) ListRepoSecurityAdvisories(ctx context.Context, owner, repo string) ([]RepositoryAdvisory, error) {
+	raw, _, err := c.getPagedRaw(ctx, fmt.Sprintf("/repos/%s/%s/security-advisories?per_page=100", owner, repo), 1000)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]RepositoryAdvisory, 0, len(raw))
+	for _, r := range raw {
+		var a struct {
+			GHSAID      string `json:"ghsa_id"`
+			State       string `json:"state"`
+			Severity    string `json:"severity"`
+			CreatedAt   string `json:"created_at"`
+			PublishedAt string `json:"published_at"`
+		}
+		if json.Unmarshal(r, &a) != nil {
+			continue
+		}
+		out = append(out, RepositoryAdvisory{
+			Repository:  owner + "/" + repo,
+			GHSAID:      a.GHSAID,
+			State:       a.State,
+			Severity:    a.Severity,
+			CreatedAt:   a.CreatedAt,
+			PublishedAt: a.PublishedAt,
+		})
+	}
+	return out, nil
+}
+
+// ListCommitCheckContexts returns the names of the check runs and commit
+// status contexts reported on ref (a branch name resolves to its head
+// commit). Only the first 100 of each are read, which covers any realistic
+// set of required checks. Requires checks:read and statuses:read.
+func (c *Client) ListCommitCheckContexts(ctx context.Context, owner, repo, ref string) ([]string, error) {
+	ref = url.PathEscape(ref)
+	var runs struct {
+		CheckRuns []struct {
+			Name string `json:"name"`
+		} `json:"check_runs"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/commits/%s/check-runs?per_page=100", owner, repo, ref), &runs); err != nil {
+		return nil, err
+	}
+	var status struct {
+		Statuses []struct {
+			Context string `json:"context"`
+		} `json:"statuses"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/commits/%s/status?per_page=100", owner, repo, ref), &status); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(runs.CheckRuns)+len(status.Statuses))
+	for _, r := range runs.CheckRuns {
+		names = append(names, r.Name)
+	}
+	for _, s := range status.Statuses {
+		names = append(names, s.Context)
+	}
+	return names, nil
+}
+
+// LabelFetchCap bounds label and labelled-issue pagination per repo.
+const LabelFetchCap = 1000
+
+// ListRepoLabels returns the names of a repo's issue labels. Requires
+// issues:read (or pull_requests:read).
+func (c *Client) ListRepoLabels(ctx context.Context, owner, repo string) ([]string, error) {
+	raw, _, err := c.getPagedRaw(ctx, fmt.Sprintf("/repos/%s/%s/labels?per_page=100", owner, repo), LabelFetchCap)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(raw))
+	for _, r := range raw {
+		var l struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(r, &l) != nil {
+			continue
+		}
+		names = append(names, l.Name)
+	}
+	return names, nil
+}
+
+// Issue is the metadata for one open issue. Titles and bodies are not emitted.
+type Issue struct {
+	Number    int    `json:"number"`
+	CreatedAt string `json:"created_at,omitempty"`
+	// Title is read only to match remediation tickets (see CreateIssue) and
+	// is never emitted.
+	Title string `json:"-"`
+}
+
+// ListOpenIssuesWithLabel returns a repo's open issues carrying label, oldest
+// first. Pull requests, which the issues endpoint also returns, are dropped.
+// Requires issues:read.
+func (c *Client) ListOpenIssuesWithLabel(ctx context.Context, owner, repo, label string) ([]Issue, error) {
+	path := fmt.Sprintf("/repos/%s/%s/issues?state=open&labels=%s&sort=created&direction=asc&per_page=100",
+		owner, repo, url.QueryEscape(label))
+	raw, _, err := c.getPagedRaw(ctx, path, LabelFetchCap)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Issue, 0, len(raw))
+	for _, r := range raw {
+		var i struct {
+			Number      int             `json:"number"`
+			CreatedAt   string          `json:"created_at"`
+			Title       string          `json:"title"`
+			PullRequest json.RawMessage `json:"pull_request"`
+		}
+		if json.Unmarshal(r, &i) != nil || i.PullRequest != nil {
+			continue
+		}
+		out = append(out, Issue{Number: i.Number, CreatedAt: i.CreatedAt, Title: i.Title})
+	}
+	return out, nil
+}
+
+// IssueRequest is the content of an issue the collector files or updates.
+type IssueRequest struct {
+	Title  string   `json:"title,omitempty"`
+	Body   string   `json:"body,omitempty"`
+	Labels []string `json:"labels,omitempty"`
+	// State and StateReason close an issue when set on an update.
+	State       string `json:"state,omitempty"`
+	StateReason string `json:"state_reason,omitempty"`
+}
+
+// CreateIssue opens an issue. It is used only for opt-in remediation tickets
+// and requires issues:write on the repository.
+func (c *Client) CreateIssue(ctx context.Context, owner, repo string, issue IssueRequest) error {
+	return c.sendJSON(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/%s/issues", owner, repo), issue)
+}
+
+// UpdateIssue replaces an issue's title and body (and labels, when set).
+// Requires issues:write.
+func (c *Client) UpdateIssue(ctx context.Context, owner, repo string, number int, issue IssueRequest) error {
+	return c.sendJSON(ctx, http.MethodPatch, fmt.Sprintf("/repos/%s/%s/issues/%d", owner, repo, number), issue)
+}
+
+// sendJSON performs a write against the REST API with in as the JSON body,
+// classifying non-2xx replies like getJSON. The reply body is discarded.
+func (c *Client) sendJSON(ctx context.Context, method, path string, in any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	setAPIHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return classifyStatus(resp, path)
+	}
+	return nil
+}
+
 // Runner is a self-hosted Actions runner (org- or repo-level).
 type Runner struct {
 	ID     int64    `json:"id"`
@@ -815,6 +1136,41 @@ func (c *Client) ListRepoRunners(ctx context.Context, owner, repo string) ([]Run
 	return c.listRunners(ctx, fmt.Sprintf("/repos/%s/%s/actions/runners?per_page=100", owner, repo))
 }
 
+// RunnerGroup is an org self-hosted runner group. Runners is counted only for
+// groups that allow public repositories.
+type RunnerGroup struct {
+	ID                       int64  `json:"id"`
+	Name                     string `json:"name"`
+	Visibility               string `json:"visibility"`
+	AllowsPublicRepositories bool   `json:"allows_public_repositories"`
+	Runners                  int    `json:"-"`
+}
+
+// ListRunnerGroups returns the org's self-hosted runner groups, with the
+// runner count of each group open to public repositories. Requires
+// organization_self_hosted_runners:read.
+func (c *Client) ListRunnerGroups(ctx context.Context, org string) ([]RunnerGroup, error) {
+	var body struct {
+		RunnerGroups []RunnerGroup `json:"runner_groups"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/orgs/%s/actions/runner-groups?per_page=100", org), &body); err != nil {
+		return nil, err
+	}
+	for i, g := range body.RunnerGroups {
+		if !g.AllowsPublicRepositories {
+			continue
+		}
+		var runners struct {
+			TotalCount int `json:"total_count"`
+		}
+		if err := c.getJSON(ctx, fmt.Sprintf("/orgs/%s/actions/runner-groups/%d/runners?per_page=1", org, g.ID), &runners); err != nil {
+			return nil, err
+		}
+		body.RunnerGroups[i].Runners = runners.TotalCount
+	}
+	return body.RunnerGroups, nil
+}
+
 // ListOrgActionsSecretNames returns org-level Actions secret names (never
 // values). Requires organization_secrets:read.
 func (c *Client) ListOrgActionsSecretNames(ctx context.Context, org string) ([]string, error) {
@@ -833,12 +1189,58 @@ func (c *Client) ListOrgActionsSecretNames(ctx context.Context, org string) ([]s
 	return names, nil
 }
 
+// ActionsSecret is the metadata for one Actions secret. The value is never
+// readable through the API.
+type ActionsSecret struct {
+	Name      string `json:"name"`
+	CreatedAt string `json:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// ListRepoActionsSecrets returns a repo's Actions secrets (names and
+// timestamps only). Requires secrets:read.
+func (c *Client) ListRepoActionsSecrets(ctx context.Context, owner, repo string) ([]ActionsSecret, error) {
+	var body struct {
+		Secrets []ActionsSecret `json:"secrets"`
+	}
+	if err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/actions/secrets?per_page=100", owner, repo), &body); err != nil {
+		return nil, err
+	}
+	return body.Secrets, nil
+}
+
 // AuditEvent is one security-relevant org audit-log event (internal level).
+// ForcePush marks a protected_branch.policy_override event whose push was a
+// force push (see isForcePushOverride).
 type AuditEvent struct {
 	Action    string `json:"action"`
 	Actor     string `json:"actor,omitempty"`
 	Repo      string `json:"repo,omitempty"`
 	CreatedAt int64  `json:"created_at"`
+	ForcePush bool   `json:"force_push,omitempty"`
+}
+
+// auditOverrideReason is one protection rule a protected_branch.policy_override
+// event's push bypassed.
+type auditOverrideReason struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// isForcePushOverride reports whether a protection override bypassed the
+// rule against force pushes: GitHub's reason code for it is non_fast_forward,
+// and its message says "force-push" or "force push".
+func isForcePushOverride(action string, reasons []auditOverrideReason) bool {
+	if action != "protected_branch.policy_override" {
+		return false
+	}
+	for _, r := range reasons {
+		msg := strings.ToLower(r.Message)
+		if r.Code == "non_fast_forward" || strings.Contains(msg, "force-push") || strings.Contains(msg, "force push") {
+			return true
+		}
+	}
+	return false
 }
 
 // AuditLogCategories are the security-relevant action prefixes the audit-log
@@ -847,6 +1249,7 @@ var AuditLogCategories = []string{
 	"member_", "repo.create", "repo.transfer", "repo.destroy", "repo.access",
 	"protected_branch.", "oauth_application.", "secret_scanning_alert.bypass",
 	"org.disable_two_factor_requirement", "org.update_member",
+	"repository_ruleset.destroy",
 }
 
 // GetOrgAuditLog fetches security-relevant audit events since sinceISO.
@@ -864,10 +1267,11 @@ func (c *Client) GetOrgAuditLog(ctx context.Context, org, sinceISO string, maxEv
 	out := make([]AuditEvent, 0, len(raw))
 	for _, r := range raw {
 		var e struct {
-			Action    string `json:"action"`
-			Actor     string `json:"actor"`
-			Repo      string `json:"repo"`
-			CreatedAt int64  `json:"created_at"`
+			Action    string                `json:"action"`
+			Actor     string                `json:"actor"`
+			Repo      string                `json:"repo"`
+			CreatedAt int64                 `json:"created_at"`
+			Reasons   []auditOverrideReason `json:"reasons"`
 		}
 		if json.Unmarshal(r, &e) != nil {
 			continue
@@ -875,7 +1279,13 @@ func (c *Client) GetOrgAuditLog(ctx context.Context, org, sinceISO string, maxEv
 		if !isSecurityRelevantAction(e.Action) {
 			continue
 		}
-		out = append(out, AuditEvent{Action: e.Action, Actor: e.Actor, Repo: e.Repo, CreatedAt: e.CreatedAt})
+		out = append(out, AuditEvent{
+			Action:    e.Action,
+			Actor:     e.Actor,
+			Repo:      e.Repo,
+			CreatedAt: e.CreatedAt,
+			ForcePush: isForcePushOverride(e.Action, e.Reasons),
+		})
 	}
 	return out, more, nil
 }
@@ -940,6 +1350,9 @@ func (c *Client) ListOrgInstallations(ctx context.Context, org string) ([]Instal
 	return out, nil
 }
 
+// PATFetchCap bounds how many fine-grained PAT grants and requests are read.
+const PATFetchCap = 5000
+
 // PATGrant is a fine-grained PAT granted access to org resources (internal).
 type PATGrant struct {
 	ID          int64    `json:"id"`
@@ -954,7 +1367,10 @@ type PATGrant struct {
 // ErrFeatureUnavailable on orgs without a fine-grained-token policy. Token
 // values are never exposed by the API and never emitted.
 func (c *Client) ListOrgPATs(ctx context.Context, org string) ([]PATGrant, bool, error) {
-	raw, more, err := c.getPagedRaw(ctx, fmt.Sprintf("/orgs/%s/personal-access-tokens?per_page=100", org), 5000)
+	if !c.instance.Supports(FeatureFineGrainedPATs) {
+		return nil, false, c.errUnsupported(FeatureFineGrainedPATs)
+	}
+	raw, more, err := c.getPagedRaw(ctx, fmt.Sprintf("/orgs/%s/personal-access-tokens?per_page=100", org), PATFetchCap)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) || errors.Is(err, ErrPermissionDenied) {
 			return nil, false, ErrFeatureUnavailable
@@ -993,3 +1409,116 @@ func (c *Client) ListOrgPATs(ctx context.Context, org string) ([]PATGrant, bool,
 	}
 	return out, more, nil
 }
+
+// CountOrgPATRequests returns how many fine-grained PAT requests await
+// approval in the org, capped at PATFetchCap, and whether the cap was hit.
+// Returns ErrFeatureUnavailable on orgs without a fine-grained-token policy.
+// Requires organization_personal_access_token_requests:read.
+func (c *Client) CountOrgPATRequests(ctx context.Context, org string) (int, bool, error) {
+	if !c.instance.Supports(FeatureFineGrainedPATs) {
+		return 0, false, c.errUnsupported(FeatureFineGrainedPATs)
+	}
+	raw, more, err := c.getPagedRaw(ctx, fmt.Sprintf("/orgs/%s/personal-access-token-requests?per_page=100", org), PATFetchCap)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return 0, false, ErrFeatureUnavailable
+		}
+		return 0, false, err
+	}
+	return len(raw), more, nil
+}
+
+// InteractionLimit is an active temporary interaction restriction on an
+// org's public repositories. Limit is existing_users, contributors_only, or
+// collaborators_only.
+type InteractionLimit struct {
+	Limit     string
+	ExpiresAt string
+}
+
+// GetOrgInteractionLimit returns the org's active interaction limit, or nil
+// when none is set. Requires organization_administration:read.
+func (c *Client) GetOrgInteractionLimit(ctx context.Context, org string) (*InteractionLimit, error) {
+	path := fmt.Sprintf("/orgs/%s/interaction-limits", org)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	setAPIHeaders(req)
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// No active limit is either 204, an empty body, or an empty object,
+	// depending on the API version.
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, classifyStatus(resp, path)
+	}
+	if resp.ContentLength == 0 {
+		return nil, nil
+	}
+	var body struct {
+		Limit     string `json:"limit"`
+		ExpiresAt string `json:"expires_at"`
+	}
+	if err := c.decodeJSON(resp, &body); err != nil {
+		return nil, err
+	}
+	if body.Limit == "" {
+		return nil, nil
+	}
+	return &InteractionLimit{Limit: body.Limit, ExpiresAt: body.ExpiresAt}, nil
+}
+
+// ListOrgBlockedUsers returns the logins the org has blocked. Requires
+// organization_user_blocking:read.
+func (c *Client) ListOrgBlockedUsers(ctx context.Context, org string) ([]string, error) {
+	return c.getAllLogins(ctx, fmt.Sprintf("/orgs/%s/blocks?per_page=100", org))
+}
+
+// ProjectFetchCap bounds how many org projects ListOrgProjects pages through.
+const ProjectFetchCap = 1000
+
+// ListOrgProjects returns the org's projects. Requires
+// organization_projects:read.
+func (c *Client) ListOrgProjects(ctx context.Context, org string) ([]Project, error) {
+	if c.graphql == nil {
+		return nil, errors.New("graphql client not configured")
+	}
+
+	var projects []Project
+	var cursor *githubv4.String
+	for pages := 0; pages < ProjectFetchCap/100; pages++ {
+		var query OrgProjectsQuery
+		variables := map[string]interface{}{
+			"org":    githubv4.String(org),
+			"cursor": cursor,
+		}
+		if err := c.query(ctx, &query, variables); err != nil {
+			return nil, classifyGraphQLError(err, "projectsV2")
+		}
+		c.recordRateLimit(query.RateLimit)
+		projects = append(projects, query.Organization.ProjectsV2.Nodes...)
+		if !query.Organization.ProjectsV2.PageInfo.HasNextPage {
+			break
+		}
+		cursor = &query.Organization.ProjectsV2.PageInfo.EndCursor
+	}
+	return projects, nil
+}
+
+// classifyGraphQLError wraps a GraphQL permission failure, which GitHub
+// reports in the errors array rather than with a 403, in ErrPermissionDenied.
+func classifyGraphQLError(err error, field string) error {
+	msg := strings.ToLower(err.Error())
+	if strings.Contains(msg, "resource not accessible") || strings.Contains(msg, "insufficient_scopes") {
+		return fmt.Errorf("%w: %s (%v)", ErrPermissionDenied, field, err)
+	}
+	return err
+}
@@ -32,6 +32,25 @@ func TestIsSecurityRelevantAction(t *testing.T) {
 	}
 }
 
+func TestIsForcePushOverride(t *testing.T) {
+	cases := []struct {
+		action  string
+		reasons []auditOverrideReason
+		want    bool
+	}{
+		{"protected_branch.policy_override", []auditOverrideReason{{Code: "non_fast_forward"}}, true},
+		{"protected_branch.policy_override", []auditOverrideReason{{Code: "required_status_checks"}, {Message: "Cannot force-push to this branch"}}, true},
+		{"protected_branch.policy_override", []auditOverrideReason{{Code: "required_status_checks", Message: "Required status check is expected"}}, false},
+		{"protected_branch.policy_override", nil, false},
+		{"protected_branch.rejected_ref_update", []auditOverrideReason{{Code: "non_fast_forward"}}, false},
+	}
+	for _, tc := range cases {
+		if got := isForcePushOverride(tc.action, tc.reasons); got != tc.want {
+			t.Errorf("isForcePushOverride(%q, %+v) = %v, want %v", tc.action, tc.reasons, got, tc.want)
+		}
+	}
+}
+
 func TestIs403FeatureDisabled(t *testing.T) {
 	// Feature-disabled 403 bodies GitHub returns when scanning/Dependabot is off.
 	featureOff := []string{
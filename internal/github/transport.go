@@ -0,0 +1,163 @@
+package github
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/time/rate"
+)
+
+// TransportConfig configures the HTTP transport shared by the REST and GraphQL
+// clients. The zero value behaves like http.DefaultTransport, including
+// honoring the HTTP_PROXY / HTTPS_PROXY / NO_PROXY environment variables.
+type TransportConfig struct {
+	// HTTPProxy, HTTPSProxy, and NoProxy override the corresponding
+	// environment variables when any of them is set. NoProxy takes the same
+	// comma-separated host / domain / CIDR list as NO_PROXY.
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+
+	// CABundlePath is a PEM file of additional trusted root CAs, appended to
+	// the system pool (for TLS-intercepting proxies or GitHub Enterprise
+	// Server with a private CA).
+	CABundlePath string
+	// InsecureSkipVerify disables TLS certificate verification. Only for
+	// troubleshooting; never use it in production.
+	InsecureSkipVerify bool
+
+	// MaxRequestsPerSecond caps the request rate across every GitHub call
+	// made through the transport (REST, GraphQL, and App token exchange),
+	// independent of GitHub's own rate limit. 0 = unlimited.
+	MaxRequestsPerSecond float64
+	// Limiter, when set, also admits every request, so several clients can
+	// share one request budget (e.g. a batch run across organizations).
+	Limiter *rate.Limiter
+
+	// MaxIdleConnsPerHost is how many idle keep-alive connections are kept
+	// per host for reuse (0 = DefaultMaxIdleConnsPerHost). Every GitHub call
+	// goes to one host, so the net/http default of 2 makes concurrent
+	// requests reconnect.
+	MaxIdleConnsPerHost int
+	// DisableHTTP2 forces HTTP/1.1, for proxies that mishandle HTTP/2.
+	// HTTP/2 multiplexes concurrent requests over one connection and is
+	// used by default.
+	DisableHTTP2 bool
+
+	// DebugCapturePath, when set, names an NDJSON file each request's
+	// sanitized metadata is appended to (see captureRecord), for support
+	// escalations. Every network attempt is recorded, including retries and
+	// App token exchanges.
+	DebugCapturePath string
+}
+
+// DefaultMaxIdleConnsPerHost is the idle connection pool size per host.
+const DefaultMaxIdleConnsPerHost = 16
+
+// NewTransport builds the base HTTP transport from cfg.
+func NewTransport(cfg TransportConfig) (http.RoundTripper, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	transport.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	transport.MaxIdleConns = max(transport.MaxIdleConns, transport.MaxIdleConnsPerHost)
+	if cfg.DisableHTTP2 {
+		// A non-nil, empty TLSNextProto disables the HTTP/2 upgrade.
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	if cfg.HTTPProxy != "" || cfg.HTTPSProxy != "" || cfg.NoProxy != "" {
+		proxyFunc := (&httpproxy.Config{
+			HTTPProxy:  cfg.HTTPProxy,
+			HTTPSProxy: cfg.HTTPSProxy,
+			NoProxy:    cfg.NoProxy,
+		}).ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	}
+
+	if cfg.CABundlePath != "" || cfg.InsecureSkipVerify {
+		tlsConfig := &tls.Config{
+			MinVersion:         tls.VersionTLS12,
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+		}
+		if cfg.CABundlePath != "" {
+			pool, err := loadCABundle(cfg.CABundlePath)
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	var rt http.RoundTripper = transport
+	if cfg.DebugCapturePath != "" {
+		// Innermost, so durations exclude the limiters' waits.
+		capture, err := newCaptureTransport(rt, cfg.DebugCapturePath)
+		if err != nil {
+			return nil, err
+		}
+		rt = capture
+	}
+	if cfg.MaxRequestsPerSecond > 0 {
+		rt = newRateLimitedTransport(rt, cfg.MaxRequestsPerSecond)
+	}
+	if cfg.Limiter != nil {
+		rt = &rateLimitedTransport{base: rt, limiter: cfg.Limiter}
+	}
+	return rt, nil
+}
+
+// rateLimitedTransport delays each request until a token-bucket limiter
+// admits it. The bucket holds one second's worth of requests (at least one),
+// so short bursts are allowed but the sustained rate stays at the limit.
+type rateLimitedTransport struct {
+	base    http.RoundTripper
+	limiter *rate.Limiter
+}
+
+func newRateLimitedTransport(base http.RoundTripper, perSecond float64) *rateLimitedTransport {
+	return &rateLimitedTransport{base: base, limiter: NewLimiter(perSecond)}
+}
+
+// NewLimiter returns a request limiter admitting perSecond requests per
+// second, with a burst of one second's worth (at least one).
+func NewLimiter(perSecond float64) *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(perSecond), max(1, int(perSecond)))
+}
+
+// RoundTrip waits for the limiter, honoring the request's context, then
+// sends the request.
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// loadCABundle returns the system root pool with the PEM certificates at path
+// appended.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA bundle %s contains no PEM certificates", path)
+	}
+	return pool, nil
+}
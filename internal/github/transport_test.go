@@ -0,0 +1,236 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTransport_Proxy(t *testing.T) {
+	rt, err := NewTransport(TransportConfig{
+		HTTPSProxy: "http://proxy.example.com:8080",
+		NoProxy:    "ghe.internal.example.com",
+	})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	transport := rt.(*http.Transport)
+
+	tests := []struct {
+		url       string
+		wantProxy string
+	}{
+		{"https://api.github.com/orgs/test-org", "http://proxy.example.com:8080"},
+		{"https://ghe.internal.example.com/api/v3", ""},
+		{"http://api.github.com/", ""}, // no http_proxy configured
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", tt.url, nil)
+		proxy, err := transport.Proxy(req)
+		if err != nil {
+			t.Fatalf("Proxy(%s) error: %v", tt.url, err)
+		}
+		got := ""
+		if proxy != nil {
+			got = proxy.String()
+		}
+		if got != tt.wantProxy {
+			t.Errorf("Proxy(%s) = %q, want %q", tt.url, got, tt.wantProxy)
+		}
+	}
+}
+
+func TestNewTransport_CABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Without the bundle the test server's self-signed cert is rejected.
+	rt, err := NewTransport(TransportConfig{})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	if resp, err := (&http.Client{Transport: rt}).Get(server.URL); err == nil {
+		_ = resp.Body.Close()
+		t.Fatal("expected TLS verification failure without CA bundle")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rt, err = NewTransport(TransportConfig{CABundlePath: bundle})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	resp, err := (&http.Client{Transport: rt}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET with CA bundle: %v", err)
+	}
+	_ = resp.Body.Close()
+}
+
+func TestNewTransport_InvalidCABundle(t *testing.T) {
+	if _, err := NewTransport(TransportConfig{CABundlePath: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("expected error for missing CA bundle")
+	}
+
+	bundle := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(bundle, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTransport(TransportConfig{CABundlePath: bundle}); err == nil {
+		t.Error("expected error for CA bundle without certificates")
+	}
+}
+
+func TestNewTransport_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	rt, err := NewTransport(TransportConfig{MaxRequestsPerSecond: 20})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	client := &http.Client{Transport: rt}
+
+	// The first 20 requests use the burst; the next 5 wait 50ms each.
+	start := time.Now()
+	for range 25 {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("25 requests at 20/s took %v, want at least 200ms", elapsed)
+	}
+}
+
+func TestNewTransport_RateLimitHonorsContext(t *testing.T) {
+	rt, err := NewTransport(TransportConfig{MaxRequestsPerSecond: 0.01})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	client := &http.Client{Transport: rt}
+
+	// Spend the single burst token, then a cancelled request fails fast
+	// instead of waiting ~100s for the next one.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", "http://127.0.0.1:0", nil)
+	_, _ = client.Do(req)
+	if _, err := client.Do(req); err == nil {
+		t.Error("expected error once the context deadline precedes the next token")
+	}
+}
+
+func TestNewTransport_SharedLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// Two transports drawing from one 20/s budget: 25 requests split across
+	// them still wait for the 5 beyond the shared burst.
+	limiter := NewLimiter(20)
+	var clients []*http.Client
+	for range 2 {
+		rt, err := NewTransport(TransportConfig{Limiter: limiter})
+		if err != nil {
+			t.Fatalf("NewTransport() error: %v", err)
+		}
+		clients = append(clients, &http.Client{Transport: rt})
+	}
+	start := time.Now()
+	for i := range 25 {
+		resp, err := clients[i%2].Get(server.URL)
+		if err != nil {
+			t.Fatalf("GET: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("25 requests sharing 20/s took %v, want at least 200ms", elapsed)
+	}
+}
+
+func TestNewTransport_ConnectionPool(t *testing.T) {
+	rt, err := NewTransport(TransportConfig{})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	transport := rt.(*http.Transport)
+	if transport.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost || !transport.ForceAttemptHTTP2 {
+		t.Errorf("default transport: MaxIdleConnsPerHost = %d, ForceAttemptHTTP2 = %v", transport.MaxIdleConnsPerHost, transport.ForceAttemptHTTP2)
+	}
+
+	rt, err = NewTransport(TransportConfig{MaxIdleConnsPerHost: 200, DisableHTTP2: true, InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	transport = rt.(*http.Transport)
+	if transport.MaxIdleConnsPerHost != 200 || transport.MaxIdleConns < 200 {
+		t.Errorf("MaxIdleConnsPerHost = %d, MaxIdleConns = %d; want 200 and at least 200", transport.MaxIdleConnsPerHost, transport.MaxIdleConns)
+	}
+	if transport.ForceAttemptHTTP2 || transport.TLSNextProto == nil {
+		t.Error("DisableHTTP2 should turn off the HTTP/2 upgrade")
+	}
+}
+
+func TestNewTransport_DebugCapture(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-GitHub-Request-Id", "C1A2:3B4C")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"message": "token ghp_secret rejected"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "capture.ndjson")
+	rt, err := NewTransport(TransportConfig{DebugCapturePath: path})
+	if err != nil {
+		t.Fatalf("NewTransport() error: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/orgs/acme/rulesets?per_page=100&sig=signed", nil)
+	req.Header.Set("Authorization", "Bearer ghp_secret")
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error: %v", err)
+	}
+	resp.Body.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("capture leaked a secret: %s", data)
+	}
+	var record captureRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		t.Fatalf("capture line %q: %v", data, err)
+	}
+	if record.Method != http.MethodGet || record.Status != http.StatusForbidden ||
+		record.URL != server.URL+"/orgs/acme/rulesets?per_page=100&sig=REDACTED" {
+		t.Errorf("record = %+v", record)
+	}
+	if record.Headers["request_id"] != "C1A2:3B4C" || record.Headers["ratelimit_remaining"] != "4999" || len(record.Headers) != 2 {
+		t.Errorf("headers = %v", record.Headers)
+	}
+
+	if _, err := NewTransport(TransportConfig{DebugCapturePath: filepath.Join(t.TempDir(), "missing", "capture.ndjson")}); err == nil {
+		t.Error("NewTransport() with an unwritable capture path should fail")
+	}
+}
@@ -0,0 +1,40 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WorkflowFileCap bounds how many workflow files ListWorkflowFiles returns
+// per repository.
+const WorkflowFileCap = 100
+
+// ListWorkflowFiles returns the paths of the repository's Actions workflow
+// files (.yml and .yaml under .github/workflows) on its default branch. A
+// repository without the directory has none. Requires contents:read.
+func (c *Client) ListWorkflowFiles(ctx context.Context, owner, repo string) ([]string, error) {
+	var entries []struct {
+		Type string `json:"type"`
+		Path string `json:"path"`
+	}
+	err := c.getJSON(ctx, fmt.Sprintf("/repos/%s/%s/contents/.github/workflows", owner, repo), &entries)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		if e.Type != "file" || !(strings.HasSuffix(e.Path, ".yml") || strings.HasSuffix(e.Path, ".yaml")) {
+			continue
+		}
+		paths = append(paths, e.Path)
+		if len(paths) == WorkflowFileCap {
+			break
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,304 @@
+package secretstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Default AWS metadata endpoints: the container credentials agent (ECS task
+// roles) and the EC2 instance metadata service.
+const (
+	awsContainerHost = "http://169.254.170.2"
+	awsIMDSURL       = "http://169.254.169.254"
+)
+
+// awsCredentials signs AWS requests.
+type awsCredentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"Token"`
+}
+
+// aws reads the secret named id, a Secrets Manager secret name or ARN,
+// returning its string value, or its binary value when it has none. The
+// region is the ARN's, or else AWS_REGION or AWS_DEFAULT_REGION.
+func (s *Store) aws(ctx context.Context, id string) ([]byte, error) {
+	region := arnRegion(id)
+	if region == "" {
+		region = s.getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = s.getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		return nil, errors.New("no region: use the secret's ARN or set AWS_REGION")
+	}
+	creds, err := s.awsCredentials(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := s.opts.awsEndpoint
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com/"
+	}
+	body, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	signV4(req, body, creds, region, "secretsmanager", s.opts.now())
+
+	var reply struct {
+		SecretString *string `json:"SecretString"`
+		SecretBinary []byte  `json:"SecretBinary"`
+	}
+	if _, err := do(s.client, req, &reply); err != nil {
+		return nil, err
+	}
+	if reply.SecretString != nil {
+		return []byte(*reply.SecretString), nil
+	}
+	if reply.SecretBinary == nil {
+		return nil, errors.New("the secret has no value")
+	}
+	return reply.SecretBinary, nil
+}
+
+// arnRegion returns the region of a Secrets Manager ARN
+// (arn:aws:secretsmanager:REGION:ACCOUNT:secret:NAME), or "" for a name.
+func arnRegion(id string) string {
+	parts := strings.SplitN(id, ":", 5)
+	if len(parts) < 5 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[3]
+}
+
+// awsCredentials finds credentials the way the AWS CLI does, less the shared
+// config files: the environment (AWS_ACCESS_KEY_ID and friends), then a web
+// identity token (EKS IAM roles for service accounts, CI OIDC federation),
+// then the container credentials agent (ECS task roles, EKS Pod Identity),
+// then the EC2 instance profile. region is where STS is called.
+func (s *Store) awsCredentials(ctx context.Context, region string) (awsCredentials, error) {
+	if id := s.getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		secret := s.getenv("AWS_SECRET_ACCESS_KEY")
+		if secret == "" {
+			return awsCredentials{}, errors.New("AWS_ACCESS_KEY_ID is set without AWS_SECRET_ACCESS_KEY")
+		}
+		return awsCredentials{AccessKeyID: id, SecretAccessKey: secret, SessionToken: s.getenv("AWS_SESSION_TOKEN")}, nil
+	}
+
+	if file := s.getenv("AWS_WEB_IDENTITY_TOKEN_FILE"); file != "" {
+		return s.webIdentityCredentials(ctx, file, region)
+	}
+
+	uri := s.getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if rel := s.getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); rel != "" {
+		uri = awsContainerHost + rel
+	}
+	if uri != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+		if err != nil {
+			return awsCredentials{}, errors.New("invalid container credentials URI")
+		}
+		token := s.getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+		if file := s.getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); file != "" {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				return awsCredentials{}, fmt.Errorf("reading container authorization token: %w", err)
+			}
+			token = strings.TrimSpace(string(data))
+		}
+		if token != "" {
+			req.Header.Set("Authorization", token)
+		}
+		return fetchAWSCredentials(s.metadata, req, "container credentials")
+	}
+
+	if strings.EqualFold(s.getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return awsCredentials{}, errors.New("no credentials: set AWS_ACCESS_KEY_ID or run with a task or instance role")
+	}
+	return s.instanceCredentials(ctx)
+}
+
+// webIdentityCredentials assumes AWS_ROLE_ARN with the web identity token in
+// file, through STS AssumeRoleWithWebIdentity. The call is unsigned: the
+// token is the credential.
+func (s *Store) webIdentityCredentials(ctx context.Context, file, region string) (awsCredentials, error) {
+	role := s.getenv("AWS_ROLE_ARN")
+	if role == "" {
+		return awsCredentials{}, errors.New("AWS_WEB_IDENTITY_TOKEN_FILE is set without AWS_ROLE_ARN")
+	}
+	token, err := os.ReadFile(file)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("reading web identity token: %w", err)
+	}
+	session := s.getenv("AWS_ROLE_SESSION_NAME")
+	if session == "" {
+		session = "epack-collector-github"
+	}
+	endpoint := s.opts.stsEndpoint
+	if endpoint == "" {
+		endpoint = "https://sts." + region + ".amazonaws.com/"
+	}
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {role},
+		"RoleSessionName":  {session},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	body, err := do(s.client, req, nil)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("web identity: %w", err)
+	}
+	var reply struct {
+		Credentials struct {
+			AccessKeyID     string `xml:"AccessKeyId"`
+			SecretAccessKey string `xml:"SecretAccessKey"`
+			SessionToken    string `xml:"SessionToken"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.Unmarshal(body, &reply); err != nil {
+		return awsCredentials{}, fmt.Errorf("web identity: decoding reply: %w", err)
+	}
+	creds := awsCredentials(reply.Credentials)
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return awsCredentials{}, errors.New("web identity: reply has no credentials")
+	}
+	return creds, nil
+}
+
+// instanceCredentials reads the EC2 instance profile's credentials through
+// IMDSv2.
+func (s *Store) instanceCredentials(ctx context.Context) (awsCredentials, error) {
+	base := s.opts.imdsURL
+	if base == "" {
+		base = awsIMDSURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, base+"/latest/api/token", nil)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	req.Header.Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "300")
+	token, err := do(s.metadata, req, nil)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("no credentials: set AWS_ACCESS_KEY_ID or run with a task or instance role (instance metadata: %v)", err)
+	}
+
+	get := func(path string) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/latest/meta-data/iam/security-credentials/"+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-Aws-Ec2-Metadata-Token", string(token))
+		return req, nil
+	}
+	req, err = get("")
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	roles, err := do(s.metadata, req, nil)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("instance profile: %w", err)
+	}
+	role, _, _ := strings.Cut(strings.TrimSpace(string(roles)), "\n")
+	if role == "" {
+		return awsCredentials{}, errors.New("instance profile: the instance has no role")
+	}
+	if req, err = get(role); err != nil {
+		return awsCredentials{}, err
+	}
+	return fetchAWSCredentials(s.metadata, req, "instance profile")
+}
+
+// fetchAWSCredentials reads the credentials a metadata service returns.
+func fetchAWSCredentials(client *http.Client, req *http.Request, source string) (awsCredentials, error) {
+	var creds awsCredentials
+	if _, err := do(client, req, &creds); err != nil {
+		return awsCredentials{}, fmt.Errorf("%s: %w", source, err)
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return awsCredentials{}, fmt.Errorf("%s: reply has no credentials", source)
+	}
+	return creds, nil
+}
+
+// signV4 signs req, whose body is body, with AWS Signature Version 4. Every
+// header already set is signed, with the host.
+func signV4(req *http.Request, body []byte, creds awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := slices.Sorted(maps.Keys(headers))
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonical := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hexSHA256(body),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(canonical))
+	key := []byte("AWS4" + creds.SecretAccessKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, toSign))))
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,302 @@
+package secretstore
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Default GCP endpoints.
+const (
+	gcpSecretManagerURL = "https://secretmanager.googleapis.com"
+	gcpMetadataHost     = "metadata.google.internal"
+	gcpOAuthTokenURL    = "https://oauth2.googleapis.com/token"
+)
+
+// gcpScope is the OAuth scope requested for Secret Manager.
+const gcpScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// gcpCredentialsFile is the subset of an application default credentials
+// file the collector reads: a service account key, a gcloud user login, or
+// a workload identity federation configuration.
+type gcpCredentialsFile struct {
+	Type string `json:"type"`
+
+	// service_account
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	PrivateKeyID string `json:"private_key_id"`
+	TokenURI     string `json:"token_uri"`
+
+	// authorized_user
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+
+	// external_account
+	Audience                       string `json:"audience"`
+	SubjectTokenType               string `json:"subject_token_type"`
+	TokenURL                       string `json:"token_url"`
+	ServiceAccountImpersonationURL string `json:"service_account_impersonation_url"`
+	CredentialSource               struct {
+		File          string            `json:"file"`
+		URL           string            `json:"url"`
+		Headers       map[string]string `json:"headers"`
+		EnvironmentID string            `json:"environment_id"`
+		Executable    json.RawMessage   `json:"executable"`
+		Format        struct {
+			Type                  string `json:"type"`
+			SubjectTokenFieldName string `json:"subject_token_field_name"`
+		} `json:"format"`
+	} `json:"credential_source"`
+}
+
+// gcp reads the secret version named name, a Secret Manager resource name
+// (projects/PROJECT/secrets/SECRET, optionally with /versions/VERSION; the
+// latest version by default). The access token is GOOGLE_OAUTH_ACCESS_TOKEN,
+// else one for the GOOGLE_APPLICATION_CREDENTIALS file, else the attached
+// service account's, from the metadata server (GCE, GKE Workload Identity,
+// Cloud Run).
+func (s *Store) gcp(ctx context.Context, name string) ([]byte, error) {
+	if !strings.HasPrefix(name, "projects/") {
+		return nil, errors.New("secret name must be projects/PROJECT/secrets/SECRET")
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	token := s.getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	if token == "" {
+		var err error
+		if file := s.getenv("GOOGLE_APPLICATION_CREDENTIALS"); file != "" {
+			token, err = s.gcpFileToken(ctx, file)
+		} else {
+			token, err = s.gcpMetadataToken(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := s.opts.gcpEndpoint
+	if endpoint == "" {
+		endpoint = gcpSecretManagerURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/v1/"+name+":access", nil)
+	if err != nil {
+		return nil, errors.New("invalid secret name")
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var reply struct {
+		Payload struct {
+			Data []byte `json:"data"`
+		} `json:"payload"`
+	}
+	if _, err := do(s.client, req, &reply); err != nil {
+		return nil, err
+	}
+	return reply.Payload.Data, nil
+}
+
+// gcpMetadataToken returns the attached service account's access token.
+// GCE_METADATA_HOST overrides the metadata server's address.
+func (s *Store) gcpMetadataToken(ctx context.Context) (string, error) {
+	host := s.getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = gcpMetadataHost
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", errors.New("invalid GCE_METADATA_HOST")
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var reply struct {
+		AccessToken string `json:"access_token"`
+	}
+	if _, err := do(s.metadata, req, &reply); err != nil {
+		return "", fmt.Errorf("no credentials: set GOOGLE_OAUTH_ACCESS_TOKEN or run with an attached service account (metadata server: %v)", err)
+	}
+	if reply.AccessToken == "" {
+		return "", errors.New("metadata server: reply has no access token")
+	}
+	return reply.AccessToken, nil
+}
+
+// gcpFileToken returns an access token for the application default
+// credentials in file: a service account key (signed JWT grant), a gcloud
+// user login (refresh token grant), or a workload identity federation
+// configuration whose subject token is read from a file or URL (token
+// exchange, then service account impersonation when configured).
+func (s *Store) gcpFileToken(ctx context.Context, file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("reading GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+	}
+	var creds gcpCredentialsFile
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return "", fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS: %w", err)
+	}
+	switch creds.Type {
+	case "service_account":
+		return s.gcpServiceAccountToken(ctx, creds)
+	case "authorized_user":
+		return s.gcpOAuthToken(ctx, gcpOAuthTokenURL, url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {creds.ClientID},
+			"client_secret": {creds.ClientSecret},
+			"refresh_token": {creds.RefreshToken},
+		})
+	case "external_account":
+		return s.gcpExternalAccountToken(ctx, creds)
+	}
+	return "", fmt.Errorf("GOOGLE_APPLICATION_CREDENTIALS: unsupported credential type %q", creds.Type)
+}
+
+// gcpServiceAccountToken exchanges a JWT signed with the service account's
+// key for an access token.
+func (s *Store) gcpServiceAccountToken(ctx context.Context, creds gcpCredentialsFile) (string, error) {
+	block, _ := pem.Decode([]byte(creds.PrivateKey))
+	if block == nil {
+		return "", errors.New("service account key: private_key is not PEM")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("service account key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("service account key: private_key is not an RSA key")
+	}
+	tokenURI := creds.TokenURI
+	if tokenURI == "" {
+		tokenURI = gcpOAuthTokenURL
+	}
+	now := s.opts.now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": creds.PrivateKeyID})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   creds.ClientEmail,
+		"scope": gcpScope,
+		"aud":   tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Unix() + 3600,
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("service account key: %w", err)
+	}
+	return s.gcpOAuthToken(ctx, tokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + base64.RawURLEncoding.EncodeToString(signature)},
+	})
+}
+
+// gcpExternalAccountToken exchanges a workload identity federation subject
+// token (a CI job's or another cloud's OIDC token) for a federated access
+// token through STS, then for the impersonated service account's token when
+// service_account_impersonation_url is set.
+func (s *Store) gcpExternalAccountToken(ctx context.Context, creds gcpCredentialsFile) (string, error) {
+	source := creds.CredentialSource
+	if source.EnvironmentID != "" || len(source.Executable) > 0 {
+		return "", errors.New("external account: only file and URL credential sources are supported")
+	}
+	var subject []byte
+	var err error
+	switch {
+	case source.File != "":
+		if subject, err = os.ReadFile(source.File); err != nil {
+			return "", fmt.Errorf("external account: reading subject token: %w", err)
+		}
+	case source.URL != "":
+		// URL sources are local metadata endpoints (Azure IMDS and the like).
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, source.URL, nil)
+		if err != nil {
+			return "", errors.New("external account: invalid credential_source url")
+		}
+		for name, value := range source.Headers {
+			req.Header.Set(name, value)
+		}
+		if subject, err = do(s.metadata, req, nil); err != nil {
+			return "", fmt.Errorf("external account: subject token: %w", err)
+		}
+	default:
+		return "", errors.New("external account: credential_source has no file or url")
+	}
+	token := strings.TrimSpace(string(subject))
+	if source.Format.Type == "json" {
+		var fields map[string]any
+		if err := json.Unmarshal(subject, &fields); err != nil {
+			return "", fmt.Errorf("external account: subject token is not JSON: %w", err)
+		}
+		if token, _ = fields[source.Format.SubjectTokenFieldName].(string); token == "" {
+			return "", fmt.Errorf("external account: subject token has no field %q", source.Format.SubjectTokenFieldName)
+		}
+	}
+
+	tokenURL := creds.TokenURL
+	if tokenURL == "" {
+		tokenURL = "https://sts.googleapis.com/v1/token"
+	}
+	federated, err := s.gcpOAuthToken(ctx, tokenURL, url.Values{
+		"grant_type":           {"urn:ietf:params:oauth:grant-type:token-exchange"},
+		"audience":             {creds.Audience},
+		"scope":                {gcpScope},
+		"requested_token_type": {"urn:ietf:params:oauth:token-type:access_token"},
+		"subject_token_type":   {creds.SubjectTokenType},
+		"subject_token":        {token},
+	})
+	if err != nil || creds.ServiceAccountImpersonationURL == "" {
+		return federated, err
+	}
+
+	body, _ := json.Marshal(map[string][]string{"scope": {gcpScope}})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, creds.ServiceAccountImpersonationURL, bytes.NewReader(body))
+	if err != nil {
+		return "", errors.New("external account: invalid service_account_impersonation_url")
+	}
+	req.Header.Set("Authorization", "Bearer "+federated)
+	req.Header.Set("Content-Type", "application/json")
+	var reply struct {
+		AccessToken string `json:"accessToken"`
+	}
+	if _, err := do(s.client, req, &reply); err != nil {
+		return "", fmt.Errorf("service account impersonation: %w", err)
+	}
+	if reply.AccessToken == "" {
+		return "", errors.New("service account impersonation: reply has no access token")
+	}
+	return reply.AccessToken, nil
+}
+
+// gcpOAuthToken posts a token grant to endpoint and returns the access
+// token.
+func (s *Store) gcpOAuthToken(ctx context.Context, endpoint string, form url.Values) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", errors.New("invalid token URL")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var reply struct {
+		AccessToken string `json:"access_token"`
+	}
+	if _, err := do(s.client, req, &reply); err != nil {
+		return "", fmt.Errorf("token grant: %w", err)
+	}
+	if reply.AccessToken == "" {
+		return "", errors.New("token grant: reply has no access token")
+	}
+	return reply.AccessToken, nil
+}
@@ -0,0 +1,219 @@
+// Package secretstore reads credentials from an external secret manager
+// (HashiCorp Vault, AWS Secrets Manager, or GCP Secret Manager), so
+// long-lived secrets don't have to pass through the runner's environment.
+package secretstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Supported providers.
+const (
+	ProviderVault = "vault"
+	ProviderAWS   = "aws"
+	ProviderGCP   = "gcp"
+)
+
+// Providers lists the supported providers.
+var Providers = []string{ProviderVault, ProviderAWS, ProviderGCP}
+
+// Vault auth methods.
+const (
+	VaultAuthToken      = "token"
+	VaultAuthKubernetes = "kubernetes"
+	VaultAuthAppRole    = "approle"
+	VaultAuthJWT        = "jwt"
+)
+
+// VaultAuthMethods lists the supported Vault auth methods.
+var VaultAuthMethods = []string{VaultAuthToken, VaultAuthKubernetes, VaultAuthAppRole, VaultAuthJWT}
+
+// maxReplyBytes bounds a reply from a secret manager or metadata service.
+const maxReplyBytes = 1 << 20
+
+// metadataTimeout bounds one request to a cloud metadata service, which
+// doesn't answer at all off the cloud it belongs to.
+const metadataTimeout = 5 * time.Second
+
+// Options configures a Store.
+type Options struct {
+	// Provider is ProviderVault, ProviderAWS, or ProviderGCP.
+	Provider string
+
+	// VaultAddress is the Vault server URL; empty uses VAULT_ADDR.
+	VaultAddress string
+	// VaultToken authenticates to Vault with the token auth method.
+	VaultToken string
+	// VaultAuthMethod is how the Store logs in to Vault, one of
+	// VaultAuthMethods; empty is VaultAuthToken.
+	VaultAuthMethod string
+	// VaultAuthMount is the auth method's mount path; empty is the method's
+	// name.
+	VaultAuthMount string
+	// VaultRole is the role to log in as: the Kubernetes or JWT role, or
+	// the AppRole role ID.
+	VaultRole string
+	// VaultSecretID is the AppRole secret ID.
+	VaultSecretID string
+	// VaultJWTPath is the file holding the JWT presented to the Kubernetes
+	// or JWT method; empty is the pod's service account token for
+	// Kubernetes.
+	VaultJWTPath string
+
+	// Client sends the secret manager requests; nil uses http.DefaultClient.
+	// Metadata service requests (cloud credentials) never use a proxy.
+	Client *http.Client
+	// Getenv reads the provider's environment (VAULT_ADDR, AWS_REGION,
+	// credentials, and the like); nil uses os.Getenv.
+	Getenv func(string) string
+
+	// Endpoint overrides, for tests.
+	awsEndpoint string
+	stsEndpoint string
+	gcpEndpoint string
+	imdsURL     string
+	now         func() time.Time
+}
+
+// Store reads secrets from one provider.
+type Store struct {
+	opts     Options
+	client   *http.Client
+	metadata *http.Client
+	getenv   func(string) string
+
+	// vaultToken is the token a Vault login returned, reused for the
+	// Store's later reads.
+	vaultToken string
+}
+
+// New returns a Store for opts.Provider.
+func New(opts Options) (*Store, error) {
+	if !slices.Contains(Providers, opts.Provider) {
+		return nil, fmt.Errorf("unknown secret store %q (want %s)", opts.Provider, strings.Join(Providers, ", "))
+	}
+	if opts.VaultAuthMethod != "" && !slices.Contains(VaultAuthMethods, opts.VaultAuthMethod) {
+		return nil, fmt.Errorf("unknown Vault auth method %q (want %s)", opts.VaultAuthMethod, strings.Join(VaultAuthMethods, ", "))
+	}
+	s := &Store{
+		opts:     opts,
+		client:   opts.Client,
+		metadata: &http.Client{Transport: &http.Transport{}, Timeout: metadataTimeout},
+		getenv:   opts.Getenv,
+	}
+	if s.client == nil {
+		s.client = http.DefaultClient
+	}
+	if s.getenv == nil {
+		s.getenv = os.Getenv
+	}
+	if s.opts.now == nil {
+		s.opts.now = time.Now
+	}
+	return s, nil
+}
+
+// Get returns the secret named by ref: a Vault API path, a Secrets Manager
+// secret name or ARN, or a Secret Manager resource name, by provider. A
+// "#field" suffix selects a field of a secret holding a JSON object. A Vault
+// secret with a single field needs no suffix.
+func (s *Store) Get(ctx context.Context, ref string) (string, error) {
+	name, field, _ := strings.Cut(ref, "#")
+	if name == "" {
+		return "", errors.New("secret reference is empty")
+	}
+	var (
+		value []byte
+		err   error
+	)
+	switch s.opts.Provider {
+	case ProviderVault:
+		value, err = s.vault(ctx, name)
+		if err == nil && field == "" {
+			// Vault secrets are always objects.
+			return onlyField(value)
+		}
+	case ProviderAWS:
+		value, err = s.aws(ctx, name)
+	case ProviderGCP:
+		value, err = s.gcp(ctx, name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", s.opts.Provider, err)
+	}
+	if field == "" {
+		return strings.TrimSpace(string(value)), nil
+	}
+	return selectField(value, field)
+}
+
+// selectField returns the string field of a JSON object.
+func selectField(value []byte, field string) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return "", fmt.Errorf("selecting field %q: the secret is not a JSON object", field)
+	}
+	v, ok := fields[field].(string)
+	if !ok {
+		return "", fmt.Errorf("the secret has no string field %q", field)
+	}
+	return v, nil
+}
+
+// onlyField returns the value of a JSON object's one string field.
+func onlyField(value []byte) (string, error) {
+	var fields map[string]any
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return "", err
+	}
+	if len(fields) != 1 {
+		return "", fmt.Errorf("the secret has fields %s; select one with #field", strings.Join(slices.Sorted(maps.Keys(fields)), ", "))
+	}
+	for name, v := range fields {
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+		return "", fmt.Errorf("field %q is not a string", name)
+	}
+	return "", nil
+}
+
+// do sends req with client and decodes a JSON reply into out, or returns the
+// reply body as is when out is nil. Errors omit the reply and request URL,
+// which can carry secret names.
+func do(client *http.Client, req *http.Request, out any) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxReplyBytes))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	if out == nil {
+		return body, nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return nil, fmt.Errorf("decoding reply from %s: %w", req.URL.Host, err)
+	}
+	return body, nil
+}
@@ -0,0 +1,413 @@
+package secretstore
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env returns a Getenv over vars.
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestNew_UnknownProvider(t *testing.T) {
+	if _, err := New(Options{Provider: "keychain"}); err == nil {
+		t.Error("New() with an unknown provider should fail")
+	}
+}
+
+func TestVault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-tok" || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/github": // KV version 2
+			_, _ = io.WriteString(w, `{"data": {"data": {"token": "ghs_kv2", "private_key": "PEM"}, "metadata": {"version": 3}}}`)
+		case "/v1/kv/github": // KV version 1
+			_, _ = io.WriteString(w, `{"data": {"token": "ghs_kv1"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	store, err := New(Options{Provider: ProviderVault, VaultToken: "vault-tok", Getenv: env(map[string]string{"VAULT_ADDR": server.URL, "VAULT_NAMESPACE": "team"})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if got, err := store.Get(ctx, "secret/data/github#token"); err != nil || got != "ghs_kv2" {
+		t.Errorf("Get(KV v2 #token) = %q, %v", got, err)
+	}
+	if got, err := store.Get(ctx, "kv/github"); err != nil || got != "ghs_kv1" {
+		t.Errorf("Get(KV v1, one field) = %q, %v", got, err)
+	}
+	if _, err := store.Get(ctx, "secret/data/github"); err == nil || !strings.Contains(err.Error(), "private_key, token") {
+		t.Errorf("Get() of two fields without #field error = %v", err)
+	}
+	if _, err := store.Get(ctx, "secret/data/missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Get() of a missing path error = %v", err)
+	}
+
+	noToken, _ := New(Options{Provider: ProviderVault, VaultAddress: server.URL})
+	if _, err := noToken.Get(ctx, "kv/github"); err == nil {
+		t.Error("Get() without VAULT_TOKEN should fail")
+	}
+}
+
+func TestVault_Login(t *testing.T) {
+	var logins []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/k8s-prod/login", "/v1/auth/approle/login":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			logins = append(logins, body)
+			_, _ = io.WriteString(w, `{"auth": {"client_token": "hvs.short"}}`)
+		case "/v1/kv/github":
+			if r.Header.Get("X-Vault-Token") != "hvs.short" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = io.WriteString(w, `{"data": {"token": "ghs_kv1"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	jwtPath := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(jwtPath, []byte("eyJ.pod.jwt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store, err := New(Options{Provider: ProviderVault, VaultAddress: server.URL, VaultAuthMethod: VaultAuthKubernetes, VaultAuthMount: "k8s-prod", VaultRole: "collector", VaultJWTPath: jwtPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two reads share one login.
+	for range 2 {
+		if got, err := store.Get(ctx, "kv/github"); err != nil || got != "ghs_kv1" {
+			t.Fatalf("Get() after a Kubernetes login = %q, %v", got, err)
+		}
+	}
+	if len(logins) != 1 || logins[0]["role"] != "collector" || logins[0]["jwt"] != "eyJ.pod.jwt" {
+		t.Errorf("Kubernetes logins = %v", logins)
+	}
+
+	store, _ = New(Options{Provider: ProviderVault, VaultAddress: server.URL, VaultAuthMethod: VaultAuthAppRole, VaultRole: "role-id", VaultSecretID: "secret-id"})
+	if got, err := store.Get(ctx, "kv/github"); err != nil || got != "ghs_kv1" {
+		t.Fatalf("Get() after an AppRole login = %q, %v", got, err)
+	}
+	if last := logins[len(logins)-1]; last["role_id"] != "role-id" || last["secret_id"] != "secret-id" {
+		t.Errorf("AppRole login = %v", last)
+	}
+
+	for _, opts := range []Options{
+		{VaultAuthMethod: VaultAuthJWT, VaultRole: "collector"},   // no JWT file
+		{VaultAuthMethod: VaultAuthAppRole, VaultRole: "role-id"}, // no secret ID
+		{VaultAuthMethod: VaultAuthKubernetes},                    // no role
+	} {
+		opts.Provider, opts.VaultAddress = ProviderVault, server.URL
+		store, _ := New(opts)
+		if _, err := store.Get(ctx, "kv/github"); err == nil {
+			t.Errorf("Get() with %+v should fail", opts)
+		}
+	}
+	if _, err := New(Options{Provider: ProviderVault, VaultAuthMethod: "ldap"}); err == nil {
+		t.Error("New() with an unknown Vault auth method should fail")
+	}
+}
+
+func TestAWS(t *testing.T) {
+	var gotAuth, gotTarget, gotSession string
+	var gotBody map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotTarget = r.Header.Get("X-Amz-Target")
+		gotSession = r.Header.Get("X-Amz-Security-Token")
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		if gotBody["SecretId"] == "binary" {
+			_, _ = io.WriteString(w, `{"SecretBinary": "UEVN"}`)
+			return
+		}
+		_, _ = io.WriteString(w, `{"SecretString": "{\"token\": \"ghs_aws\"}"}`)
+	}))
+	defer server.Close()
+
+	store, _ := New(Options{Provider: ProviderAWS, Getenv: env(map[string]string{
+		"AWS_ACCESS_KEY_ID":     "AKIDEXAMPLE",
+		"AWS_SECRET_ACCESS_KEY": "secret",
+		"AWS_SESSION_TOKEN":     "session",
+	})})
+	store.opts.awsEndpoint = server.URL + "/"
+	store.opts.now = func() time.Time { return time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC) }
+
+	arn := "arn:aws:secretsmanager:eu-west-1:123456789012:secret:github-AbCdEf"
+	if got, err := store.Get(context.Background(), arn+"#token"); err != nil || got != "ghs_aws" {
+		t.Fatalf("Get() = %q, %v", got, err)
+	}
+	if gotBody["SecretId"] != arn || gotTarget != "secretsmanager.GetSecretValue" || gotSession != "session" {
+		t.Errorf("request body %v, target %q, session %q", gotBody, gotTarget, gotSession)
+	}
+	if !strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20260302/eu-west-1/secretsmanager/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature=") {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+
+	// A name without an ARN needs the region from the environment.
+	if _, err := store.Get(context.Background(), "binary"); err == nil || !strings.Contains(err.Error(), "no region") {
+		t.Errorf("Get() without a region error = %v", err)
+	}
+	store.getenv = env(map[string]string{"AWS_REGION": "us-east-1", "AWS_ACCESS_KEY_ID": "AKIDEXAMPLE", "AWS_SECRET_ACCESS_KEY": "secret"})
+	if got, err := store.Get(context.Background(), "binary"); err != nil || got != "PEM" {
+		t.Errorf("Get() of a binary secret = %q, %v", got, err)
+	}
+}
+
+func TestAWSCredentials_Metadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/task-creds" && r.Header.Get("Authorization") == "pod-tok":
+			_, _ = io.WriteString(w, `{"AccessKeyId": "ASIATASK", "SecretAccessKey": "s", "Token": "t"}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+			_, _ = io.WriteString(w, "imds-tok")
+		case r.Header.Get("X-Aws-Ec2-Metadata-Token") != "imds-tok":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/":
+			_, _ = io.WriteString(w, "collector-role\n")
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/collector-role":
+			_, _ = io.WriteString(w, `{"AccessKeyId": "ASIAINSTANCE", "SecretAccessKey": "s", "Token": "t"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	ctx := context.Background()
+
+	store, _ := New(Options{Provider: ProviderAWS, Getenv: env(map[string]string{
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI": server.URL + "/task-creds",
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN":  "pod-tok",
+	})})
+	if creds, err := store.awsCredentials(ctx, "us-east-1"); err != nil || creds.AccessKeyID != "ASIATASK" || creds.SessionToken != "t" {
+		t.Errorf("container credentials = %+v, %v", creds, err)
+	}
+
+	store, _ = New(Options{Provider: ProviderAWS, Getenv: env(nil)})
+	store.opts.imdsURL = server.URL
+	if creds, err := store.awsCredentials(ctx, "us-east-1"); err != nil || creds.AccessKeyID != "ASIAINSTANCE" {
+		t.Errorf("instance credentials = %+v, %v", creds, err)
+	}
+
+	store.getenv = env(map[string]string{"AWS_EC2_METADATA_DISABLED": "true"})
+	if _, err := store.awsCredentials(ctx, "us-east-1"); err == nil {
+		t.Error("awsCredentials() with the metadata service disabled should fail")
+	}
+}
+
+func TestAWSCredentials_WebIdentity(t *testing.T) {
+	var form map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parsing form: %v", err)
+		}
+		form = r.PostForm
+		if r.Header.Get("Authorization") != "" {
+			t.Error("AssumeRoleWithWebIdentity request is signed")
+		}
+		_, _ = io.WriteString(w, `<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>ASIAWEB</AccessKeyId>
+      <SecretAccessKey>s</SecretAccessKey>
+      <SessionToken>t</SessionToken>
+      <Expiration>2026-03-02T01:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`)
+	}))
+	defer server.Close()
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("eyJ.irsa.jwt\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	store, _ := New(Options{Provider: ProviderAWS, Getenv: env(map[string]string{
+		"AWS_WEB_IDENTITY_TOKEN_FILE": tokenFile,
+		"AWS_ROLE_ARN":                "arn:aws:iam::123456789012:role/collector",
+	})})
+	store.opts.stsEndpoint = server.URL + "/"
+	creds, err := store.awsCredentials(context.Background(), "eu-west-1")
+	if err != nil || creds != (awsCredentials{AccessKeyID: "ASIAWEB", SecretAccessKey: "s", SessionToken: "t"}) {
+		t.Fatalf("web identity credentials = %+v, %v", creds, err)
+	}
+	if form["Action"][0] != "AssumeRoleWithWebIdentity" || form["WebIdentityToken"][0] != "eyJ.irsa.jwt" ||
+		form["RoleArn"][0] != "arn:aws:iam::123456789012:role/collector" || form["RoleSessionName"][0] != "epack-collector-github" {
+		t.Errorf("STS form = %v", form)
+	}
+
+	store.getenv = env(map[string]string{"AWS_WEB_IDENTITY_TOKEN_FILE": tokenFile})
+	if _, err := store.awsCredentials(context.Background(), "eu-west-1"); err == nil || !strings.Contains(err.Error(), "AWS_ROLE_ARN") {
+		t.Errorf("web identity without a role error = %v", err)
+	}
+}
+
+// TestSignV4 checks the signature of the example request in the AWS
+// Signature Version 4 documentation.
+func TestSignV4(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header = http.Header{}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, nil, creds, "us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGCP(t *testing.T) {
+	var gotPath string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/computeMetadata/v1/instance/service-accounts/default/token" {
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = io.WriteString(w, `{"access_token": "ya29.metadata"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer ya29.metadata" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		gotPath = r.URL.Path
+		_, _ = io.WriteString(w, `{"payload": {"data": "Z2hzX2djcAo="}}`) // "ghs_gcp\n"
+	}))
+	defer server.Close()
+
+	store, _ := New(Options{Provider: ProviderGCP, Getenv: env(map[string]string{"GCE_METADATA_HOST": strings.TrimPrefix(server.URL, "http://")})})
+	store.opts.gcpEndpoint = server.URL
+	if got, err := store.Get(context.Background(), "projects/acme/secrets/github-token"); err != nil || got != "ghs_gcp" {
+		t.Fatalf("Get() = %q, %v", got, err)
+	}
+	if gotPath != "/v1/projects/acme/secrets/github-token/versions/latest:access" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if _, err := store.Get(context.Background(), "github-token"); err == nil {
+		t.Error("Get() of a short name should fail")
+	}
+}
+
+func TestGCP_CredentialsFile(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var grants []map[string][]string
+	var impersonationAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token", "/sts":
+			_ = r.ParseForm()
+			grants = append(grants, r.PostForm)
+			_, _ = io.WriteString(w, `{"access_token": "ya29.grant"}`)
+		case "/impersonate":
+			impersonationAuth = r.Header.Get("Authorization")
+			_, _ = io.WriteString(w, `{"accessToken": "ya29.impersonated"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	write := func(name string, v any) string {
+		t.Helper()
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	ctx := context.Background()
+	store, _ := New(Options{Provider: ProviderGCP})
+
+	serviceAccount := write("sa.json", map[string]string{
+		"type":         "service_account",
+		"client_email": "collector@acme.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    server.URL + "/token",
+	})
+	if token, err := store.gcpFileToken(ctx, serviceAccount); err != nil || token != "ya29.grant" {
+		t.Fatalf("service account token = %q, %v", token, err)
+	}
+	if got := grants[0]; got["grant_type"][0] != "urn:ietf:params:oauth:grant-type:jwt-bearer" || strings.Count(got["assertion"][0], ".") != 2 {
+		t.Errorf("service account grant = %v", got)
+	}
+
+	subject := filepath.Join(dir, "oidc")
+	if err := os.WriteFile(subject, []byte(`{"value": "eyJ.ci.jwt"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	federation := map[string]any{
+		"type":                              "external_account",
+		"audience":                          "//iam.googleapis.com/projects/1/locations/global/workloadIdentityPools/ci/providers/github",
+		"subject_token_type":                "urn:ietf:params:oauth:token-type:jwt",
+		"token_url":                         server.URL + "/sts",
+		"service_account_impersonation_url": server.URL + "/impersonate",
+		"credential_source": map[string]any{
+			"file":   subject,
+			"format": map[string]string{"type": "json", "subject_token_field_name": "value"},
+		},
+	}
+	if token, err := store.gcpFileToken(ctx, write("wif.json", federation)); err != nil || token != "ya29.impersonated" {
+		t.Fatalf("workload identity federation token = %q, %v", token, err)
+	}
+	if got := grants[1]; got["subject_token"][0] != "eyJ.ci.jwt" || got["grant_type"][0] != "urn:ietf:params:oauth:grant-type:token-exchange" {
+		t.Errorf("token exchange = %v", got)
+	}
+	if impersonationAuth != "Bearer ya29.grant" {
+		t.Errorf("impersonation Authorization = %q", impersonationAuth)
+	}
+
+	federation["credential_source"] = map[string]any{"environment_id": "aws1"}
+	if _, err := store.gcpFileToken(ctx, write("aws.json", federation)); err == nil {
+		t.Error("an AWS-sourced external account should be unsupported")
+	}
+}
+
+func TestSelectField(t *testing.T) {
+	if got, err := selectField([]byte(`{"token": "a", "n": 1}`), "token"); err != nil || got != "a" {
+		t.Errorf("selectField() = %q, %v", got, err)
+	}
+	for _, field := range []string{"n", "missing"} {
+		if _, err := selectField([]byte(`{"token": "a", "n": 1}`), field); err == nil {
+			t.Errorf("selectField(%q) should fail", field)
+		}
+	}
+	if _, err := selectField([]byte("plain"), "token"); err == nil {
+		t.Error("selectField() of a non-object should fail")
+	}
+}
@@ -0,0 +1,130 @@
+package secretstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// vaultServiceAccountToken is the pod's projected service account token,
+// the Kubernetes auth method's default JWT.
+const vaultServiceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// vault reads the secret at path, an API path under /v1/ such as
+// "secret/data/github" (KV version 2) or "kv/github" (version 1), returning
+// its fields as a JSON object.
+func (s *Store) vault(ctx context.Context, path string) ([]byte, error) {
+	addr := s.opts.VaultAddress
+	if addr == "" {
+		addr = s.getenv("VAULT_ADDR")
+	}
+	if addr == "" {
+		return nil, errors.New("no address: set vault_address or VAULT_ADDR")
+	}
+	addr = strings.TrimSuffix(addr, "/")
+	token, err := s.vaultLogin(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, errors.New("invalid address")
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := s.getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	var reply struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if _, err := do(s.client, req, &reply); err != nil {
+		return nil, err
+	}
+	if reply.Data == nil {
+		return nil, errors.New("reply has no data")
+	}
+	// KV version 2 nests the fields under data.data, beside their metadata.
+	if inner, ok := reply.Data["data"]; ok {
+		if _, ok := reply.Data["metadata"]; ok {
+			return inner, nil
+		}
+	}
+	return json.Marshal(reply.Data)
+}
+
+// vaultLogin returns the token reading the secrets: VAULT_TOKEN with the
+// token auth method, or else the client token a login to the configured
+// method returns. A pod's service account token (Kubernetes), a CI job's
+// OIDC token (JWT), or an AppRole secret ID is exchanged for a short-lived
+// token, so no long-lived Vault token is needed.
+func (s *Store) vaultLogin(ctx context.Context, addr string) (string, error) {
+	method := s.opts.VaultAuthMethod
+	if method == "" || method == VaultAuthToken {
+		if s.opts.VaultToken == "" {
+			return "", errors.New("VAULT_TOKEN is not set")
+		}
+		return s.opts.VaultToken, nil
+	}
+	if s.vaultToken != "" {
+		return s.vaultToken, nil
+	}
+	if s.opts.VaultRole == "" {
+		return "", fmt.Errorf("the %s auth method requires vault_role", method)
+	}
+	var login map[string]string
+	switch method {
+	case VaultAuthAppRole:
+		if s.opts.VaultSecretID == "" {
+			return "", errors.New("the approle auth method requires the VAULT_SECRET_ID secret")
+		}
+		login = map[string]string{"role_id": s.opts.VaultRole, "secret_id": s.opts.VaultSecretID}
+	case VaultAuthKubernetes, VaultAuthJWT:
+		path := s.opts.VaultJWTPath
+		if path == "" && method == VaultAuthKubernetes {
+			path = vaultServiceAccountToken
+		}
+		if path == "" {
+			return "", errors.New("the jwt auth method requires vault_jwt_path")
+		}
+		jwt, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading the %s login token: %w", method, err)
+		}
+		login = map[string]string{"role": s.opts.VaultRole, "jwt": strings.TrimSpace(string(jwt))}
+	}
+
+	mount := s.opts.VaultAuthMount
+	if mount == "" {
+		mount = method
+	}
+	body, err := json.Marshal(login)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, addr+"/v1/auth/"+strings.Trim(mount, "/")+"/login", bytes.NewReader(body))
+	if err != nil {
+		return "", errors.New("invalid address")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ns := s.getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+	var reply struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if _, err := do(s.client, req, &reply); err != nil {
+		return "", fmt.Errorf("%s login: %w", method, err)
+	}
+	if reply.Auth.ClientToken == "" {
+		return "", fmt.Errorf("%s login: reply has no client token", method)
+	}
+	s.vaultToken = reply.Auth.ClientToken
+	return s.vaultToken, nil
+}
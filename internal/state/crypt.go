@@ -0,0 +1,125 @@
+package state
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// KeySize is the length of a state encryption key: AES-256.
+const KeySize = 32
+
+// encryptedMagic starts every encrypted state file, followed by the key ID,
+// the nonce, and the AES-GCM sealed contents.
+var encryptedMagic = []byte("epack-state-v1\x00")
+
+// keyIDSize is how many bytes of a key's SHA-256 identify it in a file, so
+// the right key is picked without trying each.
+const keyIDSize = 8
+
+// ErrNoKey is returned when reading an encrypted state file without a key
+// that decrypts it.
+var ErrNoKey = errors.New("state file is encrypted with a key not configured")
+
+// ParseKey decodes a base64-encoded KeySize-byte state encryption key.
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("state encryption key: not base64: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("state encryption key: want %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// stateKey is one usable key.
+type stateKey struct {
+	id   []byte
+	aead cipher.AEAD
+}
+
+func newStateKey(key []byte) (stateKey, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return stateKey{}, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return stateKey{}, err
+	}
+	sum := sha256.Sum256(key)
+	return stateKey{id: sum[:keyIDSize], aead: aead}, nil
+}
+
+// OpenEncrypted returns a store in dir, like Open, whose files are encrypted
+// with key (AES-256-GCM). Files encrypted with one of the previous keys, or
+// written unencrypted before encryption was turned on, are still read, and
+// rewritten with key as they are: rotating keys is running once with the new
+// key current and the old one previous.
+func OpenEncrypted(dir string, key []byte, previous ...[]byte) (*Store, error) {
+	s, err := Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range append([][]byte{key}, previous...) {
+		sk, err := newStateKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("state encryption key: %w", err)
+		}
+		s.keys = append(s.keys, sk)
+	}
+	return s, nil
+}
+
+// seal encrypts a file's contents with the current key, binding them to the
+// file's name so they can't be swapped for another account's.
+func (s *Store) seal(path string, data []byte) ([]byte, error) {
+	if len(s.keys) == 0 {
+		return data, nil
+	}
+	current := s.keys[0]
+	nonce := make([]byte, current.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(bytes.Clone(encryptedMagic), current.id...)
+	out = append(out, nonce...)
+	return current.aead.Seal(out, nonce, data, []byte(filepath.Base(path))), nil
+}
+
+// open decrypts a file's contents. stale reports that they should be
+// rewritten: they were encrypted with a previous key, or not at all while
+// the store encrypts.
+func (s *Store) open(path string, data []byte) (plain []byte, stale bool, err error) {
+	rest, encrypted := bytes.CutPrefix(data, encryptedMagic)
+	if !encrypted {
+		return data, len(s.keys) > 0, nil
+	}
+	if len(rest) < keyIDSize {
+		return nil, false, fmt.Errorf("%s: truncated encrypted state", path)
+	}
+	id, rest := rest[:keyIDSize], rest[keyIDSize:]
+	for i, k := range s.keys {
+		if !bytes.Equal(k.id, id) {
+			continue
+		}
+		size := k.aead.NonceSize()
+		if len(rest) < size {
+			return nil, false, fmt.Errorf("%s: truncated encrypted state", path)
+		}
+		plain, err := k.aead.Open(nil, rest[:size], rest[size:], []byte(filepath.Base(path)))
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: decrypting state: %w", path, err)
+		}
+		return plain, i > 0, nil
+	}
+	return nil, false, fmt.Errorf("%s: %w", path, ErrNoKey)
+}
@@ -0,0 +1,109 @@
+package state
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func TestOpenEncrypted_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store, err := OpenEncrypted(dir, testKey(1))
+	if err != nil {
+		t.Fatalf("OpenEncrypted() error: %v", err)
+	}
+	snap := &Snapshot{Repositories: []Repository{{Name: "test-org/secret-project"}}}
+	if err := store.Save("test-org", snap); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, "test-org.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(raw, []byte("secret-project")) {
+		t.Error("snapshot stored in plaintext")
+	}
+	got, err := store.Load("test-org")
+	if err != nil || got == nil || got.Repositories[0].Name != "test-org/secret-project" {
+		t.Fatalf("Load() = %+v, %v", got, err)
+	}
+
+	// Without the key, or with another, the file can't be read.
+	plain, _ := Open(dir)
+	if _, err := plain.Load("test-org"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Load() without the key error = %v, want ErrNoKey", err)
+	}
+	other, _ := OpenEncrypted(dir, testKey(2))
+	if _, err := other.Load("test-org"); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Load() with another key error = %v, want ErrNoKey", err)
+	}
+
+	// Contents are bound to their file: another account's can't stand in.
+	if err := os.WriteFile(filepath.Join(dir, "other-org.json"), raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load("other-org"); err == nil {
+		t.Error("Load() of a file copied from another account should fail")
+	}
+}
+
+func TestOpenEncrypted_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	old, _ := OpenEncrypted(dir, testKey(1))
+	if err := old.SaveHistory("test-org", []MetricDay{{Date: "2026-03-01"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reading under the new key with the old one previous re-encrypts.
+	rotating, err := OpenEncrypted(dir, testKey(2), testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if days, err := rotating.LoadHistory("test-org"); err != nil || len(days) != 1 {
+		t.Fatalf("LoadHistory() during rotation = %v, %v", days, err)
+	}
+	rotated, _ := OpenEncrypted(dir, testKey(2))
+	if days, err := rotated.LoadHistory("test-org"); err != nil || len(days) != 1 {
+		t.Errorf("LoadHistory() after rotation = %v, %v", days, err)
+	}
+}
+
+func TestOpenEncrypted_MigratesPlaintext(t *testing.T) {
+	dir := t.TempDir()
+	plain, _ := Open(dir)
+	if err := plain.SavePosture("test-org", []byte(`{"organization":"test-org"}`)); err != nil {
+		t.Fatal(err)
+	}
+	store, _ := OpenEncrypted(dir, testKey(1))
+	if data, err := store.LoadPosture("test-org"); err != nil || string(data) != `{"organization":"test-org"}` {
+		t.Fatalf("LoadPosture() = %q, %v", data, err)
+	}
+	raw, _ := os.ReadFile(filepath.Join(dir, "test-org.posture.json"))
+	if !bytes.HasPrefix(raw, encryptedMagic) {
+		t.Error("plaintext file not encrypted once read")
+	}
+	if got := store.Unencrypted(); len(got) != 1 || got[0] != "test-org.posture.json" {
+		t.Errorf("Unencrypted() = %v, want the posture file", got)
+	}
+	if _, err := store.LoadPosture("test-org"); err != nil || len(store.Unencrypted()) != 1 {
+		t.Errorf("re-reading the encrypted file: %v, Unencrypted() = %v", err, store.Unencrypted())
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	if key, err := ParseKey(base64.StdEncoding.EncodeToString(testKey(7)) + "\n"); err != nil || !bytes.Equal(key, testKey(7)) {
+		t.Errorf("ParseKey() = %x, %v", key, err)
+	}
+	for _, bad := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := ParseKey(bad); err == nil {
+			t.Errorf("ParseKey(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrLocked is returned by Lock when another process holds the account's
+// lock: a run for the same account is already in progress.
+var ErrLocked = errors.New("state is locked by another run")
+
+// Lock is an account's exclusive lock on its state files, held from Lock
+// until Unlock.
+type Lock struct {
+	f *os.File
+}
+
+// Lock takes the account's exclusive lock, so overlapping runs sharing the
+// state directory (a cron run outlasting its interval, a batch repeating an
+// account) don't interleave their reads and writes of its files. It doesn't
+// wait: a lock held by another process is ErrLocked. The lock is advisory
+// (flock on Unix, LockFileEx on Windows) and released by the OS if the
+// process dies, so a crashed run never leaves it held.
+func (s *Store) Lock(account string) (*Lock, error) {
+	path, err := s.path(account)
+	if err != nil {
+		return nil, err
+	}
+	path = strings.TrimSuffix(path, ".json") + ".lock"
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening state lock: %w", err)
+	}
+	locked, err := tryLockFile(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("locking state: %w", err)
+	}
+	if !locked {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", path, ErrLocked)
+	}
+	return &Lock{f: f}, nil
+}
+
+// Unlock releases the lock. The lock file stays, so the next run locks the
+// same file rather than racing to create it.
+func (l *Lock) Unlock() error {
+	if err := unlockFile(l.f); err != nil {
+		_ = l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
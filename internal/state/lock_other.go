@@ -0,0 +1,11 @@
+//go:build !unix && !windows
+
+package state
+
+import "os"
+
+// Platforms without file locking (wasm, plan9) run unlocked: overlapping
+// runs there are the operator's to prevent.
+func tryLockFile(*os.File) (bool, error) { return true, nil }
+
+func unlockFile(*os.File) error { return nil }
@@ -0,0 +1,39 @@
+package state
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStore_Lock(t *testing.T) {
+	dir := t.TempDir()
+	first, _ := Open(dir)
+	second, _ := Open(dir)
+
+	lock, err := first.Lock("Test-Org")
+	if err != nil {
+		t.Fatalf("Lock() error: %v", err)
+	}
+	if _, err := second.Lock("test-org"); !errors.Is(err, ErrLocked) {
+		t.Errorf("second Lock() error = %v, want ErrLocked", err)
+	}
+	// Other accounts aren't held up.
+	other, err := second.Lock("other-org")
+	if err != nil {
+		t.Fatalf("Lock(other-org) error: %v", err)
+	}
+	_ = other.Unlock()
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock() error: %v", err)
+	}
+	again, err := second.Lock("test-org")
+	if err != nil {
+		t.Fatalf("Lock() after Unlock error: %v", err)
+	}
+	_ = again.Unlock()
+
+	if _, err := first.Lock("../etc"); err == nil {
+		t.Error("Lock() of an unsafe account name should fail")
+	}
+}
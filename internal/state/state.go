@@ -0,0 +1,331 @@
+// Package state persists what one collection run leaves for the next, so
+// runs can report what changed in between.
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Snapshot is the state one run persists for the next.
+type Snapshot struct {
+	CollectedAt  time.Time    `json:"collected_at"`
+	Repositories []Repository `json:"repositories"`
+}
+
+// Repository is one repository as seen by a run, archived or not and
+// regardless of include/exclude patterns.
+type Repository struct {
+	Name     string `json:"name"` // owner/name
+	Archived bool   `json:"archived,omitempty"`
+}
+
+// Store keeps one snapshot file per account in a directory.
+type Store struct {
+	dir string
+
+	// keys encrypt the files, the current key first (see OpenEncrypted);
+	// none leaves them plaintext.
+	keys []stateKey
+
+	// unencrypted names the files an encrypting store read in plaintext.
+	unencrypted []string
+}
+
+// validKey matches GitHub account logins, which name the snapshot files.
+var validKey = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// Open returns a store in dir, creating the directory (owner-only) if needed.
+func Open(dir string) (*Store, error) {
+	if dir == "" {
+		return nil, errors.New("state directory is empty")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("creating state directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// reservedNames are Windows device names, which can't name a file whatever
+// its extension. A login among them gets a prefixed file name on every
+// platform, so a state directory moves between runners intact.
+var reservedNames = regexp.MustCompile(`^(con|prn|aux|nul|com[1-9]|lpt[1-9])$`)
+
+// path returns the snapshot file for an account.
+func (s *Store) path(account string) (string, error) {
+	if !validKey.MatchString(account) {
+		return "", fmt.Errorf("invalid account name %q", account)
+	}
+	name := strings.ToLower(account)
+	if reservedNames.MatchString(name) {
+		name = "_" + name
+	}
+	return filepath.Join(s.dir, name+".json"), nil
+}
+
+// Load returns the account's last snapshot, or nil if none was saved.
+func (s *Store) Load(account string) (*Snapshot, error) {
+	path, err := s.path(account)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading snapshot: %w", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, fmt.Errorf("parsing snapshot %s: %w", path, err)
+	}
+	return &snap, nil
+}
+
+// Save replaces the account's snapshot. The file is written to a temporary
+// name and renamed, so a crash never leaves a truncated snapshot.
+func (s *Store) Save(account string, snap *Snapshot) error {
+	path, err := s.path(account)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	if err := s.write(path, data); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	return nil
+}
+
+// LoadPosture returns the account's last saved posture artifact, or nil if
+// none was saved. The bytes are opaque to the store.
+func (s *Store) LoadPosture(account string) ([]byte, error) {
+	path, err := s.sidecarPath(account, "posture")
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading posture: %w", err)
+	}
+	return data, nil
+}
+
+// SavePosture replaces the account's saved posture artifact, atomically like
+// Save.
+func (s *Store) SavePosture(account string, data []byte) error {
+	path, err := s.sidecarPath(account, "posture")
+	if err != nil {
+		return err
+	}
+	if err := s.write(path, data); err != nil {
+		return fmt.Errorf("writing posture: %w", err)
+	}
+	return nil
+}
+
+// LastRun returns when the account's last run started, or the zero time if
+// none was recorded.
+func (s *Store) LastRun(account string) (time.Time, error) {
+	path, err := s.sidecarPath(account, "lastrun")
+	if err != nil {
+		return time.Time{}, err
+	}
+	data, err := s.read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading last run: %w", err)
+	}
+	var run lastRun
+	if err := json.Unmarshal(data, &run); err != nil {
+		return time.Time{}, fmt.Errorf("parsing last run %s: %w", path, err)
+	}
+	return run.StartedAt, nil
+}
+
+// SaveLastRun records when the account's current run started, atomically
+// like Save.
+func (s *Store) SaveLastRun(account string, startedAt time.Time) error {
+	path, err := s.sidecarPath(account, "lastrun")
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(lastRun{StartedAt: startedAt.UTC()})
+	if err != nil {
+		return err
+	}
+	if err := s.write(path, data); err != nil {
+		return fmt.Errorf("writing last run: %w", err)
+	}
+	return nil
+}
+
+// MetricDay is one day's coverage metrics, keyed by their dotted path in
+// github.json, as the day's last complete run saw them.
+type MetricDay struct {
+	Date    string         `json:"date"` // YYYY-MM-DD, UTC
+	Metrics map[string]int `json:"metrics"`
+}
+
+// LoadHistory returns the account's daily metric history, oldest first, or
+// nil if none was saved.
+func (s *Store) LoadHistory(account string) ([]MetricDay, error) {
+	path, err := s.sidecarPath(account, "history")
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading history: %w", err)
+	}
+	var days []MetricDay
+	if err := json.Unmarshal(data, &days); err != nil {
+		return nil, fmt.Errorf("parsing history %s: %w", path, err)
+	}
+	return days, nil
+}
+
+// SaveHistory replaces the account's daily metric history, atomically like
+// Save.
+func (s *Store) SaveHistory(account string, days []MetricDay) error {
+	path, err := s.sidecarPath(account, "history")
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(days)
+	if err != nil {
+		return err
+	}
+	if err := s.write(path, data); err != nil {
+		return fmt.Errorf("writing history: %w", err)
+	}
+	return nil
+}
+
+// Incident is an incident the collector opened and hasn't yet resolved,
+// kept so a later run can resolve it once its condition clears.
+type Incident struct {
+	// Baseline is the metric's value before the breach, 0 for conditions
+	// without one.
+	Baseline int `json:"baseline,omitempty"`
+}
+
+// LoadIncidents returns the account's open incidents keyed by alert
+// threshold, or nil if none were saved.
+func (s *Store) LoadIncidents(account string) (map[string]Incident, error) {
+	path, err := s.sidecarPath(account, "incidents")
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.read(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading incidents: %w", err)
+	}
+	var incidents map[string]Incident
+	if err := json.Unmarshal(data, &incidents); err != nil {
+		return nil, fmt.Errorf("parsing incidents %s: %w", path, err)
+	}
+	return incidents, nil
+}
+
+// SaveIncidents replaces the account's open incidents, atomically like Save.
+func (s *Store) SaveIncidents(account string, incidents map[string]Incident) error {
+	path, err := s.sidecarPath(account, "incidents")
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(incidents)
+	if err != nil {
+		return err
+	}
+	if err := s.write(path, data); err != nil {
+		return fmt.Errorf("writing incidents: %w", err)
+	}
+	return nil
+}
+
+// lastRun is the file SaveLastRun writes.
+type lastRun struct {
+	StartedAt time.Time `json:"started_at"`
+}
+
+// sidecarPath returns an account's file of the given kind, beside its
+// snapshot.
+func (s *Store) sidecarPath(account, kind string) (string, error) {
+	path, err := s.path(account)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(path, ".json") + "." + kind + ".json", nil
+}
+
+// read returns path's contents, decrypted. Contents encrypted with a
+// previous key, or plaintext in an encrypting store, are rewritten with the
+// current key; failing to is harmless, as the next save rewrites them too.
+func (s *Store) read(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	plain, stale, err := s.open(path, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.keys) > 0 && !bytes.HasPrefix(data, encryptedMagic) {
+		s.unencrypted = append(s.unencrypted, filepath.Base(path))
+	}
+	if stale {
+		_ = s.write(path, plain)
+	}
+	return plain, nil
+}
+
+// Unencrypted returns the names of the files an encrypting store has read
+// unencrypted (and rewritten encrypted) so far. Expected once after turning
+// encryption on; later, such a file was written by something else.
+func (s *Store) Unencrypted() []string {
+	return s.unencrypted
+}
+
+// write replaces path with data, encrypted when the store has a key, via a
+// temporary file in the store directory.
+func (s *Store) write(path string, data []byte) error {
+	data, err := s.seal(path, data)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,156 @@
+package state
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestStore_RoundTrip(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "state"))
+	if err != nil {
+		t.Fatalf("Open() error: %v", err)
+	}
+
+	snap, err := store.Load("Test-Org")
+	if err != nil || snap != nil {
+		t.Fatalf("Load() before Save = %v, %v; want nil, nil", snap, err)
+	}
+
+	want := &Snapshot{
+		CollectedAt:  time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Repositories: []Repository{{Name: "test-org/api"}, {Name: "test-org/old", Archived: true}},
+	}
+	if err := store.Save("Test-Org", want); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	got, err := store.Load("test-org")
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if !got.CollectedAt.Equal(want.CollectedAt) || len(got.Repositories) != 2 || !got.Repositories[1].Archived {
+		t.Errorf("Load() = %+v, want %+v", got, want)
+	}
+
+	info, err := os.Stat(filepath.Join(store.dir, "test-org.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Windows has no mode bits; the directory's ACL governs access.
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		t.Errorf("snapshot mode = %v, want owner-only", info.Mode().Perm())
+	}
+}
+
+func TestStore_RejectsUnsafeAccount(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, account := range []string{"", "../etc", "a/b"} {
+		if _, err := store.Load(account); err == nil {
+			t.Errorf("Load(%q) should fail", account)
+		}
+	}
+}
+
+func TestStore_ReservedAccountNames(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save("CON", &Snapshot{}); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(store.dir, "_con.json")); err != nil {
+		t.Errorf("reserved name not prefixed: %v", err)
+	}
+	if snap, err := store.Load("con"); err != nil || snap == nil {
+		t.Errorf("Load() = %v, %v", snap, err)
+	}
+	// Names merely starting with one are left alone.
+	if path, _ := store.path("console"); filepath.Base(path) != "console.json" {
+		t.Errorf("path(console) = %s", path)
+	}
+}
+
+func TestStore_PostureRoundTrip(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := store.LoadPosture("test-org"); err != nil || data != nil {
+		t.Fatalf("LoadPosture() before SavePosture = %q, %v; want nil, nil", data, err)
+	}
+	if err := store.SavePosture("Test-Org", []byte(`{"schema_version":"1.0.0"}`)); err != nil {
+		t.Fatalf("SavePosture() error: %v", err)
+	}
+	data, err := store.LoadPosture("test-org")
+	if err != nil || string(data) != `{"schema_version":"1.0.0"}` {
+		t.Errorf("LoadPosture() = %q, %v", data, err)
+	}
+	// The posture sits beside the snapshot without replacing it.
+	if snap, err := store.Load("test-org"); err != nil || snap != nil {
+		t.Errorf("Load() after SavePosture = %v, %v; want nil, nil", snap, err)
+	}
+}
+
+func TestStore_LastRunRoundTrip(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last, err := store.LastRun("test-org"); err != nil || !last.IsZero() {
+		t.Fatalf("LastRun() before SaveLastRun = %v, %v; want zero, nil", last, err)
+	}
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.SaveLastRun("test-org", started); err != nil {
+		t.Fatalf("SaveLastRun() error: %v", err)
+	}
+	if last, err := store.LastRun("Test-Org"); err != nil || !last.Equal(started) {
+		t.Errorf("LastRun() = %v, %v; want %v", last, err, started)
+	}
+}
+
+func TestStore_HistoryRoundTrip(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if days, err := store.LoadHistory("test-org"); err != nil || days != nil {
+		t.Fatalf("LoadHistory() before SaveHistory = %v, %v; want nil, nil", days, err)
+	}
+	days := []MetricDay{
+		{Date: "2026-03-01", Metrics: map[string]int{"posture.branch_protection_coverage": 88}},
+		{Date: "2026-03-02", Metrics: map[string]int{"posture.branch_protection_coverage": 92}},
+	}
+	if err := store.SaveHistory("test-org", days); err != nil {
+		t.Fatalf("SaveHistory() error: %v", err)
+	}
+	if got, err := store.LoadHistory("Test-Org"); err != nil || !reflect.DeepEqual(got, days) {
+		t.Errorf("LoadHistory() = %v, %v; want %v", got, err, days)
+	}
+}
+
+func TestStore_IncidentsRoundTrip(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if incidents, err := store.LoadIncidents("test-org"); err != nil || incidents != nil {
+		t.Fatalf("LoadIncidents() before SaveIncidents = %v, %v; want nil, nil", incidents, err)
+	}
+	incidents := map[string]Incident{
+		"posture.branch_protection_coverage": {Baseline: 90},
+		"access_control.two_factor_required": {},
+	}
+	if err := store.SaveIncidents("test-org", incidents); err != nil {
+		t.Fatalf("SaveIncidents() error: %v", err)
+	}
+	if got, err := store.LoadIncidents("Test-Org"); err != nil || !reflect.DeepEqual(got, incidents) {
+		t.Errorf("LoadIncidents() = %v, %v; want %v", got, err, incidents)
+	}
+}
@@ -0,0 +1,189 @@
+// Package batch runs posture collections for many GitHub organizations from
+// one process, as a managed service provider monitoring several customers
+// would. Collections run with bounded concurrency, share one request budget
+// and the organization-independent lookups (see github.Cache), and are
+// consolidated into a single report.
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/locktivity/epack-collector-github/internal/collector"
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack/componentsdk"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+)
+
+// Posture is one organization's collected posture, the artifact a single
+// collection writes.
+type Posture = collector.OrgPosture
+
+// ErrDegraded is wrapped by a target's error when strict mode fails it for
+// missing permissions.
+var ErrDegraded = collector.ErrDegraded
+
+// DefaultConcurrency is the number of organizations collected at once when
+// Options.Concurrency is unset.
+const DefaultConcurrency = 4
+
+// Target is one organization to collect.
+type Target struct {
+	// Name identifies the target in the report. Defaults to the configured
+	// organization.
+	Name string
+	// Config holds the same keys as the collector's component config
+	// (organization, profile, scopes, ...).
+	Config map[string]any
+	// Secrets holds GITHUB_TOKEN, GITHUB_APP_PRIVATE_KEY and SIGNING_KEY.
+	Secrets map[string]string
+}
+
+// Options controls a batch run.
+type Options struct {
+	// Level is the collection level for every target. Defaults to trust.
+	Level componentsdk.Level
+	// Concurrency bounds how many organizations are collected at once.
+	// Defaults to DefaultConcurrency.
+	Concurrency int
+	// MaxRequestsPerSecond caps GitHub API requests across all targets
+	// combined (0 = unlimited). A target's own max_requests_per_second still
+	// applies on top.
+	MaxRequestsPerSecond float64
+	// OnResult, when set, is called as each target finishes. Calls are
+	// serialized.
+	OnResult func(Result)
+}
+
+// Result is the outcome of one target.
+type Result struct {
+	Target  string   `json:"target"`
+	Posture *Posture `json:"posture,omitempty"`
+	Error   string   `json:"error,omitempty"`
+	// Err is the underlying error, e.g. one wrapping ErrDegraded
+	// when a strict-mode target lacks permissions.
+	Err error `json:"-"`
+}
+
+// Report consolidates the results of a batch run.
+type Report struct {
+	Level     componentsdk.Level `json:"level"`
+	Results   []Result           `json:"results"`
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+}
+
+// collect runs one collection; replaced in tests.
+var collect = func(ctx context.Context, config collector.Config, level componentsdk.Level) (*collector.OrgPosture, error) {
+	c, err := collector.New(config)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = c.Close() }()
+	posture, err := c.Collect(ctx, level)
+	if err != nil {
+		return nil, err
+	}
+	c.Redact(posture)
+	return posture, nil
+}
+
+// Run collects every target and returns their results in target order. A
+// target's failure is recorded in its Result and does not stop the others;
+// Run itself fails only on invalid options or targets, or when ctx is
+// canceled before all targets finish.
+func Run(ctx context.Context, targets []Target, opts Options) (*Report, error) {
+	if opts.Level == "" {
+		opts.Level = componentsdk.LevelTrust
+	}
+	if opts.Concurrency == 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	if opts.Concurrency < 0 {
+		return nil, fmt.Errorf("concurrency must be positive, got %d", opts.Concurrency)
+	}
+	if opts.MaxRequestsPerSecond < 0 {
+		return nil, fmt.Errorf("max_requests_per_second must not be negative, got %v", opts.MaxRequestsPerSecond)
+	}
+
+	names := make([]string, len(targets))
+	seen := make(map[string]bool, len(targets))
+	for i, t := range targets {
+		name := t.Name
+		if name == "" {
+			name, _ = t.Config["organization"].(string)
+		}
+		if name == "" {
+			return nil, fmt.Errorf("target %d: name or organization is required", i)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate target %q", name)
+		}
+		seen[name] = true
+		names[i] = name
+	}
+
+	var limiter *rate.Limiter
+	if opts.MaxRequestsPerSecond > 0 {
+		limiter = github.NewLimiter(opts.MaxRequestsPerSecond)
+	}
+
+	// Organizations under one App installation or token command share its
+	// tokens, and those on one instance its detection.
+	cache := github.NewCache()
+
+	report := &Report{Level: opts.Level, Results: make([]Result, len(targets))}
+	var mu sync.Mutex
+	g := new(errgroup.Group)
+	g.SetLimit(opts.Concurrency)
+	for i, t := range targets {
+		g.Go(func() error {
+			result := Result{Target: names[i]}
+			if err := ctx.Err(); err != nil {
+				result.Err = err
+			} else {
+				result.Posture, result.Err = runTarget(ctx, t, limiter, cache, opts.Level)
+			}
+			if result.Err != nil {
+				result.Error = result.Err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Results[i] = result
+			if result.Err == nil {
+				report.Succeeded++
+			} else {
+				report.Failed++
+			}
+			if opts.OnResult != nil {
+				opts.OnResult(result)
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// runTarget builds one target's config and collects it.
+func runTarget(ctx context.Context, t Target, limiter *rate.Limiter, cache *github.Cache, level componentsdk.Level) (*collector.OrgPosture, error) {
+	config, err := collector.ConfigFromMap(t.Config, func(name string) string {
+		return t.Secrets[name]
+	})
+	if err != nil {
+		return nil, err
+	}
+	if config.Organization == "" {
+		return nil, errors.New("organization is required")
+	}
+	config.RequestLimiter = limiter
+	config.SharedCache = cache
+	return collect(ctx, config, level)
+}
@@ -0,0 +1,207 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/locktivity/epack-collector-github/internal/collector"
+	"github.com/locktivity/epack-collector-github/internal/github"
+	"github.com/locktivity/epack/componentsdk"
+)
+
+// stubCollect replaces the collection for the duration of a test.
+func stubCollect(t *testing.T, fn func(ctx context.Context, config collector.Config, level componentsdk.Level) (*collector.OrgPosture, error)) {
+	t.Helper()
+	orig := collect
+	collect = fn
+	t.Cleanup(func() { collect = orig })
+}
+
+func target(org string) Target {
+	return Target{
+		Config:  map[string]any{"organization": org},
+		Secrets: map[string]string{"GITHUB_TOKEN": "token-" + org},
+	}
+}
+
+func TestRun_CollectsEachTargetInOrder(t *testing.T) {
+	stubCollect(t, func(ctx context.Context, config collector.Config, level componentsdk.Level) (*collector.OrgPosture, error) {
+		if config.GitHubToken != "token-"+config.Organization {
+			t.Errorf("%s: token = %q, want its own secret", config.Organization, config.GitHubToken)
+		}
+		if level != componentsdk.LevelAudit {
+			t.Errorf("level = %q, want audit", level)
+		}
+		if config.Organization == "broken" {
+			return nil, errors.New("boom")
+		}
+		return &collector.OrgPosture{Organization: config.Organization}, nil
+	})
+
+	named := target("acme")
+	named.Name = "customer-a"
+	report, err := Run(context.Background(), []Target{named, target("broken"), target("globex")}, Options{Level: componentsdk.LevelAudit})
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if report.Succeeded != 2 || report.Failed != 1 {
+		t.Errorf("succeeded/failed = %d/%d, want 2/1", report.Succeeded, report.Failed)
+	}
+	want := []string{"customer-a", "broken", "globex"}
+	for i, r := range report.Results {
+		if r.Target != want[i] {
+			t.Errorf("Results[%d].Target = %q, want %q", i, r.Target, want[i])
+		}
+	}
+	if r := report.Results[0]; r.Posture == nil || r.Posture.Organization != "acme" {
+		t.Errorf("Results[0].Posture = %+v, want acme posture", r.Posture)
+	}
+	if r := report.Results[1]; r.Posture != nil || r.Error != "boom" {
+		t.Errorf("Results[1] = %+v, want error boom without posture", r)
+	}
+}
+
+func TestRun_BoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	stubCollect(t, func(ctx context.Context, config collector.Config, level componentsdk.Level) (*collector.OrgPosture, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return &collector.OrgPosture{}, nil
+	})
+
+	var targets []Target
+	for _, org := range []string{"a", "b", "c", "d", "e", "f"} {
+		targets = append(targets, target(org))
+	}
+	var mu sync.Mutex
+	var finished []string
+	report, err := Run(context.Background(), targets, Options{
+		Concurrency: 2,
+		OnResult: func(r Result) {
+			mu.Lock()
+			defer mu.Unlock()
+			finished = append(finished, r.Target)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", p)
+	}
+	if report.Succeeded != 6 || len(finished) != 6 {
+		t.Errorf("succeeded = %d, OnResult calls = %d, want 6 each", report.Succeeded, len(finished))
+	}
+}
+
+func TestRun_SharesRequestLimiter(t *testing.T) {
+	var mu sync.Mutex
+	limiters := map[any]bool{}
+	stubCollect(t, func(ctx context.Context, config collector.Config, level componentsdk.Level) (*collector.OrgPosture, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		limiters[config.RequestLimiter] = true
+		return &collector.OrgPosture{}, nil
+	})
+
+	if _, err := Run(context.Background(), []Target{target("a"), target("b")}, Options{MaxRequestsPerSecond: 10}); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if len(limiters) != 1 {
+		t.Fatalf("targets used %d limiters, want one shared", len(limiters))
+	}
+	for l := range limiters {
+		if l == nil {
+			t.Error("RequestLimiter unset with MaxRequestsPerSecond configured")
+		}
+	}
+}
+
+func TestRun_SharesCache(t *testing.T) {
+	var mu sync.Mutex
+	caches := map[*github.Cache]bool{}
+	stubCollect(t, func(ctx context.Context, config collector.Config, level componentsdk.Level) (*collector.OrgPosture, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		caches[config.SharedCache] = true
+		return &collector.OrgPosture{}, nil
+	})
+
+	if _, err := Run(context.Background(), []Target{target("a"), target("b")}, Options{}); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if len(caches) != 1 || caches[nil] {
+		t.Errorf("targets used caches %v, want one shared", caches)
+	}
+}
+
+func TestRun_InvalidInput(t *testing.T) {
+	stubCollect(t, func(ctx context.Context, config collector.Config, level componentsdk.Level) (*collector.OrgPosture, error) {
+		t.Error("collect called for invalid input")
+		return nil, nil
+	})
+
+	tests := []struct {
+		name    string
+		targets []Target
+		opts    Options
+		want    string
+	}{
+		{"duplicate", []Target{target("a"), target("a")}, Options{}, "duplicate target"},
+		{"unnamed", []Target{{Config: map[string]any{}}}, Options{}, "name or organization is required"},
+		{"concurrency", []Target{target("a")}, Options{Concurrency: -1}, "concurrency"},
+		{"rate", []Target{target("a")}, Options{MaxRequestsPerSecond: -1}, "max_requests_per_second"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Run(context.Background(), tt.targets, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Run() error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_TargetConfigErrors(t *testing.T) {
+	stubCollect(t, func(ctx context.Context, config collector.Config, level componentsdk.Level) (*collector.OrgPosture, error) {
+		return &collector.OrgPosture{}, nil
+	})
+
+	bad := Target{Name: "bad", Config: map[string]any{"organization": "bad", "profile": "no-such-profile"}}
+	report, err := Run(context.Background(), []Target{bad, target("good")}, Options{})
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if report.Results[0].Err == nil || report.Results[1].Err != nil {
+		t.Errorf("results = %+v, want only the bad profile to fail", report.Results)
+	}
+}
+
+func TestRun_Canceled(t *testing.T) {
+	stubCollect(t, func(ctx context.Context, config collector.Config, level componentsdk.Level) (*collector.OrgPosture, error) {
+		t.Error("collect called after cancellation")
+		return nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	report, err := Run(ctx, []Target{target("a")}, Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run() error = %v, want context.Canceled", err)
+	}
+	if report.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", report.Failed)
+	}
+}